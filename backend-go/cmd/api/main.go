@@ -2,6 +2,7 @@ package main
 
 import (
 	"context"
+	"errors"
 	"log/slog"
 	"os"
 	"time"
@@ -14,8 +15,26 @@ import (
 	adminhttp "erpwms/backend-go/internal/modules/admin/http"
 	adminsvc "erpwms/backend-go/internal/modules/admin/service"
 	autotesthttp "erpwms/backend-go/internal/modules/autotest/http"
+	mdhttp "erpwms/backend-go/internal/modules/wms_masterdata/http"
+	mdsvc "erpwms/backend-go/internal/modules/wms_masterdata/service"
+	inboundhttp "erpwms/backend-go/internal/modules/wms_inbound/http"
+	inboundsvc "erpwms/backend-go/internal/modules/wms_inbound/service"
+	counthttp "erpwms/backend-go/internal/modules/wms_count/http"
+	countsvc "erpwms/backend-go/internal/modules/wms_count/service"
+	outboundhttp "erpwms/backend-go/internal/modules/wms_outbound/http"
+	outboundsvc "erpwms/backend-go/internal/modules/wms_outbound/service"
+	huhttp "erpwms/backend-go/internal/modules/wms_hu/http"
+	husvc "erpwms/backend-go/internal/modules/wms_hu/service"
+	returnshttp "erpwms/backend-go/internal/modules/wms_returns/http"
+	returnssvc "erpwms/backend-go/internal/modules/wms_returns/service"
+	scanhttp "erpwms/backend-go/internal/modules/wms_scan/http"
+	scansvc "erpwms/backend-go/internal/modules/wms_scan/service"
+	shippinghttp "erpwms/backend-go/internal/modules/wms_shipping/http"
+	shippingsvc "erpwms/backend-go/internal/modules/wms_shipping/service"
 	stockhttp "erpwms/backend-go/internal/modules/wms_stock/http"
 	stocksvc "erpwms/backend-go/internal/modules/wms_stock/service"
+	transferhttp "erpwms/backend-go/internal/modules/wms_transfer/http"
+	transfersvc "erpwms/backend-go/internal/modules/wms_transfer/service"
 
 	"github.com/gin-gonic/gin"
 	"github.com/jackc/pgx/v5/pgxpool"
@@ -55,6 +74,15 @@ func main() {
 		Argon:        crypto.DefaultArgon2Params(),
 	}
 	stockSvc := stocksvc.StockService{DB: db, Queries: q}
+	mdSvc := mdsvc.MasterdataService{DB: db, Queries: q}
+	inboundSvc := inboundsvc.InboundService{DB: db, Queries: q, Stock: stockSvc}
+	outboundSvc := outboundsvc.OutboundService{DB: db, Queries: q, Stock: stockSvc}
+	shippingSvc := shippingsvc.ShippingService{DB: db, Queries: q, Stock: stockSvc}
+	countSvc := countsvc.CountService{DB: db, Queries: q, Stock: stockSvc}
+	transferSvc := transfersvc.TransferService{DB: db, Queries: q, Stock: stockSvc}
+	returnsSvc := returnssvc.ReturnsService{DB: db, Queries: q, Stock: stockSvc}
+	scanSvc := scansvc.ScanService{Queries: q, Stock: stockSvc}
+	huSvc := husvc.HandlingUnitService{DB: db, Queries: q, Stock: stockSvc, CompanyPrefix: cfg.GS1CompanyPrefix, ExtensionDigit: cfg.SSCCExtensionDigit}
 
 	r := gin.New()
 	r.LoadHTMLGlob("web/templates/**/*.html")
@@ -71,8 +99,6 @@ func main() {
 	ap := adminhttp.AdminPortal{DB: db}
 	
 	ap.RegisterRoutes(r)
-r.GET("/admin/users", ap.UsersPage)
-	r.POST("/admin/users/role", ap.SetUserRole)
 
 r.GET("/health", func(c *gin.Context) {
 		ctx, cancel := context.WithTimeout(c.Request.Context(), 2*time.Second)
@@ -88,6 +114,25 @@ r.GET("/health", func(c *gin.Context) {
 		c.JSON(503, gin.H{"status": "degraded"})
 	})
 
+	// Drift between stock_balance and the ledger, as found by the last
+	// reconcile run; 503 while unfixed drift is outstanding.
+	r.GET("/health/stock", func(c *gin.Context) {
+		st, err := stockSvc.LastReconcile(c.Request.Context())
+		if errors.Is(err, stocksvc.ErrNotFound) {
+			c.JSON(200, gin.H{"status": "unknown"})
+			return
+		}
+		if err != nil {
+			c.JSON(503, gin.H{"status": "degraded"})
+			return
+		}
+		if st.DriftCount > 0 && !st.Fixed {
+			c.JSON(503, gin.H{"status": "drift", "last_run": st})
+			return
+		}
+		c.JSON(200, gin.H{"status": "ok", "last_run": st})
+	})
+
 	ah := adminhttp.AuthHandlers{Service: authSvc, CookieSecure: cfg.CookieSecure}
 	r.GET("/login", func(c *gin.Context) { c.HTML(200, "pages/login.html", nil) })
 	r.POST("/login", ah.Login)
@@ -100,6 +145,16 @@ r.GET("/health", func(c *gin.Context) {
 		c.HTML(200, "pages/stock.html", gin.H{"Rows": rows})
 	})
 
+	r.GET("/stock/items/:id/history", func(c *gin.Context) {
+		page, err := stockSvc.ListLedger(c.Request.Context(), stocksvc.LedgerFilter{
+			ItemID:     c.Param("id"),
+			LocationID: c.Query("location_id"),
+			Cursor:     c.Query("cursor"),
+			Limit:      100,
+		})
+		c.HTML(200, "pages/item_history.html", gin.H{"ItemID": c.Param("id"), "LocationID": c.Query("location_id"), "Page": page, "Err": err})
+	})
+
 	// Autotest GUI + run endpoint
 	th := autotesthttp.Handlers{
 		Enabled: cfg.AutotestEnabled,
@@ -119,7 +174,186 @@ r.GET("/health", func(c *gin.Context) {
 
 	sh := stockhttp.StockHandlers{Queries: q, Service: stockSvc}
 	authed.GET("stock/balances", middleware.RequirePermission("wms.stock.read"), sh.ListBalances)
+	authed.GET("stock/ledger", middleware.RequirePermission("wms.stock.read"), sh.ListLedger)
 	authed.POST("stock/moves", middleware.RequirePermission("wms.stock.move"), sh.Move)
+	authed.POST("stock/moves/:move_id/reverse", middleware.RequirePermission("wms.stock.reverse"), sh.Reverse)
+	authed.POST("stock/move-batches", middleware.RequirePermission("wms.stock.move"), sh.MoveBatch)
+	authed.POST("stock/receipts", middleware.RequirePermission("wms.stock.receive"), sh.Receive)
+	authed.POST("stock/issues", middleware.RequirePermission("wms.stock.issue"), sh.Issue)
+	authed.POST("stock/adjustments", middleware.RequirePermission("wms.stock.adjust"), sh.Adjust)
+	authed.POST("stock/status-changes", middleware.RequirePermission("wms.stock.status"), sh.ChangeStatus)
+	authed.GET("stock/utilization", middleware.RequirePermission("wms.stock.read"), sh.ZoneUtilization)
+	authed.GET("stock/rollup", middleware.RequirePermission("wms.stock.read"), sh.Rollup)
+	authed.GET("locations/tree", middleware.RequirePermission("wms.stock.read"), sh.LocationTree)
+	stockAlloc := middleware.RequirePermission("wms.stock.allocate")
+	authed.GET("stock/allocations", middleware.RequirePermission("wms.stock.read"), sh.ListAllocations)
+	authed.POST("stock/allocations", stockAlloc, sh.Allocate)
+	authed.DELETE("stock/allocations/:id", stockAlloc, sh.ReleaseAllocation)
+	authed.POST("stock/allocations/:id/transfer", stockAlloc, sh.TransferAllocation)
+	putawayManage := middleware.RequirePermission("wms.putaway.manage")
+	putawayExec := middleware.RequirePermission("wms.putaway.execute")
+	authed.POST("putaway/suggest", middleware.RequirePermission("wms.stock.read"), sh.SuggestPutaway)
+	authed.GET("putaway/rules", middleware.RequirePermission("wms.stock.read"), sh.ListPutawayRules)
+	authed.POST("putaway/rules", putawayManage, sh.CreatePutawayRule)
+	authed.PUT("putaway/rules/:id", putawayManage, sh.UpdatePutawayRule)
+	authed.POST("putaway/rules/:id/deactivate", putawayManage, sh.DeactivatePutawayRule)
+	authed.GET("putaway/tasks", middleware.RequirePermission("wms.stock.read"), sh.ListPutawayTasks)
+	authed.POST("putaway/tasks", putawayExec, sh.CreatePutawayTask)
+	authed.POST("putaway/tasks/:id/confirm", putawayExec, sh.ConfirmPutawayTask)
+	authed.POST("putaway/tasks/:id/cancel", putawayExec, sh.CancelPutawayTask)
+	replenishManage := middleware.RequirePermission("wms.replenishment.manage")
+	replenishExec := middleware.RequirePermission("wms.replenishment.execute")
+	authed.GET("replenishment/settings", middleware.RequirePermission("wms.stock.read"), sh.ListReplenishmentSettings)
+	authed.POST("replenishment/settings", replenishManage, sh.CreateReplenishmentSetting)
+	authed.PUT("replenishment/settings/:id", replenishManage, sh.UpdateReplenishmentSetting)
+	authed.POST("replenishment/settings/:id/deactivate", replenishManage, sh.DeactivateReplenishmentSetting)
+	authed.POST("replenishment/plan", replenishManage, sh.PlanReplenishment)
+	authed.GET("replenishment/tasks", middleware.RequirePermission("wms.stock.read"), sh.ListReplenishmentTasks)
+	authed.POST("replenishment/tasks/:id/confirm", replenishExec, sh.ConfirmReplenishmentTask)
+	authed.POST("replenishment/tasks/:id/cancel", replenishExec, sh.CancelReplenishmentTask)
+
+	mh := mdhttp.MasterdataHandlers{Service: mdSvc}
+	mdRead := middleware.RequirePermission("wms.masterdata.read")
+	mdWrite := middleware.RequirePermission("wms.masterdata.write")
+	authed.GET("warehouses", mdRead, mh.ListWarehouses)
+	authed.POST("warehouses", mdWrite, mh.CreateWarehouse)
+	authed.GET("warehouses/:id", mdRead, mh.GetWarehouse)
+	authed.PUT("warehouses/:id", mdWrite, mh.UpdateWarehouse)
+	authed.POST("warehouses/:id/deactivate", mdWrite, mh.DeactivateWarehouse)
+	authed.GET("locations", mdRead, mh.ListLocations)
+	authed.POST("locations", mdWrite, mh.CreateLocation)
+	authed.GET("locations/:id", mdRead, mh.GetLocation)
+	authed.PUT("locations/:id", mdWrite, mh.UpdateLocation)
+	authed.POST("locations/:id/deactivate", mdWrite, mh.DeactivateLocation)
+	authed.GET("location-type-policies", mdRead, mh.ListLocationTypePolicies)
+	authed.PUT("location-type-policies/:type", mdWrite, mh.SetLocationTypePolicy)
+	authed.GET("items", mdRead, mh.ListItems)
+	authed.POST("items", mdWrite, mh.CreateItem)
+	authed.GET("items/:id", mdRead, mh.GetItem)
+	authed.PUT("items/:id", mdWrite, mh.UpdateItem)
+	authed.POST("items/:id/deactivate", mdWrite, mh.DeactivateItem)
+	authed.GET("items/:id/lots", mdRead, mh.ListLots)
+	authed.POST("items/:id/lots", mdWrite, mh.CreateLot)
+	authed.GET("items/:id/serials", mdRead, mh.ListSerials)
+	authed.POST("items/:id/serials", mdWrite, mh.RegisterSerial)
+	authed.GET("items/:id/uoms", mdRead, mh.ListItemUoms)
+	authed.PUT("items/:id/uoms/:uom", mdWrite, mh.SetItemUom)
+	authed.DELETE("items/:id/uoms/:uom", mdWrite, mh.DeleteItemUom)
+	authed.GET("items/:id/barcodes", mdRead, mh.ListItemBarcodes)
+	authed.POST("items/:id/barcodes", mdWrite, mh.AddItemBarcode)
+	authed.DELETE("items/:id/barcodes/:barcode", mdWrite, mh.DeleteItemBarcode)
+	authed.GET("uoms", mdRead, mh.ListUoms)
+	authed.PUT("uoms/:code", mdWrite, mh.SaveUom)
+	// Booking clients need the catalog for their dropdowns, so reading it only
+	// takes stock read access.
+	authed.GET("reason-codes", middleware.RequirePermission("wms.stock.read"), mh.ListReasonCodes)
+	authed.POST("reason-codes", mdWrite, mh.CreateReasonCode)
+	authed.PUT("reason-codes/:code", mdWrite, mh.UpdateReasonCode)
+	authed.POST("reason-codes/:code/deactivate", mdWrite, mh.DeactivateReasonCode)
+
+	ih := inboundhttp.InboundHandlers{Service: inboundSvc}
+	inRead := middleware.RequirePermission("wms.inbound.read")
+	inWrite := middleware.RequirePermission("wms.inbound.write")
+	authed.GET("suppliers", inRead, ih.ListSuppliers)
+	authed.POST("suppliers", inWrite, ih.CreateSupplier)
+	authed.PUT("suppliers/:id", inWrite, ih.UpdateSupplier)
+	authed.POST("suppliers/:id/deactivate", inWrite, ih.DeactivateSupplier)
+	authed.GET("purchase-orders", inRead, ih.ListPurchaseOrders)
+	authed.POST("purchase-orders", inWrite, ih.CreatePurchaseOrder)
+	authed.GET("purchase-orders/:id", inRead, ih.GetPurchaseOrder)
+	authed.POST("purchase-orders/:id/close", inWrite, ih.ClosePurchaseOrder)
+	authed.POST("purchase-orders/:id/cancel", inWrite, ih.CancelPurchaseOrder)
+	authed.GET("purchase-orders/:id/asns", inRead, ih.ListAsns)
+	authed.POST("asns", inWrite, ih.CreateAsn)
+	authed.GET("asns/:id", inRead, ih.GetAsn)
+	authed.POST("inbound/receipts", middleware.RequirePermission("wms.inbound.receive"), ih.Receive)
+
+	oh := outboundhttp.OutboundHandlers{Service: outboundSvc}
+	outRead := middleware.RequirePermission("wms.outbound.read")
+	orderWrite := middleware.RequirePermission("sales.order.create")
+	outWave := middleware.RequirePermission("wms.outbound.wave")
+	authed.GET("customers", outRead, oh.ListCustomers)
+	authed.POST("customers", orderWrite, oh.CreateCustomer)
+	authed.PUT("customers/:id", orderWrite, oh.UpdateCustomer)
+	authed.POST("customers/:id/deactivate", orderWrite, oh.DeactivateCustomer)
+	authed.GET("orders", outRead, oh.ListSalesOrders)
+	authed.POST("orders", orderWrite, oh.CreateSalesOrder)
+	authed.GET("orders/:id", outRead, oh.GetSalesOrder)
+	authed.POST("orders/:id/allocate", middleware.RequirePermission("sales.order.allocate"), oh.AllocateSalesOrder)
+	authed.POST("orders/:id/cancel", orderWrite, oh.CancelSalesOrder)
+	authed.GET("waves", outRead, oh.ListWaves)
+	authed.POST("waves", outWave, oh.CreateWaves)
+	authed.GET("waves/:id", outRead, oh.GetWave)
+	authed.GET("waves/:id/pick-list", outRead, oh.PickList)
+	authed.POST("waves/:id/cancel", outWave, oh.CancelWave)
+	authed.POST("pick-tasks/:id/confirm", middleware.RequirePermission("wms.outbound.pick"), oh.ConfirmPick)
+
+	shh := shippinghttp.ShippingHandlers{Service: shippingSvc}
+	shipRead := middleware.RequirePermission("wms.shipping.read")
+	shipPack := middleware.RequirePermission("wms.shipping.pack")
+	authed.GET("shipments", shipRead, shh.ListShipments)
+	authed.POST("shipments", shipPack, shh.CreateShipment)
+	authed.GET("shipments/:id", shipRead, shh.GetShipment)
+	authed.GET("shipments/:id/ddt", shipRead, shh.DeliveryNote)
+	authed.POST("shipments/:id/cartons", shipPack, shh.AddCarton)
+	authed.POST("shipments/:id/cartons/:carton_no/lines", shipPack, shh.Pack)
+	authed.DELETE("shipments/:id/lines/:line_id", shipPack, shh.Unpack)
+	authed.POST("shipments/:id/cancel", shipPack, shh.CancelShipment)
+	authed.POST("shipments/:id/confirm", middleware.RequirePermission("wms.shipping.confirm"), shh.ConfirmShipment)
+
+	ch := counthttp.CountHandlers{Service: countSvc}
+	countManage := middleware.RequirePermission("wms.count.manage")
+	countExec := middleware.RequirePermission("wms.count.execute")
+	countApprove := middleware.RequirePermission("wms.count.approve")
+	authed.GET("count-sessions", countExec, ch.ListSessions)
+	authed.POST("count-sessions", countManage, ch.CreateSession)
+	authed.GET("count-sessions/:id", countExec, ch.GetSession)
+	authed.GET("count-sessions/:id/tasks", countExec, ch.ListTasks)
+	authed.POST("count-sessions/:id/tasks", countExec, ch.AddTask)
+	authed.GET("count-sessions/:id/variances", countApprove, ch.ListVariances)
+	authed.POST("count-sessions/:id/approve", countApprove, ch.Approve)
+	authed.POST("count-sessions/:id/cancel", countManage, ch.CancelSession)
+	authed.POST("count-tasks/:id/count", countExec, ch.SubmitCount)
+
+	trh := transferhttp.TransferHandlers{Service: transferSvc}
+	transferRead := middleware.RequirePermission("wms.transfer.read")
+	transferWrite := middleware.RequirePermission("wms.transfer.write")
+	authed.GET("transfer-orders", transferRead, trh.ListTransferOrders)
+	authed.POST("transfer-orders", transferWrite, trh.CreateTransferOrder)
+	authed.GET("transfer-orders/:id", transferRead, trh.GetTransferOrder)
+	authed.POST("transfer-orders/:id/ship", middleware.RequirePermission("wms.transfer.ship"), trh.Ship)
+	authed.POST("transfer-orders/:id/receive", middleware.RequirePermission("wms.transfer.receive"), trh.Receive)
+	authed.POST("transfer-orders/:id/close", middleware.RequirePermission("wms.transfer.receive"), trh.Close)
+	authed.POST("transfer-orders/:id/cancel", transferWrite, trh.CancelTransferOrder)
+
+	rh := returnshttp.ReturnsHandlers{Service: returnsSvc}
+	returnsRead := middleware.RequirePermission("wms.returns.read")
+	returnsAuthorize := middleware.RequirePermission("wms.returns.authorize")
+	returnsInspect := middleware.RequirePermission("wms.returns.inspect")
+	authed.GET("return-reasons", returnsRead, rh.ListReasons)
+	authed.GET("rmas", returnsRead, rh.ListRmas)
+	authed.POST("rmas", returnsAuthorize, rh.Authorize)
+	authed.GET("rmas/report", returnsRead, rh.Report)
+	authed.GET("rmas/:id", returnsRead, rh.GetRma)
+	authed.POST("rmas/:id/receive", middleware.RequirePermission("wms.returns.receive"), rh.Receive)
+	authed.POST("rmas/:id/inspect", returnsInspect, rh.Inspect)
+	authed.POST("rmas/:id/close", returnsInspect, rh.Close)
+	authed.POST("rmas/:id/cancel", returnsAuthorize, rh.Cancel)
+
+	huh := huhttp.HandlingUnitHandlers{Service: huSvc}
+	huRead := middleware.RequirePermission("wms.hu.read")
+	huWrite := middleware.RequirePermission("wms.hu.write")
+	authed.GET("handling-units", huRead, huh.List)
+	authed.POST("handling-units", huWrite, huh.Create)
+	authed.GET("handling-units/:id", huRead, huh.Get)
+	authed.POST("handling-units/:id/pack", huWrite, huh.Pack)
+	authed.POST("handling-units/:id/unpack", huWrite, huh.Unpack)
+	authed.POST("handling-units/:id/move", middleware.RequirePermission("wms.hu.move"), huh.Move)
+
+	// Resolving a scan only reads, so any client that may look at stock may
+	// use it.
+	sch := scanhttp.ScanHandlers{Service: scanSvc}
+	authed.POST("scan/resolve", middleware.RequirePermission("wms.stock.read"), sch.Resolve)
 
 	if err := r.Run(cfg.HTTPAddr); err != nil {
 		panic(err)
@@ -0,0 +1,54 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"log"
+	"os"
+	"time"
+
+	"erpwms/backend-go/internal/common/config"
+	sqlc "erpwms/backend-go/internal/db/sqlcgen"
+	stocksvc "erpwms/backend-go/internal/modules/wms_stock/service"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// reconcile compares stock_balance and the recent stock_balance_snapshots with
+// the balances implied by stock_ledger and prints the differences. It exits 2 when drift is found and not fixed.
+func main() {
+	fix := flag.Bool("fix", false, "rewrite drifted stock_balance rows from the ledger and drop stale snapshots")
+	format := flag.String("format", "json", "report format: json or csv")
+	flag.Parse()
+	if *format != "json" && *format != "csv" {
+		log.Fatalf("unknown format %q", *format)
+	}
+
+	cfg, _ := config.Load()
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Minute)
+	defer cancel()
+	db, err := pgxpool.New(ctx, cfg.DBURL)
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer db.Close()
+
+	svc := stocksvc.StockService{DB: db, Queries: sqlc.New(db)}
+	report, err := svc.Reconcile(ctx, *fix)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if *format == "csv" {
+		err = report.WriteCSV(os.Stdout)
+	} else {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		err = enc.Encode(report)
+	}
+	if err != nil {
+		log.Fatal(err)
+	}
+	if report.HasDrift() && !report.Fixed {
+		os.Exit(2)
+	}
+}
@@ -8,6 +8,7 @@ import (
 
 	"erpwms/backend-go/internal/common/config"
 	sqlc "erpwms/backend-go/internal/db/sqlcgen"
+	stocksvc "erpwms/backend-go/internal/modules/wms_stock/service"
 	"github.com/jackc/pgx/v5/pgtype"
 	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/nats-io/nats.go"
@@ -21,6 +22,17 @@ func main() {
 	nc, _ := nats.Connect(cfg.NATSURL)
 	backoff := time.Second
 
+	stockSvc := stocksvc.StockService{DB: db, Queries: q}
+	if cfg.StockSnapshotEvery > 0 {
+		go runStockSnapshots(context.Background(), stockSvc, cfg.StockSnapshotEvery, logger)
+	}
+	if cfg.StockReconcileEvery > 0 {
+		go runStockReconcile(context.Background(), stockSvc, cfg.StockReconcileEvery, logger)
+	}
+	if cfg.ReplenishEvery > 0 {
+		go runReplenishment(context.Background(), stockSvc, cfg.ReplenishEvery, logger)
+	}
+
 	for {
 		txCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 		tx, err := db.Begin(txCtx)
@@ -0,0 +1,34 @@
+package main
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	stocksvc "erpwms/backend-go/internal/modules/wms_stock/service"
+)
+
+// runStockReconcile checks stock_balance against the ledger every interval.
+// It only reports; repairs go through cmd/reconcile --fix.
+func runStockReconcile(ctx context.Context, svc stocksvc.StockService, every time.Duration, logger *slog.Logger) {
+	t := time.NewTicker(every)
+	defer t.Stop()
+	for {
+		jobCtx, cancel := context.WithTimeout(ctx, 10*time.Minute)
+		report, err := svc.Reconcile(jobCtx, false)
+		cancel()
+		switch {
+		case err != nil:
+			logger.Error("stock reconcile failed", "err", err)
+		case report.HasDrift():
+			logger.Warn("stock balance drift detected", "rows", len(report.Drift), "stale_snapshots", len(report.StaleSnapshots))
+		default:
+			logger.Info("stock reconcile clean")
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-t.C:
+		}
+	}
+}
@@ -0,0 +1,34 @@
+package main
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	stocksvc "erpwms/backend-go/internal/modules/wms_stock/service"
+	"github.com/google/uuid"
+)
+
+// runReplenishment plans replenishment of all pick faces every interval. The
+// tasks it creates reach the outbox and are published by the loop in main.
+func runReplenishment(ctx context.Context, svc stocksvc.StockService, every time.Duration, logger *slog.Logger) {
+	t := time.NewTicker(every)
+	defer t.Stop()
+	for {
+		jobCtx, cancel := context.WithTimeout(ctx, 10*time.Minute)
+		run, err := svc.PlanReplenishment(jobCtx, "", uuid.Nil)
+		cancel()
+		if err != nil {
+			logger.Error("replenishment planning failed", "err", err)
+		}
+		for _, s := range run.Shortages {
+			logger.Warn("replenishment short of reserve stock", "setting_id", s.SettingID, "item_id", s.ItemID, "location_id", s.LocationID, "qty", s.Qty)
+		}
+		logger.Info("replenishment planned", "settings", run.Settings, "tasks", len(run.Tasks))
+		select {
+		case <-ctx.Done():
+			return
+		case <-t.C:
+		}
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"os"
 	"strconv"
 	"strings"
+	"time"
 )
 
 type Config struct {
@@ -29,6 +30,11 @@ type Config struct {
 	RateLimitAPI         int
 	AutotestEnabled      bool
 	AutotestToken        string
+	StockSnapshotEvery   time.Duration
+	StockReconcileEvery  time.Duration
+	ReplenishEvery       time.Duration
+	GS1CompanyPrefix     string
+	SSCCExtensionDigit   int
 }
 
 func Load() (Config, error) {
@@ -77,6 +83,11 @@ func Load() (Config, error) {
 		RateLimitAPI:         getInt("RATE_LIMIT_API_PER_MIN", 120),
 		AutotestEnabled:      getBool("AUTOTEST_ENABLED", false),
 		AutotestToken:        os.Getenv("AUTOTEST_TOKEN"),
+		StockSnapshotEvery:   time.Duration(getInt("STOCK_SNAPSHOT_INTERVAL_HOURS", 24)) * time.Hour,
+		StockReconcileEvery:  time.Duration(getInt("STOCK_RECONCILE_INTERVAL_HOURS", 6)) * time.Hour,
+		ReplenishEvery:       time.Duration(getInt("REPLENISH_INTERVAL_MINUTES", 15)) * time.Minute,
+		GS1CompanyPrefix:     os.Getenv("GS1_COMPANY_PREFIX"),
+		SSCCExtensionDigit:   getInt("SSCC_EXTENSION_DIGIT", 0),
 	}
 
 	if cfg.Env == "prod" {
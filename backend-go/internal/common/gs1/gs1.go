@@ -0,0 +1,74 @@
+// Package gs1 implements the GS1 identifiers the warehouse prints and scans:
+// the mod-10 check digit shared by GTINs and SSCCs, SSCC-18 generation and
+// the application identifier (AI) element strings of GS1-128 and DataMatrix.
+package gs1
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// ErrInvalid is returned for identifiers or inputs that break the GS1 rules.
+var ErrInvalid = errors.New("invalid gs1 identifier")
+
+// CheckDigit computes the GS1 mod-10 check digit of data, the identifier
+// without its last digit. Digits are weighted 3 and 1 alternately starting
+// from the right.
+func CheckDigit(data string) (int, error) {
+	if data == "" {
+		return 0, fmt.Errorf("%w: empty", ErrInvalid)
+	}
+	sum := 0
+	for i := 0; i < len(data); i++ {
+		c := data[len(data)-1-i]
+		if c < '0' || c > '9' {
+			return 0, fmt.Errorf("%w: %q is not numeric", ErrInvalid, data)
+		}
+		d := int(c - '0')
+		if i%2 == 0 {
+			d *= 3
+		}
+		sum += d
+	}
+	return (10 - sum%10) % 10, nil
+}
+
+// Valid reports whether code is all digits and ends in its check digit.
+func Valid(code string) bool {
+	if len(code) < 2 {
+		return false
+	}
+	want, err := CheckDigit(code[:len(code)-1])
+	return err == nil && int(code[len(code)-1]-'0') == want
+}
+
+// SSCC builds an 18-digit Serial Shipping Container Code from the extension
+// digit, the GS1 company prefix (7 to 10 digits) and a serial reference that
+// must fit the digits the prefix leaves.
+func SSCC(extension int, companyPrefix string, serial int64) (string, error) {
+	if extension < 0 || extension > 9 {
+		return "", fmt.Errorf("%w: extension digit must be 0-9", ErrInvalid)
+	}
+	if len(companyPrefix) < 7 || len(companyPrefix) > 10 || strings.Trim(companyPrefix, "0123456789") != "" {
+		return "", fmt.Errorf("%w: company prefix must be 7 to 10 digits", ErrInvalid)
+	}
+	width := 16 - len(companyPrefix)
+	ref := strconv.FormatInt(serial, 10)
+	if serial < 0 || len(ref) > width {
+		return "", fmt.Errorf("%w: serial reference %d does not fit %d digits", ErrInvalid, serial, width)
+	}
+	data := strconv.Itoa(extension) + companyPrefix + strings.Repeat("0", width-len(ref)) + ref
+	check, err := CheckDigit(data)
+	if err != nil {
+		return "", err
+	}
+	return data + strconv.Itoa(check), nil
+}
+
+// ValidSSCC reports whether code is an 18-digit SSCC with a correct check
+// digit.
+func ValidSSCC(code string) bool {
+	return len(code) == 18 && Valid(code)
+}
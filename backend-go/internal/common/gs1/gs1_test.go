@@ -0,0 +1,45 @@
+package gs1
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestCheckDigit(t *testing.T) {
+	for data, want := range map[string]int{
+		"10614141123456789": 7, // GS1 general specification SSCC example
+		"400638133393":      1, // GTIN-13 4006381333931
+		"0":                 0,
+	} {
+		got, err := CheckDigit(data)
+		if err != nil || got != want {
+			t.Fatalf("%s: got %d %v, want %d", data, got, err, want)
+		}
+	}
+	if _, err := CheckDigit("12a4"); !errors.Is(err, ErrInvalid) {
+		t.Fatalf("got %v", err)
+	}
+}
+
+func TestSSCC(t *testing.T) {
+	got, err := SSCC(1, "0614141", 123456789)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != "106141411234567897" || !ValidSSCC(got) {
+		t.Fatalf("got %s", got)
+	}
+	// Short serial references are zero padded to the width the prefix leaves.
+	if got, _ := SSCC(0, "8012345678", 42); len(got) != 18 || got[:17] != "08012345678000042" {
+		t.Fatalf("got %s", got)
+	}
+	if _, err := SSCC(0, "8012345678", 1000000); !errors.Is(err, ErrInvalid) {
+		t.Fatalf("serial too wide: got %v", err)
+	}
+	if _, err := SSCC(0, "80123", 1); !errors.Is(err, ErrInvalid) {
+		t.Fatalf("short prefix: got %v", err)
+	}
+	if ValidSSCC("106141411234567890") {
+		t.Fatal("wrong check digit accepted")
+	}
+}
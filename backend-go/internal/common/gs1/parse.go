@@ -0,0 +1,253 @@
+package gs1
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// GS is the ASCII group separator scanners send for FNC1 between
+// variable-length element strings.
+const GS = "\x1d"
+
+// Element is one application identifier and its data.
+type Element struct {
+	AI    string `json:"ai"`
+	Value string `json:"value"`
+}
+
+// Message is a parsed GS1-128 or GS1 DataMatrix scan. Dates are zero when
+// their AI is absent.
+type Message struct {
+	Elements   []Element `json:"elements"`
+	SSCC       string    `json:"sscc,omitempty"`       // (00)
+	GTIN       string    `json:"gtin,omitempty"`       // (01)
+	Content    string    `json:"content,omitempty"`    // (02) GTIN of the trade items inside a logistic unit
+	Lot        string    `json:"lot,omitempty"`        // (10)
+	Serial     string    `json:"serial,omitempty"`     // (21)
+	Produced   time.Time `json:"produced,omitzero"`    // (11)
+	BestBefore time.Time `json:"best_before,omitzero"` // (15)
+	Expiry     time.Time `json:"expiry,omitzero"`      // (17)
+	Count      int64     `json:"count,omitempty"`      // (37)
+}
+
+type aiSpec struct {
+	fixed   int // exact data length, 0 for variable
+	max     int // maximum data length of variable fields
+	numeric bool
+	check   bool // data ends in a GS1 check digit
+	date    bool // YYMMDD
+}
+
+// ais lists the application identifiers the warehouse reads. An unknown AI
+// cannot be skipped because its length is unknown, so it fails the scan.
+var ais = map[string]aiSpec{
+	"00": {fixed: 18, numeric: true, check: true},
+	"01": {fixed: 14, numeric: true, check: true},
+	"02": {fixed: 14, numeric: true, check: true},
+	"10": {max: 20},
+	"11": {fixed: 6, numeric: true, date: true},
+	"15": {fixed: 6, numeric: true, date: true},
+	"17": {fixed: 6, numeric: true, date: true},
+	"21": {max: 20},
+	"37": {max: 8, numeric: true},
+}
+
+// now is replaced in tests; it anchors the century of two-digit years.
+var now = time.Now
+
+// HasAIs reports whether scan is unmistakably a GS1 element string: it
+// carries a symbology identifier (]C1, ]d2, ]Q3, ...), uses the bracketed
+// human readable form or contains FNC1 separators. Other scans may still be
+// unbracketed element strings, or plain codes that happen to look like one.
+func HasAIs(scan string) bool {
+	return strings.HasPrefix(scan, "]") || strings.HasPrefix(scan, "(") || strings.Contains(scan, GS)
+}
+
+// Parse splits a scan into its element strings. It accepts the raw form with
+// FNC1 sent as GS, with or without a leading symbology identifier, and the
+// bracketed human readable form "(01)09501101530003(10)AB-123".
+func Parse(scan string) (Message, error) {
+	s := scan
+	if strings.HasPrefix(s, "]") {
+		if len(s) < 3 {
+			return Message{}, fmt.Errorf("%w: truncated symbology identifier", ErrInvalid)
+		}
+		s = s[3:]
+	}
+	s = strings.TrimPrefix(s, GS)
+	if s == "" {
+		return Message{}, fmt.Errorf("%w: empty scan", ErrInvalid)
+	}
+	var (
+		els []Element
+		err error
+	)
+	if strings.HasPrefix(s, "(") {
+		els, err = splitBracketed(s)
+	} else {
+		els, err = splitRaw(s)
+	}
+	if err != nil {
+		return Message{}, err
+	}
+	return build(els)
+}
+
+func splitRaw(s string) ([]Element, error) {
+	var out []Element
+	for s != "" {
+		if len(s) < 2 {
+			return nil, fmt.Errorf("%w: trailing %q", ErrInvalid, s)
+		}
+		ai := s[:2]
+		spec, ok := ais[ai]
+		if !ok {
+			return nil, fmt.Errorf("%w: unsupported AI (%s)", ErrInvalid, ai)
+		}
+		s = s[2:]
+		var v string
+		if spec.fixed > 0 {
+			if len(s) < spec.fixed {
+				return nil, fmt.Errorf("%w: (%s) needs %d characters", ErrInvalid, ai, spec.fixed)
+			}
+			v, s = s[:spec.fixed], s[spec.fixed:]
+			// A separator after a fixed-length field is tolerated.
+			s = strings.TrimPrefix(s, GS)
+		} else {
+			v, s, _ = strings.Cut(s, GS)
+		}
+		out = append(out, Element{AI: ai, Value: v})
+	}
+	return out, nil
+}
+
+func splitBracketed(s string) ([]Element, error) {
+	var out []Element
+	for s != "" {
+		if s[0] != '(' {
+			return nil, fmt.Errorf("%w: expected ( at %q", ErrInvalid, s)
+		}
+		ai, rest, ok := strings.Cut(s[1:], ")")
+		if !ok {
+			return nil, fmt.Errorf("%w: unterminated AI", ErrInvalid)
+		}
+		if _, known := ais[ai]; !known {
+			return nil, fmt.Errorf("%w: unsupported AI (%s)", ErrInvalid, ai)
+		}
+		v := rest
+		if i := strings.IndexByte(rest, '('); i >= 0 {
+			v = rest[:i]
+		}
+		s = rest[len(v):]
+		out = append(out, Element{AI: ai, Value: v})
+	}
+	return out, nil
+}
+
+func build(els []Element) (Message, error) {
+	m := Message{Elements: els}
+	seen := make(map[string]bool, len(els))
+	for _, e := range els {
+		spec := ais[e.AI]
+		if seen[e.AI] {
+			return Message{}, fmt.Errorf("%w: (%s) appears twice", ErrInvalid, e.AI)
+		}
+		seen[e.AI] = true
+		if err := spec.validate(e); err != nil {
+			return Message{}, err
+		}
+		switch e.AI {
+		case "00":
+			m.SSCC = e.Value
+		case "01":
+			m.GTIN = e.Value
+		case "02":
+			m.Content = e.Value
+		case "10":
+			m.Lot = e.Value
+		case "21":
+			m.Serial = e.Value
+		case "11":
+			m.Produced, _ = parseDate(e.Value)
+		case "15":
+			m.BestBefore, _ = parseDate(e.Value)
+		case "17":
+			m.Expiry, _ = parseDate(e.Value)
+		case "37":
+			m.Count, _ = strconv.ParseInt(e.Value, 10, 64)
+		}
+	}
+	if m.GTIN != "" && m.Content != "" {
+		return Message{}, fmt.Errorf("%w: (01) and (02) exclude each other", ErrInvalid)
+	}
+	return m, nil
+}
+
+func (spec aiSpec) validate(e Element) error {
+	if spec.fixed > 0 && len(e.Value) != spec.fixed {
+		return fmt.Errorf("%w: (%s) needs %d characters", ErrInvalid, e.AI, spec.fixed)
+	}
+	if e.Value == "" || (spec.max > 0 && len(e.Value) > spec.max) {
+		return fmt.Errorf("%w: (%s) takes 1 to %d characters", ErrInvalid, e.AI, spec.max)
+	}
+	if spec.numeric && strings.Trim(e.Value, "0123456789") != "" {
+		return fmt.Errorf("%w: (%s) must be numeric", ErrInvalid, e.AI)
+	}
+	if !spec.numeric && strings.ContainsFunc(e.Value, func(r rune) bool { return r < '!' || r > 'z' }) {
+		return fmt.Errorf("%w: (%s) has characters outside GS1 set 82", ErrInvalid, e.AI)
+	}
+	if spec.check && !Valid(e.Value) {
+		return fmt.Errorf("%w: (%s) %s has a wrong check digit", ErrInvalid, e.AI, e.Value)
+	}
+	if spec.date {
+		if _, err := parseDate(e.Value); err != nil {
+			return fmt.Errorf("%w: (%s) %v", ErrInvalid, e.AI, err)
+		}
+	}
+	return nil
+}
+
+// parseDate reads YYMMDD. Day 00 means the last day of the month, and the
+// century is the one that puts the year within 49 years back and 50 years
+// ahead of today, as the GS1 General Specifications prescribe.
+func parseDate(v string) (time.Time, error) {
+	yy, _ := strconv.Atoi(v[0:2])
+	mm, _ := strconv.Atoi(v[2:4])
+	dd, _ := strconv.Atoi(v[4:6])
+	if mm < 1 || mm > 12 {
+		return time.Time{}, fmt.Errorf("month %02d out of range", mm)
+	}
+	cur := now().Year()
+	year := cur/100*100 + yy
+	switch diff := yy - cur%100; {
+	case diff >= 51:
+		year -= 100
+	case diff <= -50:
+		year += 100
+	}
+	last := time.Date(year, time.Month(mm)+1, 0, 0, 0, 0, 0, time.UTC).Day()
+	if dd == 0 {
+		dd = last
+	}
+	if dd > last {
+		return time.Time{}, fmt.Errorf("day %02d out of range", dd)
+	}
+	return time.Date(year, time.Month(mm), dd, 0, 0, 0, 0, time.UTC), nil
+}
+
+// GTIN14 returns code as a GTIN-14 when it is a GTIN-8, -12, -13 or -14 with
+// a valid check digit, so an EAN-13 on a box matches the (01) of a GS1-128
+// label.
+func GTIN14(code string) (string, bool) {
+	switch len(code) {
+	case 8, 12, 13, 14:
+	default:
+		return "", false
+	}
+	if !Valid(code) {
+		return "", false
+	}
+	return strings.Repeat("0", 14-len(code)) + code, true
+}
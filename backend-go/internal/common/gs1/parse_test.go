@@ -0,0 +1,83 @@
+package gs1
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestParse(t *testing.T) {
+	defer func(f func() time.Time) { now = f }(now)
+	now = func() time.Time { return time.Date(2026, 5, 1, 0, 0, 0, 0, time.UTC) }
+
+	for _, scan := range []string{
+		"]C10109501101530003" + "17270600" + "10AB-123" + GS + "3712",
+		"(01)09501101530003(17)270600(10)AB-123(37)12",
+		GS + "0109501101530003172706001" + "0AB-123" + GS + "3712",
+	} {
+		m, err := Parse(scan)
+		if err != nil {
+			t.Fatalf("%q: %v", scan, err)
+		}
+		if m.GTIN != "09501101530003" || m.Lot != "AB-123" || m.Count != 12 {
+			t.Fatalf("%q: got %+v", scan, m)
+		}
+		// Day 00 is the last day of the month.
+		if !m.Expiry.Equal(time.Date(2027, 6, 30, 0, 0, 0, 0, time.UTC)) {
+			t.Fatalf("%q: expiry %s", scan, m.Expiry)
+		}
+		if len(m.Elements) != 4 {
+			t.Fatalf("%q: elements %v", scan, m.Elements)
+		}
+	}
+
+	m, err := Parse("]d2" + "00106141411234567897" + "0209501101530003" + "3748")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if m.SSCC != "106141411234567897" || m.Content != "09501101530003" || m.Count != 48 {
+		t.Fatalf("got %+v", m)
+	}
+}
+
+func TestParseRejects(t *testing.T) {
+	for name, scan := range map[string]string{
+		"check digit":   "0109501101530004",
+		"short fixed":   "01095011015300",
+		"unknown AI":    "(99)ABC",
+		"bad month":     "0109501101530003171399" + "01",
+		"bad day":       "0109501101530003" + "17270231",
+		"duplicate":     "(10)A(10)B",
+		"01 with 02":    "(01)09501101530003(02)09501101530003",
+		"empty":         "]C1",
+		"long variable": "(37)123456789",
+		"count letters": "(37)12A",
+	} {
+		if _, err := Parse(scan); !errors.Is(err, ErrInvalid) {
+			t.Fatalf("%s: got %v", name, err)
+		}
+	}
+}
+
+func TestParseDateCentury(t *testing.T) {
+	defer func(f func() time.Time) { now = f }(now)
+	now = func() time.Time { return time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC) }
+	for v, year := range map[string]int{"760101": 2076, "770101": 1977, "990101": 1999, "000101": 2000} {
+		d, err := parseDate(v)
+		if err != nil || d.Year() != year {
+			t.Fatalf("%s: got %v %v, want %d", v, d, err, year)
+		}
+	}
+}
+
+func TestGTIN14(t *testing.T) {
+	if g, ok := GTIN14("4006381333931"); !ok || g != "04006381333931" {
+		t.Fatalf("got %s %v", g, ok)
+	}
+	if _, ok := GTIN14("4006381333932"); ok {
+		t.Fatal("wrong check digit accepted")
+	}
+	if _, ok := GTIN14("SKU-1"); ok {
+		t.Fatal("internal code accepted")
+	}
+}
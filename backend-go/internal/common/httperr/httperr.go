@@ -0,0 +1,32 @@
+// Package httperr writes the error response of the WMS module handlers. The
+// module services report errors as the store sentinels, the Stock errors they
+// pass through and idempotency conflicts; anything else is a 500.
+package httperr
+
+import (
+	"errors"
+
+	"erpwms/backend-go/internal/common/idempotency"
+	"erpwms/backend-go/internal/common/store"
+	stocksvc "erpwms/backend-go/internal/modules/wms_stock/service"
+	"github.com/gin-gonic/gin"
+)
+
+// Write maps err to its status code and writes it as {"error": ...}.
+func Write(c *gin.Context, err error) {
+	var short *stocksvc.InsufficientStockError
+	switch {
+	case errors.As(err, &short):
+		c.JSON(422, gin.H{"error": "insufficient_stock", "detail": short})
+	case errors.Is(err, store.ErrNotFound), errors.Is(err, stocksvc.ErrNotFound):
+		c.JSON(404, gin.H{"error": err.Error()})
+	case errors.Is(err, store.ErrConflict), errors.Is(err, idempotency.ErrConflict):
+		c.JSON(409, gin.H{"error": err.Error()})
+	case errors.Is(err, store.ErrInvalid), errors.Is(err, stocksvc.ErrInvalidMove):
+		c.JSON(400, gin.H{"error": err.Error()})
+	case errors.Is(err, stocksvc.ErrForbidden):
+		c.JSON(403, gin.H{"error": err.Error()})
+	default:
+		c.JSON(500, gin.H{"error": "db"})
+	}
+}
@@ -0,0 +1,38 @@
+package httperr
+
+import (
+	"errors"
+	"fmt"
+	"net/http/httptest"
+	"testing"
+
+	"erpwms/backend-go/internal/common/idempotency"
+	"erpwms/backend-go/internal/common/store"
+	stocksvc "erpwms/backend-go/internal/modules/wms_stock/service"
+	"github.com/gin-gonic/gin"
+)
+
+func TestWrite(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	for _, tc := range []struct {
+		err  error
+		code int
+	}{
+		{&stocksvc.InsufficientStockError{}, 422},
+		{fmt.Errorf("%w: po", store.ErrNotFound), 404},
+		{stocksvc.ErrNotFound, 404},
+		{fmt.Errorf("%w: sku", store.ErrConflict), 409},
+		{idempotency.ErrConflict, 409},
+		{fmt.Errorf("%w: qty", store.ErrInvalid), 400},
+		{stocksvc.ErrInvalidMove, 400},
+		{stocksvc.ErrForbidden, 403},
+		{errors.New("connection reset"), 500},
+	} {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		Write(c, tc.err)
+		if w.Code != tc.code {
+			t.Fatalf("%v: got %d, want %d", tc.err, w.Code, tc.code)
+		}
+	}
+}
@@ -0,0 +1,49 @@
+// Package idempotency replays the stored response of a POST that was already
+// handled under the same Idempotency-Key, and stores new ones.
+package idempotency
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+
+	"erpwms/backend-go/internal/db/sqlcgen"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// ErrConflict is returned when a key is reused with a different request body.
+var ErrConflict = errors.New("idempotency conflict")
+
+// Hash fingerprints a request so a reused key can be matched to its body.
+func Hash(v any) string {
+	b, _ := json.Marshal(v)
+	h := sha256.Sum256(b)
+	return hex.EncodeToString(h[:])
+}
+
+// Replay loads the stored response for a repeated key into out. It reports
+// false when the key has not been used yet.
+func Replay(ctx context.Context, q *sqlcgen.Queries, endpoint, key, reqHash string, out any) (bool, error) {
+	existing, err := q.GetIdempotency(ctx, sqlcgen.GetIdempotencyParams{Key: key, Endpoint: endpoint})
+	if errors.Is(err, pgx.ErrNoRows) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	if existing.RequestHash != reqHash {
+		return false, ErrConflict
+	}
+	_ = json.Unmarshal(existing.ResponseJson, out)
+	return true, nil
+}
+
+// Remember stores resp under the key inside the caller's transaction, so the
+// key only exists if the work it guards committed.
+func Remember(ctx context.Context, q *sqlcgen.Queries, endpoint, key string, actorID pgtype.UUID, reqHash string, resp any) error {
+	respJSON, _ := json.Marshal(resp)
+	return q.InsertIdempotency(ctx, sqlcgen.InsertIdempotencyParams{Key: key, Endpoint: endpoint, ActorUserID: actorID, RequestHash: reqHash, ResponseJson: respJSON})
+}
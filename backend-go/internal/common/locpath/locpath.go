@@ -0,0 +1,55 @@
+// Package locpath checks location paths, the dot-separated labels that place
+// a location in its warehouse tree, e.g. "B.03.R2.L1.B05" for zone B, aisle
+// 03, rack R2, level L1, bin B05. Paths are stored as ltree.
+package locpath
+
+import (
+	"fmt"
+	"strings"
+)
+
+// maxLabel is the longest label every supported PostgreSQL accepts.
+const maxLabel = 255
+
+// Levels names the tree levels below the warehouse by depth, starting at 1.
+var Levels = []string{"zone", "aisle", "rack", "level", "bin"}
+
+// Normalize trims p and accepts "/" as well as "." between labels. Labels
+// take letters, digits and "_". An empty path stays empty.
+func Normalize(p string) (string, error) {
+	p = strings.Trim(strings.TrimSpace(p), "./")
+	if p == "" {
+		return "", nil
+	}
+	labels := strings.FieldsFunc(p, func(r rune) bool { return r == '.' || r == '/' })
+	if len(labels) != strings.Count(p, ".")+strings.Count(p, "/")+1 {
+		return "", fmt.Errorf("path %q has an empty label", p)
+	}
+	for _, l := range labels {
+		if len(l) > maxLabel {
+			return "", fmt.Errorf("path label %.20q... is longer than %d characters", l, maxLabel)
+		}
+		if strings.ContainsFunc(l, func(r rune) bool {
+			return !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '_')
+		}) {
+			return "", fmt.Errorf("path label %q may only hold letters, digits and _", l)
+		}
+	}
+	return strings.Join(labels, "."), nil
+}
+
+// Depth is the number of labels in a normalized path.
+func Depth(p string) int {
+	if p == "" {
+		return 0
+	}
+	return strings.Count(p, ".") + 1
+}
+
+// Level names the tree level at depth, or "" below the bin.
+func Level(depth int) string {
+	if depth < 1 || depth > len(Levels) {
+		return ""
+	}
+	return Levels[depth-1]
+}
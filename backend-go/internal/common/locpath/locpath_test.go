@@ -0,0 +1,33 @@
+package locpath
+
+import "testing"
+
+func TestNormalize(t *testing.T) {
+	for in, want := range map[string]string{
+		"":                 "",
+		"  ":               "",
+		"B":                "B",
+		"B.03.R2.L1.B05":   "B.03.R2.L1.B05",
+		"/B/03/R2/":        "B.03.R2",
+		" cold_store.A1. ": "cold_store.A1",
+	} {
+		got, err := Normalize(in)
+		if err != nil || got != want {
+			t.Fatalf("%q: got %q %v, want %q", in, got, err, want)
+		}
+	}
+	for _, in := range []string{"B..03", "B/.03", "B.0-3", "zone B", "B.ä"} {
+		if got, err := Normalize(in); err == nil {
+			t.Fatalf("%q: accepted as %q", in, got)
+		}
+	}
+}
+
+func TestDepthAndLevel(t *testing.T) {
+	if Depth("") != 0 || Depth("B") != 1 || Depth("B.03.R2.L1.B05") != 5 {
+		t.Fatal("depth")
+	}
+	if Level(1) != "zone" || Level(5) != "bin" || Level(0) != "" || Level(6) != "" {
+		t.Fatal("level")
+	}
+}
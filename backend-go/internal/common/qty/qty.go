@@ -0,0 +1,99 @@
+// Package qty converts stock quantities between their API string form,
+// exact big.Rat arithmetic and pgtype.Numeric for the database.
+package qty
+
+import (
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// maxScale bounds the decimal places kept when a value does not terminate.
+const maxScale = 18
+
+// Parse reads a plain decimal such as "12", "-3.5" or "0.125".
+func Parse(s string) (*big.Rat, error) {
+	s = strings.TrimSpace(s)
+	if s == "" || strings.ContainsAny(s, "/eE") {
+		return nil, fmt.Errorf("invalid quantity %q", s)
+	}
+	r, ok := new(big.Rat).SetString(s)
+	if !ok {
+		return nil, fmt.Errorf("invalid quantity %q", s)
+	}
+	return r, nil
+}
+
+// FromNumeric returns zero for NULL and NaN values.
+func FromNumeric(n pgtype.Numeric) *big.Rat {
+	if !n.Valid || n.NaN || n.Int == nil {
+		return new(big.Rat)
+	}
+	r := new(big.Rat).SetInt(n.Int)
+	if n.Exp == 0 {
+		return r
+	}
+	p := new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(abs(n.Exp))), nil)
+	if n.Exp > 0 {
+		return r.Mul(r, new(big.Rat).SetInt(p))
+	}
+	return r.Quo(r, new(big.Rat).SetInt(p))
+}
+
+func ToNumeric(r *big.Rat) pgtype.Numeric {
+	var n pgtype.Numeric
+	_ = n.Scan(String(r))
+	return n
+}
+
+// String formats r as a decimal without trailing zeros.
+func String(r *big.Rat) string {
+	s := r.FloatString(maxScale)
+	if strings.Contains(s, ".") {
+		s = strings.TrimRight(strings.TrimRight(s, "0"), ".")
+	}
+	if s == "-0" {
+		return "0"
+	}
+	return s
+}
+
+// Decimals reports how many decimal places r needs, or false when r does not
+// terminate within maxScale places.
+func Decimals(r *big.Rat) (int, bool) {
+	n := new(big.Rat).Set(r)
+	ten := big.NewRat(10, 1)
+	for d := 0; d <= maxScale; d++ {
+		if n.IsInt() {
+			return d, true
+		}
+		n.Mul(n, ten)
+	}
+	return 0, false
+}
+
+func Zero() *big.Rat { return new(big.Rat) }
+
+func Neg(r *big.Rat) *big.Rat { return new(big.Rat).Neg(r) }
+
+func Add(a, b *big.Rat) *big.Rat { return new(big.Rat).Add(a, b) }
+
+func Sub(a, b *big.Rat) *big.Rat { return new(big.Rat).Sub(a, b) }
+
+func Mul(a, b *big.Rat) *big.Rat { return new(big.Rat).Mul(a, b) }
+
+func Min(a, b *big.Rat) *big.Rat {
+	if a.Cmp(b) <= 0 {
+		return a
+	}
+	return b
+}
+
+func abs(v int32) int32 {
+	if v < 0 {
+		return -v
+	}
+	return v
+}
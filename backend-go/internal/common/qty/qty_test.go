@@ -0,0 +1,37 @@
+package qty
+
+import (
+	"math/big"
+	"testing"
+)
+
+func TestParseRejectsNonDecimal(t *testing.T) {
+	for _, s := range []string{"", "1/3", "1e3", "abc"} {
+		if _, err := Parse(s); err == nil {
+			t.Fatalf("expected error for %q", s)
+		}
+	}
+}
+
+func TestNumericRoundTrip(t *testing.T) {
+	for _, s := range []string{"0", "12", "-3.5", "0.125", "1200"} {
+		r, err := Parse(s)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := String(FromNumeric(ToNumeric(r))); got != s {
+			t.Fatalf("round trip %q got %q", s, got)
+		}
+	}
+}
+
+func TestDecimals(t *testing.T) {
+	r, _ := Parse("0.333")
+	if d, ok := Decimals(r); !ok || d != 3 {
+		t.Fatalf("expected 3 decimals, got %d %v", d, ok)
+	}
+	third := big.NewRat(1, 3)
+	if _, ok := Decimals(third); ok {
+		t.Fatal("1/3 must not terminate")
+	}
+}
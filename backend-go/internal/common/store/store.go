@@ -0,0 +1,103 @@
+// Package store holds what the WMS module services share around their
+// writes: a transaction that also records the outbox event and audit entry,
+// the mapping of database errors to the module errors, and the conversions
+// between request values and pgtype columns.
+package store
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"erpwms/backend-go/internal/db/sqlcgen"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// Module errors. Each module re-exports them so its handlers match on the
+// module's own names.
+var (
+	ErrNotFound = errors.New("not found")
+	ErrConflict = errors.New("already exists")
+	ErrInvalid  = errors.New("invalid input")
+)
+
+// Mutate runs fn in a transaction and writes the outbox event and audit entry
+// for the change in that same transaction. fn returns the id of the changed
+// resource and the event payload; its error goes through MapErr.
+func Mutate(ctx context.Context, db *pgxpool.Pool, queries *sqlcgen.Queries, actor uuid.UUID, topic, resource string, conflicts map[string]string, fn func(q *sqlcgen.Queries) (string, any, error)) error {
+	tx, err := db.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+	q := queries.WithTx(tx)
+
+	id, body, err := fn(q)
+	if err != nil {
+		return MapErr(err, conflicts)
+	}
+	payload, _ := json.Marshal(body)
+	if err := Record(ctx, q, actor, topic, resource, id, payload); err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
+}
+
+// Record writes the outbox event and audit entry for a change made with q.
+// A failed audit insert does not fail the change.
+func Record(ctx context.Context, q *sqlcgen.Queries, actor uuid.UUID, topic, resource, id string, payload []byte) error {
+	if _, err := q.InsertOutboxEvent(ctx, sqlcgen.InsertOutboxEventParams{Topic: topic, Payload: payload}); err != nil {
+		return err
+	}
+	requestID, _ := ctx.Value("request_id").(string)
+	_ = q.InsertAuditLog(ctx, sqlcgen.InsertAuditLogParams{ActorUserID: UUID(actor), ActorType: "user", Action: topic, Resource: resource, ResourceID: Text(id), Status: "ok", RequestID: Text(requestID), Metadata: payload})
+	return nil
+}
+
+// MapErr turns a missing row into ErrNotFound, a unique violation into
+// ErrConflict naming the field from conflicts, and a foreign key violation
+// into ErrInvalid. Other errors pass through.
+func MapErr(err error, conflicts map[string]string) error {
+	if errors.Is(err, pgx.ErrNoRows) {
+		return ErrNotFound
+	}
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) {
+		switch pgErr.Code {
+		case "23505":
+			if f, ok := conflicts[pgErr.ConstraintName]; ok {
+				return fmt.Errorf("%w: %s", ErrConflict, f)
+			}
+			return ErrConflict
+		case "23503":
+			return fmt.Errorf("%w: unknown reference", ErrInvalid)
+		}
+	}
+	return err
+}
+
+// ParseUUID parses the uuid in field v of a request.
+func ParseUUID(field, v string) (uuid.UUID, error) {
+	id, err := uuid.Parse(v)
+	if err != nil {
+		return uuid.Nil, fmt.Errorf("%w: %s must be a uuid", ErrInvalid, field)
+	}
+	return id, nil
+}
+
+// OptUUID renders a nullable uuid column, empty when NULL.
+func OptUUID(u pgtype.UUID) string {
+	if !u.Valid {
+		return ""
+	}
+	return u.String()
+}
+
+// UUID and Text map the zero value to NULL.
+func UUID(id uuid.UUID) pgtype.UUID { return pgtype.UUID{Bytes: id, Valid: id != uuid.Nil} }
+func Text(v string) pgtype.Text     { return pgtype.Text{String: v, Valid: v != ""} }
@@ -0,0 +1,44 @@
+package store
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+func TestMapErr(t *testing.T) {
+	conflicts := map[string]string{"suppliers_code_key": "supplier code"}
+	if err := MapErr(fmt.Errorf("get: %w", pgx.ErrNoRows), conflicts); err != ErrNotFound {
+		t.Fatalf("no rows: %v", err)
+	}
+	err := MapErr(&pgconn.PgError{Code: "23505", ConstraintName: "suppliers_code_key"}, conflicts)
+	if !errors.Is(err, ErrConflict) || err.Error() != "already exists: supplier code" {
+		t.Fatalf("named conflict: %v", err)
+	}
+	if err := MapErr(&pgconn.PgError{Code: "23505", ConstraintName: "other_key"}, nil); err != ErrConflict {
+		t.Fatalf("unnamed conflict: %v", err)
+	}
+	if err := MapErr(&pgconn.PgError{Code: "23503"}, nil); !errors.Is(err, ErrInvalid) {
+		t.Fatalf("foreign key: %v", err)
+	}
+	other := errors.New("boom")
+	if err := MapErr(other, nil); err != other {
+		t.Fatalf("other: %v", err)
+	}
+}
+
+func TestParseUUID(t *testing.T) {
+	if _, err := ParseUUID("item_id", "nope"); !errors.Is(err, ErrInvalid) || err.Error() != "invalid input: item_id must be a uuid" {
+		t.Fatalf("got %v", err)
+	}
+	id, err := ParseUUID("item_id", "6f1c1c2e-2b7a-4c39-9d3e-0d6a1f0b8e11")
+	if err != nil || !UUID(id).Valid || OptUUID(UUID(id)) != id.String() {
+		t.Fatalf("got %v %v", id, err)
+	}
+	if UUID([16]byte{}).Valid || Text("").Valid || OptUUID(UUID([16]byte{})) != "" {
+		t.Fatal("zero values must map to NULL")
+	}
+}
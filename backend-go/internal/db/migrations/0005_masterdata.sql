@@ -0,0 +1,37 @@
+-- +goose Up
+
+ALTER TABLE warehouses
+  ADD COLUMN IF NOT EXISTS active BOOLEAN NOT NULL DEFAULT true,
+  ADD COLUMN IF NOT EXISTS created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  ADD COLUMN IF NOT EXISTS updated_at TIMESTAMPTZ NOT NULL DEFAULT now();
+
+ALTER TABLE locations
+  ADD COLUMN IF NOT EXISTS active BOOLEAN NOT NULL DEFAULT true,
+  ADD COLUMN IF NOT EXISTS created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  ADD COLUMN IF NOT EXISTS updated_at TIMESTAMPTZ NOT NULL DEFAULT now();
+
+ALTER TABLE items
+  ADD COLUMN IF NOT EXISTS active BOOLEAN NOT NULL DEFAULT true,
+  ADD COLUMN IF NOT EXISTS created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  ADD COLUMN IF NOT EXISTS updated_at TIMESTAMPTZ NOT NULL DEFAULT now();
+
+CREATE INDEX IF NOT EXISTS idx_locations_warehouse ON locations(warehouse_id, code);
+
+INSERT INTO permissions(name) VALUES
+  ('wms.masterdata.read'),
+  ('wms.masterdata.write')
+ON CONFLICT DO NOTHING;
+
+INSERT INTO role_permissions(role_id, permission_id)
+SELECT r.id, p.id
+FROM roles r
+JOIN permissions p ON p.name IN ('wms.masterdata.read','wms.masterdata.write')
+WHERE r.name='SuperAdmin'
+ON CONFLICT DO NOTHING;
+
+-- +goose Down
+DELETE FROM permissions WHERE name IN ('wms.masterdata.read','wms.masterdata.write');
+DROP INDEX IF EXISTS idx_locations_warehouse;
+ALTER TABLE items DROP COLUMN IF EXISTS active, DROP COLUMN IF EXISTS created_at, DROP COLUMN IF EXISTS updated_at;
+ALTER TABLE locations DROP COLUMN IF EXISTS active, DROP COLUMN IF EXISTS created_at, DROP COLUMN IF EXISTS updated_at;
+ALTER TABLE warehouses DROP COLUMN IF EXISTS active, DROP COLUMN IF EXISTS created_at, DROP COLUMN IF EXISTS updated_at;
@@ -0,0 +1,16 @@
+-- +goose Up
+
+ALTER TABLE warehouses
+  ADD COLUMN IF NOT EXISTS allow_negative_stock BOOLEAN NOT NULL DEFAULT false;
+
+-- Per location type override, e.g. a production floor that back-flushes
+-- consumption before the matching receipt is booked.
+CREATE TABLE IF NOT EXISTS location_type_policies (
+  type TEXT PRIMARY KEY,
+  allow_negative_stock BOOLEAN NOT NULL DEFAULT false,
+  updated_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+-- +goose Down
+DROP TABLE IF EXISTS location_type_policies;
+ALTER TABLE warehouses DROP COLUMN IF EXISTS allow_negative_stock;
@@ -0,0 +1,59 @@
+-- +goose Up
+
+ALTER TABLE items
+  ADD COLUMN IF NOT EXISTS tracking_mode TEXT NOT NULL DEFAULT 'none'
+    CHECK (tracking_mode IN ('none','lot','serial'));
+
+CREATE TABLE IF NOT EXISTS lots (
+  id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+  item_id UUID NOT NULL REFERENCES items(id),
+  lot_code TEXT NOT NULL,
+  manufactured_on DATE,
+  expires_on DATE,
+  received_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  UNIQUE (item_id, lot_code)
+);
+
+-- location_id is where the unit currently sits; NULL once it has left stock.
+CREATE TABLE IF NOT EXISTS serials (
+  id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+  item_id UUID NOT NULL REFERENCES items(id),
+  serial_no TEXT NOT NULL,
+  lot_id UUID REFERENCES lots(id),
+  location_id UUID REFERENCES locations(id),
+  created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  updated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  UNIQUE (item_id, serial_no)
+);
+
+ALTER TABLE stock_ledger
+  ADD COLUMN IF NOT EXISTS lot_id UUID REFERENCES lots(id),
+  ADD COLUMN IF NOT EXISTS serial_id UUID REFERENCES serials(id);
+
+ALTER TABLE stock_balance
+  ADD COLUMN IF NOT EXISTS lot_id UUID REFERENCES lots(id),
+  ADD COLUMN IF NOT EXISTS serial_id UUID REFERENCES serials(id);
+
+-- lot/serial are part of the balance key; untracked stock keeps NULLs.
+ALTER TABLE stock_balance DROP CONSTRAINT IF EXISTS stock_balance_pkey;
+ALTER TABLE stock_balance
+  ADD CONSTRAINT stock_balance_key UNIQUE NULLS NOT DISTINCT (item_id, location_id, lot_id, serial_id);
+
+-- Backstop for MoveStock: a serial can only be on hand in one place.
+CREATE UNIQUE INDEX IF NOT EXISTS uq_stock_balance_serial_on_hand
+  ON stock_balance(serial_id) WHERE serial_id IS NOT NULL AND qty_on_hand > 0;
+
+CREATE INDEX IF NOT EXISTS idx_lots_item_expiry ON lots(item_id, expires_on);
+CREATE INDEX IF NOT EXISTS idx_stock_ledger_serial ON stock_ledger(serial_id) WHERE serial_id IS NOT NULL;
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_stock_ledger_serial;
+DROP INDEX IF EXISTS idx_lots_item_expiry;
+DROP INDEX IF EXISTS uq_stock_balance_serial_on_hand;
+ALTER TABLE stock_balance DROP CONSTRAINT IF EXISTS stock_balance_key;
+ALTER TABLE stock_balance DROP COLUMN IF EXISTS serial_id, DROP COLUMN IF EXISTS lot_id;
+ALTER TABLE stock_balance ADD PRIMARY KEY (item_id, location_id);
+ALTER TABLE stock_ledger DROP COLUMN IF EXISTS serial_id, DROP COLUMN IF EXISTS lot_id;
+DROP TABLE IF EXISTS serials;
+DROP TABLE IF EXISTS lots;
+ALTER TABLE items DROP COLUMN IF EXISTS tracking_mode;
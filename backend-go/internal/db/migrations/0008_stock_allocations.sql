@@ -0,0 +1,55 @@
+-- +goose Up
+
+CREATE TABLE IF NOT EXISTS stock_allocations (
+  id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+  item_id UUID NOT NULL REFERENCES items(id),
+  location_id UUID NOT NULL REFERENCES locations(id),
+  lot_id UUID REFERENCES lots(id),
+  serial_id UUID REFERENCES serials(id),
+  qty NUMERIC NOT NULL CHECK (qty > 0),
+  ref_type TEXT NOT NULL,
+  ref_id TEXT NOT NULL,
+  strategy TEXT NOT NULL,
+  status TEXT NOT NULL DEFAULT 'active' CHECK (status IN ('active','released','consumed')),
+  created_by UUID REFERENCES users(id),
+  created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  updated_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS idx_stock_allocations_ref ON stock_allocations(ref_type, ref_id) WHERE status = 'active';
+CREATE INDEX IF NOT EXISTS idx_stock_allocations_item_loc ON stock_allocations(item_id, location_id) WHERE status = 'active';
+
+-- Append-only trail of every change to qty_allocated, kept next to stock_ledger.
+CREATE TABLE IF NOT EXISTS stock_allocation_log (
+  id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+  ts TIMESTAMPTZ NOT NULL DEFAULT now(),
+  allocation_id UUID NOT NULL REFERENCES stock_allocations(id),
+  action TEXT NOT NULL,
+  qty NUMERIC NOT NULL,
+  ref_type TEXT NOT NULL,
+  ref_id TEXT NOT NULL,
+  actor_user_id UUID REFERENCES users(id),
+  request_id TEXT
+);
+
+CREATE INDEX IF NOT EXISTS idx_stock_allocation_log_alloc ON stock_allocation_log(allocation_id, ts);
+
+DROP TRIGGER IF EXISTS trg_stock_allocation_log_no_update ON stock_allocation_log;
+CREATE TRIGGER trg_stock_allocation_log_no_update
+BEFORE UPDATE OR DELETE ON stock_allocation_log
+FOR EACH ROW EXECUTE FUNCTION forbid_update_delete();
+
+INSERT INTO permissions(name) VALUES ('wms.stock.allocate') ON CONFLICT DO NOTHING;
+
+INSERT INTO role_permissions(role_id, permission_id)
+SELECT r.id, p.id
+FROM roles r
+JOIN permissions p ON p.name = 'wms.stock.allocate'
+WHERE r.name='SuperAdmin'
+ON CONFLICT DO NOTHING;
+
+-- +goose Down
+DELETE FROM permissions WHERE name = 'wms.stock.allocate';
+DROP TRIGGER IF EXISTS trg_stock_allocation_log_no_update ON stock_allocation_log;
+DROP TABLE IF EXISTS stock_allocation_log;
+DROP TABLE IF EXISTS stock_allocations;
@@ -0,0 +1,37 @@
+-- +goose Up
+ALTER TABLE stock_ledger
+  ADD COLUMN IF NOT EXISTS move_type TEXT NOT NULL DEFAULT 'transfer'
+    CHECK (move_type IN ('receipt','issue','adjustment','transfer'));
+
+-- Receipts have no source, issues no destination, adjustments exactly one side.
+ALTER TABLE stock_ledger ADD CONSTRAINT stock_ledger_move_type_sides CHECK (
+  CASE move_type
+    WHEN 'receipt' THEN from_location_id IS NULL AND to_location_id IS NOT NULL
+    WHEN 'issue' THEN from_location_id IS NOT NULL AND to_location_id IS NULL
+    WHEN 'adjustment' THEN (from_location_id IS NULL) <> (to_location_id IS NULL)
+    ELSE from_location_id IS NOT NULL AND to_location_id IS NOT NULL
+  END
+) NOT VALID;
+
+CREATE INDEX IF NOT EXISTS idx_stock_ledger_move_type_ts ON stock_ledger(move_type, ts DESC);
+
+INSERT INTO permissions(name) VALUES
+  ('wms.stock.read'),
+  ('wms.stock.move'),
+  ('wms.stock.receive'),
+  ('wms.stock.issue'),
+  ('wms.stock.adjust')
+ON CONFLICT DO NOTHING;
+
+INSERT INTO role_permissions(role_id, permission_id)
+SELECT r.id, p.id
+FROM roles r
+JOIN permissions p ON p.name IN ('wms.stock.read','wms.stock.move','wms.stock.receive','wms.stock.issue','wms.stock.adjust')
+WHERE r.name='SuperAdmin'
+ON CONFLICT DO NOTHING;
+
+-- +goose Down
+DELETE FROM permissions WHERE name IN ('wms.stock.receive','wms.stock.issue','wms.stock.adjust');
+DROP INDEX IF EXISTS idx_stock_ledger_move_type_ts;
+ALTER TABLE stock_ledger DROP CONSTRAINT IF EXISTS stock_ledger_move_type_sides;
+ALTER TABLE stock_ledger DROP COLUMN IF EXISTS move_type;
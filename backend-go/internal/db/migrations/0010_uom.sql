@@ -0,0 +1,38 @@
+-- +goose Up
+CREATE TABLE IF NOT EXISTS uoms (
+  code TEXT PRIMARY KEY,
+  name TEXT NOT NULL,
+  decimals INT NOT NULL DEFAULT 0 CHECK (decimals BETWEEN 0 AND 6),
+  updated_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+INSERT INTO uoms(code, name, decimals) VALUES
+  ('EA', 'Each', 0),
+  ('CT', 'Carton', 0),
+  ('PAL', 'Pallet', 0),
+  ('KG', 'Kilogram', 3),
+  ('L', 'Litre', 3),
+  ('M', 'Metre', 3)
+ON CONFLICT DO NOTHING;
+
+-- Free-text units already on items become catalog entries so the FK holds;
+-- their precision can be tightened afterwards.
+INSERT INTO uoms(code, name, decimals)
+SELECT DISTINCT uom, uom, 3 FROM items
+ON CONFLICT DO NOTHING;
+
+ALTER TABLE items ADD CONSTRAINT items_uom_fkey FOREIGN KEY (uom) REFERENCES uoms(code) ON UPDATE CASCADE;
+
+-- factor is the number of base units (items.uom) in one uom, e.g. CT = 12 EA.
+CREATE TABLE IF NOT EXISTS item_uom_conversions (
+  item_id UUID NOT NULL REFERENCES items(id) ON DELETE CASCADE,
+  uom TEXT NOT NULL REFERENCES uoms(code),
+  factor NUMERIC NOT NULL CHECK (factor > 0),
+  updated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  PRIMARY KEY (item_id, uom)
+);
+
+-- +goose Down
+DROP TABLE IF EXISTS item_uom_conversions;
+ALTER TABLE items DROP CONSTRAINT IF EXISTS items_uom_fkey;
+DROP TABLE IF EXISTS uoms;
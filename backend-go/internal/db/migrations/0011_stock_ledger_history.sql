@@ -0,0 +1,11 @@
+-- +goose Up
+CREATE INDEX IF NOT EXISTS idx_stock_ledger_ts ON stock_ledger(ts DESC, move_id DESC);
+CREATE INDEX IF NOT EXISTS idx_stock_ledger_from_loc_ts ON stock_ledger(from_location_id, ts DESC) WHERE from_location_id IS NOT NULL;
+CREATE INDEX IF NOT EXISTS idx_stock_ledger_to_loc_ts ON stock_ledger(to_location_id, ts DESC) WHERE to_location_id IS NOT NULL;
+CREATE INDEX IF NOT EXISTS idx_stock_ledger_ref ON stock_ledger(ref_type, ref_id) WHERE ref_type IS NOT NULL;
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_stock_ledger_ref;
+DROP INDEX IF EXISTS idx_stock_ledger_to_loc_ts;
+DROP INDEX IF EXISTS idx_stock_ledger_from_loc_ts;
+DROP INDEX IF EXISTS idx_stock_ledger_ts;
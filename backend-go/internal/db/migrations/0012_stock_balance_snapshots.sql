@@ -0,0 +1,22 @@
+-- +goose Up
+-- Checkpoints of on-hand stock rebuilt from stock_ledger, so as-of queries
+-- only replay the moves after the nearest one.
+CREATE TABLE IF NOT EXISTS stock_balance_snapshots (
+  id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+  taken_at TIMESTAMPTZ NOT NULL UNIQUE,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE TABLE IF NOT EXISTS stock_balance_snapshot_lines (
+  snapshot_id UUID NOT NULL REFERENCES stock_balance_snapshots(id) ON DELETE CASCADE,
+  item_id UUID NOT NULL REFERENCES items(id),
+  location_id UUID NOT NULL REFERENCES locations(id),
+  lot_id UUID REFERENCES lots(id),
+  serial_id UUID REFERENCES serials(id),
+  qty_on_hand NUMERIC NOT NULL,
+  CONSTRAINT stock_balance_snapshot_lines_key UNIQUE NULLS NOT DISTINCT (snapshot_id, item_id, location_id, lot_id, serial_id)
+);
+
+-- +goose Down
+DROP TABLE IF EXISTS stock_balance_snapshot_lines;
+DROP TABLE IF EXISTS stock_balance_snapshots;
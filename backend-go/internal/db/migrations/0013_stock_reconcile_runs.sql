@@ -0,0 +1,14 @@
+-- +goose Up
+-- One row per ledger-vs-balance reconciliation, read by /health/stock.
+CREATE TABLE IF NOT EXISTS stock_reconcile_runs (
+  id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+  ran_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  drift_count INT NOT NULL,
+  fixed BOOLEAN NOT NULL DEFAULT false,
+  report JSONB NOT NULL DEFAULT '[]'::jsonb
+);
+
+CREATE INDEX IF NOT EXISTS idx_stock_reconcile_runs_ran_at ON stock_reconcile_runs(ran_at DESC);
+
+-- +goose Down
+DROP TABLE IF EXISTS stock_reconcile_runs;
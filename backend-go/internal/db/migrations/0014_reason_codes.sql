@@ -0,0 +1,41 @@
+-- +goose Up
+CREATE TABLE IF NOT EXISTS reason_codes (
+  code TEXT PRIMARY KEY CHECK (code ~ '^[A-Z][A-Z0-9_]*$'),
+  description TEXT NOT NULL,
+  move_types TEXT[] NOT NULL CHECK (
+    cardinality(move_types) > 0
+    AND move_types <@ ARRAY['receipt','issue','adjustment','transfer']::text[]
+  ),
+  requires_comment BOOLEAN NOT NULL DEFAULT false,
+  requires_reference BOOLEAN NOT NULL DEFAULT false,
+  permission TEXT REFERENCES permissions(name) ON UPDATE CASCADE,
+  active BOOLEAN NOT NULL DEFAULT true,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  updated_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+ALTER TABLE stock_ledger ADD COLUMN IF NOT EXISTS comment TEXT;
+
+INSERT INTO permissions(name) VALUES ('wms.stock.scrap') ON CONFLICT DO NOTHING;
+
+INSERT INTO role_permissions(role_id, permission_id)
+SELECT r.id, p.id
+FROM roles r
+JOIN permissions p ON p.name = 'wms.stock.scrap'
+WHERE r.name='SuperAdmin'
+ON CONFLICT DO NOTHING;
+
+INSERT INTO reason_codes(code, description, move_types, requires_comment, requires_reference, permission) VALUES
+  ('TRANSFER', 'Internal transfer', ARRAY['transfer'], false, false, NULL),
+  ('RECEIPT', 'Goods receipt', ARRAY['receipt'], false, false, NULL),
+  ('ISSUE', 'Goods issue', ARRAY['issue'], false, false, NULL),
+  ('COUNT', 'Inventory count correction', ARRAY['adjustment'], false, false, NULL),
+  ('ADJUST', 'Manual correction', ARRAY['adjustment'], true, false, NULL),
+  ('DAMAGE', 'Damaged goods', ARRAY['issue','adjustment','transfer'], true, false, NULL),
+  ('SCRAP', 'Scrapped goods', ARRAY['issue','adjustment'], true, false, 'wms.stock.scrap')
+ON CONFLICT DO NOTHING;
+
+-- +goose Down
+DROP TABLE IF EXISTS reason_codes;
+ALTER TABLE stock_ledger DROP COLUMN IF EXISTS comment;
+DELETE FROM permissions WHERE name = 'wms.stock.scrap';
@@ -0,0 +1,22 @@
+-- +goose Up
+-- A move can be reversed once: the compensating line points back at it
+-- through ref_type='reversal' and ref_id=<move_id>.
+CREATE UNIQUE INDEX IF NOT EXISTS uq_stock_ledger_reversal ON stock_ledger(ref_id) WHERE ref_type = 'reversal';
+
+INSERT INTO permissions(name) VALUES ('wms.stock.reverse') ON CONFLICT DO NOTHING;
+
+INSERT INTO role_permissions(role_id, permission_id)
+SELECT r.id, p.id
+FROM roles r
+JOIN permissions p ON p.name = 'wms.stock.reverse'
+WHERE r.name='SuperAdmin'
+ON CONFLICT DO NOTHING;
+
+INSERT INTO reason_codes(code, description, move_types, requires_comment, requires_reference, permission) VALUES
+  ('REVERSAL', 'Reversal of an earlier move', ARRAY['receipt','issue','adjustment','transfer'], false, true, NULL)
+ON CONFLICT DO NOTHING;
+
+-- +goose Down
+DELETE FROM reason_codes WHERE code = 'REVERSAL';
+DELETE FROM permissions WHERE name = 'wms.stock.reverse';
+DROP INDEX IF EXISTS uq_stock_ledger_reversal;
@@ -0,0 +1,114 @@
+-- +goose Up
+
+CREATE TABLE IF NOT EXISTS suppliers (
+  id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+  code TEXT NOT NULL UNIQUE,
+  name TEXT NOT NULL,
+  active BOOLEAN NOT NULL DEFAULT true,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  updated_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+-- Quantities on purchase order lines are in the item's base unit. A line may
+-- be over-received by up to over_receipt_pct percent of qty_ordered.
+CREATE TABLE IF NOT EXISTS purchase_orders (
+  id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+  po_number TEXT NOT NULL UNIQUE,
+  supplier_id UUID NOT NULL REFERENCES suppliers(id),
+  warehouse_id UUID NOT NULL REFERENCES warehouses(id),
+  status TEXT NOT NULL DEFAULT 'open' CHECK (status IN ('open','receiving','closed','cancelled')),
+  expected_on DATE,
+  over_receipt_pct NUMERIC NOT NULL DEFAULT 0 CHECK (over_receipt_pct >= 0),
+  created_by UUID REFERENCES users(id),
+  created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  updated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  closed_at TIMESTAMPTZ
+);
+
+CREATE INDEX IF NOT EXISTS idx_purchase_orders_supplier ON purchase_orders(supplier_id, created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_purchase_orders_open ON purchase_orders(warehouse_id) WHERE status IN ('open','receiving');
+
+CREATE TABLE IF NOT EXISTS purchase_order_lines (
+  id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+  po_id UUID NOT NULL REFERENCES purchase_orders(id),
+  line_no INT NOT NULL CHECK (line_no > 0),
+  item_id UUID NOT NULL REFERENCES items(id),
+  qty_ordered NUMERIC NOT NULL CHECK (qty_ordered > 0),
+  qty_received NUMERIC NOT NULL DEFAULT 0 CHECK (qty_received >= 0),
+  UNIQUE (po_id, line_no)
+);
+
+CREATE TABLE IF NOT EXISTS asns (
+  id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+  asn_number TEXT NOT NULL,
+  supplier_id UUID NOT NULL REFERENCES suppliers(id),
+  po_id UUID NOT NULL REFERENCES purchase_orders(id),
+  expected_at TIMESTAMPTZ,
+  status TEXT NOT NULL DEFAULT 'open' CHECK (status IN ('open','received','cancelled')),
+  created_by UUID REFERENCES users(id),
+  created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  updated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  UNIQUE (supplier_id, asn_number)
+);
+
+CREATE INDEX IF NOT EXISTS idx_asns_po ON asns(po_id);
+
+CREATE TABLE IF NOT EXISTS asn_lines (
+  id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+  asn_id UUID NOT NULL REFERENCES asns(id),
+  po_line_id UUID NOT NULL REFERENCES purchase_order_lines(id),
+  qty NUMERIC NOT NULL CHECK (qty > 0),
+  lot_code TEXT,
+  expires_on DATE
+);
+
+CREATE INDEX IF NOT EXISTS idx_asn_lines_asn ON asn_lines(asn_id);
+
+-- A goods receipt is the document behind ledger lines with
+-- ref_type='receipt' and ref_id=<goods_receipts.id>.
+CREATE TABLE IF NOT EXISTS goods_receipts (
+  id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+  po_id UUID NOT NULL REFERENCES purchase_orders(id),
+  asn_id UUID REFERENCES asns(id),
+  dock_location_id UUID NOT NULL REFERENCES locations(id),
+  received_by UUID REFERENCES users(id),
+  received_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  request_id TEXT
+);
+
+CREATE INDEX IF NOT EXISTS idx_goods_receipts_po ON goods_receipts(po_id, received_at);
+
+CREATE TABLE IF NOT EXISTS goods_receipt_lines (
+  id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+  receipt_id UUID NOT NULL REFERENCES goods_receipts(id),
+  po_line_id UUID NOT NULL REFERENCES purchase_order_lines(id),
+  qty NUMERIC NOT NULL CHECK (qty > 0),
+  lot_code TEXT,
+  serial_no TEXT,
+  move_id UUID NOT NULL REFERENCES stock_ledger(move_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_goods_receipt_lines_receipt ON goods_receipt_lines(receipt_id);
+
+INSERT INTO permissions(name) VALUES
+  ('wms.inbound.read'),
+  ('wms.inbound.write'),
+  ('wms.inbound.receive')
+ON CONFLICT DO NOTHING;
+
+INSERT INTO role_permissions(role_id, permission_id)
+SELECT r.id, p.id
+FROM roles r
+JOIN permissions p ON p.name IN ('wms.inbound.read','wms.inbound.write','wms.inbound.receive')
+WHERE r.name='SuperAdmin'
+ON CONFLICT DO NOTHING;
+
+-- +goose Down
+DELETE FROM permissions WHERE name IN ('wms.inbound.read','wms.inbound.write','wms.inbound.receive');
+DROP TABLE IF EXISTS goods_receipt_lines;
+DROP TABLE IF EXISTS goods_receipts;
+DROP TABLE IF EXISTS asn_lines;
+DROP TABLE IF EXISTS asns;
+DROP TABLE IF EXISTS purchase_order_lines;
+DROP TABLE IF EXISTS purchase_orders;
+DROP TABLE IF EXISTS suppliers;
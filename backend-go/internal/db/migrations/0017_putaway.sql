@@ -0,0 +1,71 @@
+-- +goose Up
+ALTER TABLE items ADD COLUMN IF NOT EXISTS item_class TEXT;
+
+-- Rules are tried by ascending priority; NULL filters match anything. The
+-- strategy picks among the matching bins: consolidate with stock of the same
+-- item, an empty bin, or any bin. max_qty caps what a bin may hold of the
+-- item, so a bin is only suggested if the whole quantity still fits.
+CREATE TABLE IF NOT EXISTS putaway_rules (
+  id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+  warehouse_id UUID NOT NULL REFERENCES warehouses(id),
+  name TEXT NOT NULL,
+  priority INT NOT NULL DEFAULT 100,
+  item_id UUID REFERENCES items(id),
+  item_class TEXT,
+  location_type TEXT,
+  path_prefix TEXT,
+  strategy TEXT NOT NULL CHECK (strategy IN ('consolidate','empty','any')),
+  max_qty NUMERIC CHECK (max_qty > 0),
+  active BOOLEAN NOT NULL DEFAULT true,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  updated_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS idx_putaway_rules_wh ON putaway_rules(warehouse_id, priority) WHERE active;
+
+-- Quantities are in the item's base unit. to_location_id is the suggested
+-- bin until the task is confirmed, then the bin actually used.
+CREATE TABLE IF NOT EXISTS putaway_tasks (
+  id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+  warehouse_id UUID NOT NULL REFERENCES warehouses(id),
+  item_id UUID NOT NULL REFERENCES items(id),
+  qty NUMERIC NOT NULL CHECK (qty > 0),
+  lot_code TEXT,
+  serial_no TEXT,
+  from_location_id UUID NOT NULL REFERENCES locations(id),
+  to_location_id UUID NOT NULL REFERENCES locations(id),
+  rule_id UUID REFERENCES putaway_rules(id),
+  ref_type TEXT,
+  ref_id TEXT,
+  status TEXT NOT NULL DEFAULT 'open' CHECK (status IN ('open','confirmed','cancelled')),
+  move_id UUID REFERENCES stock_ledger(move_id),
+  created_by UUID REFERENCES users(id),
+  confirmed_by UUID REFERENCES users(id),
+  created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  updated_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS idx_putaway_tasks_open ON putaway_tasks(warehouse_id, created_at) WHERE status = 'open';
+
+INSERT INTO permissions(name) VALUES
+  ('wms.putaway.manage'),
+  ('wms.putaway.execute')
+ON CONFLICT DO NOTHING;
+
+INSERT INTO role_permissions(role_id, permission_id)
+SELECT r.id, p.id
+FROM roles r
+JOIN permissions p ON p.name IN ('wms.putaway.manage','wms.putaway.execute')
+WHERE r.name='SuperAdmin'
+ON CONFLICT DO NOTHING;
+
+INSERT INTO reason_codes(code, description, move_types, requires_comment, requires_reference, permission) VALUES
+  ('PUTAWAY', 'Putaway from dock to storage', ARRAY['transfer'], false, true, NULL)
+ON CONFLICT DO NOTHING;
+
+-- +goose Down
+DELETE FROM reason_codes WHERE code = 'PUTAWAY';
+DELETE FROM permissions WHERE name IN ('wms.putaway.manage','wms.putaway.execute');
+DROP TABLE IF EXISTS putaway_tasks;
+DROP TABLE IF EXISTS putaway_rules;
+ALTER TABLE items DROP COLUMN IF EXISTS item_class;
@@ -0,0 +1,116 @@
+-- +goose Up
+
+CREATE TABLE IF NOT EXISTS customers (
+  id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+  code TEXT NOT NULL UNIQUE,
+  name TEXT NOT NULL,
+  active BOOLEAN NOT NULL DEFAULT true,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  updated_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+-- A sales order is allocated once every line is fully reserved, picking from
+-- the moment a wave takes it, and picked when none of its pick tasks is open.
+CREATE TABLE IF NOT EXISTS sales_orders (
+  id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+  order_number TEXT NOT NULL UNIQUE,
+  customer_id UUID NOT NULL REFERENCES customers(id),
+  warehouse_id UUID NOT NULL REFERENCES warehouses(id),
+  carrier TEXT,
+  cutoff_at TIMESTAMPTZ,
+  status TEXT NOT NULL DEFAULT 'open' CHECK (status IN ('open','allocated','picking','picked','cancelled')),
+  wave_id UUID,
+  created_by UUID REFERENCES users(id),
+  created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  updated_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS idx_sales_orders_customer ON sales_orders(customer_id, created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_sales_orders_waveable ON sales_orders(warehouse_id, carrier, cutoff_at) WHERE status = 'allocated' AND wave_id IS NULL;
+
+-- Line quantities are in the item's base unit. Reservations live in
+-- stock_allocations with ref_type='sales_order_line' and ref_id=<line id>;
+-- qty_short is what a short pick could not re-allocate.
+CREATE TABLE IF NOT EXISTS sales_order_lines (
+  id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+  order_id UUID NOT NULL REFERENCES sales_orders(id),
+  line_no INT NOT NULL CHECK (line_no > 0),
+  item_id UUID NOT NULL REFERENCES items(id),
+  qty_ordered NUMERIC NOT NULL CHECK (qty_ordered > 0),
+  qty_picked NUMERIC NOT NULL DEFAULT 0 CHECK (qty_picked >= 0),
+  qty_short NUMERIC NOT NULL DEFAULT 0 CHECK (qty_short >= 0),
+  UNIQUE (order_id, line_no)
+);
+
+CREATE SEQUENCE IF NOT EXISTS wave_no_seq;
+
+CREATE TABLE IF NOT EXISTS waves (
+  id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+  wave_no BIGINT NOT NULL UNIQUE DEFAULT nextval('wave_no_seq'),
+  warehouse_id UUID NOT NULL REFERENCES warehouses(id),
+  carrier TEXT,
+  cutoff_at TIMESTAMPTZ,
+  staging_location_id UUID NOT NULL REFERENCES locations(id),
+  status TEXT NOT NULL DEFAULT 'picking' CHECK (status IN ('picking','completed','cancelled')),
+  created_by UUID REFERENCES users(id),
+  created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  updated_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+ALTER TABLE sales_orders DROP CONSTRAINT IF EXISTS sales_orders_wave_id_fkey;
+ALTER TABLE sales_orders ADD CONSTRAINT sales_orders_wave_id_fkey FOREIGN KEY (wave_id) REFERENCES waves(id);
+
+-- One task per allocation, walked in sequence, which follows location path.
+-- A confirmed pick is the ledger transfer with ref_type='pick_task' and
+-- ref_id=<task id>.
+CREATE TABLE IF NOT EXISTS pick_tasks (
+  id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+  wave_id UUID NOT NULL REFERENCES waves(id),
+  order_id UUID NOT NULL REFERENCES sales_orders(id),
+  order_line_id UUID NOT NULL REFERENCES sales_order_lines(id),
+  allocation_id UUID NOT NULL REFERENCES stock_allocations(id),
+  item_id UUID NOT NULL REFERENCES items(id),
+  location_id UUID NOT NULL REFERENCES locations(id),
+  qty NUMERIC NOT NULL CHECK (qty > 0),
+  qty_picked NUMERIC NOT NULL DEFAULT 0 CHECK (qty_picked >= 0),
+  sequence INT NOT NULL,
+  status TEXT NOT NULL DEFAULT 'open' CHECK (status IN ('open','picked','short','cancelled')),
+  short_reason TEXT,
+  move_id UUID REFERENCES stock_ledger(move_id),
+  picked_by UUID REFERENCES users(id),
+  picked_at TIMESTAMPTZ,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  UNIQUE (wave_id, sequence)
+);
+
+CREATE INDEX IF NOT EXISTS idx_pick_tasks_order ON pick_tasks(order_id) WHERE status = 'open';
+
+INSERT INTO permissions(name) VALUES
+  ('wms.outbound.read'),
+  ('sales.order.create'),
+  ('sales.order.allocate'),
+  ('wms.outbound.wave'),
+  ('wms.outbound.pick')
+ON CONFLICT DO NOTHING;
+
+INSERT INTO role_permissions(role_id, permission_id)
+SELECT r.id, p.id
+FROM roles r
+JOIN permissions p ON p.name IN ('wms.outbound.read','sales.order.create','sales.order.allocate','wms.outbound.wave','wms.outbound.pick')
+WHERE r.name='SuperAdmin'
+ON CONFLICT DO NOTHING;
+
+INSERT INTO reason_codes(code, description, move_types, requires_comment, requires_reference, permission) VALUES
+  ('PICK', 'Pick from bin to staging', ARRAY['transfer'], false, true, NULL)
+ON CONFLICT DO NOTHING;
+
+-- +goose Down
+DELETE FROM reason_codes WHERE code = 'PICK';
+DELETE FROM permissions WHERE name IN ('wms.outbound.read','sales.order.create','sales.order.allocate','wms.outbound.wave','wms.outbound.pick');
+DROP TABLE IF EXISTS pick_tasks;
+ALTER TABLE sales_orders DROP CONSTRAINT IF EXISTS sales_orders_wave_id_fkey;
+DROP TABLE IF EXISTS waves;
+DROP SEQUENCE IF EXISTS wave_no_seq;
+DROP TABLE IF EXISTS sales_order_lines;
+DROP TABLE IF EXISTS sales_orders;
+DROP TABLE IF EXISTS customers;
@@ -0,0 +1,113 @@
+-- +goose Up
+
+-- The consignee address printed on delivery notes.
+ALTER TABLE customers ADD COLUMN IF NOT EXISTS address TEXT;
+ALTER TABLE customers ADD COLUMN IF NOT EXISTS city TEXT;
+ALTER TABLE customers ADD COLUMN IF NOT EXISTS postal_code TEXT;
+ALTER TABLE customers ADD COLUMN IF NOT EXISTS country TEXT;
+ALTER TABLE customers ADD COLUMN IF NOT EXISTS vat_number TEXT;
+
+ALTER TABLE sales_order_lines ADD COLUMN IF NOT EXISTS qty_shipped NUMERIC NOT NULL DEFAULT 0 CHECK (qty_shipped >= 0);
+
+ALTER TABLE sales_orders DROP CONSTRAINT IF EXISTS sales_orders_status_check;
+ALTER TABLE sales_orders ADD CONSTRAINT sales_orders_status_check
+  CHECK (status IN ('open','allocated','picking','picked','shipped','cancelled'));
+
+-- A shipment takes picked goods of one order out of the wave's staging
+-- location. The ship_to_* fields are copied from the customer when the
+-- shipment is opened so a printed DDT never changes afterwards.
+CREATE TABLE IF NOT EXISTS shipments (
+  id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+  order_id UUID NOT NULL REFERENCES sales_orders(id),
+  warehouse_id UUID NOT NULL REFERENCES warehouses(id),
+  staging_location_id UUID NOT NULL REFERENCES locations(id),
+  carrier TEXT,
+  transport_reason TEXT NOT NULL DEFAULT 'Vendita',
+  ship_to_name TEXT NOT NULL,
+  ship_to_address TEXT,
+  ship_to_city TEXT,
+  ship_to_postal_code TEXT,
+  ship_to_country TEXT,
+  ship_to_vat_number TEXT,
+  status TEXT NOT NULL DEFAULT 'open' CHECK (status IN ('open','confirmed','cancelled')),
+  ddt_year INT,
+  ddt_number INT,
+  created_by UUID REFERENCES users(id),
+  confirmed_by UUID REFERENCES users(id),
+  confirmed_at TIMESTAMPTZ,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  updated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  CHECK ((status = 'confirmed') = (ddt_number IS NOT NULL)),
+  UNIQUE (warehouse_id, ddt_year, ddt_number)
+);
+
+CREATE INDEX IF NOT EXISTS idx_shipments_order ON shipments(order_id);
+
+CREATE TABLE IF NOT EXISTS shipment_cartons (
+  id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+  shipment_id UUID NOT NULL REFERENCES shipments(id),
+  carton_no INT NOT NULL CHECK (carton_no > 0),
+  weight_kg NUMERIC CHECK (weight_kg >= 0),
+  created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  UNIQUE (shipment_id, carton_no)
+);
+
+-- A confirmed carton line is the ledger issue with ref_type='shipment' and
+-- ref_id=<shipment id>.
+CREATE TABLE IF NOT EXISTS shipment_carton_lines (
+  id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+  carton_id UUID NOT NULL REFERENCES shipment_cartons(id) ON DELETE CASCADE,
+  order_line_id UUID NOT NULL REFERENCES sales_order_lines(id),
+  item_id UUID NOT NULL REFERENCES items(id),
+  qty NUMERIC NOT NULL CHECK (qty > 0),
+  lot_code TEXT,
+  serial_no TEXT,
+  move_id UUID REFERENCES stock_ledger(move_id),
+  created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS idx_shipment_carton_lines_carton ON shipment_carton_lines(carton_id);
+
+-- DDT numbers run 1..n per warehouse and calendar year without gaps: the
+-- counter row is bumped inside the confirming transaction, so a rollback
+-- gives the number back.
+CREATE TABLE IF NOT EXISTS ddt_counters (
+  warehouse_id UUID NOT NULL REFERENCES warehouses(id),
+  year INT NOT NULL,
+  last_number INT NOT NULL,
+  PRIMARY KEY (warehouse_id, year)
+);
+
+INSERT INTO permissions(name) VALUES
+  ('wms.shipping.read'),
+  ('wms.shipping.pack'),
+  ('wms.shipping.confirm')
+ON CONFLICT DO NOTHING;
+
+INSERT INTO role_permissions(role_id, permission_id)
+SELECT r.id, p.id
+FROM roles r
+JOIN permissions p ON p.name IN ('wms.shipping.read','wms.shipping.pack','wms.shipping.confirm')
+WHERE r.name='SuperAdmin'
+ON CONFLICT DO NOTHING;
+
+INSERT INTO reason_codes(code, description, move_types, requires_comment, requires_reference, permission) VALUES
+  ('SHIPMENT', 'Shipped to customer', ARRAY['issue'], false, true, NULL)
+ON CONFLICT DO NOTHING;
+
+-- +goose Down
+DELETE FROM reason_codes WHERE code = 'SHIPMENT';
+DELETE FROM permissions WHERE name IN ('wms.shipping.read','wms.shipping.pack','wms.shipping.confirm');
+DROP TABLE IF EXISTS ddt_counters;
+DROP TABLE IF EXISTS shipment_carton_lines;
+DROP TABLE IF EXISTS shipment_cartons;
+DROP TABLE IF EXISTS shipments;
+ALTER TABLE sales_orders DROP CONSTRAINT IF EXISTS sales_orders_status_check;
+ALTER TABLE sales_orders ADD CONSTRAINT sales_orders_status_check
+  CHECK (status IN ('open','allocated','picking','picked','cancelled'));
+ALTER TABLE sales_order_lines DROP COLUMN IF EXISTS qty_shipped;
+ALTER TABLE customers DROP COLUMN IF EXISTS vat_number;
+ALTER TABLE customers DROP COLUMN IF EXISTS country;
+ALTER TABLE customers DROP COLUMN IF EXISTS postal_code;
+ALTER TABLE customers DROP COLUMN IF EXISTS city;
+ALTER TABLE customers DROP COLUMN IF EXISTS address;
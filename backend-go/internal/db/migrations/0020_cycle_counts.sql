@@ -0,0 +1,72 @@
+-- +goose Up
+
+ALTER TABLE items ADD COLUMN IF NOT EXISTS abc_class TEXT CHECK (abc_class IN ('A','B','C'));
+
+-- A count session covers the stock of one warehouse, optionally narrowed to
+-- a location path prefix (zone), an item class and an ABC class. Variances
+-- beyond the tolerance (the larger of tolerance_qty and tolerance_pct of the
+-- expected quantity) are recounted once before a supervisor approves them.
+CREATE TABLE IF NOT EXISTS count_sessions (
+  id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+  warehouse_id UUID NOT NULL REFERENCES warehouses(id),
+  path_prefix TEXT,
+  item_class TEXT,
+  abc_class TEXT CHECK (abc_class IN ('A','B','C')),
+  blind BOOLEAN NOT NULL DEFAULT true,
+  freeze BOOLEAN NOT NULL DEFAULT false,
+  tolerance_qty NUMERIC NOT NULL DEFAULT 0 CHECK (tolerance_qty >= 0),
+  tolerance_pct NUMERIC NOT NULL DEFAULT 0 CHECK (tolerance_pct >= 0),
+  status TEXT NOT NULL DEFAULT 'counting' CHECK (status IN ('counting','approved','cancelled')),
+  created_by UUID REFERENCES users(id),
+  approved_by UUID REFERENCES users(id),
+  approved_at TIMESTAMPTZ,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  updated_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+-- One task per balance key in scope. expected_qty is the on-hand quantity
+-- when the task was last counted; first_qty keeps the count that triggered
+-- the recount.
+CREATE TABLE IF NOT EXISTS count_tasks (
+  id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+  session_id UUID NOT NULL REFERENCES count_sessions(id),
+  location_id UUID NOT NULL REFERENCES locations(id),
+  item_id UUID NOT NULL REFERENCES items(id),
+  lot_id UUID REFERENCES lots(id),
+  serial_id UUID REFERENCES serials(id),
+  status TEXT NOT NULL DEFAULT 'open' CHECK (status IN ('open','recount','counted')),
+  expected_qty NUMERIC NOT NULL DEFAULT 0,
+  first_qty NUMERIC,
+  first_counted_by UUID REFERENCES users(id),
+  counted_qty NUMERIC CHECK (counted_qty >= 0),
+  counted_by UUID REFERENCES users(id),
+  counted_at TIMESTAMPTZ,
+  move_id UUID REFERENCES stock_ledger(move_id),
+  created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  CONSTRAINT count_tasks_key UNIQUE NULLS NOT DISTINCT (session_id, location_id, item_id, lot_id, serial_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_count_tasks_session ON count_tasks(session_id, status);
+
+-- A frozen location takes no moves except the count's own adjustments.
+ALTER TABLE locations ADD COLUMN IF NOT EXISTS frozen_by_count_id UUID REFERENCES count_sessions(id);
+
+INSERT INTO permissions(name) VALUES
+  ('wms.count.manage'),
+  ('wms.count.execute'),
+  ('wms.count.approve')
+ON CONFLICT DO NOTHING;
+
+INSERT INTO role_permissions(role_id, permission_id)
+SELECT r.id, p.id
+FROM roles r
+JOIN permissions p ON p.name IN ('wms.count.manage','wms.count.execute','wms.count.approve')
+WHERE r.name='SuperAdmin'
+ON CONFLICT DO NOTHING;
+
+-- +goose Down
+DELETE FROM permissions WHERE name IN ('wms.count.manage','wms.count.execute','wms.count.approve');
+ALTER TABLE locations DROP COLUMN IF EXISTS frozen_by_count_id;
+DROP TABLE IF EXISTS count_tasks;
+DROP TABLE IF EXISTS count_sessions;
+ALTER TABLE items DROP COLUMN IF EXISTS abc_class;
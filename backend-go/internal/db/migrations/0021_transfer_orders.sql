@@ -0,0 +1,74 @@
+-- +goose Up
+
+-- A transfer order moves stock between warehouses in two legs: shipping
+-- books it from the source bins into the destination warehouse's in-transit
+-- location, receiving books it out of there into destination bins. What is
+-- still in transit when the order is closed is written off as a discrepancy.
+CREATE SEQUENCE IF NOT EXISTS transfer_no_seq;
+
+CREATE TABLE IF NOT EXISTS transfer_orders (
+  id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+  transfer_no BIGINT NOT NULL UNIQUE DEFAULT nextval('transfer_no_seq'),
+  from_warehouse_id UUID NOT NULL REFERENCES warehouses(id),
+  to_warehouse_id UUID NOT NULL REFERENCES warehouses(id),
+  transit_location_id UUID NOT NULL REFERENCES locations(id),
+  status TEXT NOT NULL DEFAULT 'open' CHECK (status IN ('open','in_transit','received','closed','cancelled')),
+  comment TEXT,
+  created_by UUID REFERENCES users(id),
+  created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  updated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  CHECK (from_warehouse_id <> to_warehouse_id)
+);
+
+CREATE TABLE IF NOT EXISTS transfer_order_lines (
+  id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+  order_id UUID NOT NULL REFERENCES transfer_orders(id),
+  line_no INT NOT NULL,
+  item_id UUID NOT NULL REFERENCES items(id),
+  qty NUMERIC NOT NULL CHECK (qty > 0),
+  qty_shipped NUMERIC NOT NULL DEFAULT 0 CHECK (qty_shipped >= 0),
+  qty_received NUMERIC NOT NULL DEFAULT 0 CHECK (qty_received >= 0),
+  qty_lost NUMERIC NOT NULL DEFAULT 0 CHECK (qty_lost >= 0),
+  UNIQUE (order_id, line_no),
+  CHECK (qty_shipped <= qty),
+  CHECK (qty_received + qty_lost <= qty_shipped)
+);
+
+-- Every ledger line booked for an order, by leg. Lot and serial come from
+-- the ledger, which is how receipts know what is in transit for the order.
+CREATE TABLE IF NOT EXISTS transfer_order_moves (
+  move_id UUID PRIMARY KEY REFERENCES stock_ledger(move_id),
+  order_id UUID NOT NULL REFERENCES transfer_orders(id),
+  line_id UUID NOT NULL REFERENCES transfer_order_lines(id),
+  leg TEXT NOT NULL CHECK (leg IN ('ship','receive','loss')),
+  created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS idx_transfer_order_moves_order ON transfer_order_moves(order_id);
+
+INSERT INTO permissions(name) VALUES
+  ('wms.transfer.read'),
+  ('wms.transfer.write'),
+  ('wms.transfer.ship'),
+  ('wms.transfer.receive')
+ON CONFLICT DO NOTHING;
+
+INSERT INTO role_permissions(role_id, permission_id)
+SELECT r.id, p.id
+FROM roles r
+JOIN permissions p ON p.name IN ('wms.transfer.read','wms.transfer.write','wms.transfer.ship','wms.transfer.receive')
+WHERE r.name='SuperAdmin'
+ON CONFLICT DO NOTHING;
+
+INSERT INTO reason_codes(code, description, move_types, requires_comment, requires_reference, permission) VALUES
+  ('TRANSIT', 'Inter-warehouse transfer leg', ARRAY['transfer'], false, true, NULL),
+  ('TRANSIT_LOSS', 'Lost in transit', ARRAY['issue'], true, true, NULL)
+ON CONFLICT DO NOTHING;
+
+-- +goose Down
+DELETE FROM reason_codes WHERE code IN ('TRANSIT','TRANSIT_LOSS');
+DELETE FROM permissions WHERE name IN ('wms.transfer.read','wms.transfer.write','wms.transfer.ship','wms.transfer.receive');
+DROP TABLE IF EXISTS transfer_order_moves;
+DROP TABLE IF EXISTS transfer_order_lines;
+DROP TABLE IF EXISTS transfer_orders;
+DROP SEQUENCE IF EXISTS transfer_no_seq;
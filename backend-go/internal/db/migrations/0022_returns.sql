@@ -0,0 +1,104 @@
+-- +goose Up
+
+-- Why customers send goods back; reported on with the dispositions.
+CREATE TABLE IF NOT EXISTS return_reasons (
+  code TEXT PRIMARY KEY,
+  description TEXT NOT NULL,
+  active BOOLEAN NOT NULL DEFAULT true
+);
+
+INSERT INTO return_reasons(code, description) VALUES
+  ('DAMAGED', 'Damaged on arrival'),
+  ('DEFECTIVE', 'Defective'),
+  ('WRONG_ITEM', 'Wrong item shipped'),
+  ('NOT_NEEDED', 'No longer needed'),
+  ('OTHER', 'Other')
+ON CONFLICT DO NOTHING;
+
+CREATE SEQUENCE IF NOT EXISTS rma_no_seq;
+
+-- A return authorization refers to one confirmed shipment and can only take
+-- back what it shipped. Returned goods are received into returns_location_id
+-- (a returns or quarantine location of the shipping warehouse) and leave it
+-- by disposition.
+CREATE TABLE IF NOT EXISTS rmas (
+  id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+  rma_no BIGINT NOT NULL UNIQUE DEFAULT nextval('rma_no_seq'),
+  shipment_id UUID NOT NULL REFERENCES shipments(id),
+  order_id UUID NOT NULL REFERENCES sales_orders(id),
+  customer_id UUID NOT NULL REFERENCES customers(id),
+  warehouse_id UUID NOT NULL REFERENCES warehouses(id),
+  returns_location_id UUID NOT NULL REFERENCES locations(id),
+  status TEXT NOT NULL DEFAULT 'authorized' CHECK (status IN ('authorized','received','inspected','closed','cancelled')),
+  comment TEXT,
+  created_by UUID REFERENCES users(id),
+  created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  updated_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS idx_rmas_shipment ON rmas(shipment_id);
+
+CREATE TABLE IF NOT EXISTS rma_lines (
+  id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+  rma_id UUID NOT NULL REFERENCES rmas(id),
+  line_no INT NOT NULL,
+  item_id UUID NOT NULL REFERENCES items(id),
+  lot_code TEXT,
+  serial_no TEXT,
+  reason_code TEXT NOT NULL REFERENCES return_reasons(code),
+  qty_authorized NUMERIC NOT NULL CHECK (qty_authorized > 0),
+  qty_received NUMERIC NOT NULL DEFAULT 0 CHECK (qty_received >= 0),
+  qty_dispositioned NUMERIC NOT NULL DEFAULT 0 CHECK (qty_dispositioned >= 0),
+  UNIQUE (rma_id, line_no),
+  CHECK (qty_received <= qty_authorized),
+  CHECK (qty_dispositioned <= qty_received)
+);
+
+-- One inspection outcome and the ledger move it booked: restock and
+-- refurbish transfer to to_location_id, scrap and rtv issue the goods.
+CREATE TABLE IF NOT EXISTS rma_dispositions (
+  id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+  rma_id UUID NOT NULL REFERENCES rmas(id),
+  line_id UUID NOT NULL REFERENCES rma_lines(id),
+  disposition TEXT NOT NULL CHECK (disposition IN ('restock','refurbish','scrap','rtv')),
+  qty NUMERIC NOT NULL CHECK (qty > 0),
+  to_location_id UUID REFERENCES locations(id),
+  move_id UUID NOT NULL REFERENCES stock_ledger(move_id),
+  comment TEXT,
+  inspected_by UUID REFERENCES users(id),
+  created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  CHECK ((disposition IN ('restock','refurbish')) = (to_location_id IS NOT NULL))
+);
+
+CREATE INDEX IF NOT EXISTS idx_rma_dispositions_rma ON rma_dispositions(rma_id);
+
+INSERT INTO permissions(name) VALUES
+  ('wms.returns.read'),
+  ('wms.returns.authorize'),
+  ('wms.returns.receive'),
+  ('wms.returns.inspect')
+ON CONFLICT DO NOTHING;
+
+INSERT INTO role_permissions(role_id, permission_id)
+SELECT r.id, p.id
+FROM roles r
+JOIN permissions p ON p.name IN ('wms.returns.read','wms.returns.authorize','wms.returns.receive','wms.returns.inspect')
+WHERE r.name='SuperAdmin'
+ON CONFLICT DO NOTHING;
+
+INSERT INTO reason_codes(code, description, move_types, requires_comment, requires_reference, permission) VALUES
+  ('RETURN', 'Customer return receipt', ARRAY['receipt'], false, true, NULL),
+  ('RMA_RESTOCK', 'Return restocked', ARRAY['transfer'], false, true, NULL),
+  ('RMA_REFURBISH', 'Return sent to refurbishment', ARRAY['transfer'], false, true, NULL),
+  ('RMA_SCRAP', 'Return scrapped', ARRAY['issue'], false, true, NULL),
+  ('RMA_RTV', 'Return to vendor', ARRAY['issue'], false, true, NULL)
+ON CONFLICT DO NOTHING;
+
+-- +goose Down
+DELETE FROM reason_codes WHERE code IN ('RETURN','RMA_RESTOCK','RMA_REFURBISH','RMA_SCRAP','RMA_RTV');
+DELETE FROM permissions WHERE name IN ('wms.returns.read','wms.returns.authorize','wms.returns.receive','wms.returns.inspect');
+DROP TABLE IF EXISTS rma_dispositions;
+DROP TABLE IF EXISTS rma_lines;
+DROP TABLE IF EXISTS rmas;
+DROP SEQUENCE IF EXISTS rma_no_seq;
+DROP TABLE IF EXISTS return_reasons;
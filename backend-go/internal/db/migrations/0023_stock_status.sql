@@ -0,0 +1,119 @@
+-- +goose Up
+
+-- Stock status is part of the balance key: a quarantined pallet stays in its
+-- bin but no longer counts as available. Only available stock is allocated.
+ALTER TABLE stock_balance
+  ADD COLUMN IF NOT EXISTS status TEXT NOT NULL DEFAULT 'available'
+    CHECK (status IN ('available','quarantine','damaged','blocked'));
+ALTER TABLE stock_balance DROP CONSTRAINT IF EXISTS stock_balance_key;
+ALTER TABLE stock_balance
+  ADD CONSTRAINT stock_balance_key UNIQUE NULLS NOT DISTINCT (item_id, location_id, lot_id, serial_id, status);
+ALTER TABLE stock_balance
+  ADD CONSTRAINT stock_balance_allocated_available CHECK (status = 'available' OR qty_allocated = 0);
+
+-- Every ledger line records the status of the stock it moves. Status moves
+-- keep the stock in place (from = to) and carry the new status in to_status.
+ALTER TABLE stock_ledger
+  ADD COLUMN IF NOT EXISTS status TEXT NOT NULL DEFAULT 'available'
+    CHECK (status IN ('available','quarantine','damaged','blocked')),
+  ADD COLUMN IF NOT EXISTS to_status TEXT
+    CHECK (to_status IN ('available','quarantine','damaged','blocked'));
+
+ALTER TABLE stock_ledger DROP CONSTRAINT IF EXISTS stock_ledger_move_type_check;
+ALTER TABLE stock_ledger ADD CONSTRAINT stock_ledger_move_type_check
+  CHECK (move_type IN ('receipt','issue','adjustment','transfer','status'));
+
+ALTER TABLE stock_ledger DROP CONSTRAINT IF EXISTS stock_ledger_move_type_sides;
+ALTER TABLE stock_ledger ADD CONSTRAINT stock_ledger_move_type_sides CHECK (
+  CASE move_type
+    WHEN 'receipt' THEN from_location_id IS NULL AND to_location_id IS NOT NULL
+    WHEN 'issue' THEN from_location_id IS NOT NULL AND to_location_id IS NULL
+    WHEN 'adjustment' THEN (from_location_id IS NULL) <> (to_location_id IS NULL)
+    WHEN 'status' THEN from_location_id IS NOT NULL AND from_location_id = to_location_id AND to_status IS NOT NULL AND to_status <> status
+    ELSE from_location_id IS NOT NULL AND to_location_id IS NOT NULL
+  END
+) NOT VALID;
+ALTER TABLE stock_ledger ADD CONSTRAINT stock_ledger_to_status_only_status
+  CHECK (move_type = 'status' OR to_status IS NULL);
+
+ALTER TABLE reason_codes DROP CONSTRAINT IF EXISTS reason_codes_move_types_check;
+ALTER TABLE reason_codes ADD CONSTRAINT reason_codes_move_types_check CHECK (
+  cardinality(move_types) > 0
+  AND move_types <@ ARRAY['receipt','issue','adjustment','transfer','status']::text[]
+);
+
+ALTER TABLE stock_balance_snapshot_lines
+  ADD COLUMN IF NOT EXISTS status TEXT NOT NULL DEFAULT 'available';
+ALTER TABLE stock_balance_snapshot_lines DROP CONSTRAINT IF EXISTS stock_balance_snapshot_lines_key;
+ALTER TABLE stock_balance_snapshot_lines
+  ADD CONSTRAINT stock_balance_snapshot_lines_key UNIQUE NULLS NOT DISTINCT (snapshot_id, item_id, location_id, lot_id, serial_id, status);
+
+INSERT INTO permissions(name) VALUES ('wms.stock.status') ON CONFLICT DO NOTHING;
+
+INSERT INTO role_permissions(role_id, permission_id)
+SELECT r.id, p.id
+FROM roles r
+JOIN permissions p ON p.name = 'wms.stock.status'
+WHERE r.name='SuperAdmin'
+ON CONFLICT DO NOTHING;
+
+INSERT INTO reason_codes(code, description, move_types, requires_comment, requires_reference, permission) VALUES
+  ('QC_HOLD', 'Quality hold', ARRAY['status'], true, false, 'wms.stock.status'),
+  ('QC_RELEASE', 'Released by quality control', ARRAY['status'], true, false, 'wms.stock.status'),
+  ('STATUS_CHANGE', 'Stock status change', ARRAY['status'], true, false, 'wms.stock.status')
+ON CONFLICT DO NOTHING;
+
+UPDATE reason_codes SET move_types = move_types || ARRAY['status']
+WHERE code = 'REVERSAL' AND NOT 'status' = ANY(move_types);
+
+-- +goose Down
+UPDATE reason_codes SET move_types = array_remove(move_types, 'status') WHERE code = 'REVERSAL';
+DELETE FROM reason_codes WHERE code IN ('QC_HOLD','QC_RELEASE','STATUS_CHANGE');
+DELETE FROM permissions WHERE name = 'wms.stock.status';
+
+ALTER TABLE stock_balance_snapshot_lines DROP CONSTRAINT IF EXISTS stock_balance_snapshot_lines_key;
+DELETE FROM stock_balance_snapshot_lines WHERE status <> 'available';
+ALTER TABLE stock_balance_snapshot_lines DROP COLUMN IF EXISTS status;
+ALTER TABLE stock_balance_snapshot_lines
+  ADD CONSTRAINT stock_balance_snapshot_lines_key UNIQUE NULLS NOT DISTINCT (snapshot_id, item_id, location_id, lot_id, serial_id);
+
+ALTER TABLE reason_codes DROP CONSTRAINT IF EXISTS reason_codes_move_types_check;
+ALTER TABLE reason_codes ADD CONSTRAINT reason_codes_move_types_check CHECK (
+  cardinality(move_types) > 0
+  AND move_types <@ ARRAY['receipt','issue','adjustment','transfer']::text[]
+) NOT VALID;
+
+-- The ledger is append-only, so status lines stay; the narrower checks only
+-- apply to new rows.
+ALTER TABLE stock_ledger DROP CONSTRAINT IF EXISTS stock_ledger_to_status_only_status;
+ALTER TABLE stock_ledger DROP CONSTRAINT IF EXISTS stock_ledger_move_type_sides;
+ALTER TABLE stock_ledger ADD CONSTRAINT stock_ledger_move_type_sides CHECK (
+  CASE move_type
+    WHEN 'receipt' THEN from_location_id IS NULL AND to_location_id IS NOT NULL
+    WHEN 'issue' THEN from_location_id IS NOT NULL AND to_location_id IS NULL
+    WHEN 'adjustment' THEN (from_location_id IS NULL) <> (to_location_id IS NULL)
+    ELSE from_location_id IS NOT NULL AND to_location_id IS NOT NULL
+  END
+) NOT VALID;
+ALTER TABLE stock_ledger DROP CONSTRAINT IF EXISTS stock_ledger_move_type_check;
+ALTER TABLE stock_ledger ADD CONSTRAINT stock_ledger_move_type_check
+  CHECK (move_type IN ('receipt','issue','adjustment','transfer')) NOT VALID;
+ALTER TABLE stock_ledger DROP COLUMN IF EXISTS to_status;
+ALTER TABLE stock_ledger DROP COLUMN IF EXISTS status;
+
+-- Fold held stock back into the available rows before the key narrows.
+ALTER TABLE stock_balance DROP CONSTRAINT IF EXISTS stock_balance_allocated_available;
+WITH held AS (
+  DELETE FROM stock_balance WHERE status <> 'available'
+  RETURNING item_id, location_id, lot_id, serial_id, qty_on_hand
+)
+INSERT INTO stock_balance (item_id, location_id, lot_id, serial_id, qty_on_hand, qty_allocated, status)
+SELECT item_id, location_id, lot_id, serial_id, SUM(qty_on_hand), 0, 'available'
+FROM held
+GROUP BY item_id, location_id, lot_id, serial_id
+ON CONFLICT ON CONSTRAINT stock_balance_key
+DO UPDATE SET qty_on_hand = stock_balance.qty_on_hand + EXCLUDED.qty_on_hand, updated_at = now();
+ALTER TABLE stock_balance DROP CONSTRAINT IF EXISTS stock_balance_key;
+ALTER TABLE stock_balance DROP COLUMN IF EXISTS status;
+ALTER TABLE stock_balance
+  ADD CONSTRAINT stock_balance_key UNIQUE NULLS NOT DISTINCT (item_id, location_id, lot_id, serial_id);
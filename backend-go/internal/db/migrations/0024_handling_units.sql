@@ -0,0 +1,101 @@
+-- +goose Up
+
+-- Serial references for generated SSCCs; the company prefix comes from the
+-- GS1_COMPANY_PREFIX setting.
+CREATE SEQUENCE IF NOT EXISTS sscc_serial_seq;
+
+-- A handling unit (pallet, carton, tote) identified by an SSCC-18 or a free
+-- LPN. Nested units sit at their parent's location and move with it.
+CREATE TABLE IF NOT EXISTS handling_units (
+  id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+  code TEXT NOT NULL UNIQUE,
+  hu_type TEXT NOT NULL CHECK (hu_type IN ('pallet','carton','tote')),
+  parent_id UUID REFERENCES handling_units(id),
+  location_id UUID NOT NULL REFERENCES locations(id),
+  created_by UUID REFERENCES users(id),
+  created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  updated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  CHECK (parent_id <> id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_handling_units_parent ON handling_units(parent_id) WHERE parent_id IS NOT NULL;
+CREATE INDEX IF NOT EXISTS idx_handling_units_location ON handling_units(location_id);
+
+-- Stock inside a handling unit is its own balance row; loose stock keeps a
+-- NULL hu_id.
+ALTER TABLE stock_balance ADD COLUMN IF NOT EXISTS hu_id UUID REFERENCES handling_units(id);
+ALTER TABLE stock_balance DROP CONSTRAINT IF EXISTS stock_balance_key;
+ALTER TABLE stock_balance
+  ADD CONSTRAINT stock_balance_key UNIQUE NULLS NOT DISTINCT (item_id, location_id, lot_id, serial_id, status, hu_id);
+CREATE INDEX IF NOT EXISTS idx_stock_balance_hu ON stock_balance(hu_id) WHERE hu_id IS NOT NULL;
+
+ALTER TABLE stock_ledger
+  ADD COLUMN IF NOT EXISTS from_hu_id UUID REFERENCES handling_units(id),
+  ADD COLUMN IF NOT EXISTS to_hu_id UUID REFERENCES handling_units(id);
+CREATE INDEX IF NOT EXISTS idx_stock_ledger_to_hu ON stock_ledger(to_hu_id) WHERE to_hu_id IS NOT NULL;
+
+ALTER TABLE stock_allocations ADD COLUMN IF NOT EXISTS hu_id UUID REFERENCES handling_units(id);
+
+ALTER TABLE stock_balance_snapshot_lines ADD COLUMN IF NOT EXISTS hu_id UUID;
+ALTER TABLE stock_balance_snapshot_lines DROP CONSTRAINT IF EXISTS stock_balance_snapshot_lines_key;
+ALTER TABLE stock_balance_snapshot_lines
+  ADD CONSTRAINT stock_balance_snapshot_lines_key UNIQUE NULLS NOT DISTINCT (snapshot_id, item_id, location_id, lot_id, serial_id, status, hu_id);
+
+INSERT INTO permissions(name) VALUES
+  ('wms.hu.read'),
+  ('wms.hu.write'),
+  ('wms.hu.move')
+ON CONFLICT DO NOTHING;
+
+INSERT INTO role_permissions(role_id, permission_id)
+SELECT r.id, p.id
+FROM roles r
+JOIN permissions p ON p.name IN ('wms.hu.read','wms.hu.write','wms.hu.move')
+WHERE r.name='SuperAdmin'
+ON CONFLICT DO NOTHING;
+
+INSERT INTO reason_codes(code, description, move_types, requires_comment, requires_reference, permission) VALUES
+  ('HU_PACK', 'Packed into a handling unit', ARRAY['transfer'], false, true, NULL),
+  ('HU_UNPACK', 'Unpacked from a handling unit', ARRAY['transfer'], false, true, NULL),
+  ('HU_MOVE', 'Handling unit moved', ARRAY['transfer'], false, true, NULL)
+ON CONFLICT DO NOTHING;
+
+-- +goose Down
+DELETE FROM reason_codes WHERE code IN ('HU_PACK','HU_UNPACK','HU_MOVE');
+DELETE FROM permissions WHERE name IN ('wms.hu.read','wms.hu.write','wms.hu.move');
+
+ALTER TABLE stock_balance_snapshot_lines DROP CONSTRAINT IF EXISTS stock_balance_snapshot_lines_key;
+DELETE FROM stock_balance_snapshot_lines WHERE hu_id IS NOT NULL;
+ALTER TABLE stock_balance_snapshot_lines DROP COLUMN IF EXISTS hu_id;
+ALTER TABLE stock_balance_snapshot_lines
+  ADD CONSTRAINT stock_balance_snapshot_lines_key UNIQUE NULLS NOT DISTINCT (snapshot_id, item_id, location_id, lot_id, serial_id, status);
+
+ALTER TABLE stock_allocations DROP COLUMN IF EXISTS hu_id;
+
+-- The ledger is append-only; its handling unit columns go with the table.
+DROP INDEX IF EXISTS idx_stock_ledger_to_hu;
+ALTER TABLE stock_ledger DROP COLUMN IF EXISTS to_hu_id;
+ALTER TABLE stock_ledger DROP COLUMN IF EXISTS from_hu_id;
+
+-- Fold packed stock back into the loose rows before the key narrows.
+DROP INDEX IF EXISTS idx_stock_balance_hu;
+WITH packed AS (
+  DELETE FROM stock_balance WHERE hu_id IS NOT NULL
+  RETURNING item_id, location_id, lot_id, serial_id, status, qty_on_hand, qty_allocated
+)
+INSERT INTO stock_balance (item_id, location_id, lot_id, serial_id, status, qty_on_hand, qty_allocated)
+SELECT item_id, location_id, lot_id, serial_id, status, SUM(qty_on_hand), SUM(qty_allocated)
+FROM packed
+GROUP BY item_id, location_id, lot_id, serial_id, status
+ON CONFLICT ON CONSTRAINT stock_balance_key
+DO UPDATE SET
+  qty_on_hand = stock_balance.qty_on_hand + EXCLUDED.qty_on_hand,
+  qty_allocated = stock_balance.qty_allocated + EXCLUDED.qty_allocated,
+  updated_at = now();
+ALTER TABLE stock_balance DROP CONSTRAINT IF EXISTS stock_balance_key;
+ALTER TABLE stock_balance DROP COLUMN IF EXISTS hu_id;
+ALTER TABLE stock_balance
+  ADD CONSTRAINT stock_balance_key UNIQUE NULLS NOT DISTINCT (item_id, location_id, lot_id, serial_id, status);
+
+DROP TABLE IF EXISTS handling_units;
+DROP SEQUENCE IF EXISTS sscc_serial_seq;
@@ -0,0 +1,40 @@
+-- +goose Up
+
+-- An item can carry several barcodes, each standing for one of its units:
+-- the EAN on the single piece, a GTIN-14 on the carton, internal labels.
+-- gtin holds the GTIN-14 form of EAN and GTIN-14 codes so an EAN-13 also
+-- matches the (01) of a GS1-128 label; internal codes have none.
+CREATE TABLE IF NOT EXISTS item_barcodes (
+  barcode TEXT PRIMARY KEY,
+  gtin TEXT UNIQUE,
+  item_id UUID NOT NULL REFERENCES items(id) ON DELETE CASCADE,
+  barcode_type TEXT NOT NULL CHECK (barcode_type IN ('ean','gtin14','internal')),
+  uom TEXT NOT NULL REFERENCES uoms(code) ON UPDATE CASCADE,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  CHECK ((barcode_type = 'internal') = (gtin IS NULL))
+);
+
+CREATE INDEX IF NOT EXISTS idx_item_barcodes_item ON item_barcodes(item_id);
+
+-- items.barcode stays as the primary barcode and is carried over in the base
+-- unit. Numeric codes with a valid GS1 check digit become EAN/GTIN-14; a code
+-- shared by several items is kept for the first one only.
+INSERT INTO item_barcodes (barcode, gtin, item_id, barcode_type, uom)
+SELECT i.barcode,
+       CASE WHEN v.gs1 THEN lpad(i.barcode, 14, '0') END,
+       i.id,
+       CASE WHEN NOT v.gs1 THEN 'internal' WHEN length(i.barcode) = 14 THEN 'gtin14' ELSE 'ean' END,
+       i.uom
+FROM items i
+CROSS JOIN LATERAL (
+  SELECT CASE WHEN i.barcode ~ '^([0-9]{8}|[0-9]{12,14})$' THEN (
+    SELECT SUM(substr(lpad(i.barcode, 14, '0'), n, 1)::int * CASE WHEN n % 2 = 1 THEN 3 ELSE 1 END) % 10 = 0
+    FROM generate_series(1, 14) n
+  ) ELSE false END AS gs1
+) v
+WHERE COALESCE(i.barcode, '') <> ''
+ORDER BY i.created_at, i.id
+ON CONFLICT DO NOTHING;
+
+-- +goose Down
+DROP TABLE IF EXISTS item_barcodes;
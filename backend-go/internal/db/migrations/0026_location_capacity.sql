@@ -0,0 +1,49 @@
+-- +goose Up
+
+-- Storage limits of a bin; NULL means unlimited. max_hu counts the top-level
+-- handling units standing in it (0 for shelf bins that take no pallets),
+-- max_skus the distinct items with stock on hand. With mixed_lots off an item
+-- may only lie in the bin in one lot at a time. zone groups bins for the
+-- utilization report.
+ALTER TABLE locations
+  ADD COLUMN IF NOT EXISTS zone TEXT,
+  ADD COLUMN IF NOT EXISTS max_weight_kg NUMERIC CHECK (max_weight_kg > 0),
+  ADD COLUMN IF NOT EXISTS max_volume_m3 NUMERIC CHECK (max_volume_m3 > 0),
+  ADD COLUMN IF NOT EXISTS max_hu INT CHECK (max_hu >= 0),
+  ADD COLUMN IF NOT EXISTS max_skus INT CHECK (max_skus > 0),
+  ADD COLUMN IF NOT EXISTS mixed_lots BOOLEAN NOT NULL DEFAULT true;
+
+CREATE INDEX IF NOT EXISTS idx_locations_zone ON locations(warehouse_id, zone);
+
+-- Weight and dimensions of one base unit of the item. Items without them do
+-- not count against weight or volume limits.
+ALTER TABLE items
+  ADD COLUMN IF NOT EXISTS weight_kg NUMERIC CHECK (weight_kg > 0),
+  ADD COLUMN IF NOT EXISTS length_cm NUMERIC CHECK (length_cm > 0),
+  ADD COLUMN IF NOT EXISTS width_cm NUMERIC CHECK (width_cm > 0),
+  ADD COLUMN IF NOT EXISTS height_cm NUMERIC CHECK (height_cm > 0);
+
+INSERT INTO permissions(name) VALUES ('wms.stock.capacity_override') ON CONFLICT DO NOTHING;
+
+INSERT INTO role_permissions(role_id, permission_id)
+SELECT r.id, p.id
+FROM roles r
+JOIN permissions p ON p.name = 'wms.stock.capacity_override'
+WHERE r.name='SuperAdmin'
+ON CONFLICT DO NOTHING;
+
+-- +goose Down
+DELETE FROM permissions WHERE name = 'wms.stock.capacity_override';
+ALTER TABLE items
+  DROP COLUMN IF EXISTS height_cm,
+  DROP COLUMN IF EXISTS width_cm,
+  DROP COLUMN IF EXISTS length_cm,
+  DROP COLUMN IF EXISTS weight_kg;
+DROP INDEX IF EXISTS idx_locations_zone;
+ALTER TABLE locations
+  DROP COLUMN IF EXISTS mixed_lots,
+  DROP COLUMN IF EXISTS max_skus,
+  DROP COLUMN IF EXISTS max_hu,
+  DROP COLUMN IF EXISTS max_volume_m3,
+  DROP COLUMN IF EXISTS max_weight_kg,
+  DROP COLUMN IF EXISTS zone;
@@ -0,0 +1,24 @@
+-- +goose Up
+CREATE EXTENSION IF NOT EXISTS ltree;
+
+-- Location paths become ltree so stock can be queried at any node of the
+-- warehouse tree (zone.aisle.rack.level.bin). Existing paths keep their
+-- labels: "/" and ">" separate labels like ".", any other character outside
+-- [A-Za-z0-9_] becomes "_", and empty paths become NULL. Putaway rules and
+-- count sessions select a subtree with the same labels.
+ALTER TABLE locations
+  ALTER COLUMN path TYPE ltree USING NULLIF(btrim(regexp_replace(regexp_replace(translate(btrim(path), '/>', '..'), '[^A-Za-z0-9_.]', '_', 'g'), '\.{2,}', '.', 'g'), '.'), '')::ltree;
+
+ALTER TABLE putaway_rules
+  ALTER COLUMN path_prefix TYPE ltree USING NULLIF(btrim(regexp_replace(regexp_replace(translate(btrim(path_prefix), '/>', '..'), '[^A-Za-z0-9_.]', '_', 'g'), '\.{2,}', '.', 'g'), '.'), '')::ltree;
+
+ALTER TABLE count_sessions
+  ALTER COLUMN path_prefix TYPE ltree USING NULLIF(btrim(regexp_replace(regexp_replace(translate(btrim(path_prefix), '/>', '..'), '[^A-Za-z0-9_.]', '_', 'g'), '\.{2,}', '.', 'g'), '.'), '')::ltree;
+
+CREATE INDEX IF NOT EXISTS idx_locations_path ON locations USING gist (path);
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_locations_path;
+ALTER TABLE count_sessions ALTER COLUMN path_prefix TYPE TEXT USING path_prefix::text;
+ALTER TABLE putaway_rules ALTER COLUMN path_prefix TYPE TEXT USING path_prefix::text;
+ALTER TABLE locations ALTER COLUMN path TYPE TEXT USING path::text;
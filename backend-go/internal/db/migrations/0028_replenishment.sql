@@ -0,0 +1,71 @@
+-- +goose Up
+
+-- A pick face (item at location) is replenished once its free stock plus
+-- open inbound tasks drops below min_qty: up to max_qty, or by whole
+-- multiples of fixed_qty until min_qty is reached again. Quantities are in
+-- the item's base unit. source_path limits the reserve to a subtree of the
+-- location tree.
+CREATE TABLE IF NOT EXISTS replenishment_settings (
+  id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+  warehouse_id UUID NOT NULL REFERENCES warehouses(id),
+  item_id UUID NOT NULL REFERENCES items(id),
+  location_id UUID NOT NULL REFERENCES locations(id),
+  min_qty NUMERIC NOT NULL CHECK (min_qty >= 0),
+  max_qty NUMERIC CHECK (max_qty > 0),
+  fixed_qty NUMERIC CHECK (fixed_qty > 0),
+  source_path ltree,
+  active BOOLEAN NOT NULL DEFAULT true,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  updated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  UNIQUE (item_id, location_id),
+  CHECK ((max_qty IS NULL) <> (fixed_qty IS NULL)),
+  CHECK (max_qty IS NULL OR max_qty > min_qty)
+);
+
+CREATE INDEX IF NOT EXISTS idx_replenishment_settings_wh ON replenishment_settings(warehouse_id) WHERE active;
+
+-- The source stock of an open task is held by a stock allocation with
+-- ref_type replenishment_task and ref_id the task id. created_by is NULL for
+-- tasks the planner generated.
+CREATE TABLE IF NOT EXISTS replenishment_tasks (
+  id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+  setting_id UUID NOT NULL REFERENCES replenishment_settings(id),
+  warehouse_id UUID NOT NULL REFERENCES warehouses(id),
+  item_id UUID NOT NULL REFERENCES items(id),
+  qty NUMERIC NOT NULL CHECK (qty > 0),
+  lot_code TEXT,
+  serial_no TEXT,
+  from_location_id UUID NOT NULL REFERENCES locations(id),
+  to_location_id UUID NOT NULL REFERENCES locations(id),
+  status TEXT NOT NULL DEFAULT 'open' CHECK (status IN ('open','confirmed','cancelled')),
+  move_id UUID REFERENCES stock_ledger(move_id),
+  created_by UUID REFERENCES users(id),
+  confirmed_by UUID REFERENCES users(id),
+  created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  updated_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS idx_replenishment_tasks_open ON replenishment_tasks(to_location_id, item_id) WHERE status = 'open';
+CREATE INDEX IF NOT EXISTS idx_replenishment_tasks_wh ON replenishment_tasks(warehouse_id, created_at);
+
+INSERT INTO permissions(name) VALUES
+  ('wms.replenishment.manage'),
+  ('wms.replenishment.execute')
+ON CONFLICT DO NOTHING;
+
+INSERT INTO role_permissions(role_id, permission_id)
+SELECT r.id, p.id
+FROM roles r
+JOIN permissions p ON p.name IN ('wms.replenishment.manage','wms.replenishment.execute')
+WHERE r.name='SuperAdmin'
+ON CONFLICT DO NOTHING;
+
+INSERT INTO reason_codes(code, description, move_types, requires_comment, requires_reference, permission) VALUES
+  ('REPLENISH', 'Replenishment of a pick face from reserve', ARRAY['transfer'], false, true, NULL)
+ON CONFLICT DO NOTHING;
+
+-- +goose Down
+DELETE FROM reason_codes WHERE code = 'REPLENISH';
+DELETE FROM permissions WHERE name IN ('wms.replenishment.manage','wms.replenishment.execute');
+DROP TABLE IF EXISTS replenishment_tasks;
+DROP TABLE IF EXISTS replenishment_settings;
@@ -0,0 +1,18 @@
+-- +goose Up
+
+-- A task counts one balance key, so the stock status is part of it: held
+-- and available stock in the same bin are counted and adjusted apart. The
+-- column is stock_status because status is the task's own state.
+ALTER TABLE count_tasks
+  ADD COLUMN IF NOT EXISTS stock_status TEXT NOT NULL DEFAULT 'available'
+    CHECK (stock_status IN ('available','quarantine','damaged','blocked'));
+ALTER TABLE count_tasks DROP CONSTRAINT IF EXISTS count_tasks_key;
+ALTER TABLE count_tasks
+  ADD CONSTRAINT count_tasks_key UNIQUE NULLS NOT DISTINCT (session_id, location_id, item_id, lot_id, serial_id, stock_status);
+
+-- +goose Down
+ALTER TABLE count_tasks DROP CONSTRAINT IF EXISTS count_tasks_key;
+DELETE FROM count_tasks WHERE stock_status <> 'available';
+ALTER TABLE count_tasks DROP COLUMN IF EXISTS stock_status;
+ALTER TABLE count_tasks
+  ADD CONSTRAINT count_tasks_key UNIQUE NULLS NOT DISTINCT (session_id, location_id, item_id, lot_id, serial_id);
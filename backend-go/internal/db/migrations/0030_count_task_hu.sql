@@ -0,0 +1,15 @@
+-- +goose Up
+
+-- Stock inside a handling unit is its own balance row, so a task counts the
+-- contents of one unit; loose stock keeps a NULL hu_id.
+ALTER TABLE count_tasks ADD COLUMN IF NOT EXISTS hu_id UUID REFERENCES handling_units(id);
+ALTER TABLE count_tasks DROP CONSTRAINT IF EXISTS count_tasks_key;
+ALTER TABLE count_tasks
+  ADD CONSTRAINT count_tasks_key UNIQUE NULLS NOT DISTINCT (session_id, location_id, item_id, lot_id, serial_id, stock_status, hu_id);
+
+-- +goose Down
+ALTER TABLE count_tasks DROP CONSTRAINT IF EXISTS count_tasks_key;
+DELETE FROM count_tasks WHERE hu_id IS NOT NULL;
+ALTER TABLE count_tasks DROP COLUMN IF EXISTS hu_id;
+ALTER TABLE count_tasks
+  ADD CONSTRAINT count_tasks_key UNIQUE NULLS NOT DISTINCT (session_id, location_id, item_id, lot_id, serial_id, stock_status);
@@ -0,0 +1,64 @@
+-- name: ListAllocationCandidates :many
+SELECT sb.location_id, sb.lot_id, sb.serial_id, sb.hu_id, sb.qty_on_hand, sb.qty_allocated,
+       l.code location_code, lo.expires_on,
+       COALESCE(lo.received_at, (
+         SELECT min(sl.ts) FROM stock_ledger sl
+         WHERE sl.item_id = sb.item_id AND sl.to_location_id = sb.location_id
+       ), sb.updated_at)::timestamptz AS received_at
+FROM stock_balance sb
+JOIN locations l ON l.id = sb.location_id
+LEFT JOIN lots lo ON lo.id = sb.lot_id
+WHERE sb.item_id = sqlc.arg(item_id)
+  AND l.active
+  AND l.type <> 'in_transit'
+  AND sb.status = 'available'
+  AND (sqlc.narg(warehouse_id)::uuid IS NULL OR l.warehouse_id = sqlc.narg(warehouse_id))
+  AND (sqlc.narg(exclude_location_id)::uuid IS NULL OR sb.location_id <> sqlc.narg(exclude_location_id))
+  AND sb.qty_on_hand - sb.qty_allocated > 0
+  AND (lo.expires_on IS NULL OR lo.expires_on >= CURRENT_DATE)
+ORDER BY l.code
+FOR UPDATE OF sb;
+
+-- name: InsertStockAllocation :one
+INSERT INTO stock_allocations (item_id, location_id, lot_id, serial_id, qty, ref_type, ref_id, strategy, created_by, hu_id)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+RETURNING *;
+
+-- name: LockStockAllocation :one
+SELECT * FROM stock_allocations WHERE id = $1
+FOR UPDATE;
+
+-- name: LockActiveStockAllocationsByRef :many
+SELECT * FROM stock_allocations
+WHERE ref_type = $1 AND ref_id = $2 AND status = 'active'
+ORDER BY created_at
+FOR UPDATE;
+
+-- name: GetStockAllocationTracking :one
+SELECT lo.lot_code, se.serial_no
+FROM stock_allocations sa
+LEFT JOIN lots lo ON lo.id = sa.lot_id
+LEFT JOIN serials se ON se.id = sa.serial_id
+WHERE sa.id = $1;
+
+-- name: ListStockAllocationsByRef :many
+SELECT * FROM stock_allocations
+WHERE ref_type = sqlc.arg(ref_type) AND ref_id = sqlc.arg(ref_id)
+  AND (sqlc.arg(include_closed)::bool OR status = 'active')
+ORDER BY created_at;
+
+-- name: SetStockAllocationStatus :exec
+UPDATE stock_allocations SET status = $2, updated_at = now()
+WHERE id = $1;
+
+-- name: SetStockAllocationQty :exec
+UPDATE stock_allocations SET qty = $2, updated_at = now()
+WHERE id = $1;
+
+-- name: SetStockAllocationRef :exec
+UPDATE stock_allocations SET ref_type = $2, ref_id = $3, updated_at = now()
+WHERE id = $1;
+
+-- name: InsertStockAllocationLog :exec
+INSERT INTO stock_allocation_log (allocation_id, action, qty, ref_type, ref_id, actor_user_id, request_id)
+VALUES ($1, $2, $3, $4, $5, $6, $7);
@@ -0,0 +1,57 @@
+-- name: GetLocationLimits :one
+SELECT id, code, max_weight_kg, max_volume_m3, max_hu, max_skus, mixed_lots
+FROM locations WHERE id = $1;
+
+-- name: LockLocationLimits :one
+SELECT id, code, max_weight_kg, max_volume_m3, max_hu, max_skus, mixed_lots
+FROM locations WHERE id = $1
+FOR NO KEY UPDATE;
+
+-- name: GetLocationUsage :one
+SELECT
+  COALESCE(SUM(sb.qty_on_hand * i.weight_kg), 0)::numeric AS weight_kg,
+  COALESCE(SUM(sb.qty_on_hand * i.length_cm * i.width_cm * i.height_cm / 1000000), 0)::numeric AS volume_m3,
+  count(DISTINCT sb.item_id)::int AS skus,
+  COALESCE(bool_or(sb.item_id = @item_id), false)::bool AS holds_item,
+  COALESCE(bool_or(sb.item_id = @item_id AND sb.lot_id IS DISTINCT FROM sqlc.narg(lot_id)::uuid), false)::bool AS holds_other_lot,
+  (SELECT count(*) FROM handling_units h WHERE h.location_id = @location_id AND h.parent_id IS NULL)::int AS handling_units
+FROM stock_balance sb
+JOIN items i ON i.id = sb.item_id
+WHERE sb.location_id = @location_id AND sb.qty_on_hand > 0;
+
+-- name: GetItemMeasures :one
+SELECT weight_kg, (length_cm * width_cm * height_cm / 1000000)::numeric AS volume_m3
+FROM items WHERE id = $1;
+
+-- name: ListZoneUtilization :many
+WITH loc AS (
+  SELECT l.id, COALESCE(l.zone, '') AS zone, l.max_weight_kg, l.max_volume_m3, l.max_hu,
+         COALESCE(u.weight_kg, 0) AS weight_kg, COALESCE(u.volume_m3, 0) AS volume_m3, COALESCE(u.occupied, false) AS occupied,
+         (SELECT count(*) FROM handling_units h WHERE h.location_id = l.id AND h.parent_id IS NULL) AS hus
+  FROM locations l
+  LEFT JOIN LATERAL (
+    SELECT SUM(sb.qty_on_hand * i.weight_kg) AS weight_kg,
+           SUM(sb.qty_on_hand * i.length_cm * i.width_cm * i.height_cm / 1000000) AS volume_m3,
+           true AS occupied
+    FROM stock_balance sb
+    JOIN items i ON i.id = sb.item_id
+    WHERE sb.location_id = l.id AND sb.qty_on_hand > 0
+  ) u ON true
+  WHERE l.warehouse_id = @warehouse_id AND l.active AND l.type <> 'in_transit'
+    AND (@zone::text = '' OR COALESCE(l.zone, '') = @zone)
+)
+SELECT zone,
+       count(*)::int AS locations,
+       count(*) FILTER (WHERE occupied)::int AS occupied_locations,
+       COALESCE(SUM(weight_kg), 0)::numeric AS weight_kg,
+       SUM(max_weight_kg)::numeric AS max_weight_kg,
+       COALESCE(SUM(weight_kg) FILTER (WHERE max_weight_kg IS NOT NULL), 0)::numeric AS limited_weight_kg,
+       COALESCE(SUM(volume_m3), 0)::numeric AS volume_m3,
+       SUM(max_volume_m3)::numeric AS max_volume_m3,
+       COALESCE(SUM(volume_m3) FILTER (WHERE max_volume_m3 IS NOT NULL), 0)::numeric AS limited_volume_m3,
+       COALESCE(SUM(hus), 0)::int AS handling_units,
+       SUM(max_hu)::int AS max_hu,
+       COALESCE(SUM(hus) FILTER (WHERE max_hu IS NOT NULL), 0)::int AS limited_handling_units
+FROM loc
+GROUP BY zone
+ORDER BY zone;
@@ -0,0 +1,103 @@
+-- name: CreateCountSession :one
+INSERT INTO count_sessions (warehouse_id, path_prefix, item_class, abc_class, blind, freeze, tolerance_qty, tolerance_pct, created_by)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+RETURNING *;
+
+-- name: GetCountSession :one
+SELECT * FROM count_sessions WHERE id = $1;
+
+-- name: LockCountSession :one
+SELECT * FROM count_sessions WHERE id = $1
+FOR UPDATE;
+
+-- name: ListCountSessions :many
+SELECT * FROM count_sessions
+WHERE (sqlc.narg(warehouse_id)::uuid IS NULL OR warehouse_id = sqlc.narg(warehouse_id))
+  AND (sqlc.arg(status)::text = '' OR status = sqlc.arg(status))
+ORDER BY created_at DESC, id
+LIMIT sqlc.arg(page_limit) OFFSET sqlc.arg(page_offset);
+
+-- name: SetCountSessionStatus :one
+UPDATE count_sessions SET status = $2, updated_at = now()
+WHERE id = $1
+RETURNING *;
+
+-- name: ApproveCountSession :one
+UPDATE count_sessions SET status = 'approved', approved_by = $2, approved_at = now(), updated_at = now()
+WHERE id = $1
+RETURNING *;
+
+-- name: InsertScopedCountTasks :execrows
+INSERT INTO count_tasks (session_id, location_id, item_id, lot_id, serial_id, stock_status, hu_id, expected_qty)
+SELECT cs.id, sb.location_id, sb.item_id, sb.lot_id, sb.serial_id, sb.status, sb.hu_id, SUM(sb.qty_on_hand)
+FROM count_sessions cs
+JOIN locations l ON l.warehouse_id = cs.warehouse_id
+JOIN stock_balance sb ON sb.location_id = l.id
+JOIN items i ON i.id = sb.item_id
+WHERE cs.id = $1
+  AND (cs.path_prefix IS NULL OR l.path <@ cs.path_prefix)
+  AND (cs.item_class IS NULL OR i.item_class = cs.item_class)
+  AND (cs.abc_class IS NULL OR i.abc_class = cs.abc_class)
+GROUP BY cs.id, sb.location_id, sb.item_id, sb.lot_id, sb.serial_id, sb.status, sb.hu_id
+HAVING SUM(sb.qty_on_hand) <> 0;
+
+-- name: InsertCountTask :one
+INSERT INTO count_tasks (session_id, location_id, item_id, lot_id, serial_id, stock_status, hu_id)
+VALUES ($1, $2, $3, $4, $5, $6, $7)
+RETURNING *;
+
+-- name: ListFrozenCountLocations :many
+SELECT DISTINCT l.code FROM count_tasks ct
+JOIN locations l ON l.id = ct.location_id
+WHERE ct.session_id = $1 AND l.frozen_by_count_id IS NOT NULL AND l.frozen_by_count_id <> $1
+ORDER BY l.code;
+
+-- name: FreezeCountLocations :exec
+UPDATE locations SET frozen_by_count_id = $1, updated_at = now()
+WHERE id IN (SELECT location_id FROM count_tasks WHERE session_id = $1) AND frozen_by_count_id IS NULL;
+
+-- name: UnfreezeCountLocations :exec
+UPDATE locations SET frozen_by_count_id = NULL, updated_at = now()
+WHERE frozen_by_count_id = $1;
+
+-- name: ListCountTasks :many
+SELECT ct.id, ct.status, ct.location_id, l.code AS location_code, l.path, ct.item_id, i.sku, i.name AS item_name, i.uom,
+       ct.lot_id, lo.lot_code, ct.serial_id, se.serial_no, ct.stock_status, ct.hu_id, hu.code AS hu_code, ct.expected_qty, ct.first_qty, ct.counted_qty, ct.counted_by, ct.counted_at, ct.move_id
+FROM count_tasks ct
+JOIN locations l ON l.id = ct.location_id
+JOIN items i ON i.id = ct.item_id
+LEFT JOIN lots lo ON lo.id = ct.lot_id
+LEFT JOIN serials se ON se.id = ct.serial_id
+LEFT JOIN handling_units hu ON hu.id = ct.hu_id
+WHERE ct.session_id = sqlc.arg(session_id)
+  AND (sqlc.arg(status)::text = '' OR ct.status = sqlc.arg(status))
+ORDER BY l.path NULLS LAST, l.code, i.sku, lo.lot_code NULLS FIRST, se.serial_no NULLS FIRST, hu.code NULLS FIRST;
+
+-- name: LockCountTask :one
+SELECT * FROM count_tasks WHERE id = $1
+FOR UPDATE;
+
+-- name: SetCountTaskRecount :one
+UPDATE count_tasks
+SET status = 'recount', expected_qty = $2, first_qty = $3, first_counted_by = $4, counted_at = now()
+WHERE id = $1
+RETURNING *;
+
+-- name: SetCountTaskCounted :one
+UPDATE count_tasks
+SET status = 'counted', expected_qty = $2, counted_qty = $3, counted_by = $4, counted_at = now()
+WHERE id = $1
+RETURNING *;
+
+-- name: SetCountTaskMove :exec
+UPDATE count_tasks SET move_id = $2 WHERE id = $1;
+
+-- name: CountSessionHasOpenTasks :one
+SELECT EXISTS (SELECT 1 FROM count_tasks WHERE session_id = $1 AND status <> 'counted');
+
+-- name: GetOnHand :one
+SELECT COALESCE((
+  SELECT SUM(qty_on_hand) FROM stock_balance
+  WHERE item_id = $1 AND location_id = $2 AND lot_id IS NOT DISTINCT FROM $3 AND serial_id IS NOT DISTINCT FROM $4
+    AND status = $5 AND hu_id IS NOT DISTINCT FROM $6
+), 0)::numeric AS qty_on_hand;
@@ -0,0 +1,63 @@
+-- name: NextSsccSerial :one
+SELECT nextval('sscc_serial_seq')::bigint AS serial;
+
+-- name: CreateHandlingUnit :one
+INSERT INTO handling_units (code, hu_type, parent_id, location_id, created_by)
+VALUES ($1, $2, $3, $4, $5)
+RETURNING *;
+
+-- name: GetHandlingUnit :one
+SELECT * FROM handling_units WHERE id = $1;
+
+-- name: LockHandlingUnit :one
+SELECT * FROM handling_units WHERE id = $1
+FOR UPDATE;
+
+-- name: ListHandlingUnits :many
+SELECT hu.id, hu.code, hu.hu_type, hu.parent_id, hu.location_id, l.code AS location_code, hu.created_at,
+       (SELECT count(*) FROM stock_balance sb WHERE sb.hu_id = hu.id AND sb.qty_on_hand <> 0)::int AS content_lines,
+       (SELECT count(*) FROM handling_units c WHERE c.parent_id = hu.id)::int AS children
+FROM handling_units hu
+JOIN locations l ON l.id = hu.location_id
+WHERE (sqlc.narg(location_id)::uuid IS NULL OR hu.location_id = sqlc.narg(location_id))
+  AND (sqlc.narg(warehouse_id)::uuid IS NULL OR l.warehouse_id = sqlc.narg(warehouse_id))
+  AND (sqlc.narg(parent_id)::uuid IS NULL OR hu.parent_id = sqlc.narg(parent_id))
+  AND (@code::text = '' OR hu.code = @code)
+  AND (@hu_type::text = '' OR hu.hu_type = @hu_type)
+  AND (NOT @top_level::bool OR hu.parent_id IS NULL)
+ORDER BY hu.created_at DESC, hu.code
+LIMIT @page_limit OFFSET @page_offset;
+
+-- name: LockHandlingUnitTree :many
+WITH RECURSIVE tree AS (
+  SELECT h.id FROM handling_units h WHERE h.id = @id::uuid
+  UNION ALL
+  SELECT c.id FROM handling_units c JOIN tree t ON c.parent_id = t.id
+)
+SELECT hu.* FROM handling_units hu
+WHERE hu.id IN (SELECT id FROM tree)
+ORDER BY hu.id
+FOR UPDATE;
+
+-- name: ListHandlingUnitContents :many
+SELECT sb.hu_id, hu.code AS hu_code, sb.item_id, i.sku, i.uom, sb.location_id, sb.lot_id, lo.lot_code, sb.serial_id, se.serial_no,
+       sb.status, sb.qty_on_hand, sb.qty_allocated
+FROM stock_balance sb
+JOIN handling_units hu ON hu.id = sb.hu_id
+JOIN items i ON i.id = sb.item_id
+LEFT JOIN lots lo ON lo.id = sb.lot_id
+LEFT JOIN serials se ON se.id = sb.serial_id
+WHERE sb.hu_id = ANY(@hu_ids::uuid[]) AND sb.qty_on_hand <> 0
+ORDER BY hu.code, i.sku, lo.lot_code, se.serial_no, sb.status;
+
+-- name: ListHandlingUnitChildren :many
+SELECT * FROM handling_units WHERE parent_id = $1
+ORDER BY code;
+
+-- name: SetHandlingUnitsLocation :exec
+UPDATE handling_units SET location_id = @location_id, updated_at = now()
+WHERE id = ANY(@ids::uuid[]);
+
+-- name: SetHandlingUnitParent :exec
+UPDATE handling_units SET parent_id = $2, updated_at = now()
+WHERE id = $1;
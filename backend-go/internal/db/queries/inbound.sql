@@ -0,0 +1,101 @@
+-- name: ListSuppliers :many
+SELECT * FROM suppliers
+WHERE (@include_inactive::bool OR active)
+ORDER BY code
+LIMIT @page_limit OFFSET @page_offset;
+
+-- name: GetSupplier :one
+SELECT * FROM suppliers WHERE id = $1;
+
+-- name: CreateSupplier :one
+INSERT INTO suppliers (code, name) VALUES ($1, $2)
+RETURNING *;
+
+-- name: UpdateSupplier :one
+UPDATE suppliers SET code = $2, name = $3, updated_at = now()
+WHERE id = $1
+RETURNING *;
+
+-- name: SetSupplierActive :one
+UPDATE suppliers SET active = $2, updated_at = now()
+WHERE id = $1
+RETURNING *;
+
+-- name: CreatePurchaseOrder :one
+INSERT INTO purchase_orders (po_number, supplier_id, warehouse_id, expected_on, over_receipt_pct, created_by)
+VALUES ($1, $2, $3, $4, $5, $6)
+RETURNING *;
+
+-- name: InsertPurchaseOrderLine :one
+INSERT INTO purchase_order_lines (po_id, line_no, item_id, qty_ordered)
+VALUES ($1, $2, $3, $4)
+RETURNING *;
+
+-- name: GetPurchaseOrder :one
+SELECT * FROM purchase_orders WHERE id = $1;
+
+-- name: LockPurchaseOrder :one
+SELECT * FROM purchase_orders WHERE id = $1
+FOR UPDATE;
+
+-- name: ListPurchaseOrders :many
+SELECT * FROM purchase_orders
+WHERE (sqlc.narg(supplier_id)::uuid IS NULL OR supplier_id = sqlc.narg(supplier_id))
+  AND (sqlc.narg(warehouse_id)::uuid IS NULL OR warehouse_id = sqlc.narg(warehouse_id))
+  AND (@status::text = '' OR status = @status)
+ORDER BY created_at DESC, id
+LIMIT @page_limit OFFSET @page_offset;
+
+-- name: ListPurchaseOrderLines :many
+SELECT * FROM purchase_order_lines WHERE po_id = $1
+ORDER BY line_no;
+
+-- name: AddPurchaseOrderLineReceived :one
+UPDATE purchase_order_lines SET qty_received = qty_received + $2
+WHERE id = $1
+RETURNING *;
+
+-- name: SetPurchaseOrderStatus :one
+UPDATE purchase_orders SET status = $2, updated_at = now(),
+  closed_at = CASE WHEN $2 IN ('closed','cancelled') THEN now() END
+WHERE id = $1
+RETURNING *;
+
+-- name: PurchaseOrderHasReceipts :one
+SELECT EXISTS (SELECT 1 FROM goods_receipts WHERE po_id = $1) AS exists;
+
+-- name: CreateAsn :one
+INSERT INTO asns (asn_number, supplier_id, po_id, expected_at, created_by)
+VALUES ($1, $2, $3, $4, $5)
+RETURNING *;
+
+-- name: InsertAsnLine :one
+INSERT INTO asn_lines (asn_id, po_line_id, qty, lot_code, expires_on)
+VALUES ($1, $2, $3, $4, $5)
+RETURNING *;
+
+-- name: GetAsn :one
+SELECT * FROM asns WHERE id = $1;
+
+-- name: ListAsnsByPurchaseOrder :many
+SELECT * FROM asns WHERE po_id = $1
+ORDER BY created_at;
+
+-- name: ListAsnLines :many
+SELECT * FROM asn_lines WHERE asn_id = $1
+ORDER BY id;
+
+-- name: SetAsnStatus :one
+UPDATE asns SET status = $2, updated_at = now()
+WHERE id = $1
+RETURNING *;
+
+-- name: InsertGoodsReceipt :one
+INSERT INTO goods_receipts (po_id, asn_id, dock_location_id, received_by, request_id)
+VALUES ($1, $2, $3, $4, $5)
+RETURNING *;
+
+-- name: InsertGoodsReceiptLine :one
+INSERT INTO goods_receipt_lines (receipt_id, po_line_id, qty, lot_code, serial_no, move_id)
+VALUES ($1, $2, $3, $4, $5, $6)
+RETURNING *;
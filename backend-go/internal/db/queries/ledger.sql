@@ -0,0 +1,48 @@
+-- name: ListStockLedger :many
+SELECT sl.move_id, sl.ts, sl.move_type, sl.item_id, i.sku, sl.qty,
+       sl.from_location_id, fl.code AS from_location_code,
+       sl.to_location_id, tl.code AS to_location_code,
+       sl.reason_code, sl.ref_type, sl.ref_id, sl.actor_user_id, sl.request_id,
+       lo.lot_code, se.serial_no, sl.status, sl.to_status, sl.comment, fh.code AS from_hu_code, th.code AS to_hu_code,
+       (CASE WHEN sqlc.narg(item_id)::uuid IS NOT NULL AND sqlc.narg(location_id)::uuid IS NOT NULL THEN (
+         SELECT SUM(CASE WHEN h.to_location_id = sqlc.narg(location_id) THEN h.qty ELSE 0 END
+                  - CASE WHEN h.from_location_id = sqlc.narg(location_id) THEN h.qty ELSE 0 END)
+         FROM stock_ledger h
+         WHERE h.item_id = sl.item_id
+           AND (h.from_location_id = sqlc.narg(location_id) OR h.to_location_id = sqlc.narg(location_id))
+           AND (h.ts, h.move_id) <= (sl.ts, sl.move_id)
+       ) END)::numeric AS running_balance
+FROM stock_ledger sl
+JOIN items i ON i.id = sl.item_id
+LEFT JOIN locations fl ON fl.id = sl.from_location_id
+LEFT JOIN locations tl ON tl.id = sl.to_location_id
+LEFT JOIN lots lo ON lo.id = sl.lot_id
+LEFT JOIN serials se ON se.id = sl.serial_id
+LEFT JOIN handling_units fh ON fh.id = sl.from_hu_id
+LEFT JOIN handling_units th ON th.id = sl.to_hu_id
+WHERE (sqlc.narg(item_id)::uuid IS NULL OR sl.item_id = sqlc.narg(item_id))
+  AND (sqlc.narg(location_id)::uuid IS NULL OR sl.from_location_id = sqlc.narg(location_id) OR sl.to_location_id = sqlc.narg(location_id))
+  AND (sqlc.narg(warehouse_id)::uuid IS NULL OR fl.warehouse_id = sqlc.narg(warehouse_id) OR tl.warehouse_id = sqlc.narg(warehouse_id))
+  AND (@reason_code::text = '' OR sl.reason_code = @reason_code)
+  AND (sqlc.narg(actor_user_id)::uuid IS NULL OR sl.actor_user_id = sqlc.narg(actor_user_id))
+  AND (sqlc.narg(from_ts)::timestamptz IS NULL OR sl.ts >= sqlc.narg(from_ts))
+  AND (sqlc.narg(to_ts)::timestamptz IS NULL OR sl.ts < sqlc.narg(to_ts))
+  AND (@ref_type::text = '' OR sl.ref_type = @ref_type)
+  AND (@ref_id::text = '' OR sl.ref_id = @ref_id)
+  AND (sqlc.narg(after_ts)::timestamptz IS NULL OR (sl.ts, sl.move_id) < (sqlc.narg(after_ts), sqlc.narg(after_id)::uuid))
+ORDER BY sl.ts DESC, sl.move_id DESC
+LIMIT @page_limit;
+
+-- name: LockStockLedgerMove :one
+SELECT sl.move_id, sl.move_type, sl.item_id, sl.qty, sl.from_location_id, sl.to_location_id,
+       sl.ref_type, sl.ref_id, lo.lot_code, se.serial_no, sl.status, sl.to_status,
+       sl.from_hu_id, sl.to_hu_id
+FROM stock_ledger sl
+LEFT JOIN lots lo ON lo.id = sl.lot_id
+LEFT JOIN serials se ON se.id = sl.serial_id
+WHERE sl.move_id = $1
+FOR UPDATE OF sl;
+
+-- name: GetReversalOf :one
+SELECT move_id FROM stock_ledger
+WHERE ref_type = 'reversal' AND ref_id = $1;
@@ -0,0 +1,37 @@
+-- name: ListLocationTreeNodes :many
+WITH under AS (
+  SELECT l.id, l.code, l.path, subpath(l.path, 0, @depth::int + 1) AS node
+  FROM locations l
+  WHERE l.warehouse_id = @warehouse_id AND l.active
+    AND nlevel(l.path) > @depth::int
+    AND (@parent::text = '' OR l.path <@ @parent::text::ltree)
+)
+SELECT u.node::text AS path,
+       count(DISTINCT u.id)::int AS locations,
+       COALESCE(array_agg(DISTINCT u.code) FILTER (WHERE u.path = u.node), '{}')::text[] AS location_codes,
+       bool_or(u.path <> u.node)::bool AS has_children,
+       COALESCE(SUM(sb.qty_on_hand), 0)::numeric AS qty_on_hand,
+       COALESCE(SUM(sb.qty_allocated), 0)::numeric AS qty_allocated,
+       count(DISTINCT sb.item_id)::int AS items
+FROM under u
+LEFT JOIN stock_balance sb ON sb.location_id = u.id AND sb.qty_on_hand <> 0
+  AND (sqlc.narg(item_id)::uuid IS NULL OR sb.item_id = sqlc.narg(item_id))
+  AND (@sku::text = '' OR sb.item_id IN (SELECT i.id FROM items i WHERE i.sku = @sku))
+GROUP BY u.node
+ORDER BY u.node;
+
+-- name: ListStockRollup :many
+SELECT sb.item_id, i.sku, i.name AS item_name, i.uom, sb.status,
+       SUM(sb.qty_on_hand)::numeric AS qty_on_hand,
+       SUM(sb.qty_allocated)::numeric AS qty_allocated,
+       count(DISTINCT sb.location_id)::int AS locations
+FROM stock_balance sb
+JOIN locations l ON l.id = sb.location_id
+JOIN items i ON i.id = sb.item_id
+WHERE l.warehouse_id = @warehouse_id
+  AND (@path::text = '' OR l.path <@ @path::text::ltree)
+  AND (sqlc.narg(item_id)::uuid IS NULL OR sb.item_id = sqlc.narg(item_id))
+  AND (@sku::text = '' OR i.sku = @sku)
+  AND sb.qty_on_hand <> 0
+GROUP BY sb.item_id, i.sku, i.name, i.uom, sb.status
+ORDER BY i.sku, sb.status;
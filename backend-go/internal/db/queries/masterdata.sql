@@ -0,0 +1,188 @@
+-- name: CreateWarehouse :one
+INSERT INTO warehouses (code, name, allow_negative_stock)
+VALUES ($1, $2, $3)
+RETURNING *;
+
+-- name: GetWarehouse :one
+SELECT * FROM warehouses WHERE id = $1;
+
+-- name: ListWarehouses :many
+SELECT * FROM warehouses
+WHERE (@include_inactive::bool OR active)
+ORDER BY code
+LIMIT @page_limit OFFSET @page_offset;
+
+-- name: UpdateWarehouse :one
+UPDATE warehouses SET code = $2, name = $3, allow_negative_stock = $4, updated_at = now()
+WHERE id = $1
+RETURNING *;
+
+-- name: SetWarehouseActive :one
+UPDATE warehouses SET active = $2, updated_at = now()
+WHERE id = $1
+RETURNING *;
+
+-- name: CreateLocation :one
+INSERT INTO locations (warehouse_id, code, type, path, zone, max_weight_kg, max_volume_m3, max_hu, max_skus, mixed_lots)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+RETURNING *;
+
+-- name: GetLocation :one
+SELECT * FROM locations WHERE id = $1;
+
+-- name: ListLocations :many
+SELECT * FROM locations
+WHERE (sqlc.narg(warehouse_id)::uuid IS NULL OR warehouse_id = sqlc.narg(warehouse_id))
+  AND (@type::text = '' OR type = @type)
+  AND (@path::text = '' OR path <@ @path::text::ltree)
+  AND (@include_inactive::bool OR active)
+ORDER BY code
+LIMIT @page_limit OFFSET @page_offset;
+
+-- name: UpdateLocation :one
+UPDATE locations SET code = $2, type = $3, path = $4, zone = $5, max_weight_kg = $6, max_volume_m3 = $7,
+  max_hu = $8, max_skus = $9, mixed_lots = $10, updated_at = now()
+WHERE id = $1
+RETURNING *;
+
+-- name: SetLocationActive :one
+UPDATE locations SET active = $2, updated_at = now()
+WHERE id = $1
+RETURNING *;
+
+-- name: LocationHasStock :one
+SELECT EXISTS (SELECT 1 FROM stock_balance WHERE location_id = $1 AND qty_on_hand <> 0);
+
+-- name: CreateItem :one
+INSERT INTO items (sku, name, barcode, uom, tracking_mode, item_class, abc_class, weight_kg, length_cm, width_cm, height_cm)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+RETURNING *;
+
+-- name: GetItem :one
+SELECT * FROM items WHERE id = $1;
+
+-- name: ListItems :many
+SELECT * FROM items
+WHERE (@q::text = '' OR sku ILIKE '%' || @q || '%' OR name ILIKE '%' || @q || '%')
+  AND (@include_inactive::bool OR active)
+ORDER BY sku
+LIMIT @page_limit OFFSET @page_offset;
+
+-- name: UpdateItem :one
+UPDATE items SET sku = $2, name = $3, barcode = $4, uom = $5, tracking_mode = $6, item_class = $7, abc_class = $8,
+  weight_kg = $9, length_cm = $10, width_cm = $11, height_cm = $12, updated_at = now()
+WHERE id = $1
+RETURNING *;
+
+-- name: SetItemActive :one
+UPDATE items SET active = $2, updated_at = now()
+WHERE id = $1
+RETURNING *;
+
+-- name: ItemHasStock :one
+SELECT EXISTS (SELECT 1 FROM stock_balance WHERE item_id = $1 AND qty_on_hand <> 0);
+
+-- name: WarehouseHasStock :one
+SELECT EXISTS (
+  SELECT 1 FROM stock_balance sb
+  JOIN locations l ON l.id = sb.location_id
+  WHERE l.warehouse_id = $1 AND sb.qty_on_hand <> 0
+);
+
+-- name: ListLocationTypePolicies :many
+SELECT * FROM location_type_policies ORDER BY type;
+
+-- name: UpsertLocationTypePolicy :one
+INSERT INTO location_type_policies (type, allow_negative_stock)
+VALUES ($1, $2)
+ON CONFLICT (type) DO UPDATE SET
+  allow_negative_stock = EXCLUDED.allow_negative_stock,
+  updated_at = now()
+RETURNING *;
+
+-- name: CreateLot :one
+INSERT INTO lots (item_id, lot_code, manufactured_on, expires_on)
+VALUES ($1, $2, $3, $4)
+RETURNING *;
+
+-- name: ListLotsByItem :many
+SELECT * FROM lots
+WHERE item_id = $1
+ORDER BY expires_on NULLS LAST, lot_code;
+
+-- name: CreateSerial :one
+INSERT INTO serials (item_id, serial_no, lot_id)
+VALUES ($1, $2, $3)
+RETURNING *;
+
+-- name: ListSerialsByItem :many
+SELECT * FROM serials
+WHERE item_id = $1
+ORDER BY serial_no
+LIMIT $2 OFFSET $3;
+
+-- name: ListUoms :many
+SELECT * FROM uoms ORDER BY code;
+
+-- name: UpsertUom :one
+INSERT INTO uoms (code, name, decimals)
+VALUES ($1, $2, $3)
+ON CONFLICT (code) DO UPDATE SET
+  name = EXCLUDED.name,
+  decimals = EXCLUDED.decimals,
+  updated_at = now()
+RETURNING *;
+
+-- name: ListItemUomConversions :many
+SELECT * FROM item_uom_conversions WHERE item_id = $1 ORDER BY uom;
+
+-- name: UpsertItemUomConversion :one
+INSERT INTO item_uom_conversions (item_id, uom, factor)
+VALUES ($1, $2, $3)
+ON CONFLICT (item_id, uom) DO UPDATE SET
+  factor = EXCLUDED.factor,
+  updated_at = now()
+RETURNING *;
+
+-- name: DeleteItemUomConversion :execrows
+DELETE FROM item_uom_conversions WHERE item_id = $1 AND uom = $2;
+
+-- name: ListReasonCodes :many
+SELECT * FROM reason_codes
+WHERE (@move_type::text = '' OR @move_type = ANY(move_types))
+  AND (@include_inactive::bool OR active)
+ORDER BY code;
+
+-- name: GetReasonCode :one
+SELECT * FROM reason_codes WHERE code = $1;
+
+-- name: CreateReasonCode :one
+INSERT INTO reason_codes (code, description, move_types, requires_comment, requires_reference, permission)
+VALUES ($1, $2, $3, $4, $5, $6)
+RETURNING *;
+
+-- name: UpdateReasonCode :one
+UPDATE reason_codes SET description = $2, move_types = $3, requires_comment = $4, requires_reference = $5,
+  permission = $6, updated_at = now()
+WHERE code = $1
+RETURNING *;
+
+-- name: SetReasonCodeActive :one
+UPDATE reason_codes SET active = $2, updated_at = now()
+WHERE code = $1
+RETURNING *;
+
+-- name: ListItemBarcodes :many
+SELECT * FROM item_barcodes WHERE item_id = $1 ORDER BY created_at, barcode;
+
+-- name: GetItemBarcode :one
+SELECT * FROM item_barcodes WHERE barcode = @barcode OR gtin = NULLIF(@gtin::text, '')
+LIMIT 1;
+
+-- name: CreateItemBarcode :one
+INSERT INTO item_barcodes (barcode, gtin, item_id, barcode_type, uom)
+VALUES ($1, $2, $3, $4, $5)
+RETURNING *;
+
+-- name: DeleteItemBarcode :execrows
+DELETE FROM item_barcodes WHERE item_id = $1 AND barcode = $2;
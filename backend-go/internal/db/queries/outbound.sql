@@ -0,0 +1,168 @@
+-- name: ListCustomers :many
+SELECT * FROM customers
+WHERE (@include_inactive::bool OR active)
+ORDER BY code
+LIMIT @page_limit OFFSET @page_offset;
+
+-- name: GetCustomer :one
+SELECT * FROM customers WHERE id = $1;
+
+-- name: CreateCustomer :one
+INSERT INTO customers (code, name, address, city, postal_code, country, vat_number)
+VALUES ($1, $2, $3, $4, $5, $6, $7)
+RETURNING *;
+
+-- name: UpdateCustomer :one
+UPDATE customers
+SET code = $2, name = $3, address = $4, city = $5, postal_code = $6, country = $7, vat_number = $8, updated_at = now()
+WHERE id = $1
+RETURNING *;
+
+-- name: SetCustomerActive :one
+UPDATE customers SET active = $2, updated_at = now()
+WHERE id = $1
+RETURNING *;
+
+-- name: CreateSalesOrder :one
+INSERT INTO sales_orders (order_number, customer_id, warehouse_id, carrier, cutoff_at, created_by)
+VALUES ($1, $2, $3, $4, $5, $6)
+RETURNING *;
+
+-- name: InsertSalesOrderLine :one
+INSERT INTO sales_order_lines (order_id, line_no, item_id, qty_ordered)
+VALUES ($1, $2, $3, $4)
+RETURNING *;
+
+-- name: GetSalesOrder :one
+SELECT * FROM sales_orders WHERE id = $1;
+
+-- name: LockSalesOrder :one
+SELECT * FROM sales_orders WHERE id = $1
+FOR UPDATE;
+
+-- name: ListSalesOrders :many
+SELECT * FROM sales_orders
+WHERE (sqlc.narg(customer_id)::uuid IS NULL OR customer_id = sqlc.narg(customer_id))
+  AND (sqlc.narg(warehouse_id)::uuid IS NULL OR warehouse_id = sqlc.narg(warehouse_id))
+  AND (sqlc.narg(wave_id)::uuid IS NULL OR wave_id = sqlc.narg(wave_id))
+  AND (sqlc.arg(status)::text = '' OR status = sqlc.arg(status))
+  AND (sqlc.arg(carrier)::text = '' OR carrier = sqlc.arg(carrier))
+ORDER BY created_at DESC, id
+LIMIT sqlc.arg(page_limit) OFFSET sqlc.arg(page_offset);
+
+-- name: ListSalesOrderLines :many
+SELECT * FROM sales_order_lines WHERE order_id = $1
+ORDER BY line_no;
+
+-- name: ListSalesOrderLineAllocated :many
+SELECT sol.id AS line_id, COALESCE(sum(sa.qty), 0)::numeric AS qty_allocated
+FROM sales_order_lines sol
+LEFT JOIN stock_allocations sa
+  ON sa.ref_type = 'sales_order_line' AND sa.ref_id = sol.id::text AND sa.status = 'active'
+WHERE sol.order_id = $1
+GROUP BY sol.id;
+
+-- name: AddSalesOrderLinePicked :one
+UPDATE sales_order_lines SET qty_picked = qty_picked + $2, qty_short = qty_short + $3
+WHERE id = $1
+RETURNING *;
+
+-- name: SetSalesOrderStatus :one
+UPDATE sales_orders SET status = $2, updated_at = now()
+WHERE id = $1
+RETURNING *;
+
+-- name: SetSalesOrderWave :exec
+UPDATE sales_orders SET wave_id = $2, status = 'picking', updated_at = now()
+WHERE id = $1;
+
+-- name: DetachWaveOrders :exec
+UPDATE sales_orders SET wave_id = NULL, status = 'allocated', updated_at = now()
+WHERE wave_id = $1;
+
+-- name: ListWaveableOrders :many
+SELECT * FROM sales_orders
+WHERE warehouse_id = sqlc.arg(warehouse_id)
+  AND status = 'allocated' AND wave_id IS NULL
+  AND (sqlc.arg(carrier)::text = '' OR carrier = sqlc.arg(carrier))
+  AND (sqlc.narg(cutoff_before)::timestamptz IS NULL OR cutoff_at <= sqlc.narg(cutoff_before))
+ORDER BY cutoff_at NULLS LAST, created_at, id
+LIMIT sqlc.arg(page_limit)
+FOR UPDATE SKIP LOCKED;
+
+-- name: CreateWave :one
+INSERT INTO waves (warehouse_id, carrier, cutoff_at, staging_location_id, created_by)
+VALUES ($1, $2, $3, $4, $5)
+RETURNING *;
+
+-- name: GetWave :one
+SELECT * FROM waves WHERE id = $1;
+
+-- name: LockWave :one
+SELECT * FROM waves WHERE id = $1
+FOR UPDATE;
+
+-- name: ListWaves :many
+SELECT * FROM waves
+WHERE (sqlc.narg(warehouse_id)::uuid IS NULL OR warehouse_id = sqlc.narg(warehouse_id))
+  AND (sqlc.arg(status)::text = '' OR status = sqlc.arg(status))
+ORDER BY wave_no DESC
+LIMIT sqlc.arg(page_limit) OFFSET sqlc.arg(page_offset);
+
+-- name: SetWaveStatus :one
+UPDATE waves SET status = $2, updated_at = now()
+WHERE id = $1
+RETURNING *;
+
+-- name: ListPickAllocations :many
+SELECT sa.id AS allocation_id, sol.order_id, sol.id AS order_line_id, sa.item_id, sa.location_id, sa.qty
+FROM stock_allocations sa
+JOIN sales_order_lines sol ON sa.ref_type = 'sales_order_line' AND sa.ref_id = sol.id::text
+JOIN locations l ON l.id = sa.location_id
+WHERE sa.status = 'active'
+  AND (sol.order_id = ANY(@order_ids::uuid[]) OR sa.id = ANY(@allocation_ids::uuid[]))
+ORDER BY l.path NULLS LAST, l.code, sol.order_id, sol.line_no;
+
+-- name: NextPickSequence :one
+SELECT (COALESCE(max(sequence), 0) + 1)::int AS next FROM pick_tasks WHERE wave_id = $1;
+
+-- name: InsertPickTask :one
+INSERT INTO pick_tasks (wave_id, order_id, order_line_id, allocation_id, item_id, location_id, qty, sequence)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+RETURNING *;
+
+-- name: LockPickTask :one
+SELECT * FROM pick_tasks WHERE id = $1
+FOR UPDATE;
+
+-- name: ListPickList :many
+SELECT pt.id, pt.sequence, pt.status, pt.order_id, pt.order_line_id, pt.item_id, i.sku,
+       pt.location_id, l.code AS location_code, l.path, lo.lot_code, se.serial_no,
+       pt.qty, pt.qty_picked, pt.short_reason, pt.move_id
+FROM pick_tasks pt
+JOIN items i ON i.id = pt.item_id
+JOIN locations l ON l.id = pt.location_id
+JOIN stock_allocations sa ON sa.id = pt.allocation_id
+LEFT JOIN lots lo ON lo.id = sa.lot_id
+LEFT JOIN serials se ON se.id = sa.serial_id
+WHERE pt.wave_id = sqlc.arg(wave_id)
+  AND (sqlc.arg(status)::text = '' OR pt.status = sqlc.arg(status))
+ORDER BY pt.sequence;
+
+-- name: ConfirmPickTask :one
+UPDATE pick_tasks
+SET qty_picked = $2, status = $3, short_reason = $4, move_id = $5, picked_by = $6, picked_at = now()
+WHERE id = $1
+RETURNING *;
+
+-- name: CancelOpenPickTasks :exec
+UPDATE pick_tasks SET status = 'cancelled' WHERE wave_id = $1 AND status = 'open';
+
+-- name: OrderHasOpenPicks :one
+SELECT EXISTS (SELECT 1 FROM pick_tasks WHERE order_id = $1 AND status = 'open');
+
+-- name: WaveHasOpenPicks :one
+SELECT EXISTS (SELECT 1 FROM pick_tasks WHERE wave_id = $1 AND status = 'open');
+
+-- name: WaveHasConfirmedPicks :one
+SELECT EXISTS (SELECT 1 FROM pick_tasks WHERE wave_id = $1 AND status IN ('picked','short'));
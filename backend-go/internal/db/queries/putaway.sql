@@ -0,0 +1,81 @@
+-- Candidate bins are storage bins: never docks (where putaway starts),
+-- staging, transit, returns or quarantine bins, nor bins frozen by a count.
+-- Quantities of open putaway tasks count as already in their target bin, so
+-- two tasks do not get the same empty bin.
+
+-- name: ListPutawayRules :many
+SELECT * FROM putaway_rules
+WHERE (sqlc.narg(warehouse_id)::uuid IS NULL OR warehouse_id = sqlc.narg(warehouse_id))
+  AND (@include_inactive::bool OR active)
+ORDER BY warehouse_id, priority, created_at;
+
+-- name: ListMatchingPutawayRules :many
+SELECT * FROM putaway_rules
+WHERE warehouse_id = @warehouse_id AND active
+  AND (item_id IS NULL OR item_id = @item_id)
+  AND (item_class IS NULL OR item_class = @item_class)
+ORDER BY priority, created_at;
+
+-- name: CreatePutawayRule :one
+INSERT INTO putaway_rules (warehouse_id, name, priority, item_id, item_class, location_type, path_prefix, strategy, max_qty)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+RETURNING *;
+
+-- name: UpdatePutawayRule :one
+UPDATE putaway_rules SET name = $2, priority = $3, item_id = $4, item_class = $5, location_type = $6,
+  path_prefix = $7, strategy = $8, max_qty = $9, updated_at = now()
+WHERE id = $1
+RETURNING *;
+
+-- name: SetPutawayRuleActive :one
+UPDATE putaway_rules SET active = $2, updated_at = now()
+WHERE id = $1
+RETURNING *;
+
+-- name: ListPutawayCandidates :many
+SELECT l.id AS location_id, l.code AS location_code, l.path,
+       (COALESCE(SUM(sb.qty_on_hand) FILTER (WHERE sb.item_id = @item_id), 0)
+        + COALESCE((SELECT SUM(t.qty) FROM putaway_tasks t
+                    WHERE t.to_location_id = l.id AND t.status = 'open' AND t.item_id = @item_id), 0))::numeric AS item_qty,
+       (COALESCE(SUM(sb.qty_on_hand), 0)
+        + COALESCE((SELECT SUM(t.qty) FROM putaway_tasks t
+                    WHERE t.to_location_id = l.id AND t.status = 'open'), 0))::numeric AS total_qty
+FROM locations l
+LEFT JOIN stock_balance sb ON sb.location_id = l.id AND sb.qty_on_hand <> 0
+WHERE l.warehouse_id = @warehouse_id AND l.active
+  AND l.frozen_by_count_id IS NULL
+  AND l.type NOT IN ('dock', 'staging', 'in_transit', 'returns', 'quarantine')
+  AND (@location_type::text = '' OR l.type = @location_type)
+  AND (@path_prefix::text = '' OR l.path <@ @path_prefix::text::ltree)
+  AND (sqlc.narg(exclude_id)::uuid IS NULL OR l.id <> sqlc.narg(exclude_id))
+GROUP BY l.id
+ORDER BY l.path NULLS LAST, l.code;
+
+-- name: CreatePutawayTask :one
+INSERT INTO putaway_tasks (warehouse_id, item_id, qty, lot_code, serial_no, from_location_id, to_location_id, rule_id, ref_type, ref_id, created_by)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+RETURNING *;
+
+-- name: GetPutawayTask :one
+SELECT * FROM putaway_tasks WHERE id = $1;
+
+-- name: LockPutawayTask :one
+SELECT * FROM putaway_tasks WHERE id = $1
+FOR UPDATE;
+
+-- name: ListPutawayTasks :many
+SELECT * FROM putaway_tasks
+WHERE (sqlc.narg(warehouse_id)::uuid IS NULL OR warehouse_id = sqlc.narg(warehouse_id))
+  AND (@status::text = '' OR status = @status)
+ORDER BY created_at, id
+LIMIT @page_limit OFFSET @page_offset;
+
+-- name: ConfirmPutawayTask :one
+UPDATE putaway_tasks SET status = 'confirmed', to_location_id = $2, move_id = $3, confirmed_by = $4, updated_at = now()
+WHERE id = $1
+RETURNING *;
+
+-- name: CancelPutawayTask :one
+UPDATE putaway_tasks SET status = 'cancelled', updated_at = now()
+WHERE id = $1
+RETURNING *;
@@ -0,0 +1,62 @@
+-- FULL JOIN needs hashable join conditions, so ListBalanceDrift compares NULL
+-- lot/serial/handling unit ids through a sentinel instead of IS NOT DISTINCT FROM.
+
+-- name: ListBalanceDrift :many
+WITH expected AS (
+  SELECT item_id, location_id, lot_id, serial_id, status, hu_id, SUM(on_hand) AS on_hand, SUM(allocated) AS allocated
+  FROM (
+    SELECT item_id, to_location_id AS location_id, lot_id, serial_id, COALESCE(to_status, status) AS status, to_hu_id AS hu_id, qty AS on_hand, 0 AS allocated
+    FROM stock_ledger WHERE to_location_id IS NOT NULL
+    UNION ALL
+    SELECT item_id, from_location_id, lot_id, serial_id, status, from_hu_id, -qty, 0
+    FROM stock_ledger WHERE from_location_id IS NOT NULL
+    UNION ALL
+    SELECT item_id, location_id, lot_id, serial_id, 'available', hu_id, 0, qty
+    FROM stock_allocations WHERE status = 'active'
+  ) x
+  GROUP BY item_id, location_id, lot_id, serial_id, status, hu_id
+), d AS (
+  SELECT COALESCE(e.item_id, sb.item_id) AS item_id,
+         COALESCE(e.location_id, sb.location_id) AS location_id,
+         CASE WHEN e.item_id IS NOT NULL THEN e.lot_id ELSE sb.lot_id END AS lot_id,
+         CASE WHEN e.item_id IS NOT NULL THEN e.serial_id ELSE sb.serial_id END AS serial_id,
+         COALESCE(e.status, sb.status) AS status,
+         CASE WHEN e.item_id IS NOT NULL THEN e.hu_id ELSE sb.hu_id END AS hu_id,
+         COALESCE(e.on_hand, 0) AS expected_on_hand, COALESCE(sb.qty_on_hand, 0) AS actual_on_hand,
+         COALESCE(e.allocated, 0) AS expected_allocated, COALESCE(sb.qty_allocated, 0) AS actual_allocated
+  FROM expected e
+  FULL JOIN stock_balance sb
+    ON sb.item_id = e.item_id AND sb.location_id = e.location_id AND sb.status = e.status
+   AND COALESCE(sb.lot_id, '00000000-0000-0000-0000-000000000000') = COALESCE(e.lot_id, '00000000-0000-0000-0000-000000000000')
+   AND COALESCE(sb.serial_id, '00000000-0000-0000-0000-000000000000') = COALESCE(e.serial_id, '00000000-0000-0000-0000-000000000000')
+   AND COALESCE(sb.hu_id, '00000000-0000-0000-0000-000000000000') = COALESCE(e.hu_id, '00000000-0000-0000-0000-000000000000')
+)
+SELECT d.item_id::uuid AS item_id, i.sku, d.location_id::uuid AS location_id, l.code AS location_code,
+       d.lot_id::uuid AS lot_id, d.serial_id::uuid AS serial_id, d.status::text AS status, d.hu_id::uuid AS hu_id,
+       d.expected_on_hand::numeric AS expected_on_hand, d.actual_on_hand::numeric AS actual_on_hand,
+       d.expected_allocated::numeric AS expected_allocated, d.actual_allocated::numeric AS actual_allocated
+FROM d
+JOIN items i ON i.id = d.item_id
+JOIN locations l ON l.id = d.location_id
+WHERE d.expected_on_hand <> d.actual_on_hand OR d.expected_allocated <> d.actual_allocated
+ORDER BY i.sku, l.code, d.status;
+
+-- name: LockStockBalanceTable :exec
+LOCK TABLE stock_balance IN SHARE ROW EXCLUSIVE MODE;
+
+-- name: SetStockBalance :exec
+INSERT INTO stock_balance (item_id, location_id, lot_id, serial_id, status, hu_id, qty_on_hand, qty_allocated)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+ON CONFLICT ON CONSTRAINT stock_balance_key
+DO UPDATE SET
+  qty_on_hand = EXCLUDED.qty_on_hand,
+  qty_allocated = EXCLUDED.qty_allocated,
+  updated_at = now();
+
+-- name: InsertStockReconcileRun :one
+INSERT INTO stock_reconcile_runs (drift_count, fixed, report)
+VALUES ($1, $2, $3)
+RETURNING *;
+
+-- name: GetLatestStockReconcileRun :one
+SELECT * FROM stock_reconcile_runs ORDER BY ran_at DESC LIMIT 1;
@@ -0,0 +1,98 @@
+-- A pick face is an item at the location of an active replenishment
+-- setting. Its level is the free available stock there plus what open
+-- replenishment tasks are bringing in; reserve stock of the item in another
+-- pick face is never taken as a source.
+
+-- name: ListReplenishmentSettings :many
+SELECT * FROM replenishment_settings
+WHERE (sqlc.narg(warehouse_id)::uuid IS NULL OR warehouse_id = sqlc.narg(warehouse_id))
+  AND (sqlc.narg(item_id)::uuid IS NULL OR item_id = sqlc.narg(item_id))
+  AND (@include_inactive::bool OR active)
+ORDER BY warehouse_id, created_at;
+
+-- name: CreateReplenishmentSetting :one
+INSERT INTO replenishment_settings (warehouse_id, item_id, location_id, min_qty, max_qty, fixed_qty, source_path)
+VALUES ($1, $2, $3, $4, $5, $6, $7)
+RETURNING *;
+
+-- name: UpdateReplenishmentSetting :one
+UPDATE replenishment_settings SET min_qty = $2, max_qty = $3, fixed_qty = $4, source_path = $5, updated_at = now()
+WHERE id = $1
+RETURNING *;
+
+-- name: SetReplenishmentSettingActive :one
+UPDATE replenishment_settings SET active = $2, updated_at = now()
+WHERE id = $1
+RETURNING *;
+
+-- name: ListActiveReplenishmentSettingIDs :many
+SELECT id FROM replenishment_settings
+WHERE active AND (sqlc.narg(warehouse_id)::uuid IS NULL OR warehouse_id = sqlc.narg(warehouse_id))
+ORDER BY warehouse_id, created_at;
+
+-- Planner runs working on the same pick face skip it instead of waiting, so
+-- a face is never planned twice at once.
+-- name: LockReplenishmentSetting :one
+SELECT * FROM replenishment_settings
+WHERE id = $1 AND active
+FOR UPDATE SKIP LOCKED;
+
+-- name: GetReplenishmentLevel :one
+SELECT COALESCE((SELECT SUM(sb.qty_on_hand) FROM stock_balance sb
+                 WHERE sb.item_id = @item_id AND sb.location_id = @location_id AND sb.status = 'available'), 0)::numeric AS qty_on_hand,
+       COALESCE((SELECT SUM(sb.qty_allocated) FROM stock_balance sb
+                 WHERE sb.item_id = @item_id AND sb.location_id = @location_id AND sb.status = 'available'), 0)::numeric AS qty_allocated,
+       COALESCE((SELECT SUM(t.qty) FROM replenishment_tasks t
+                 WHERE t.item_id = @item_id AND t.to_location_id = @location_id AND t.status = 'open'), 0)::numeric AS qty_inbound;
+
+-- name: ListReplenishmentSources :many
+SELECT sb.location_id, sb.lot_id, sb.serial_id, sb.hu_id, sb.qty_on_hand, sb.qty_allocated,
+       l.code location_code, lo.expires_on,
+       COALESCE(lo.received_at, (
+         SELECT min(sl.ts) FROM stock_ledger sl
+         WHERE sl.item_id = sb.item_id AND sl.to_location_id = sb.location_id
+       ), sb.updated_at)::timestamptz AS received_at,
+       lo.lot_code, se.serial_no
+FROM stock_balance sb
+JOIN locations l ON l.id = sb.location_id
+LEFT JOIN lots lo ON lo.id = sb.lot_id
+LEFT JOIN serials se ON se.id = sb.serial_id
+WHERE sb.item_id = @item_id
+  AND l.warehouse_id = @warehouse_id
+  AND l.active
+  AND l.frozen_by_count_id IS NULL
+  AND l.type NOT IN ('dock','in_transit')
+  AND (@source_path::text = '' OR l.path <@ @source_path::text::ltree)
+  AND NOT EXISTS (SELECT 1 FROM replenishment_settings rs
+                  WHERE rs.item_id = sb.item_id AND rs.location_id = sb.location_id AND rs.active)
+  AND sb.status = 'available'
+  AND sb.qty_on_hand - sb.qty_allocated > 0
+  AND (lo.expires_on IS NULL OR lo.expires_on >= CURRENT_DATE)
+ORDER BY l.code
+FOR UPDATE OF sb;
+
+-- name: CreateReplenishmentTask :one
+INSERT INTO replenishment_tasks (setting_id, warehouse_id, item_id, qty, lot_code, serial_no, from_location_id, to_location_id, created_by)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+RETURNING *;
+
+-- name: LockReplenishmentTask :one
+SELECT * FROM replenishment_tasks WHERE id = $1
+FOR UPDATE;
+
+-- name: ListReplenishmentTasks :many
+SELECT * FROM replenishment_tasks
+WHERE (sqlc.narg(warehouse_id)::uuid IS NULL OR warehouse_id = sqlc.narg(warehouse_id))
+  AND (@status::text = '' OR status = @status)
+ORDER BY created_at, id
+LIMIT @page_limit OFFSET @page_offset;
+
+-- name: ConfirmReplenishmentTask :one
+UPDATE replenishment_tasks SET status = 'confirmed', move_id = $2, confirmed_by = $3, updated_at = now()
+WHERE id = $1
+RETURNING *;
+
+-- name: CancelReplenishmentTask :one
+UPDATE replenishment_tasks SET status = 'cancelled', updated_at = now()
+WHERE id = $1
+RETURNING *;
@@ -0,0 +1,94 @@
+-- name: ListReturnReasons :many
+SELECT * FROM return_reasons WHERE active
+ORDER BY code;
+
+-- name: CreateRma :one
+INSERT INTO rmas (shipment_id, order_id, customer_id, warehouse_id, returns_location_id, comment, created_by)
+VALUES ($1, $2, $3, $4, $5, $6, $7)
+RETURNING *;
+
+-- name: InsertRmaLine :one
+INSERT INTO rma_lines (rma_id, line_no, item_id, lot_code, serial_no, reason_code, qty_authorized)
+VALUES ($1, $2, $3, $4, $5, $6, $7)
+RETURNING *;
+
+-- name: GetRma :one
+SELECT * FROM rmas WHERE id = $1;
+
+-- name: LockRma :one
+SELECT * FROM rmas WHERE id = $1
+FOR UPDATE;
+
+-- name: ListRmas :many
+SELECT * FROM rmas
+WHERE (sqlc.narg(shipment_id)::uuid IS NULL OR shipment_id = sqlc.narg(shipment_id))
+  AND (sqlc.narg(customer_id)::uuid IS NULL OR customer_id = sqlc.narg(customer_id))
+  AND (sqlc.narg(warehouse_id)::uuid IS NULL OR warehouse_id = sqlc.narg(warehouse_id))
+  AND (sqlc.arg(status)::text = '' OR status = sqlc.arg(status))
+ORDER BY rma_no DESC
+LIMIT sqlc.arg(page_limit) OFFSET sqlc.arg(page_offset);
+
+-- name: ListRmaLines :many
+SELECT * FROM rma_lines WHERE rma_id = $1
+ORDER BY line_no;
+
+-- name: GetRmaLineByNo :one
+SELECT * FROM rma_lines WHERE rma_id = $1 AND line_no = $2;
+
+-- name: SetRmaStatus :one
+UPDATE rmas SET status = $2, updated_at = now()
+WHERE id = $1
+RETURNING *;
+
+-- name: AddRmaLineReceived :one
+UPDATE rma_lines SET qty_received = qty_received + sqlc.arg(qty)
+WHERE id = sqlc.arg(id)
+RETURNING *;
+
+-- name: AddRmaLineDispositioned :one
+UPDATE rma_lines SET qty_dispositioned = qty_dispositioned + sqlc.arg(qty)
+WHERE id = sqlc.arg(id)
+RETURNING *;
+
+-- name: InsertRmaDisposition :one
+INSERT INTO rma_dispositions (rma_id, line_id, disposition, qty, to_location_id, move_id, comment, inspected_by)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+RETURNING *;
+
+-- name: ListRmaDispositions :many
+SELECT d.id, rl.line_no, d.disposition, d.qty, d.to_location_id, d.move_id, d.comment, d.inspected_by, d.created_at
+FROM rma_dispositions d
+JOIN rma_lines rl ON rl.id = d.line_id
+WHERE d.rma_id = $1
+ORDER BY d.created_at, rl.line_no;
+
+-- name: SumAuthorizedForShipment :many
+SELECT rl.item_id, rl.lot_code, rl.serial_no, sum(rl.qty_authorized)::numeric AS qty
+FROM rma_lines rl
+JOIN rmas r ON r.id = rl.rma_id
+WHERE r.shipment_id = $1 AND r.status <> 'cancelled'
+GROUP BY rl.item_id, rl.lot_code, rl.serial_no;
+
+-- name: ReportReturnReasons :many
+SELECT rl.reason_code, rr.description, count(DISTINCT r.id) AS rmas,
+       sum(rl.qty_authorized)::numeric AS qty_authorized, sum(rl.qty_received)::numeric AS qty_received
+FROM rma_lines rl
+JOIN rmas r ON r.id = rl.rma_id
+JOIN return_reasons rr ON rr.code = rl.reason_code
+WHERE r.status <> 'cancelled'
+  AND (sqlc.narg(warehouse_id)::uuid IS NULL OR r.warehouse_id = sqlc.narg(warehouse_id))
+  AND (sqlc.narg(from_ts)::timestamptz IS NULL OR r.created_at >= sqlc.narg(from_ts))
+  AND (sqlc.narg(to_ts)::timestamptz IS NULL OR r.created_at < sqlc.narg(to_ts))
+GROUP BY rl.reason_code, rr.description
+ORDER BY rl.reason_code;
+
+-- name: ReportRmaDispositions :many
+SELECT d.disposition, rl.reason_code, count(*) AS lines, sum(d.qty)::numeric AS qty
+FROM rma_dispositions d
+JOIN rma_lines rl ON rl.id = d.line_id
+JOIN rmas r ON r.id = d.rma_id
+WHERE (sqlc.narg(warehouse_id)::uuid IS NULL OR r.warehouse_id = sqlc.narg(warehouse_id))
+  AND (sqlc.narg(from_ts)::timestamptz IS NULL OR d.created_at >= sqlc.narg(from_ts))
+  AND (sqlc.narg(to_ts)::timestamptz IS NULL OR d.created_at < sqlc.narg(to_ts))
+GROUP BY d.disposition, rl.reason_code
+ORDER BY d.disposition, rl.reason_code;
@@ -0,0 +1,24 @@
+-- name: FindItemByBarcode :one
+SELECT b.barcode, b.barcode_type, b.uom, i.id AS item_id, i.sku, i.name, i.uom AS base_uom, i.tracking_mode, i.active
+FROM item_barcodes b
+JOIN items i ON i.id = b.item_id
+WHERE b.barcode = @code OR b.gtin = NULLIF(@gtin::text, '')
+ORDER BY (b.barcode = @code) DESC
+LIMIT 1;
+
+-- name: GetHandlingUnitByCode :one
+SELECT hu.id, hu.code, hu.hu_type, hu.parent_id, hu.location_id, l.code AS location_code, l.warehouse_id
+FROM handling_units hu
+JOIN locations l ON l.id = hu.location_id
+WHERE hu.code = $1;
+
+-- name: FindLocationsByCode :many
+SELECT l.id, l.warehouse_id, w.code AS warehouse_code, l.code, l.type, l.active
+FROM locations l
+JOIN warehouses w ON w.id = l.warehouse_id
+WHERE l.code = @code
+  AND (sqlc.narg(warehouse_id)::uuid IS NULL OR l.warehouse_id = sqlc.narg(warehouse_id))
+ORDER BY w.code;
+
+-- name: FindSerial :one
+SELECT * FROM serials WHERE item_id = $1 AND serial_no = $2;
@@ -0,0 +1,91 @@
+-- name: CreateShipment :one
+INSERT INTO shipments (
+  order_id, warehouse_id, staging_location_id, carrier, transport_reason,
+  ship_to_name, ship_to_address, ship_to_city, ship_to_postal_code, ship_to_country, ship_to_vat_number, created_by
+) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
+RETURNING *;
+
+-- name: GetShipment :one
+SELECT * FROM shipments WHERE id = $1;
+
+-- name: LockShipment :one
+SELECT * FROM shipments WHERE id = $1
+FOR UPDATE;
+
+-- name: ListShipments :many
+SELECT * FROM shipments
+WHERE (sqlc.narg(order_id)::uuid IS NULL OR order_id = sqlc.narg(order_id))
+  AND (sqlc.narg(warehouse_id)::uuid IS NULL OR warehouse_id = sqlc.narg(warehouse_id))
+  AND (sqlc.arg(status)::text = '' OR status = sqlc.arg(status))
+ORDER BY created_at DESC, id
+LIMIT sqlc.arg(page_limit) OFFSET sqlc.arg(page_offset);
+
+-- name: SetShipmentStatus :one
+UPDATE shipments SET status = $2, updated_at = now()
+WHERE id = $1
+RETURNING *;
+
+-- name: ConfirmShipment :one
+UPDATE shipments
+SET status = 'confirmed', ddt_year = $2, ddt_number = $3, confirmed_by = $4, confirmed_at = now(), updated_at = now()
+WHERE id = $1
+RETURNING *;
+
+-- name: NextDdtNumber :one
+INSERT INTO ddt_counters (warehouse_id, year, last_number) VALUES ($1, $2, 1)
+ON CONFLICT (warehouse_id, year) DO UPDATE SET last_number = ddt_counters.last_number + 1
+RETURNING last_number;
+
+-- name: NextCartonNo :one
+SELECT (COALESCE(max(carton_no), 0) + 1)::int AS next FROM shipment_cartons WHERE shipment_id = $1;
+
+-- name: InsertShipmentCarton :one
+INSERT INTO shipment_cartons (shipment_id, carton_no, weight_kg)
+VALUES ($1, $2, $3)
+RETURNING *;
+
+-- name: GetShipmentCarton :one
+SELECT * FROM shipment_cartons WHERE shipment_id = $1 AND carton_no = $2;
+
+-- name: ListShipmentCartons :many
+SELECT * FROM shipment_cartons WHERE shipment_id = $1
+ORDER BY carton_no;
+
+-- name: InsertShipmentCartonLine :one
+INSERT INTO shipment_carton_lines (carton_id, order_line_id, item_id, qty, lot_code, serial_no)
+VALUES ($1, $2, $3, $4, $5, $6)
+RETURNING *;
+
+-- name: DeleteShipmentCartonLine :one
+DELETE FROM shipment_carton_lines scl
+USING shipment_cartons sc
+WHERE scl.id = $1 AND sc.id = scl.carton_id AND sc.shipment_id = $2
+RETURNING scl.id, scl.carton_id, scl.order_line_id, scl.item_id, scl.qty, scl.lot_code, scl.serial_no, scl.move_id, scl.created_at;
+
+-- name: SetShipmentCartonLineMove :exec
+UPDATE shipment_carton_lines SET move_id = $2 WHERE id = $1;
+
+-- name: ListShipmentContent :many
+SELECT scl.id, sc.carton_no, scl.order_line_id, sol.line_no, scl.item_id, i.sku, i.name AS item_name, i.uom,
+       scl.qty, scl.lot_code, scl.serial_no, scl.move_id
+FROM shipment_carton_lines scl
+JOIN shipment_cartons sc ON sc.id = scl.carton_id
+JOIN sales_order_lines sol ON sol.id = scl.order_line_id
+JOIN items i ON i.id = scl.item_id
+WHERE sc.shipment_id = $1
+ORDER BY sc.carton_no, sol.line_no, scl.created_at;
+
+-- name: GetSalesOrderLineByNo :one
+SELECT * FROM sales_order_lines WHERE order_id = $1 AND line_no = $2;
+
+-- name: SumPackedForOrderLine :one
+SELECT COALESCE(sum(scl.qty), 0)::numeric AS qty_packed
+FROM shipment_carton_lines scl
+JOIN shipment_cartons sc ON sc.id = scl.carton_id
+JOIN shipments s ON s.id = sc.shipment_id
+WHERE scl.order_line_id = $1 AND s.status IN ('open','confirmed');
+
+-- name: AddSalesOrderLineShipped :one
+UPDATE sales_order_lines SET qty_shipped = qty_shipped + $2
+WHERE id = $1
+RETURNING *;
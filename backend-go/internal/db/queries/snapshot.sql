@@ -0,0 +1,116 @@
+-- ListStaleSnapshots flags a checkpoint that differs from its predecessor plus
+-- the ledger rows between them: a move committed after the checkpoint was
+-- taken, with a ts at or before taken_at. Only checkpoints taken after since
+-- are checked.
+
+-- name: InsertStockBalanceSnapshot :one
+INSERT INTO stock_balance_snapshots (taken_at)
+VALUES ($1)
+RETURNING *;
+
+-- name: InsertStockBalanceSnapshotLines :execrows
+WITH prev AS (
+  SELECT id, taken_at FROM stock_balance_snapshots
+  WHERE taken_at < @taken_at::timestamptz
+  ORDER BY taken_at DESC
+  LIMIT 1
+), d AS (
+  SELECT item_id, location_id, lot_id, serial_id, status, hu_id, qty_on_hand AS qty
+  FROM stock_balance_snapshot_lines
+  WHERE snapshot_id = (SELECT id FROM prev)
+  UNION ALL
+  SELECT item_id, to_location_id, lot_id, serial_id, COALESCE(to_status, status), to_hu_id, qty
+  FROM stock_ledger
+  WHERE to_location_id IS NOT NULL
+    AND ts > COALESCE((SELECT taken_at FROM prev), '-infinity') AND ts <= @taken_at::timestamptz
+  UNION ALL
+  SELECT item_id, from_location_id, lot_id, serial_id, status, from_hu_id, -qty
+  FROM stock_ledger
+  WHERE from_location_id IS NOT NULL
+    AND ts > COALESCE((SELECT taken_at FROM prev), '-infinity') AND ts <= @taken_at::timestamptz
+)
+INSERT INTO stock_balance_snapshot_lines (snapshot_id, item_id, location_id, lot_id, serial_id, status, hu_id, qty_on_hand)
+SELECT @snapshot_id::uuid, item_id, location_id, lot_id, serial_id, status, hu_id, SUM(qty)
+FROM d
+GROUP BY item_id, location_id, lot_id, serial_id, status, hu_id
+HAVING SUM(qty) <> 0;
+
+-- name: ListStockBalancesAsOf :many
+WITH cp AS (
+  SELECT id, taken_at FROM stock_balance_snapshots
+  WHERE taken_at <= @as_of::timestamptz
+  ORDER BY taken_at DESC
+  LIMIT 1
+), d AS (
+  SELECT item_id, location_id, lot_id, serial_id, status, hu_id, qty_on_hand AS qty
+  FROM stock_balance_snapshot_lines
+  WHERE snapshot_id = (SELECT id FROM cp)
+  UNION ALL
+  SELECT item_id, to_location_id, lot_id, serial_id, COALESCE(to_status, status), to_hu_id, qty
+  FROM stock_ledger
+  WHERE to_location_id IS NOT NULL
+    AND ts > COALESCE((SELECT taken_at FROM cp), '-infinity') AND ts <= @as_of::timestamptz
+  UNION ALL
+  SELECT item_id, from_location_id, lot_id, serial_id, status, from_hu_id, -qty
+  FROM stock_ledger
+  WHERE from_location_id IS NOT NULL
+    AND ts > COALESCE((SELECT taken_at FROM cp), '-infinity') AND ts <= @as_of::timestamptz
+), sb AS (
+  SELECT item_id, location_id, lot_id, serial_id, status, hu_id, SUM(qty) AS qty_on_hand
+  FROM d
+  GROUP BY item_id, location_id, lot_id, serial_id, status, hu_id
+  HAVING SUM(qty) <> 0
+)
+SELECT sb.item_id, sb.location_id, sb.lot_id, sb.serial_id, sb.status, sb.qty_on_hand::numeric AS qty_on_hand,
+       0::numeric AS qty_allocated, @as_of::timestamptz AS updated_at,
+       i.sku, i.name item_name, l.code location_code, l.type location_type, w.code warehouse_code,
+       lo.lot_code, lo.expires_on, se.serial_no, sb.hu_id, hu.code hu_code
+FROM sb
+JOIN items i ON i.id = sb.item_id
+JOIN locations l ON l.id = sb.location_id
+JOIN warehouses w ON w.id = l.warehouse_id
+LEFT JOIN lots lo ON lo.id = sb.lot_id
+LEFT JOIN serials se ON se.id = sb.serial_id
+LEFT JOIN handling_units hu ON hu.id = sb.hu_id
+WHERE (@q::text = '' OR i.sku ILIKE '%' || @q || '%' OR i.name ILIKE '%' || @q || '%')
+  AND (@warehouse::text = '' OR w.code = @warehouse)
+  AND (@location::text = '' OR l.code = @location)
+  AND (@status::text = '' OR sb.status = @status)
+  AND (@hu::text = '' OR hu.code = @hu)
+  AND (@path::text = '' OR l.path <@ @path::text::ltree)
+ORDER BY i.sku, l.code, lo.expires_on NULLS LAST, lo.lot_code, se.serial_no, sb.status, hu.code NULLS FIRST
+LIMIT @page_limit OFFSET @page_offset;
+-- name: ListStaleSnapshots :many
+WITH s AS (
+  SELECT id, taken_at, lag(id) OVER w AS prev_id, lag(taken_at) OVER w AS prev_taken_at
+  FROM stock_balance_snapshots
+  WINDOW w AS (ORDER BY taken_at)
+), chk AS (
+  SELECT id, taken_at, prev_id, prev_taken_at FROM s WHERE taken_at > @since::timestamptz
+), d AS (
+  SELECT chk.id AS snapshot_id, sl.item_id, sl.location_id, sl.lot_id, sl.serial_id, sl.status, sl.hu_id, sl.qty_on_hand AS qty
+  FROM chk JOIN stock_balance_snapshot_lines sl ON sl.snapshot_id = chk.prev_id
+  UNION ALL
+  SELECT chk.id, l.item_id, l.to_location_id, l.lot_id, l.serial_id, COALESCE(l.to_status, l.status), l.to_hu_id, l.qty
+  FROM chk JOIN stock_ledger l ON l.to_location_id IS NOT NULL
+    AND l.ts > COALESCE(chk.prev_taken_at, '-infinity') AND l.ts <= chk.taken_at
+  UNION ALL
+  SELECT chk.id, l.item_id, l.from_location_id, l.lot_id, l.serial_id, l.status, l.from_hu_id, -l.qty
+  FROM chk JOIN stock_ledger l ON l.from_location_id IS NOT NULL
+    AND l.ts > COALESCE(chk.prev_taken_at, '-infinity') AND l.ts <= chk.taken_at
+  UNION ALL
+  SELECT chk.id, sl.item_id, sl.location_id, sl.lot_id, sl.serial_id, sl.status, sl.hu_id, -sl.qty_on_hand
+  FROM chk JOIN stock_balance_snapshot_lines sl ON sl.snapshot_id = chk.id
+), x AS (
+  SELECT snapshot_id
+  FROM d
+  GROUP BY snapshot_id, item_id, location_id, lot_id, serial_id, status, hu_id
+  HAVING SUM(qty) <> 0
+)
+SELECT chk.id AS snapshot_id, chk.taken_at, count(*)::int AS drifted_lines
+FROM x JOIN chk ON chk.id = x.snapshot_id
+GROUP BY chk.id, chk.taken_at
+ORDER BY chk.taken_at;
+
+-- name: DeleteStockBalanceSnapshotsFrom :execrows
+DELETE FROM stock_balance_snapshots WHERE taken_at >= @taken_at::timestamptz;
@@ -1,27 +1,83 @@
 -- name: ListStockBalances :many
-SELECT sb.item_id, sb.location_id, sb.qty_on_hand, sb.qty_allocated, sb.updated_at,
-       i.sku, i.name item_name, l.code location_code, w.code warehouse_code
+SELECT sb.item_id, sb.location_id, sb.lot_id, sb.serial_id, sb.status, sb.qty_on_hand, sb.qty_allocated, sb.updated_at,
+       i.sku, i.name item_name, l.code location_code, l.type location_type, w.code warehouse_code,
+       lo.lot_code, lo.expires_on, se.serial_no, sb.hu_id, hu.code hu_code
 FROM stock_balance sb
 JOIN items i ON i.id = sb.item_id
 JOIN locations l ON l.id = sb.location_id
 JOIN warehouses w ON w.id = l.warehouse_id
+LEFT JOIN lots lo ON lo.id = sb.lot_id
+LEFT JOIN serials se ON se.id = sb.serial_id
+LEFT JOIN handling_units hu ON hu.id = sb.hu_id
 WHERE ($1::text = '' OR i.sku ILIKE '%' || $1 || '%' OR i.name ILIKE '%' || $1 || '%')
   AND ($2::text = '' OR w.code = $2)
   AND ($3::text = '' OR l.code = $3)
-ORDER BY i.sku, l.code
-LIMIT $4 OFFSET $5;
+  AND ($4::text = '' OR sb.status = $4)
+  AND ($5::text = '' OR hu.code = $5)
+  AND ($6::text = '' OR l.path <@ $6::text::ltree)
+ORDER BY i.sku, l.code, lo.expires_on NULLS LAST, lo.lot_code, se.serial_no, sb.status, hu.code NULLS FIRST
+LIMIT $7 OFFSET $8;
 
 -- name: InsertStockLedgerMove :one
 INSERT INTO stock_ledger (
-  item_id, qty, from_location_id, to_location_id, reason_code, ref_type, ref_id, actor_user_id, request_id
-) VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9)
+  item_id, qty, from_location_id, to_location_id, reason_code, ref_type, ref_id, actor_user_id, request_id,
+  lot_id, serial_id, move_type, comment, status, to_status, from_hu_id, to_hu_id
+) VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9,$10,$11,$12,$13,$14,$15,$16,$17)
 RETURNING *;
 
 -- name: UpsertStockBalanceDelta :exec
-INSERT INTO stock_balance (item_id, location_id, qty_on_hand, qty_allocated)
-VALUES ($1, $2, $3, $4)
-ON CONFLICT (item_id, location_id)
+INSERT INTO stock_balance (item_id, location_id, lot_id, serial_id, status, hu_id, qty_on_hand, qty_allocated)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+ON CONFLICT ON CONSTRAINT stock_balance_key
 DO UPDATE SET
   qty_on_hand = stock_balance.qty_on_hand + EXCLUDED.qty_on_hand,
   qty_allocated = stock_balance.qty_allocated + EXCLUDED.qty_allocated,
   updated_at = now();
+
+-- name: LockStockBalance :one
+SELECT * FROM stock_balance
+WHERE item_id = $1 AND location_id = $2
+  AND lot_id IS NOT DISTINCT FROM $3
+  AND serial_id IS NOT DISTINCT FROM $4
+  AND status = $5
+  AND hu_id IS NOT DISTINCT FROM $6
+FOR UPDATE;
+
+-- name: AllowsNegativeStock :one
+SELECT (w.allow_negative_stock OR COALESCE(p.allow_negative_stock, false))::bool AS allowed
+FROM locations l
+JOIN warehouses w ON w.id = l.warehouse_id
+LEFT JOIN location_type_policies p ON p.type = l.type
+WHERE l.id = $1;
+
+-- name: GetLotByCode :one
+SELECT * FROM lots WHERE item_id = $1 AND lot_code = $2;
+
+-- name: LockSerialByNo :one
+SELECT * FROM serials WHERE item_id = $1 AND serial_no = $2
+FOR UPDATE;
+
+-- name: SetSerialLocation :exec
+UPDATE serials SET location_id = $2, updated_at = now()
+WHERE id = $1;
+
+-- name: GetUomConversion :one
+SELECT i.uom AS base_uom, bu.decimals AS base_decimals, u.decimals AS uom_decimals,
+       (CASE WHEN u.code = i.uom THEN 1 ELSE c.factor END)::numeric AS factor
+FROM items i
+JOIN uoms bu ON bu.code = i.uom
+JOIN uoms u ON u.code = COALESCE(NULLIF(@uom::text, ''), i.uom)
+LEFT JOIN item_uom_conversions c ON c.item_id = i.id AND c.uom = u.code
+WHERE i.id = @item_id;
+
+-- name: ListFrozenLocations :many
+SELECT id, code, frozen_by_count_id FROM locations
+WHERE id = ANY(@ids::uuid[]) AND frozen_by_count_id IS NOT NULL;
+
+-- name: ListTransitLocations :many
+SELECT code FROM locations
+WHERE id = ANY(@ids::uuid[]) AND type = 'in_transit';
+
+-- name: ListHandlingUnitLocations :many
+SELECT id, code, location_id FROM handling_units
+WHERE id = ANY(@ids::uuid[]);
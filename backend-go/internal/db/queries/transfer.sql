@@ -0,0 +1,79 @@
+-- name: EnsureTransitLocation :one
+INSERT INTO locations (warehouse_id, code, type) VALUES ($1, 'IN-TRANSIT', 'in_transit')
+ON CONFLICT (warehouse_id, code) DO UPDATE SET updated_at = locations.updated_at
+RETURNING *;
+
+-- name: CreateTransferOrder :one
+INSERT INTO transfer_orders (from_warehouse_id, to_warehouse_id, transit_location_id, comment, created_by)
+VALUES ($1, $2, $3, $4, $5)
+RETURNING *;
+
+-- name: InsertTransferOrderLine :one
+INSERT INTO transfer_order_lines (order_id, line_no, item_id, qty)
+VALUES ($1, $2, $3, $4)
+RETURNING *;
+
+-- name: GetTransferOrder :one
+SELECT * FROM transfer_orders WHERE id = $1;
+
+-- name: LockTransferOrder :one
+SELECT * FROM transfer_orders WHERE id = $1
+FOR UPDATE;
+
+-- name: ListTransferOrders :many
+SELECT * FROM transfer_orders t
+WHERE (sqlc.narg(from_warehouse_id)::uuid IS NULL OR t.from_warehouse_id = sqlc.narg(from_warehouse_id))
+  AND (sqlc.narg(to_warehouse_id)::uuid IS NULL OR t.to_warehouse_id = sqlc.narg(to_warehouse_id))
+  AND (sqlc.arg(status)::text = '' OR t.status = sqlc.arg(status))
+  AND (NOT sqlc.arg(discrepancy)::bool OR EXISTS (
+    SELECT 1 FROM transfer_order_lines tl WHERE tl.order_id = t.id AND tl.qty_lost > 0
+  ))
+ORDER BY t.transfer_no DESC
+LIMIT sqlc.arg(page_limit) OFFSET sqlc.arg(page_offset);
+
+-- name: ListTransferOrderLines :many
+SELECT * FROM transfer_order_lines WHERE order_id = $1
+ORDER BY line_no;
+
+-- name: GetTransferOrderLineByNo :one
+SELECT * FROM transfer_order_lines WHERE order_id = $1 AND line_no = $2;
+
+-- name: AddTransferLineQty :one
+UPDATE transfer_order_lines
+SET qty_shipped = qty_shipped + sqlc.arg(shipped),
+    qty_received = qty_received + sqlc.arg(received),
+    qty_lost = qty_lost + sqlc.arg(lost)
+WHERE id = sqlc.arg(id)
+RETURNING *;
+
+-- name: SetTransferOrderStatus :one
+UPDATE transfer_orders SET status = $2, updated_at = now()
+WHERE id = $1
+RETURNING *;
+
+-- name: InsertTransferOrderMove :exec
+INSERT INTO transfer_order_moves (move_id, order_id, line_id, leg)
+VALUES ($1, $2, $3, $4);
+
+-- name: ListTransferInTransit :many
+SELECT tm.line_id, sl.lot_id, sl.serial_id, lo.lot_code, se.serial_no,
+       sum(CASE tm.leg WHEN 'ship' THEN sl.qty ELSE -sl.qty END)::numeric AS qty
+FROM transfer_order_moves tm
+JOIN stock_ledger sl ON sl.move_id = tm.move_id
+LEFT JOIN lots lo ON lo.id = sl.lot_id
+LEFT JOIN serials se ON se.id = sl.serial_id
+WHERE tm.order_id = $1
+GROUP BY tm.line_id, sl.lot_id, sl.serial_id, lo.lot_code, se.serial_no
+HAVING sum(CASE tm.leg WHEN 'ship' THEN sl.qty ELSE -sl.qty END) > 0
+ORDER BY lo.lot_code NULLS FIRST, se.serial_no NULLS FIRST;
+
+-- name: ListTransferOrderMoves :many
+SELECT tm.move_id, tm.leg, tl.line_no, sl.item_id, sl.qty, sl.from_location_id, sl.to_location_id,
+       lo.lot_code, se.serial_no, sl.actor_user_id, sl.ts
+FROM transfer_order_moves tm
+JOIN transfer_order_lines tl ON tl.id = tm.line_id
+JOIN stock_ledger sl ON sl.move_id = tm.move_id
+LEFT JOIN lots lo ON lo.id = sl.lot_id
+LEFT JOIN serials se ON se.id = sl.serial_id
+WHERE tm.order_id = $1
+ORDER BY sl.ts, tl.line_no;
@@ -8,6 +8,27 @@ import (
 	"github.com/jackc/pgx/v5/pgtype"
 )
 
+type Asn struct {
+	ID         pgtype.UUID
+	AsnNumber  string
+	SupplierID pgtype.UUID
+	PoID       pgtype.UUID
+	ExpectedAt pgtype.Timestamptz
+	Status     string
+	CreatedBy  pgtype.UUID
+	CreatedAt  pgtype.Timestamptz
+	UpdatedAt  pgtype.Timestamptz
+}
+
+type AsnLine struct {
+	ID        pgtype.UUID
+	AsnID     pgtype.UUID
+	PoLineID  pgtype.UUID
+	Qty       pgtype.Numeric
+	LotCode   pgtype.Text
+	ExpiresOn pgtype.Date
+}
+
 type AuditLog struct {
 	ID          pgtype.UUID
 	Ts          pgtype.Timestamptz
@@ -24,6 +45,95 @@ type AuditLog struct {
 	Metadata    []byte
 }
 
+type CountSession struct {
+	ID           pgtype.UUID
+	WarehouseID  pgtype.UUID
+	PathPrefix   pgtype.Text
+	ItemClass    pgtype.Text
+	AbcClass     pgtype.Text
+	Blind        bool
+	Freeze       bool
+	ToleranceQty pgtype.Numeric
+	TolerancePct pgtype.Numeric
+	Status       string
+	CreatedBy    pgtype.UUID
+	ApprovedBy   pgtype.UUID
+	ApprovedAt   pgtype.Timestamptz
+	CreatedAt    pgtype.Timestamptz
+	UpdatedAt    pgtype.Timestamptz
+}
+
+type CountTask struct {
+	ID             pgtype.UUID
+	SessionID      pgtype.UUID
+	LocationID     pgtype.UUID
+	ItemID         pgtype.UUID
+	LotID          pgtype.UUID
+	SerialID       pgtype.UUID
+	Status         string
+	ExpectedQty    pgtype.Numeric
+	FirstQty       pgtype.Numeric
+	FirstCountedBy pgtype.UUID
+	CountedQty     pgtype.Numeric
+	CountedBy      pgtype.UUID
+	CountedAt      pgtype.Timestamptz
+	MoveID         pgtype.UUID
+	CreatedAt      pgtype.Timestamptz
+	StockStatus    string
+	HuID           pgtype.UUID
+}
+
+type Customer struct {
+	ID         pgtype.UUID
+	Code       string
+	Name       string
+	Active     bool
+	CreatedAt  pgtype.Timestamptz
+	UpdatedAt  pgtype.Timestamptz
+	Address    pgtype.Text
+	City       pgtype.Text
+	PostalCode pgtype.Text
+	Country    pgtype.Text
+	VatNumber  pgtype.Text
+}
+
+type DdtCounter struct {
+	WarehouseID pgtype.UUID
+	Year        int32
+	LastNumber  int32
+}
+
+type GoodsReceipt struct {
+	ID             pgtype.UUID
+	PoID           pgtype.UUID
+	AsnID          pgtype.UUID
+	DockLocationID pgtype.UUID
+	ReceivedBy     pgtype.UUID
+	ReceivedAt     pgtype.Timestamptz
+	RequestID      pgtype.Text
+}
+
+type GoodsReceiptLine struct {
+	ID        pgtype.UUID
+	ReceiptID pgtype.UUID
+	PoLineID  pgtype.UUID
+	Qty       pgtype.Numeric
+	LotCode   pgtype.Text
+	SerialNo  pgtype.Text
+	MoveID    pgtype.UUID
+}
+
+type HandlingUnit struct {
+	ID         pgtype.UUID
+	Code       string
+	HuType     string
+	ParentID   pgtype.UUID
+	LocationID pgtype.UUID
+	CreatedBy  pgtype.UUID
+	CreatedAt  pgtype.Timestamptz
+	UpdatedAt  pgtype.Timestamptz
+}
+
 type IdempotencyKey struct {
 	Key          string
 	Endpoint     string
@@ -34,19 +144,70 @@ type IdempotencyKey struct {
 }
 
 type Item struct {
-	ID      pgtype.UUID
-	Sku     string
-	Name    string
-	Barcode pgtype.Text
-	Uom     string
+	ID           pgtype.UUID
+	Sku          string
+	Name         string
+	Barcode      pgtype.Text
+	Uom          string
+	Active       bool
+	CreatedAt    pgtype.Timestamptz
+	UpdatedAt    pgtype.Timestamptz
+	TrackingMode string
+	ItemClass    pgtype.Text
+	AbcClass     pgtype.Text
+	WeightKg     pgtype.Numeric
+	LengthCm     pgtype.Numeric
+	WidthCm      pgtype.Numeric
+	HeightCm     pgtype.Numeric
+}
+
+type ItemBarcode struct {
+	Barcode     string
+	Gtin        pgtype.Text
+	ItemID      pgtype.UUID
+	BarcodeType string
+	Uom         string
+	CreatedAt   pgtype.Timestamptz
+}
+
+type ItemUomConversion struct {
+	ItemID    pgtype.UUID
+	Uom       string
+	Factor    pgtype.Numeric
+	UpdatedAt pgtype.Timestamptz
 }
 
 type Location struct {
-	ID          pgtype.UUID
-	WarehouseID pgtype.UUID
-	Code        string
-	Type        string
-	Path        pgtype.Text
+	ID              pgtype.UUID
+	WarehouseID     pgtype.UUID
+	Code            string
+	Type            string
+	Path            pgtype.Text
+	Active          bool
+	CreatedAt       pgtype.Timestamptz
+	UpdatedAt       pgtype.Timestamptz
+	FrozenByCountID pgtype.UUID
+	Zone            pgtype.Text
+	MaxWeightKg     pgtype.Numeric
+	MaxVolumeM3     pgtype.Numeric
+	MaxHu           pgtype.Int4
+	MaxSkus         pgtype.Int4
+	MixedLots       bool
+}
+
+type LocationTypePolicy struct {
+	Type               string
+	AllowNegativeStock bool
+	UpdatedAt          pgtype.Timestamptz
+}
+
+type Lot struct {
+	ID             pgtype.UUID
+	ItemID         pgtype.UUID
+	LotCode        string
+	ManufacturedOn pgtype.Date
+	ExpiresOn      pgtype.Date
+	ReceivedAt     pgtype.Timestamptz
 }
 
 type OutboxEvent struct {
@@ -64,6 +225,96 @@ type Permission struct {
 	Name string
 }
 
+type PickTask struct {
+	ID           pgtype.UUID
+	WaveID       pgtype.UUID
+	OrderID      pgtype.UUID
+	OrderLineID  pgtype.UUID
+	AllocationID pgtype.UUID
+	ItemID       pgtype.UUID
+	LocationID   pgtype.UUID
+	Qty          pgtype.Numeric
+	QtyPicked    pgtype.Numeric
+	Sequence     int32
+	Status       string
+	ShortReason  pgtype.Text
+	MoveID       pgtype.UUID
+	PickedBy     pgtype.UUID
+	PickedAt     pgtype.Timestamptz
+	CreatedAt    pgtype.Timestamptz
+}
+
+type PurchaseOrder struct {
+	ID             pgtype.UUID
+	PoNumber       string
+	SupplierID     pgtype.UUID
+	WarehouseID    pgtype.UUID
+	Status         string
+	ExpectedOn     pgtype.Date
+	OverReceiptPct pgtype.Numeric
+	CreatedBy      pgtype.UUID
+	CreatedAt      pgtype.Timestamptz
+	UpdatedAt      pgtype.Timestamptz
+	ClosedAt       pgtype.Timestamptz
+}
+
+type PurchaseOrderLine struct {
+	ID          pgtype.UUID
+	PoID        pgtype.UUID
+	LineNo      int32
+	ItemID      pgtype.UUID
+	QtyOrdered  pgtype.Numeric
+	QtyReceived pgtype.Numeric
+}
+
+type PutawayRule struct {
+	ID           pgtype.UUID
+	WarehouseID  pgtype.UUID
+	Name         string
+	Priority     int32
+	ItemID       pgtype.UUID
+	ItemClass    pgtype.Text
+	LocationType pgtype.Text
+	PathPrefix   pgtype.Text
+	Strategy     string
+	MaxQty       pgtype.Numeric
+	Active       bool
+	CreatedAt    pgtype.Timestamptz
+	UpdatedAt    pgtype.Timestamptz
+}
+
+type PutawayTask struct {
+	ID             pgtype.UUID
+	WarehouseID    pgtype.UUID
+	ItemID         pgtype.UUID
+	Qty            pgtype.Numeric
+	LotCode        pgtype.Text
+	SerialNo       pgtype.Text
+	FromLocationID pgtype.UUID
+	ToLocationID   pgtype.UUID
+	RuleID         pgtype.UUID
+	RefType        pgtype.Text
+	RefID          pgtype.Text
+	Status         string
+	MoveID         pgtype.UUID
+	CreatedBy      pgtype.UUID
+	ConfirmedBy    pgtype.UUID
+	CreatedAt      pgtype.Timestamptz
+	UpdatedAt      pgtype.Timestamptz
+}
+
+type ReasonCode struct {
+	Code              string
+	Description       string
+	MoveTypes         []string
+	RequiresComment   bool
+	RequiresReference bool
+	Permission        pgtype.Text
+	Active            bool
+	CreatedAt         pgtype.Timestamptz
+	UpdatedAt         pgtype.Timestamptz
+}
+
 type RefreshSession struct {
 	ID          pgtype.UUID
 	UserID      pgtype.UUID
@@ -75,6 +326,85 @@ type RefreshSession struct {
 	CreatedAt   pgtype.Timestamptz
 }
 
+type ReplenishmentSetting struct {
+	ID          pgtype.UUID
+	WarehouseID pgtype.UUID
+	ItemID      pgtype.UUID
+	LocationID  pgtype.UUID
+	MinQty      pgtype.Numeric
+	MaxQty      pgtype.Numeric
+	FixedQty    pgtype.Numeric
+	SourcePath  pgtype.Text
+	Active      bool
+	CreatedAt   pgtype.Timestamptz
+	UpdatedAt   pgtype.Timestamptz
+}
+
+type ReplenishmentTask struct {
+	ID             pgtype.UUID
+	SettingID      pgtype.UUID
+	WarehouseID    pgtype.UUID
+	ItemID         pgtype.UUID
+	Qty            pgtype.Numeric
+	LotCode        pgtype.Text
+	SerialNo       pgtype.Text
+	FromLocationID pgtype.UUID
+	ToLocationID   pgtype.UUID
+	Status         string
+	MoveID         pgtype.UUID
+	CreatedBy      pgtype.UUID
+	ConfirmedBy    pgtype.UUID
+	CreatedAt      pgtype.Timestamptz
+	UpdatedAt      pgtype.Timestamptz
+}
+
+type ReturnReason struct {
+	Code        string
+	Description string
+	Active      bool
+}
+
+type Rma struct {
+	ID                pgtype.UUID
+	RmaNo             int64
+	ShipmentID        pgtype.UUID
+	OrderID           pgtype.UUID
+	CustomerID        pgtype.UUID
+	WarehouseID       pgtype.UUID
+	ReturnsLocationID pgtype.UUID
+	Status            string
+	Comment           pgtype.Text
+	CreatedBy         pgtype.UUID
+	CreatedAt         pgtype.Timestamptz
+	UpdatedAt         pgtype.Timestamptz
+}
+
+type RmaDisposition struct {
+	ID           pgtype.UUID
+	RmaID        pgtype.UUID
+	LineID       pgtype.UUID
+	Disposition  string
+	Qty          pgtype.Numeric
+	ToLocationID pgtype.UUID
+	MoveID       pgtype.UUID
+	Comment      pgtype.Text
+	InspectedBy  pgtype.UUID
+	CreatedAt    pgtype.Timestamptz
+}
+
+type RmaLine struct {
+	ID               pgtype.UUID
+	RmaID            pgtype.UUID
+	LineNo           int32
+	ItemID           pgtype.UUID
+	LotCode          pgtype.Text
+	SerialNo         pgtype.Text
+	ReasonCode       string
+	QtyAuthorized    pgtype.Numeric
+	QtyReceived      pgtype.Numeric
+	QtyDispositioned pgtype.Numeric
+}
+
 type Role struct {
 	ID        pgtype.UUID
 	Name      string
@@ -86,12 +416,140 @@ type RolePermission struct {
 	PermissionID pgtype.UUID
 }
 
+type SalesOrder struct {
+	ID          pgtype.UUID
+	OrderNumber string
+	CustomerID  pgtype.UUID
+	WarehouseID pgtype.UUID
+	Carrier     pgtype.Text
+	CutoffAt    pgtype.Timestamptz
+	Status      string
+	WaveID      pgtype.UUID
+	CreatedBy   pgtype.UUID
+	CreatedAt   pgtype.Timestamptz
+	UpdatedAt   pgtype.Timestamptz
+}
+
+type SalesOrderLine struct {
+	ID         pgtype.UUID
+	OrderID    pgtype.UUID
+	LineNo     int32
+	ItemID     pgtype.UUID
+	QtyOrdered pgtype.Numeric
+	QtyPicked  pgtype.Numeric
+	QtyShort   pgtype.Numeric
+	QtyShipped pgtype.Numeric
+}
+
+type Serial struct {
+	ID         pgtype.UUID
+	ItemID     pgtype.UUID
+	SerialNo   string
+	LotID      pgtype.UUID
+	LocationID pgtype.UUID
+	CreatedAt  pgtype.Timestamptz
+	UpdatedAt  pgtype.Timestamptz
+}
+
+type Shipment struct {
+	ID                pgtype.UUID
+	OrderID           pgtype.UUID
+	WarehouseID       pgtype.UUID
+	StagingLocationID pgtype.UUID
+	Carrier           pgtype.Text
+	TransportReason   string
+	ShipToName        string
+	ShipToAddress     pgtype.Text
+	ShipToCity        pgtype.Text
+	ShipToPostalCode  pgtype.Text
+	ShipToCountry     pgtype.Text
+	ShipToVatNumber   pgtype.Text
+	Status            string
+	DdtYear           pgtype.Int4
+	DdtNumber         pgtype.Int4
+	CreatedBy         pgtype.UUID
+	ConfirmedBy       pgtype.UUID
+	ConfirmedAt       pgtype.Timestamptz
+	CreatedAt         pgtype.Timestamptz
+	UpdatedAt         pgtype.Timestamptz
+}
+
+type ShipmentCarton struct {
+	ID         pgtype.UUID
+	ShipmentID pgtype.UUID
+	CartonNo   int32
+	WeightKg   pgtype.Numeric
+	CreatedAt  pgtype.Timestamptz
+}
+
+type ShipmentCartonLine struct {
+	ID          pgtype.UUID
+	CartonID    pgtype.UUID
+	OrderLineID pgtype.UUID
+	ItemID      pgtype.UUID
+	Qty         pgtype.Numeric
+	LotCode     pgtype.Text
+	SerialNo    pgtype.Text
+	MoveID      pgtype.UUID
+	CreatedAt   pgtype.Timestamptz
+}
+
+type StockAllocation struct {
+	ID         pgtype.UUID
+	ItemID     pgtype.UUID
+	LocationID pgtype.UUID
+	LotID      pgtype.UUID
+	SerialID   pgtype.UUID
+	Qty        pgtype.Numeric
+	RefType    string
+	RefID      string
+	Strategy   string
+	Status     string
+	CreatedBy  pgtype.UUID
+	CreatedAt  pgtype.Timestamptz
+	UpdatedAt  pgtype.Timestamptz
+	HuID       pgtype.UUID
+}
+
+type StockAllocationLog struct {
+	ID           pgtype.UUID
+	Ts           pgtype.Timestamptz
+	AllocationID pgtype.UUID
+	Action       string
+	Qty          pgtype.Numeric
+	RefType      string
+	RefID        string
+	ActorUserID  pgtype.UUID
+	RequestID    pgtype.Text
+}
+
 type StockBalance struct {
 	ItemID       pgtype.UUID
 	LocationID   pgtype.UUID
 	QtyOnHand    pgtype.Numeric
 	QtyAllocated pgtype.Numeric
 	UpdatedAt    pgtype.Timestamptz
+	LotID        pgtype.UUID
+	SerialID     pgtype.UUID
+	Status       string
+	HuID         pgtype.UUID
+}
+
+type StockBalanceSnapshot struct {
+	ID        pgtype.UUID
+	TakenAt   pgtype.Timestamptz
+	CreatedAt pgtype.Timestamptz
+}
+
+type StockBalanceSnapshotLine struct {
+	SnapshotID pgtype.UUID
+	ItemID     pgtype.UUID
+	LocationID pgtype.UUID
+	LotID      pgtype.UUID
+	SerialID   pgtype.UUID
+	QtyOnHand  pgtype.Numeric
+	Status     string
+	HuID       pgtype.UUID
 }
 
 type StockLedger struct {
@@ -106,6 +564,70 @@ type StockLedger struct {
 	RefID          pgtype.Text
 	ActorUserID    pgtype.UUID
 	RequestID      pgtype.Text
+	LotID          pgtype.UUID
+	SerialID       pgtype.UUID
+	MoveType       string
+	Comment        pgtype.Text
+	Status         string
+	ToStatus       pgtype.Text
+	FromHuID       pgtype.UUID
+	ToHuID         pgtype.UUID
+}
+
+type StockReconcileRun struct {
+	ID         pgtype.UUID
+	RanAt      pgtype.Timestamptz
+	DriftCount int32
+	Fixed      bool
+	Report     []byte
+}
+
+type Supplier struct {
+	ID        pgtype.UUID
+	Code      string
+	Name      string
+	Active    bool
+	CreatedAt pgtype.Timestamptz
+	UpdatedAt pgtype.Timestamptz
+}
+
+type TransferOrder struct {
+	ID                pgtype.UUID
+	TransferNo        int64
+	FromWarehouseID   pgtype.UUID
+	ToWarehouseID     pgtype.UUID
+	TransitLocationID pgtype.UUID
+	Status            string
+	Comment           pgtype.Text
+	CreatedBy         pgtype.UUID
+	CreatedAt         pgtype.Timestamptz
+	UpdatedAt         pgtype.Timestamptz
+}
+
+type TransferOrderLine struct {
+	ID          pgtype.UUID
+	OrderID     pgtype.UUID
+	LineNo      int32
+	ItemID      pgtype.UUID
+	Qty         pgtype.Numeric
+	QtyShipped  pgtype.Numeric
+	QtyReceived pgtype.Numeric
+	QtyLost     pgtype.Numeric
+}
+
+type TransferOrderMove struct {
+	MoveID    pgtype.UUID
+	OrderID   pgtype.UUID
+	LineID    pgtype.UUID
+	Leg       string
+	CreatedAt pgtype.Timestamptz
+}
+
+type Uom struct {
+	Code      string
+	Name      string
+	Decimals  int32
+	UpdatedAt pgtype.Timestamptz
 }
 
 type User struct {
@@ -126,7 +648,24 @@ type UserRole struct {
 }
 
 type Warehouse struct {
-	ID   pgtype.UUID
-	Code string
-	Name string
+	ID                 pgtype.UUID
+	Code               string
+	Name               string
+	Active             bool
+	CreatedAt          pgtype.Timestamptz
+	UpdatedAt          pgtype.Timestamptz
+	AllowNegativeStock bool
+}
+
+type Wave struct {
+	ID                pgtype.UUID
+	WaveNo            int64
+	WarehouseID       pgtype.UUID
+	Carrier           pgtype.Text
+	CutoffAt          pgtype.Timestamptz
+	StagingLocationID pgtype.UUID
+	Status            string
+	CreatedBy         pgtype.UUID
+	CreatedAt         pgtype.Timestamptz
+	UpdatedAt         pgtype.Timestamptz
 }
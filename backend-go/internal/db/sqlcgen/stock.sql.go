@@ -11,11 +11,84 @@ import (
 	"github.com/jackc/pgx/v5/pgtype"
 )
 
+const allowsNegativeStock = `-- name: AllowsNegativeStock :one
+SELECT (w.allow_negative_stock OR COALESCE(p.allow_negative_stock, false))::bool AS allowed
+FROM locations l
+JOIN warehouses w ON w.id = l.warehouse_id
+LEFT JOIN location_type_policies p ON p.type = l.type
+WHERE l.id = $1
+`
+
+func (q *Queries) AllowsNegativeStock(ctx context.Context, id pgtype.UUID) (bool, error) {
+	row := q.db.QueryRow(ctx, allowsNegativeStock, id)
+	var allowed bool
+	err := row.Scan(&allowed)
+	return allowed, err
+}
+
+const getLotByCode = `-- name: GetLotByCode :one
+SELECT id, item_id, lot_code, manufactured_on, expires_on, received_at FROM lots WHERE item_id = $1 AND lot_code = $2
+`
+
+type GetLotByCodeParams struct {
+	ItemID  pgtype.UUID
+	LotCode string
+}
+
+func (q *Queries) GetLotByCode(ctx context.Context, arg GetLotByCodeParams) (Lot, error) {
+	row := q.db.QueryRow(ctx, getLotByCode, arg.ItemID, arg.LotCode)
+	var i Lot
+	err := row.Scan(
+		&i.ID,
+		&i.ItemID,
+		&i.LotCode,
+		&i.ManufacturedOn,
+		&i.ExpiresOn,
+		&i.ReceivedAt,
+	)
+	return i, err
+}
+
+const getUomConversion = `-- name: GetUomConversion :one
+SELECT i.uom AS base_uom, bu.decimals AS base_decimals, u.decimals AS uom_decimals,
+       (CASE WHEN u.code = i.uom THEN 1 ELSE c.factor END)::numeric AS factor
+FROM items i
+JOIN uoms bu ON bu.code = i.uom
+JOIN uoms u ON u.code = COALESCE(NULLIF($1::text, ''), i.uom)
+LEFT JOIN item_uom_conversions c ON c.item_id = i.id AND c.uom = u.code
+WHERE i.id = $2
+`
+
+type GetUomConversionParams struct {
+	Uom    string
+	ItemID pgtype.UUID
+}
+
+type GetUomConversionRow struct {
+	BaseUom      string
+	BaseDecimals int32
+	UomDecimals  int32
+	Factor       pgtype.Numeric
+}
+
+func (q *Queries) GetUomConversion(ctx context.Context, arg GetUomConversionParams) (GetUomConversionRow, error) {
+	row := q.db.QueryRow(ctx, getUomConversion, arg.Uom, arg.ItemID)
+	var i GetUomConversionRow
+	err := row.Scan(
+		&i.BaseUom,
+		&i.BaseDecimals,
+		&i.UomDecimals,
+		&i.Factor,
+	)
+	return i, err
+}
+
 const insertStockLedgerMove = `-- name: InsertStockLedgerMove :one
 INSERT INTO stock_ledger (
-  item_id, qty, from_location_id, to_location_id, reason_code, ref_type, ref_id, actor_user_id, request_id
-) VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9)
-RETURNING move_id, ts, item_id, qty, from_location_id, to_location_id, reason_code, ref_type, ref_id, actor_user_id, request_id
+  item_id, qty, from_location_id, to_location_id, reason_code, ref_type, ref_id, actor_user_id, request_id,
+  lot_id, serial_id, move_type, comment, status, to_status, from_hu_id, to_hu_id
+) VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9,$10,$11,$12,$13,$14,$15,$16,$17)
+RETURNING move_id, ts, item_id, qty, from_location_id, to_location_id, reason_code, ref_type, ref_id, actor_user_id, request_id, lot_id, serial_id, move_type, comment, status, to_status, from_hu_id, to_hu_id
 `
 
 type InsertStockLedgerMoveParams struct {
@@ -28,6 +101,14 @@ type InsertStockLedgerMoveParams struct {
 	RefID          pgtype.Text
 	ActorUserID    pgtype.UUID
 	RequestID      pgtype.Text
+	LotID          pgtype.UUID
+	SerialID       pgtype.UUID
+	MoveType       string
+	Comment        pgtype.Text
+	Status         string
+	ToStatus       pgtype.Text
+	FromHuID       pgtype.UUID
+	ToHuID         pgtype.UUID
 }
 
 func (q *Queries) InsertStockLedgerMove(ctx context.Context, arg InsertStockLedgerMoveParams) (StockLedger, error) {
@@ -41,6 +122,14 @@ func (q *Queries) InsertStockLedgerMove(ctx context.Context, arg InsertStockLedg
 		arg.RefID,
 		arg.ActorUserID,
 		arg.RequestID,
+		arg.LotID,
+		arg.SerialID,
+		arg.MoveType,
+		arg.Comment,
+		arg.Status,
+		arg.ToStatus,
+		arg.FromHuID,
+		arg.ToHuID,
 	)
 	var i StockLedger
 	err := row.Scan(
@@ -55,28 +144,116 @@ func (q *Queries) InsertStockLedgerMove(ctx context.Context, arg InsertStockLedg
 		&i.RefID,
 		&i.ActorUserID,
 		&i.RequestID,
+		&i.LotID,
+		&i.SerialID,
+		&i.MoveType,
+		&i.Comment,
+		&i.Status,
+		&i.ToStatus,
+		&i.FromHuID,
+		&i.ToHuID,
 	)
 	return i, err
 }
 
+const listFrozenLocations = `-- name: ListFrozenLocations :many
+SELECT id, code, frozen_by_count_id FROM locations
+WHERE id = ANY($1::uuid[]) AND frozen_by_count_id IS NOT NULL
+`
+
+type ListFrozenLocationsRow struct {
+	ID              pgtype.UUID
+	Code            string
+	FrozenByCountID pgtype.UUID
+}
+
+func (q *Queries) ListFrozenLocations(ctx context.Context, ids []pgtype.UUID) ([]ListFrozenLocationsRow, error) {
+	rows, err := q.db.Query(ctx, listFrozenLocations, ids)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListFrozenLocationsRow
+	for rows.Next() {
+		var i ListFrozenLocationsRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.Code,
+			&i.FrozenByCountID,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listHandlingUnitLocations = `-- name: ListHandlingUnitLocations :many
+SELECT id, code, location_id FROM handling_units
+WHERE id = ANY($1::uuid[])
+`
+
+type ListHandlingUnitLocationsRow struct {
+	ID         pgtype.UUID
+	Code       string
+	LocationID pgtype.UUID
+}
+
+func (q *Queries) ListHandlingUnitLocations(ctx context.Context, ids []pgtype.UUID) ([]ListHandlingUnitLocationsRow, error) {
+	rows, err := q.db.Query(ctx, listHandlingUnitLocations, ids)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListHandlingUnitLocationsRow
+	for rows.Next() {
+		var i ListHandlingUnitLocationsRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.Code,
+			&i.LocationID,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const listStockBalances = `-- name: ListStockBalances :many
-SELECT sb.item_id, sb.location_id, sb.qty_on_hand, sb.qty_allocated, sb.updated_at,
-       i.sku, i.name item_name, l.code location_code, w.code warehouse_code
+SELECT sb.item_id, sb.location_id, sb.lot_id, sb.serial_id, sb.status, sb.qty_on_hand, sb.qty_allocated, sb.updated_at,
+       i.sku, i.name item_name, l.code location_code, l.type location_type, w.code warehouse_code,
+       lo.lot_code, lo.expires_on, se.serial_no, sb.hu_id, hu.code hu_code
 FROM stock_balance sb
 JOIN items i ON i.id = sb.item_id
 JOIN locations l ON l.id = sb.location_id
 JOIN warehouses w ON w.id = l.warehouse_id
+LEFT JOIN lots lo ON lo.id = sb.lot_id
+LEFT JOIN serials se ON se.id = sb.serial_id
+LEFT JOIN handling_units hu ON hu.id = sb.hu_id
 WHERE ($1::text = '' OR i.sku ILIKE '%' || $1 || '%' OR i.name ILIKE '%' || $1 || '%')
   AND ($2::text = '' OR w.code = $2)
   AND ($3::text = '' OR l.code = $3)
-ORDER BY i.sku, l.code
-LIMIT $4 OFFSET $5
+  AND ($4::text = '' OR sb.status = $4)
+  AND ($5::text = '' OR hu.code = $5)
+  AND ($6::text = '' OR l.path <@ $6::text::ltree)
+ORDER BY i.sku, l.code, lo.expires_on NULLS LAST, lo.lot_code, se.serial_no, sb.status, hu.code NULLS FIRST
+LIMIT $7 OFFSET $8
 `
 
 type ListStockBalancesParams struct {
 	Column1 string
 	Column2 string
 	Column3 string
+	Column4 string
+	Column5 string
+	Column6 string
 	Limit   int32
 	Offset  int32
 }
@@ -84,13 +261,22 @@ type ListStockBalancesParams struct {
 type ListStockBalancesRow struct {
 	ItemID        pgtype.UUID
 	LocationID    pgtype.UUID
+	LotID         pgtype.UUID
+	SerialID      pgtype.UUID
+	Status        string
 	QtyOnHand     pgtype.Numeric
 	QtyAllocated  pgtype.Numeric
 	UpdatedAt     pgtype.Timestamptz
 	Sku           string
 	ItemName      string
 	LocationCode  string
+	LocationType  string
 	WarehouseCode string
+	LotCode       pgtype.Text
+	ExpiresOn     pgtype.Date
+	SerialNo      pgtype.Text
+	HuID          pgtype.UUID
+	HuCode        pgtype.Text
 }
 
 func (q *Queries) ListStockBalances(ctx context.Context, arg ListStockBalancesParams) ([]ListStockBalancesRow, error) {
@@ -98,6 +284,9 @@ func (q *Queries) ListStockBalances(ctx context.Context, arg ListStockBalancesPa
 		arg.Column1,
 		arg.Column2,
 		arg.Column3,
+		arg.Column4,
+		arg.Column5,
+		arg.Column6,
 		arg.Limit,
 		arg.Offset,
 	)
@@ -111,13 +300,22 @@ func (q *Queries) ListStockBalances(ctx context.Context, arg ListStockBalancesPa
 		if err := rows.Scan(
 			&i.ItemID,
 			&i.LocationID,
+			&i.LotID,
+			&i.SerialID,
+			&i.Status,
 			&i.QtyOnHand,
 			&i.QtyAllocated,
 			&i.UpdatedAt,
 			&i.Sku,
 			&i.ItemName,
 			&i.LocationCode,
+			&i.LocationType,
 			&i.WarehouseCode,
+			&i.LotCode,
+			&i.ExpiresOn,
+			&i.SerialNo,
+			&i.HuID,
+			&i.HuCode,
 		); err != nil {
 			return nil, err
 		}
@@ -129,10 +327,118 @@ func (q *Queries) ListStockBalances(ctx context.Context, arg ListStockBalancesPa
 	return items, nil
 }
 
+const listTransitLocations = `-- name: ListTransitLocations :many
+SELECT code FROM locations
+WHERE id = ANY($1::uuid[]) AND type = 'in_transit'
+`
+
+func (q *Queries) ListTransitLocations(ctx context.Context, ids []pgtype.UUID) ([]string, error) {
+	rows, err := q.db.Query(ctx, listTransitLocations, ids)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []string
+	for rows.Next() {
+		var code string
+		if err := rows.Scan(&code); err != nil {
+			return nil, err
+		}
+		items = append(items, code)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const lockSerialByNo = `-- name: LockSerialByNo :one
+SELECT id, item_id, serial_no, lot_id, location_id, created_at, updated_at FROM serials WHERE item_id = $1 AND serial_no = $2
+FOR UPDATE
+`
+
+type LockSerialByNoParams struct {
+	ItemID   pgtype.UUID
+	SerialNo string
+}
+
+func (q *Queries) LockSerialByNo(ctx context.Context, arg LockSerialByNoParams) (Serial, error) {
+	row := q.db.QueryRow(ctx, lockSerialByNo, arg.ItemID, arg.SerialNo)
+	var i Serial
+	err := row.Scan(
+		&i.ID,
+		&i.ItemID,
+		&i.SerialNo,
+		&i.LotID,
+		&i.LocationID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const lockStockBalance = `-- name: LockStockBalance :one
+SELECT item_id, location_id, qty_on_hand, qty_allocated, updated_at, lot_id, serial_id, status, hu_id FROM stock_balance
+WHERE item_id = $1 AND location_id = $2
+  AND lot_id IS NOT DISTINCT FROM $3
+  AND serial_id IS NOT DISTINCT FROM $4
+  AND status = $5
+  AND hu_id IS NOT DISTINCT FROM $6
+FOR UPDATE
+`
+
+type LockStockBalanceParams struct {
+	ItemID     pgtype.UUID
+	LocationID pgtype.UUID
+	LotID      pgtype.UUID
+	SerialID   pgtype.UUID
+	Status     string
+	HuID       pgtype.UUID
+}
+
+func (q *Queries) LockStockBalance(ctx context.Context, arg LockStockBalanceParams) (StockBalance, error) {
+	row := q.db.QueryRow(ctx, lockStockBalance,
+		arg.ItemID,
+		arg.LocationID,
+		arg.LotID,
+		arg.SerialID,
+		arg.Status,
+		arg.HuID,
+	)
+	var i StockBalance
+	err := row.Scan(
+		&i.ItemID,
+		&i.LocationID,
+		&i.QtyOnHand,
+		&i.QtyAllocated,
+		&i.UpdatedAt,
+		&i.LotID,
+		&i.SerialID,
+		&i.Status,
+		&i.HuID,
+	)
+	return i, err
+}
+
+const setSerialLocation = `-- name: SetSerialLocation :exec
+UPDATE serials SET location_id = $2, updated_at = now()
+WHERE id = $1
+`
+
+type SetSerialLocationParams struct {
+	ID         pgtype.UUID
+	LocationID pgtype.UUID
+}
+
+func (q *Queries) SetSerialLocation(ctx context.Context, arg SetSerialLocationParams) error {
+	_, err := q.db.Exec(ctx, setSerialLocation, arg.ID, arg.LocationID)
+	return err
+}
+
 const upsertStockBalanceDelta = `-- name: UpsertStockBalanceDelta :exec
-INSERT INTO stock_balance (item_id, location_id, qty_on_hand, qty_allocated)
-VALUES ($1, $2, $3, $4)
-ON CONFLICT (item_id, location_id)
+INSERT INTO stock_balance (item_id, location_id, lot_id, serial_id, status, hu_id, qty_on_hand, qty_allocated)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+ON CONFLICT ON CONSTRAINT stock_balance_key
 DO UPDATE SET
   qty_on_hand = stock_balance.qty_on_hand + EXCLUDED.qty_on_hand,
   qty_allocated = stock_balance.qty_allocated + EXCLUDED.qty_allocated,
@@ -142,6 +448,10 @@ DO UPDATE SET
 type UpsertStockBalanceDeltaParams struct {
 	ItemID       pgtype.UUID
 	LocationID   pgtype.UUID
+	LotID        pgtype.UUID
+	SerialID     pgtype.UUID
+	Status       string
+	HuID         pgtype.UUID
 	QtyOnHand    pgtype.Numeric
 	QtyAllocated pgtype.Numeric
 }
@@ -150,6 +460,10 @@ func (q *Queries) UpsertStockBalanceDelta(ctx context.Context, arg UpsertStockBa
 	_, err := q.db.Exec(ctx, upsertStockBalanceDelta,
 		arg.ItemID,
 		arg.LocationID,
+		arg.LotID,
+		arg.SerialID,
+		arg.Status,
+		arg.HuID,
 		arg.QtyOnHand,
 		arg.QtyAllocated,
 	)
@@ -0,0 +1,188 @@
+package http
+
+import (
+	"strconv"
+
+	"erpwms/backend-go/internal/common/httperr"
+	"erpwms/backend-go/internal/modules/wms_count/service"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type CountHandlers struct {
+	Service service.CountService
+}
+
+func (h CountHandlers) ListSessions(c *gin.Context) {
+	limit, offset := page(c)
+	rows, err := h.Service.ListSessions(c.Request.Context(), service.SessionFilter{
+		WarehouseID: c.Query("warehouse_id"), Status: c.Query("status"), Limit: limit, Offset: offset,
+	})
+	if err != nil {
+		httperr.Write(c, err)
+		return
+	}
+	c.JSON(200, gin.H{"items": rows})
+}
+
+func (h CountHandlers) GetSession(c *gin.Context) {
+	id, ok := paramID(c)
+	if !ok {
+		return
+	}
+	cs, err := h.Service.GetSession(c.Request.Context(), id)
+	if err != nil {
+		httperr.Write(c, err)
+		return
+	}
+	c.JSON(200, cs)
+}
+
+func (h CountHandlers) CreateSession(c *gin.Context) {
+	var in service.SessionInput
+	if err := c.ShouldBindJSON(&in); err != nil {
+		c.JSON(400, gin.H{"error": "bad request"})
+		return
+	}
+	actor, ok := actorID(c)
+	if !ok {
+		return
+	}
+	cs, err := h.Service.CreateSession(c.Request.Context(), in, actor)
+	if err != nil {
+		httperr.Write(c, err)
+		return
+	}
+	c.JSON(201, cs)
+}
+
+func (h CountHandlers) CancelSession(c *gin.Context) {
+	id, ok := paramID(c)
+	if !ok {
+		return
+	}
+	actor, ok := actorID(c)
+	if !ok {
+		return
+	}
+	cs, err := h.Service.CancelSession(c.Request.Context(), id, actor)
+	if err != nil {
+		httperr.Write(c, err)
+		return
+	}
+	c.JSON(200, cs)
+}
+
+// ListTasks is the counters' view; blind sessions hide expected quantities.
+func (h CountHandlers) ListTasks(c *gin.Context) {
+	h.listTasks(c, false)
+}
+
+// ListVariances is the supervisor's review with expected quantities and
+// variances.
+func (h CountHandlers) ListVariances(c *gin.Context) {
+	h.listTasks(c, true)
+}
+
+func (h CountHandlers) listTasks(c *gin.Context, reveal bool) {
+	id, ok := paramID(c)
+	if !ok {
+		return
+	}
+	rows, err := h.Service.ListTasks(c.Request.Context(), id, c.Query("status"), reveal)
+	if err != nil {
+		httperr.Write(c, err)
+		return
+	}
+	c.JSON(200, gin.H{"items": rows})
+}
+
+func (h CountHandlers) AddTask(c *gin.Context) {
+	id, ok := paramID(c)
+	if !ok {
+		return
+	}
+	var in service.TaskInput
+	if err := c.ShouldBindJSON(&in); err != nil {
+		c.JSON(400, gin.H{"error": "bad request"})
+		return
+	}
+	actor, ok := actorID(c)
+	if !ok {
+		return
+	}
+	t, err := h.Service.AddTask(c.Request.Context(), id, in, actor)
+	if err != nil {
+		httperr.Write(c, err)
+		return
+	}
+	c.JSON(201, t)
+}
+
+func (h CountHandlers) SubmitCount(c *gin.Context) {
+	id, ok := paramID(c)
+	if !ok {
+		return
+	}
+	var in service.CountInput
+	if err := c.ShouldBindJSON(&in); err != nil {
+		c.JSON(400, gin.H{"error": "bad request"})
+		return
+	}
+	actor, ok := actorID(c)
+	if !ok {
+		return
+	}
+	res, err := h.Service.SubmitCount(c.Request.Context(), id, in, actor)
+	if err != nil {
+		httperr.Write(c, err)
+		return
+	}
+	c.JSON(200, res)
+}
+
+func (h CountHandlers) Approve(c *gin.Context) {
+	key := c.GetHeader("Idempotency-Key")
+	if key == "" {
+		c.JSON(400, gin.H{"error": "Idempotency-Key required"})
+		return
+	}
+	id, ok := paramID(c)
+	if !ok {
+		return
+	}
+	actor, ok := actorID(c)
+	if !ok {
+		return
+	}
+	resp, err := h.Service.Approve(c.Request.Context(), id, actor, "/api/count-sessions/approve", key)
+	if err != nil {
+		httperr.Write(c, err)
+		return
+	}
+	c.JSON(200, resp)
+}
+
+func page(c *gin.Context) (int32, int32) {
+	limit, _ := strconv.ParseInt(c.DefaultQuery("limit", "50"), 10, 32)
+	offset, _ := strconv.ParseInt(c.DefaultQuery("offset", "0"), 10, 32)
+	return int32(limit), int32(offset)
+}
+
+func paramID(c *gin.Context) (uuid.UUID, bool) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(400, gin.H{"error": "invalid id"})
+		return uuid.Nil, false
+	}
+	return id, true
+}
+
+func actorID(c *gin.Context) (uuid.UUID, bool) {
+	uid, err := uuid.Parse(c.GetString("user_id"))
+	if err != nil || uid == uuid.Nil {
+		c.JSON(401, gin.H{"error": "unauthorized"})
+		return uuid.Nil, false
+	}
+	return uid, true
+}
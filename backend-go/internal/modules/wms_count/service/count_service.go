@@ -0,0 +1,564 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"slices"
+	"strings"
+	"time"
+
+	"erpwms/backend-go/internal/common/idempotency"
+	"erpwms/backend-go/internal/common/locpath"
+	"erpwms/backend-go/internal/common/qty"
+	"erpwms/backend-go/internal/common/store"
+	"erpwms/backend-go/internal/db/sqlcgen"
+	stocksvc "erpwms/backend-go/internal/modules/wms_stock/service"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+var (
+	ErrNotFound = store.ErrNotFound
+	ErrConflict = store.ErrConflict
+	ErrInvalid  = store.ErrInvalid
+)
+
+// Count session states. A session is counting until a supervisor approves
+// its variances or cancels it; both lift the freeze.
+const (
+	SessionCounting  = "counting"
+	SessionApproved  = "approved"
+	SessionCancelled = "cancelled"
+)
+
+// Count task states. A first count beyond the tolerance moves an open task
+// to recount; the second count is final.
+const (
+	TaskOpen    = "open"
+	TaskRecount = "recount"
+	TaskCounted = "counted"
+)
+
+var conflictFields = map[string]string{
+	"count_tasks_key": "count task for this location, item, lot, serial, stock status and handling unit",
+}
+
+// CountService runs cycle counts and physical inventories. Approved variances
+// are posted through Stock as adjustments with ref_type count.
+type CountService struct {
+	DB      *pgxpool.Pool
+	Queries *sqlcgen.Queries
+	Stock   stocksvc.StockService
+}
+
+// SessionInput scopes a count. Blind defaults to true. Tolerances are
+// non-negative decimals; a variance above the larger of ToleranceQty and
+// TolerancePct percent of the expected quantity is recounted.
+type SessionInput struct {
+	WarehouseID  string `json:"warehouse_id"`
+	PathPrefix   string `json:"path_prefix,omitempty"`
+	ItemClass    string `json:"item_class,omitempty"`
+	AbcClass     string `json:"abc_class,omitempty"`
+	Blind        *bool  `json:"blind,omitempty"`
+	Freeze       bool   `json:"freeze"`
+	ToleranceQty string `json:"tolerance_qty,omitempty"`
+	TolerancePct string `json:"tolerance_pct,omitempty"`
+}
+
+type Session struct {
+	ID           string     `json:"id"`
+	WarehouseID  string     `json:"warehouse_id"`
+	PathPrefix   string     `json:"path_prefix,omitempty"`
+	ItemClass    string     `json:"item_class,omitempty"`
+	AbcClass     string     `json:"abc_class,omitempty"`
+	Blind        bool       `json:"blind"`
+	Freeze       bool       `json:"freeze"`
+	ToleranceQty string     `json:"tolerance_qty"`
+	TolerancePct string     `json:"tolerance_pct"`
+	Status       string     `json:"status"`
+	Tasks        *int64     `json:"tasks,omitempty"`
+	CreatedAt    time.Time  `json:"created_at"`
+	ApprovedAt   *time.Time `json:"approved_at,omitempty"`
+}
+
+type SessionFilter struct {
+	WarehouseID string
+	Status      string
+	Limit       int32
+	Offset      int32
+}
+
+// Task is one balance key to count, in the item's base unit. In a blind
+// session ExpectedQty and FirstQty are left out of the counters' view.
+type Task struct {
+	ID           string `json:"id"`
+	Status       string `json:"status"`
+	LocationID   string `json:"location_id"`
+	LocationCode string `json:"location_code"`
+	ItemID       string `json:"item_id"`
+	Sku          string `json:"sku"`
+	ItemName     string `json:"item_name"`
+	Uom          string `json:"uom"`
+	LotCode      string `json:"lot_code,omitempty"`
+	SerialNo     string `json:"serial_no,omitempty"`
+	StockStatus  string `json:"stock_status"`
+	HuID         string `json:"hu_id,omitempty"`
+	HuCode       string `json:"hu_code,omitempty"`
+	ExpectedQty  string `json:"expected_qty,omitempty"`
+	FirstQty     string `json:"first_qty,omitempty"`
+	CountedQty   string `json:"counted_qty,omitempty"`
+	Variance     string `json:"variance,omitempty"`
+	MoveID       string `json:"move_id,omitempty"`
+}
+
+// TaskInput records stock found where no task expected it. StockStatus
+// defaults to available; HuID names the handling unit it was found in, which
+// must be at the location.
+type TaskInput struct {
+	LocationID  string `json:"location_id"`
+	ItemID      string `json:"item_id"`
+	LotCode     string `json:"lot_code,omitempty"`
+	SerialNo    string `json:"serial_no,omitempty"`
+	StockStatus string `json:"stock_status,omitempty"`
+	HuID        string `json:"hu_id,omitempty"`
+}
+
+// CountInput is the quantity found, in Uom or the item's base unit.
+type CountInput struct {
+	Qty string `json:"qty"`
+	Uom string `json:"uom,omitempty"`
+}
+
+type CountResult struct {
+	Task    Task `json:"task"`
+	Recount bool `json:"recount"`
+}
+
+type ApproveResponse struct {
+	Session     Session `json:"session"`
+	Adjustments []Task  `json:"adjustments"`
+}
+
+// CreateSession snapshots every non-zero balance in scope as a task and,
+// with Freeze, freezes the task locations. A location frozen by another
+// session is a conflict.
+func (s CountService) CreateSession(ctx context.Context, in SessionInput, actor uuid.UUID) (Session, error) {
+	warehouseID, err := store.ParseUUID("warehouse_id", in.WarehouseID)
+	if err != nil {
+		return Session{}, err
+	}
+	switch in.AbcClass {
+	case "", "A", "B", "C":
+	default:
+		return Session{}, fmt.Errorf("%w: abc_class must be A, B or C", ErrInvalid)
+	}
+	tolQty, err := parseTolerance("tolerance_qty", in.ToleranceQty)
+	if err != nil {
+		return Session{}, err
+	}
+	tolPct, err := parseTolerance("tolerance_pct", in.TolerancePct)
+	if err != nil {
+		return Session{}, err
+	}
+	prefix, err := locpath.Normalize(in.PathPrefix)
+	if err != nil {
+		return Session{}, fmt.Errorf("%w: path_prefix: %v", ErrInvalid, err)
+	}
+	blind := in.Blind == nil || *in.Blind
+	var out Session
+	err = s.mutate(ctx, actor, "count.created", func(q *sqlcgen.Queries) (string, any, error) {
+		cs, err := q.CreateCountSession(ctx, sqlcgen.CreateCountSessionParams{
+			WarehouseID: store.UUID(warehouseID), PathPrefix: store.Text(prefix), ItemClass: store.Text(in.ItemClass), AbcClass: store.Text(in.AbcClass),
+			Blind: blind, Freeze: in.Freeze, ToleranceQty: qty.ToNumeric(tolQty), TolerancePct: qty.ToNumeric(tolPct), CreatedBy: store.UUID(actor),
+		})
+		if err != nil {
+			return "", nil, err
+		}
+		n, err := q.InsertScopedCountTasks(ctx, cs.ID)
+		if err != nil {
+			return "", nil, err
+		}
+		if cs.Freeze {
+			if err := freeze(ctx, q, cs.ID); err != nil {
+				return "", nil, err
+			}
+		}
+		out = toSession(cs)
+		out.Tasks = &n
+		return out.ID, out, nil
+	})
+	return out, err
+}
+
+func (s CountService) GetSession(ctx context.Context, id uuid.UUID) (Session, error) {
+	cs, err := s.Queries.GetCountSession(ctx, store.UUID(id))
+	if err != nil {
+		return Session{}, store.MapErr(err, conflictFields)
+	}
+	return toSession(cs), nil
+}
+
+func (s CountService) ListSessions(ctx context.Context, f SessionFilter) ([]Session, error) {
+	p := sqlcgen.ListCountSessionsParams{Status: f.Status, PageLimit: f.Limit, PageOffset: f.Offset}
+	if f.WarehouseID != "" {
+		id, err := store.ParseUUID("warehouse_id", f.WarehouseID)
+		if err != nil {
+			return nil, err
+		}
+		p.WarehouseID = store.UUID(id)
+	}
+	rows, err := s.Queries.ListCountSessions(ctx, p)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]Session, 0, len(rows))
+	for _, r := range rows {
+		out = append(out, toSession(r))
+	}
+	return out, nil
+}
+
+// ListTasks is the counters' list in walking order. reveal shows expected
+// quantities and variances even in a blind session, for the review before
+// approval.
+func (s CountService) ListTasks(ctx context.Context, id uuid.UUID, status string, reveal bool) ([]Task, error) {
+	cs, err := s.Queries.GetCountSession(ctx, store.UUID(id))
+	if err != nil {
+		return nil, store.MapErr(err, conflictFields)
+	}
+	rows, err := s.Queries.ListCountTasks(ctx, sqlcgen.ListCountTasksParams{SessionID: cs.ID, Status: status})
+	if err != nil {
+		return nil, err
+	}
+	out := make([]Task, 0, len(rows))
+	for _, r := range rows {
+		out = append(out, toTask(r, reveal || !cs.Blind))
+	}
+	return out, nil
+}
+
+// AddTask records stock a counter found that no task expected, with an
+// expected quantity of zero. The count itself is submitted as for any task.
+func (s CountService) AddTask(ctx context.Context, id uuid.UUID, in TaskInput, actor uuid.UUID) (Task, error) {
+	locationID, err := store.ParseUUID("location_id", in.LocationID)
+	if err != nil {
+		return Task{}, err
+	}
+	itemID, err := store.ParseUUID("item_id", in.ItemID)
+	if err != nil {
+		return Task{}, err
+	}
+	if in.StockStatus == "" {
+		in.StockStatus = stocksvc.StatusAvailable
+	}
+	if !slices.Contains([]string{stocksvc.StatusAvailable, stocksvc.StatusQuarantine, stocksvc.StatusDamaged, stocksvc.StatusBlocked}, in.StockStatus) {
+		return Task{}, fmt.Errorf("%w: unknown stock_status %q", ErrInvalid, in.StockStatus)
+	}
+	var huID uuid.UUID
+	if in.HuID != "" {
+		if huID, err = store.ParseUUID("hu_id", in.HuID); err != nil {
+			return Task{}, err
+		}
+	}
+	var out Task
+	err = s.mutate(ctx, actor, "count.task_added", func(q *sqlcgen.Queries) (string, any, error) {
+		cs, err := lockCounting(ctx, q, id)
+		if err != nil {
+			return "", nil, err
+		}
+		loc, err := q.GetLocation(ctx, store.UUID(locationID))
+		if err != nil {
+			return "", nil, err
+		}
+		if loc.WarehouseID != cs.WarehouseID {
+			return "", nil, fmt.Errorf("%w: location is not in the session's warehouse", ErrInvalid)
+		}
+		var lotID, serialID pgtype.UUID
+		if in.LotCode != "" {
+			lot, err := q.GetLotByCode(ctx, sqlcgen.GetLotByCodeParams{ItemID: store.UUID(itemID), LotCode: in.LotCode})
+			if errors.Is(err, pgx.ErrNoRows) {
+				return "", nil, fmt.Errorf("%w: unknown lot %s", ErrInvalid, in.LotCode)
+			}
+			if err != nil {
+				return "", nil, err
+			}
+			lotID = lot.ID
+		}
+		if in.SerialNo != "" {
+			serial, err := q.LockSerialByNo(ctx, sqlcgen.LockSerialByNoParams{ItemID: store.UUID(itemID), SerialNo: in.SerialNo})
+			if errors.Is(err, pgx.ErrNoRows) {
+				return "", nil, fmt.Errorf("%w: unknown serial %s", ErrInvalid, in.SerialNo)
+			}
+			if err != nil {
+				return "", nil, err
+			}
+			serialID = serial.ID
+		}
+		var huCode string
+		if huID != uuid.Nil {
+			hu, err := q.GetHandlingUnit(ctx, store.UUID(huID))
+			if errors.Is(err, pgx.ErrNoRows) {
+				return "", nil, fmt.Errorf("%w: unknown handling unit", ErrInvalid)
+			}
+			if err != nil {
+				return "", nil, err
+			}
+			if hu.LocationID != loc.ID {
+				return "", nil, fmt.Errorf("%w: handling unit %s is not at the location", ErrInvalid, hu.Code)
+			}
+			huCode = hu.Code
+		}
+		t, err := q.InsertCountTask(ctx, sqlcgen.InsertCountTaskParams{SessionID: cs.ID, LocationID: loc.ID, ItemID: store.UUID(itemID), LotID: lotID, SerialID: serialID, StockStatus: in.StockStatus, HuID: store.UUID(huID)})
+		if err != nil {
+			return "", nil, err
+		}
+		if cs.Freeze {
+			if err := freeze(ctx, q, cs.ID); err != nil {
+				return "", nil, err
+			}
+		}
+		out = Task{ID: t.ID.String(), Status: t.Status, LocationID: loc.ID.String(), LocationCode: loc.Code, ItemID: t.ItemID.String(), LotCode: in.LotCode, SerialNo: in.SerialNo, StockStatus: t.StockStatus, HuID: store.OptUUID(t.HuID), HuCode: huCode}
+		return cs.ID.String(), out, nil
+	})
+	return out, err
+}
+
+// SubmitCount records what was found for a task. The expected quantity is
+// refreshed from the balance at that moment, so moves into an unfrozen
+// location before the count are not mistaken for variance.
+func (s CountService) SubmitCount(ctx context.Context, taskID uuid.UUID, in CountInput, actor uuid.UUID) (CountResult, error) {
+	var out CountResult
+	err := s.mutate(ctx, actor, "count.counted", func(q *sqlcgen.Queries) (string, any, error) {
+		t, err := q.LockCountTask(ctx, store.UUID(taskID))
+		if err != nil {
+			return "", nil, err
+		}
+		cs, err := lockCounting(ctx, q, uuid.UUID(t.SessionID.Bytes))
+		if err != nil {
+			return "", nil, err
+		}
+		if t.Status == TaskCounted {
+			return "", nil, fmt.Errorf("%w: task is already counted", ErrInvalid)
+		}
+		counted, err := s.Stock.BaseQty(ctx, q, uuid.UUID(t.ItemID.Bytes), in.Qty, in.Uom)
+		if err != nil {
+			return "", nil, err
+		}
+		if counted.Sign() < 0 {
+			return "", nil, fmt.Errorf("%w: qty must not be negative", ErrInvalid)
+		}
+		onHand, err := q.GetOnHand(ctx, sqlcgen.GetOnHandParams{ItemID: t.ItemID, LocationID: t.LocationID, LotID: t.LotID, SerialID: t.SerialID, Status: t.StockStatus, HuID: t.HuID})
+		if err != nil {
+			return "", nil, err
+		}
+		expected := qty.FromNumeric(onHand)
+		if t.Status == TaskOpen && needsRecount(expected, counted, qty.FromNumeric(cs.ToleranceQty), qty.FromNumeric(cs.TolerancePct)) {
+			if t, err = q.SetCountTaskRecount(ctx, sqlcgen.SetCountTaskRecountParams{ID: t.ID, ExpectedQty: onHand, FirstQty: qty.ToNumeric(counted), FirstCountedBy: store.UUID(actor)}); err != nil {
+				return "", nil, err
+			}
+			out.Recount = true
+		} else if t, err = q.SetCountTaskCounted(ctx, sqlcgen.SetCountTaskCountedParams{ID: t.ID, ExpectedQty: onHand, CountedQty: qty.ToNumeric(counted), CountedBy: store.UUID(actor)}); err != nil {
+			return "", nil, err
+		}
+		out.Task = Task{ID: t.ID.String(), Status: t.Status, LocationID: t.LocationID.String(), ItemID: t.ItemID.String(), StockStatus: t.StockStatus, HuID: store.OptUUID(t.HuID)}
+		if t.Status == TaskCounted {
+			out.Task.CountedQty = qty.String(counted)
+		}
+		if !cs.Blind {
+			out.Task.ExpectedQty = qty.String(expected)
+		}
+		// The event always carries the full figures for the audit trail.
+		return cs.ID.String(), map[string]any{
+			"task_id": t.ID.String(), "status": t.Status, "expected_qty": qty.String(expected), "counted_qty": qty.String(counted),
+		}, nil
+	})
+	return out, err
+}
+
+// Approve posts each counted variance as an adjustment at the task's location
+// with reason COUNT, ref_type count and ref_id the session, then lifts the
+// freeze. Every task must be counted first.
+func (s CountService) Approve(ctx context.Context, id uuid.UUID, actor uuid.UUID, endpoint, idemKey string) (ApproveResponse, error) {
+	reqHash := idempotency.Hash(id.String())
+	var prev ApproveResponse
+	if found, err := idempotency.Replay(ctx, s.Queries, endpoint, idemKey, reqHash, &prev); err != nil || found {
+		return prev, err
+	}
+
+	tx, err := s.DB.Begin(ctx)
+	if err != nil {
+		return ApproveResponse{}, err
+	}
+	defer tx.Rollback(ctx)
+	q := s.Queries.WithTx(tx)
+
+	cs, err := lockCounting(ctx, q, id)
+	if err != nil {
+		return ApproveResponse{}, store.MapErr(err, conflictFields)
+	}
+	if open, err := q.CountSessionHasOpenTasks(ctx, cs.ID); err != nil {
+		return ApproveResponse{}, err
+	} else if open {
+		return ApproveResponse{}, fmt.Errorf("%w: session has tasks still to count", ErrInvalid)
+	}
+	rows, err := q.ListCountTasks(ctx, sqlcgen.ListCountTasksParams{SessionID: cs.ID})
+	if err != nil {
+		return ApproveResponse{}, err
+	}
+	resp := ApproveResponse{Adjustments: []Task{}}
+	for _, r := range rows {
+		v := variance(r)
+		if v.Sign() == 0 {
+			continue
+		}
+		move, _, err := s.Stock.PostMove(ctx, q, stocksvc.MoveAdjustment, stocksvc.MoveRequest{
+			ItemID: r.ItemID.String(), Qty: qty.String(v), LocationID: r.LocationID.String(), ReasonCode: "COUNT",
+			LotCode: r.LotCode.String, SerialNo: r.SerialNo.String, Status: r.StockStatus, HuID: store.OptUUID(r.HuID), RefType: stocksvc.RefCount, RefID: cs.ID.String(),
+		}, actor)
+		if err != nil {
+			return ApproveResponse{}, fmt.Errorf("%s %s: %w", r.LocationCode, r.Sku, err)
+		}
+		if err := q.SetCountTaskMove(ctx, sqlcgen.SetCountTaskMoveParams{ID: r.ID, MoveID: move.MoveID}); err != nil {
+			return ApproveResponse{}, err
+		}
+		r.MoveID = move.MoveID
+		resp.Adjustments = append(resp.Adjustments, toTask(r, true))
+	}
+	if err := q.UnfreezeCountLocations(ctx, cs.ID); err != nil {
+		return ApproveResponse{}, err
+	}
+	if cs, err = q.ApproveCountSession(ctx, sqlcgen.ApproveCountSessionParams{ID: cs.ID, ApprovedBy: store.UUID(actor)}); err != nil {
+		return ApproveResponse{}, err
+	}
+	resp.Session = toSession(cs)
+
+	payload, _ := json.Marshal(resp)
+	if err := store.Record(ctx, q, actor, "count.approved", "count_sessions", cs.ID.String(), payload); err != nil {
+		return ApproveResponse{}, err
+	}
+	if err := idempotency.Remember(ctx, q, endpoint, idemKey, store.UUID(actor), reqHash, resp); err != nil {
+		return ApproveResponse{}, err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return ApproveResponse{}, err
+	}
+	return resp, nil
+}
+
+// CancelSession drops a counting session without posting anything.
+func (s CountService) CancelSession(ctx context.Context, id uuid.UUID, actor uuid.UUID) (Session, error) {
+	var out Session
+	err := s.mutate(ctx, actor, "count.cancelled", func(q *sqlcgen.Queries) (string, any, error) {
+		cs, err := lockCounting(ctx, q, id)
+		if err != nil {
+			return "", nil, err
+		}
+		if err := q.UnfreezeCountLocations(ctx, cs.ID); err != nil {
+			return "", nil, err
+		}
+		if cs, err = q.SetCountSessionStatus(ctx, sqlcgen.SetCountSessionStatusParams{ID: cs.ID, Status: SessionCancelled}); err != nil {
+			return "", nil, err
+		}
+		out = toSession(cs)
+		return out.ID, out, nil
+	})
+	return out, err
+}
+
+// mutate runs fn in a transaction with its outbox event and audit entry
+// on count_sessions; see store.Mutate.
+func (s CountService) mutate(ctx context.Context, actor uuid.UUID, topic string, fn func(q *sqlcgen.Queries) (string, any, error)) error {
+	return store.Mutate(ctx, s.DB, s.Queries, actor, topic, "count_sessions", conflictFields, fn)
+}
+
+func lockCounting(ctx context.Context, q *sqlcgen.Queries, id uuid.UUID) (sqlcgen.CountSession, error) {
+	cs, err := q.LockCountSession(ctx, store.UUID(id))
+	if err != nil {
+		return sqlcgen.CountSession{}, err
+	}
+	if cs.Status != SessionCounting {
+		return sqlcgen.CountSession{}, fmt.Errorf("%w: session is %s", ErrInvalid, cs.Status)
+	}
+	return cs, nil
+}
+
+// freeze marks the session's task locations frozen. Locations another
+// session already froze are a conflict rather than silently shared.
+func freeze(ctx context.Context, q *sqlcgen.Queries, sessionID pgtype.UUID) error {
+	taken, err := q.ListFrozenCountLocations(ctx, sessionID)
+	if err != nil {
+		return err
+	}
+	if len(taken) > 0 {
+		return fmt.Errorf("%w: locations frozen by another count: %s", ErrConflict, strings.Join(taken, ", "))
+	}
+	return q.FreezeCountLocations(ctx, sessionID)
+}
+
+// needsRecount reports whether a first count is off by more than the
+// tolerance: the larger of tolQty and tolPct percent of expected.
+func needsRecount(expected, counted, tolQty, tolPct *big.Rat) bool {
+	diff := new(big.Rat).Abs(qty.Sub(counted, expected))
+	limit := qty.Mul(new(big.Rat).Abs(expected), new(big.Rat).Quo(tolPct, big.NewRat(100, 1)))
+	if tolQty.Cmp(limit) > 0 {
+		limit = tolQty
+	}
+	return diff.Cmp(limit) > 0
+}
+
+// variance is counted minus expected; zero for tasks not counted yet.
+func variance(r sqlcgen.ListCountTasksRow) *big.Rat {
+	if !r.CountedQty.Valid {
+		return qty.Zero()
+	}
+	return qty.Sub(qty.FromNumeric(r.CountedQty), qty.FromNumeric(r.ExpectedQty))
+}
+
+func parseTolerance(field, v string) (*big.Rat, error) {
+	if v == "" {
+		return qty.Zero(), nil
+	}
+	r, err := qty.Parse(v)
+	if err != nil || r.Sign() < 0 {
+		return nil, fmt.Errorf("%w: %s must be a non-negative decimal", ErrInvalid, field)
+	}
+	return r, nil
+}
+
+func toSession(cs sqlcgen.CountSession) Session {
+	out := Session{
+		ID: cs.ID.String(), WarehouseID: cs.WarehouseID.String(), PathPrefix: cs.PathPrefix.String, ItemClass: cs.ItemClass.String,
+		AbcClass: cs.AbcClass.String, Blind: cs.Blind, Freeze: cs.Freeze, ToleranceQty: qty.String(qty.FromNumeric(cs.ToleranceQty)),
+		TolerancePct: qty.String(qty.FromNumeric(cs.TolerancePct)), Status: cs.Status, CreatedAt: cs.CreatedAt.Time,
+	}
+	if cs.ApprovedAt.Valid {
+		out.ApprovedAt = &cs.ApprovedAt.Time
+	}
+	return out
+}
+
+func toTask(r sqlcgen.ListCountTasksRow, reveal bool) Task {
+	out := Task{
+		ID: r.ID.String(), Status: r.Status, LocationID: r.LocationID.String(), LocationCode: r.LocationCode,
+		ItemID: r.ItemID.String(), Sku: r.Sku, ItemName: r.ItemName, Uom: r.Uom, LotCode: r.LotCode.String, SerialNo: r.SerialNo.String,
+		StockStatus: r.StockStatus, HuID: store.OptUUID(r.HuID), HuCode: r.HuCode.String, MoveID: store.OptUUID(r.MoveID),
+	}
+	if r.CountedQty.Valid {
+		out.CountedQty = qty.String(qty.FromNumeric(r.CountedQty))
+	}
+	if reveal {
+		out.ExpectedQty = qty.String(qty.FromNumeric(r.ExpectedQty))
+		if r.FirstQty.Valid {
+			out.FirstQty = qty.String(qty.FromNumeric(r.FirstQty))
+		}
+		if r.CountedQty.Valid {
+			out.Variance = qty.String(variance(r))
+		}
+	}
+	return out
+}
@@ -0,0 +1,67 @@
+package service
+
+import (
+	"math/big"
+	"testing"
+
+	"erpwms/backend-go/internal/common/qty"
+	"erpwms/backend-go/internal/db/sqlcgen"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+func rat(s string) *big.Rat {
+	r, err := qty.Parse(s)
+	if err != nil {
+		panic(err)
+	}
+	return r
+}
+
+func TestNeedsRecount(t *testing.T) {
+	for _, tc := range []struct {
+		expected, counted, tolQty, tolPct string
+		want                              bool
+	}{
+		{"10", "10", "0", "0", false},
+		{"10", "9", "0", "0", true},
+		{"10", "9", "1", "0", false},
+		{"10", "8", "1", "0", true},
+		{"100", "95", "0", "5", false},
+		{"100", "94", "0", "5", true},
+		// The larger of the two tolerances applies.
+		{"100", "94", "6", "5", false},
+		{"0", "1", "0", "50", true},
+	} {
+		if got := needsRecount(rat(tc.expected), rat(tc.counted), rat(tc.tolQty), rat(tc.tolPct)); got != tc.want {
+			t.Fatalf("expected %s counted %s tol %s/%s%%: got %v", tc.expected, tc.counted, tc.tolQty, tc.tolPct, got)
+		}
+	}
+}
+
+func TestVariance(t *testing.T) {
+	r := sqlcgen.ListCountTasksRow{ExpectedQty: qty.ToNumeric(rat("10")), CountedQty: qty.ToNumeric(rat("7.5"))}
+	if got := variance(r); got.Cmp(rat("-2.5")) != 0 {
+		t.Fatalf("got %s", got.RatString())
+	}
+	r.CountedQty.Valid = false
+	if got := variance(r); got.Sign() != 0 {
+		t.Fatalf("uncounted: got %s", got.RatString())
+	}
+}
+
+func TestToTaskHidesExpectedWhenBlind(t *testing.T) {
+	r := sqlcgen.ListCountTasksRow{Status: TaskRecount, ExpectedQty: qty.ToNumeric(rat("10")), FirstQty: qty.ToNumeric(rat("4"))}
+	if got := toTask(r, false); got.ExpectedQty != "" || got.FirstQty != "" || got.Variance != "" {
+		t.Fatalf("blind view leaks %+v", got)
+	}
+	if got := toTask(r, true); got.ExpectedQty != "10" || got.FirstQty != "4" {
+		t.Fatalf("review view: %+v", got)
+	}
+}
+
+func TestToTaskKeepsBalanceKey(t *testing.T) {
+	r := sqlcgen.ListCountTasksRow{Status: TaskCounted, StockStatus: "quarantine", HuCode: pgtype.Text{String: "PAL-1", Valid: true}, ExpectedQty: qty.ToNumeric(rat("5")), CountedQty: qty.ToNumeric(rat("4"))}
+	if got := toTask(r, true); got.StockStatus != "quarantine" || got.HuCode != "PAL-1" || got.HuID != "" || got.Variance != "-1" {
+		t.Fatalf("got %+v", got)
+	}
+}
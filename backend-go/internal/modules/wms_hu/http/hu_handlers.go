@@ -0,0 +1,166 @@
+package http
+
+import (
+	"strconv"
+
+	"erpwms/backend-go/internal/common/httperr"
+	"erpwms/backend-go/internal/modules/wms_hu/service"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type HandlingUnitHandlers struct {
+	Service service.HandlingUnitService
+}
+
+func (h HandlingUnitHandlers) List(c *gin.Context) {
+	limit, offset := page(c)
+	rows, err := h.Service.List(c.Request.Context(), service.HandlingUnitFilter{
+		LocationID: c.Query("location_id"), WarehouseID: c.Query("warehouse_id"), ParentID: c.Query("parent_id"),
+		Code: c.Query("code"), Type: c.Query("hu_type"), TopLevel: c.Query("top_level") == "true", Limit: limit, Offset: offset,
+	})
+	if err != nil {
+		httperr.Write(c, err)
+		return
+	}
+	c.JSON(200, gin.H{"items": rows})
+}
+
+func (h HandlingUnitHandlers) Get(c *gin.Context) {
+	id, ok := paramID(c)
+	if !ok {
+		return
+	}
+	hu, err := h.Service.Get(c.Request.Context(), id)
+	if err != nil {
+		httperr.Write(c, err)
+		return
+	}
+	c.JSON(200, hu)
+}
+
+func (h HandlingUnitHandlers) Create(c *gin.Context) {
+	var in service.HandlingUnitInput
+	if err := c.ShouldBindJSON(&in); err != nil {
+		c.JSON(400, gin.H{"error": "bad request"})
+		return
+	}
+	actor, ok := actorID(c)
+	if !ok {
+		return
+	}
+	hu, err := h.Service.Create(c.Request.Context(), in, actor)
+	if err != nil {
+		httperr.Write(c, err)
+		return
+	}
+	c.JSON(201, hu)
+}
+
+func (h HandlingUnitHandlers) Pack(c *gin.Context) {
+	key := c.GetHeader("Idempotency-Key")
+	if key == "" {
+		c.JSON(400, gin.H{"error": "Idempotency-Key required"})
+		return
+	}
+	id, ok := paramID(c)
+	if !ok {
+		return
+	}
+	var in service.PackRequest
+	if err := c.ShouldBindJSON(&in); err != nil {
+		c.JSON(400, gin.H{"error": "bad request"})
+		return
+	}
+	actor, ok := actorID(c)
+	if !ok {
+		return
+	}
+	resp, err := h.Service.Pack(c.Request.Context(), id, in, actor, "/api/handling-units/pack", key)
+	if err != nil {
+		httperr.Write(c, err)
+		return
+	}
+	c.JSON(200, resp)
+}
+
+// Unpack takes an empty body to empty the unit completely.
+func (h HandlingUnitHandlers) Unpack(c *gin.Context) {
+	key := c.GetHeader("Idempotency-Key")
+	if key == "" {
+		c.JSON(400, gin.H{"error": "Idempotency-Key required"})
+		return
+	}
+	id, ok := paramID(c)
+	if !ok {
+		return
+	}
+	var in service.UnpackRequest
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&in); err != nil {
+			c.JSON(400, gin.H{"error": "bad request"})
+			return
+		}
+	}
+	actor, ok := actorID(c)
+	if !ok {
+		return
+	}
+	resp, err := h.Service.Unpack(c.Request.Context(), id, in, actor, "/api/handling-units/unpack", key)
+	if err != nil {
+		httperr.Write(c, err)
+		return
+	}
+	c.JSON(200, resp)
+}
+
+func (h HandlingUnitHandlers) Move(c *gin.Context) {
+	key := c.GetHeader("Idempotency-Key")
+	if key == "" {
+		c.JSON(400, gin.H{"error": "Idempotency-Key required"})
+		return
+	}
+	id, ok := paramID(c)
+	if !ok {
+		return
+	}
+	var in service.MoveRequest
+	if err := c.ShouldBindJSON(&in); err != nil {
+		c.JSON(400, gin.H{"error": "bad request"})
+		return
+	}
+	actor, ok := actorID(c)
+	if !ok {
+		return
+	}
+	resp, err := h.Service.Move(c.Request.Context(), id, in, actor, "/api/handling-units/move", key)
+	if err != nil {
+		httperr.Write(c, err)
+		return
+	}
+	c.JSON(200, resp)
+}
+
+func page(c *gin.Context) (int32, int32) {
+	limit, _ := strconv.ParseInt(c.DefaultQuery("limit", "50"), 10, 32)
+	offset, _ := strconv.ParseInt(c.DefaultQuery("offset", "0"), 10, 32)
+	return int32(limit), int32(offset)
+}
+
+func paramID(c *gin.Context) (uuid.UUID, bool) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(400, gin.H{"error": "invalid id"})
+		return uuid.Nil, false
+	}
+	return id, true
+}
+
+func actorID(c *gin.Context) (uuid.UUID, bool) {
+	uid, err := uuid.Parse(c.GetString("user_id"))
+	if err != nil || uid == uuid.Nil {
+		c.JSON(401, gin.H{"error": "unauthorized"})
+		return uuid.Nil, false
+	}
+	return uid, true
+}
@@ -0,0 +1,615 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"erpwms/backend-go/internal/common/gs1"
+	"erpwms/backend-go/internal/common/idempotency"
+	"erpwms/backend-go/internal/common/qty"
+	"erpwms/backend-go/internal/common/store"
+	"erpwms/backend-go/internal/db/sqlcgen"
+	stocksvc "erpwms/backend-go/internal/modules/wms_stock/service"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+var (
+	ErrNotFound = store.ErrNotFound
+	ErrConflict = store.ErrConflict
+	ErrInvalid  = store.ErrInvalid
+)
+
+// conflictFields names the field behind each unique constraint.
+var conflictFields = map[string]string{
+	"handling_units_code_key": "handling unit code",
+}
+
+// Handling unit types.
+const (
+	TypePallet = "pallet"
+	TypeCarton = "carton"
+	TypeTote   = "tote"
+)
+
+// Reason codes booked by handling unit operations.
+const (
+	ReasonPack   = "HU_PACK"
+	ReasonUnpack = "HU_UNPACK"
+	ReasonMove   = "HU_MOVE"
+)
+
+// HandlingUnitService manages pallets, cartons and totes and the stock inside
+// them. Packing and unpacking are transfers within one location into or out
+// of a unit; moving a unit books one transfer per content line, all with
+// ref_type handling_unit.
+type HandlingUnitService struct {
+	DB      *pgxpool.Pool
+	Queries *sqlcgen.Queries
+	Stock   stocksvc.StockService
+	// CompanyPrefix and ExtensionDigit make up generated SSCCs. Without a
+	// prefix every new unit needs an explicit code.
+	CompanyPrefix  string
+	ExtensionDigit int
+}
+
+// HandlingUnitInput creates a unit at LocationID. Code is an SSCC-18 or any
+// other LPN; an SSCC is generated when it is empty. A ParentID nests the new
+// unit in one at the same location.
+type HandlingUnitInput struct {
+	Code       string `json:"code,omitempty"`
+	Type       string `json:"hu_type"`
+	LocationID string `json:"location_id"`
+	ParentID   string `json:"parent_id,omitempty"`
+	// OverrideCapacity places a top-level unit past the location's max_hu.
+	OverrideCapacity bool `json:"override_capacity,omitempty"`
+}
+
+type HandlingUnit struct {
+	ID           string         `json:"id"`
+	Code         string         `json:"code"`
+	Type         string         `json:"hu_type"`
+	ParentID     string         `json:"parent_id,omitempty"`
+	LocationID   string         `json:"location_id"`
+	LocationCode string         `json:"location_code,omitempty"`
+	ContentLines int32          `json:"content_lines"`
+	CreatedAt    time.Time      `json:"created_at"`
+	UpdatedAt    time.Time      `json:"updated_at,omitzero"`
+	Children     []HandlingUnit `json:"children,omitempty"`
+	Contents     []ContentLine  `json:"contents,omitempty"`
+}
+
+// ContentLine is one balance row inside a unit or a unit nested in it.
+// Quantities are in the item's base unit.
+type ContentLine struct {
+	HuID         string `json:"hu_id"`
+	HuCode       string `json:"hu_code"`
+	ItemID       string `json:"item_id"`
+	Sku          string `json:"sku"`
+	Uom          string `json:"uom"`
+	LotCode      string `json:"lot_code,omitempty"`
+	SerialNo     string `json:"serial_no,omitempty"`
+	Status       string `json:"status"`
+	QtyOnHand    string `json:"qty_on_hand"`
+	QtyAllocated string `json:"qty_allocated"`
+}
+
+type HandlingUnitFilter struct {
+	LocationID  string
+	WarehouseID string
+	ParentID    string
+	Code        string
+	Type        string
+	TopLevel    bool
+	Limit       int32
+	Offset      int32
+}
+
+// PackRequest puts stock lying at the unit's location into the unit, loose
+// or out of another unit (FromHuID), and nests the units in HuIDs.
+type PackRequest struct {
+	Lines []PackLine `json:"lines"`
+	HuIDs []string   `json:"hu_ids,omitempty"`
+}
+
+// PackLine is a quantity in Uom or the item's base unit.
+type PackLine struct {
+	ItemID   string `json:"item_id"`
+	Qty      string `json:"qty"`
+	Uom      string `json:"uom,omitempty"`
+	LotCode  string `json:"lot_code,omitempty"`
+	SerialNo string `json:"serial_no,omitempty"`
+	Status   string `json:"status,omitempty"`
+	FromHuID string `json:"from_hu_id,omitempty"`
+}
+
+// UnpackRequest takes stock out of the unit, loose or into another unit at
+// the same location (ToHuID), and detaches the nested units in HuIDs. An
+// empty request unpacks everything.
+type UnpackRequest struct {
+	Lines []UnpackLine `json:"lines"`
+	HuIDs []string     `json:"hu_ids,omitempty"`
+}
+
+type UnpackLine struct {
+	ItemID   string `json:"item_id"`
+	Qty      string `json:"qty"`
+	Uom      string `json:"uom,omitempty"`
+	LotCode  string `json:"lot_code,omitempty"`
+	SerialNo string `json:"serial_no,omitempty"`
+	Status   string `json:"status,omitempty"`
+	ToHuID   string `json:"to_hu_id,omitempty"`
+}
+
+// MoveRequest carries a top-level unit with everything in it to another
+// location. ReasonCode defaults to HU_MOVE. OverrideCapacity books past the
+// destination's limits.
+type MoveRequest struct {
+	ToLocationID     string `json:"to_location_id"`
+	ReasonCode       string `json:"reason_code,omitempty"`
+	OverrideCapacity bool   `json:"override_capacity,omitempty"`
+}
+
+// Response is the unit after an operation plus the ledger lines it booked.
+type Response struct {
+	HandlingUnit HandlingUnit `json:"handling_unit"`
+	MoveIDs      []string     `json:"move_ids"`
+}
+
+func (s HandlingUnitService) Create(ctx context.Context, in HandlingUnitInput, actor uuid.UUID) (HandlingUnit, error) {
+	switch in.Type {
+	case TypePallet, TypeCarton, TypeTote:
+	default:
+		return HandlingUnit{}, fmt.Errorf("%w: hu_type must be pallet, carton or tote", ErrInvalid)
+	}
+	locID, err := store.ParseUUID("location_id", in.LocationID)
+	if err != nil {
+		return HandlingUnit{}, err
+	}
+	var parentID uuid.UUID
+	if in.ParentID != "" {
+		if parentID, err = store.ParseUUID("parent_id", in.ParentID); err != nil {
+			return HandlingUnit{}, err
+		}
+	}
+	code := strings.TrimSpace(in.Code)
+	if code != "" {
+		if err := checkCode(code); err != nil {
+			return HandlingUnit{}, err
+		}
+	} else if s.CompanyPrefix == "" {
+		return HandlingUnit{}, fmt.Errorf("%w: code is required while no GS1 company prefix is configured", ErrInvalid)
+	}
+
+	var out HandlingUnit
+	err = s.mutate(ctx, actor, "hu.created", func(q *sqlcgen.Queries) (string, any, error) {
+		loc, err := q.GetLocation(ctx, store.UUID(locID))
+		if errors.Is(err, pgx.ErrNoRows) {
+			return "", nil, fmt.Errorf("%w: unknown location_id", ErrInvalid)
+		}
+		if err != nil {
+			return "", nil, err
+		}
+		if !loc.Active {
+			return "", nil, fmt.Errorf("%w: location %s is inactive", ErrInvalid, loc.Code)
+		}
+		if parentID != uuid.Nil {
+			parent, err := q.LockHandlingUnit(ctx, store.UUID(parentID))
+			if errors.Is(err, pgx.ErrNoRows) {
+				return "", nil, fmt.Errorf("%w: unknown parent_id", ErrInvalid)
+			}
+			if err != nil {
+				return "", nil, err
+			}
+			if parent.LocationID != loc.ID {
+				return "", nil, fmt.Errorf("%w: parent %s is not at location %s", ErrInvalid, parent.Code, loc.Code)
+			}
+		} else if err := s.Stock.CheckHandlingUnitRoom(ctx, q, loc.ID, actor, in.OverrideCapacity); err != nil {
+			return "", nil, err
+		}
+		if code == "" {
+			serial, err := q.NextSsccSerial(ctx)
+			if err != nil {
+				return "", nil, err
+			}
+			if code, err = gs1.SSCC(s.ExtensionDigit, s.CompanyPrefix, serial); err != nil {
+				return "", nil, fmt.Errorf("%w: %v", ErrInvalid, err)
+			}
+		}
+		hu, err := q.CreateHandlingUnit(ctx, sqlcgen.CreateHandlingUnitParams{
+			Code: code, HuType: in.Type, ParentID: store.UUID(parentID), LocationID: loc.ID, CreatedBy: store.UUID(actor),
+		})
+		if err != nil {
+			return "", nil, err
+		}
+		out = toHandlingUnit(hu)
+		out.LocationCode = loc.Code
+		return hu.ID.String(), out, nil
+	})
+	return out, err
+}
+
+// Get returns the unit with its direct children and the content of the unit
+// and everything nested in it.
+func (s HandlingUnitService) Get(ctx context.Context, id uuid.UUID) (HandlingUnit, error) {
+	return s.load(ctx, s.Queries, store.UUID(id))
+}
+
+func (s HandlingUnitService) List(ctx context.Context, f HandlingUnitFilter) ([]HandlingUnit, error) {
+	p := sqlcgen.ListHandlingUnitsParams{Code: f.Code, HuType: f.Type, TopLevel: f.TopLevel, PageLimit: f.Limit, PageOffset: f.Offset}
+	for _, u := range []struct {
+		field, v string
+		dst      *pgtype.UUID
+	}{{"location_id", f.LocationID, &p.LocationID}, {"warehouse_id", f.WarehouseID, &p.WarehouseID}, {"parent_id", f.ParentID, &p.ParentID}} {
+		if u.v == "" {
+			continue
+		}
+		id, err := store.ParseUUID(u.field, u.v)
+		if err != nil {
+			return nil, err
+		}
+		*u.dst = store.UUID(id)
+	}
+	if p.PageLimit <= 0 || p.PageLimit > 200 {
+		p.PageLimit = 50
+	}
+	rows, err := s.Queries.ListHandlingUnits(ctx, p)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]HandlingUnit, 0, len(rows))
+	for _, r := range rows {
+		out = append(out, HandlingUnit{
+			ID: r.ID.String(), Code: r.Code, Type: r.HuType, ParentID: store.OptUUID(r.ParentID), LocationID: r.LocationID.String(),
+			LocationCode: r.LocationCode, ContentLines: r.ContentLines, CreatedAt: r.CreatedAt.Time,
+		})
+	}
+	return out, nil
+}
+
+// Pack books the lines into the unit with reason HU_PACK and nests the units
+// in HuIDs. Stock and nested units must already be at the unit's location,
+// and a unit packed in another one has to be unpacked from it first.
+func (s HandlingUnitService) Pack(ctx context.Context, id uuid.UUID, req PackRequest, actor uuid.UUID, endpoint, idemKey string) (Response, error) {
+	reqHash := idempotency.Hash([]any{id.String(), req})
+	var prev Response
+	if found, err := idempotency.Replay(ctx, s.Queries, endpoint, idemKey, reqHash, &prev); err != nil || found {
+		return prev, err
+	}
+	if len(req.Lines) == 0 && len(req.HuIDs) == 0 {
+		return Response{}, fmt.Errorf("%w: lines or hu_ids are required", ErrInvalid)
+	}
+
+	tx, err := s.DB.Begin(ctx)
+	if err != nil {
+		return Response{}, err
+	}
+	defer tx.Rollback(ctx)
+	q := s.Queries.WithTx(tx)
+
+	hu, err := q.LockHandlingUnit(ctx, store.UUID(id))
+	if err != nil {
+		return Response{}, store.MapErr(err, conflictFields)
+	}
+	resp := Response{MoveIDs: []string{}}
+	for i, l := range req.Lines {
+		move, _, err := s.Stock.PostMove(ctx, q, stocksvc.MoveTransfer, stocksvc.MoveRequest{
+			ItemID: l.ItemID, Qty: l.Qty, Uom: l.Uom, LotCode: l.LotCode, SerialNo: l.SerialNo, Status: l.Status,
+			FromLocationID: hu.LocationID.String(), ToLocationID: hu.LocationID.String(), HuID: l.FromHuID, ToHuID: hu.ID.String(),
+			ReasonCode: ReasonPack, RefType: stocksvc.RefHandlingUnit, RefID: hu.ID.String(),
+		}, actor)
+		if err != nil {
+			return Response{}, fmt.Errorf("line %d: %w", i+1, err)
+		}
+		resp.MoveIDs = append(resp.MoveIDs, move.MoveID.String())
+	}
+	for _, v := range req.HuIDs {
+		childID, err := store.ParseUUID("hu_ids", v)
+		if err != nil {
+			return Response{}, err
+		}
+		tree, err := q.LockHandlingUnitTree(ctx, store.UUID(childID))
+		if err != nil {
+			return Response{}, err
+		}
+		if err := checkNest(hu, childID, tree); err != nil {
+			return Response{}, err
+		}
+		if err := q.SetHandlingUnitParent(ctx, sqlcgen.SetHandlingUnitParentParams{ID: store.UUID(childID), ParentID: hu.ID}); err != nil {
+			return Response{}, err
+		}
+	}
+	return s.finish(ctx, tx, q, hu, resp, actor, "hu.packed", endpoint, idemKey, reqHash)
+}
+
+// Unpack books the lines out of the unit with reason HU_UNPACK and detaches
+// the nested units in HuIDs; they stay at the location. Without lines and
+// units it empties the unit completely.
+func (s HandlingUnitService) Unpack(ctx context.Context, id uuid.UUID, req UnpackRequest, actor uuid.UUID, endpoint, idemKey string) (Response, error) {
+	reqHash := idempotency.Hash([]any{id.String(), req})
+	var prev Response
+	if found, err := idempotency.Replay(ctx, s.Queries, endpoint, idemKey, reqHash, &prev); err != nil || found {
+		return prev, err
+	}
+
+	tx, err := s.DB.Begin(ctx)
+	if err != nil {
+		return Response{}, err
+	}
+	defer tx.Rollback(ctx)
+	q := s.Queries.WithTx(tx)
+
+	hu, err := q.LockHandlingUnit(ctx, store.UUID(id))
+	if err != nil {
+		return Response{}, store.MapErr(err, conflictFields)
+	}
+	if len(req.Lines) == 0 && len(req.HuIDs) == 0 {
+		if req, err = unpackAll(ctx, q, hu); err != nil {
+			return Response{}, err
+		}
+	}
+	resp := Response{MoveIDs: []string{}}
+	for i, l := range req.Lines {
+		move, _, err := s.Stock.PostMove(ctx, q, stocksvc.MoveTransfer, stocksvc.MoveRequest{
+			ItemID: l.ItemID, Qty: l.Qty, Uom: l.Uom, LotCode: l.LotCode, SerialNo: l.SerialNo, Status: l.Status,
+			FromLocationID: hu.LocationID.String(), ToLocationID: hu.LocationID.String(), HuID: hu.ID.String(), ToHuID: l.ToHuID,
+			ReasonCode: ReasonUnpack, RefType: stocksvc.RefHandlingUnit, RefID: hu.ID.String(),
+		}, actor)
+		if err != nil {
+			return Response{}, fmt.Errorf("line %d: %w", i+1, err)
+		}
+		resp.MoveIDs = append(resp.MoveIDs, move.MoveID.String())
+	}
+	for _, v := range req.HuIDs {
+		childID, err := store.ParseUUID("hu_ids", v)
+		if err != nil {
+			return Response{}, err
+		}
+		child, err := q.LockHandlingUnit(ctx, store.UUID(childID))
+		if err != nil {
+			return Response{}, store.MapErr(err, conflictFields)
+		}
+		if child.ParentID != hu.ID {
+			return Response{}, fmt.Errorf("%w: %s is not packed in %s", ErrInvalid, child.Code, hu.Code)
+		}
+		if err := q.SetHandlingUnitParent(ctx, sqlcgen.SetHandlingUnitParentParams{ID: child.ID}); err != nil {
+			return Response{}, err
+		}
+	}
+	return s.finish(ctx, tx, q, hu, resp, actor, "hu.unpacked", endpoint, idemKey, reqHash)
+}
+
+// Move carries a top-level unit and every unit nested in it to another
+// location: one transfer per content line, keeping each line's unit, lot,
+// serial and status, then the units themselves. Allocated stock pins a unit
+// to its location until the allocation is picked or released.
+func (s HandlingUnitService) Move(ctx context.Context, id uuid.UUID, req MoveRequest, actor uuid.UUID, endpoint, idemKey string) (Response, error) {
+	reqHash := idempotency.Hash([]any{id.String(), req})
+	var prev Response
+	if found, err := idempotency.Replay(ctx, s.Queries, endpoint, idemKey, reqHash, &prev); err != nil || found {
+		return prev, err
+	}
+	toID, err := store.ParseUUID("to_location_id", req.ToLocationID)
+	if err != nil {
+		return Response{}, err
+	}
+	reason := req.ReasonCode
+	if reason == "" {
+		reason = ReasonMove
+	}
+
+	tx, err := s.DB.Begin(ctx)
+	if err != nil {
+		return Response{}, err
+	}
+	defer tx.Rollback(ctx)
+	q := s.Queries.WithTx(tx)
+
+	hu, err := q.LockHandlingUnit(ctx, store.UUID(id))
+	if err != nil {
+		return Response{}, store.MapErr(err, conflictFields)
+	}
+	if hu.ParentID.Valid {
+		return Response{}, fmt.Errorf("%w: %s is packed in another unit; unpack it first", ErrInvalid, hu.Code)
+	}
+	to, err := q.GetLocation(ctx, store.UUID(toID))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return Response{}, fmt.Errorf("%w: unknown to_location_id", ErrInvalid)
+	}
+	if err != nil {
+		return Response{}, err
+	}
+	if !to.Active {
+		return Response{}, fmt.Errorf("%w: location %s is inactive", ErrInvalid, to.Code)
+	}
+	if to.ID == hu.LocationID {
+		return Response{}, fmt.Errorf("%w: %s is already at %s", ErrInvalid, hu.Code, to.Code)
+	}
+	if err := s.Stock.CheckHandlingUnitRoom(ctx, q, to.ID, actor, req.OverrideCapacity); err != nil {
+		return Response{}, err
+	}
+	tree, err := q.LockHandlingUnitTree(ctx, hu.ID)
+	if err != nil {
+		return Response{}, err
+	}
+	ids := make([]pgtype.UUID, 0, len(tree))
+	for _, t := range tree {
+		ids = append(ids, t.ID)
+	}
+	contents, err := q.ListHandlingUnitContents(ctx, ids)
+	if err != nil {
+		return Response{}, err
+	}
+	if err := checkMovable(contents); err != nil {
+		return Response{}, err
+	}
+	resp := Response{MoveIDs: []string{}}
+	for _, c := range contents {
+		if qty.FromNumeric(c.QtyOnHand).Sign() <= 0 {
+			continue
+		}
+		move, _, err := s.Stock.PostMove(ctx, q, stocksvc.MoveTransfer, stocksvc.MoveRequest{
+			ItemID: c.ItemID.String(), Qty: qty.String(qty.FromNumeric(c.QtyOnHand)), LotCode: c.LotCode.String, SerialNo: c.SerialNo.String,
+			Status: c.Status, FromLocationID: hu.LocationID.String(), ToLocationID: to.ID.String(), HuID: c.HuID.String(), ToHuID: c.HuID.String(),
+			ReasonCode: reason, RefType: stocksvc.RefHandlingUnit, RefID: hu.ID.String(), OverrideCapacity: req.OverrideCapacity,
+		}, actor)
+		if err != nil {
+			return Response{}, fmt.Errorf("%s %s: %w", c.HuCode, c.Sku, err)
+		}
+		resp.MoveIDs = append(resp.MoveIDs, move.MoveID.String())
+	}
+	if err := q.SetHandlingUnitsLocation(ctx, sqlcgen.SetHandlingUnitsLocationParams{LocationID: to.ID, Ids: ids}); err != nil {
+		return Response{}, err
+	}
+	return s.finish(ctx, tx, q, hu, resp, actor, "hu.moved", endpoint, idemKey, reqHash)
+}
+
+// unpackAll turns the unit's own content and children into an unpack request.
+func unpackAll(ctx context.Context, q *sqlcgen.Queries, hu sqlcgen.HandlingUnit) (UnpackRequest, error) {
+	var req UnpackRequest
+	contents, err := q.ListHandlingUnitContents(ctx, []pgtype.UUID{hu.ID})
+	if err != nil {
+		return req, err
+	}
+	for _, c := range contents {
+		if qty.FromNumeric(c.QtyOnHand).Sign() <= 0 {
+			continue
+		}
+		req.Lines = append(req.Lines, UnpackLine{
+			ItemID: c.ItemID.String(), Qty: qty.String(qty.FromNumeric(c.QtyOnHand)), LotCode: c.LotCode.String, SerialNo: c.SerialNo.String, Status: c.Status,
+		})
+	}
+	children, err := q.ListHandlingUnitChildren(ctx, hu.ID)
+	if err != nil {
+		return req, err
+	}
+	for _, c := range children {
+		req.HuIDs = append(req.HuIDs, c.ID.String())
+	}
+	if len(req.Lines) == 0 && len(req.HuIDs) == 0 {
+		return req, fmt.Errorf("%w: %s is empty", ErrInvalid, hu.Code)
+	}
+	return req, nil
+}
+
+func (s HandlingUnitService) load(ctx context.Context, q *sqlcgen.Queries, id pgtype.UUID) (HandlingUnit, error) {
+	hu, err := q.GetHandlingUnit(ctx, id)
+	if err != nil {
+		return HandlingUnit{}, store.MapErr(err, conflictFields)
+	}
+	out := toHandlingUnit(hu)
+	if loc, err := q.GetLocation(ctx, hu.LocationID); err == nil {
+		out.LocationCode = loc.Code
+	}
+	tree, err := q.LockHandlingUnitTree(ctx, hu.ID)
+	if err != nil {
+		return HandlingUnit{}, err
+	}
+	ids := make([]pgtype.UUID, 0, len(tree))
+	for _, t := range tree {
+		ids = append(ids, t.ID)
+		if t.ParentID == hu.ID {
+			out.Children = append(out.Children, toHandlingUnit(t))
+		}
+	}
+	contents, err := q.ListHandlingUnitContents(ctx, ids)
+	if err != nil {
+		return HandlingUnit{}, err
+	}
+	for _, c := range contents {
+		if c.HuID == hu.ID {
+			out.ContentLines++
+		}
+		out.Contents = append(out.Contents, ContentLine{
+			HuID: c.HuID.String(), HuCode: c.HuCode, ItemID: c.ItemID.String(), Sku: c.Sku, Uom: c.Uom,
+			LotCode: c.LotCode.String, SerialNo: c.SerialNo.String, Status: c.Status,
+			QtyOnHand: qty.String(qty.FromNumeric(c.QtyOnHand)), QtyAllocated: qty.String(qty.FromNumeric(c.QtyAllocated)),
+		})
+	}
+	return out, nil
+}
+
+// finish records the event and audit entry, remembers the response for the
+// idempotency key and commits.
+func (s HandlingUnitService) finish(ctx context.Context, tx pgx.Tx, q *sqlcgen.Queries, hu sqlcgen.HandlingUnit, resp Response, actor uuid.UUID, topic, endpoint, idemKey, reqHash string) (Response, error) {
+	var err error
+	if resp.HandlingUnit, err = s.load(ctx, q, hu.ID); err != nil {
+		return Response{}, err
+	}
+	payload, _ := json.Marshal(resp)
+	if err := store.Record(ctx, q, actor, topic, "handling_units", hu.ID.String(), payload); err != nil {
+		return Response{}, err
+	}
+	if err := idempotency.Remember(ctx, q, endpoint, idemKey, store.UUID(actor), reqHash, resp); err != nil {
+		return Response{}, err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return Response{}, err
+	}
+	return resp, nil
+}
+
+// mutate runs fn in a transaction with its outbox event and audit entry
+// on handling_units; see store.Mutate.
+func (s HandlingUnitService) mutate(ctx context.Context, actor uuid.UUID, topic string, fn func(q *sqlcgen.Queries) (string, any, error)) error {
+	return store.Mutate(ctx, s.DB, s.Queries, actor, topic, "handling_units", conflictFields, fn)
+}
+
+// checkCode accepts any printable LPN, but an 18-digit code is taken for an
+// SSCC and must carry a valid check digit.
+func checkCode(code string) error {
+	if len(code) > 40 || strings.ContainsFunc(code, func(r rune) bool { return r < '!' || r > '~' }) {
+		return fmt.Errorf("%w: code must be up to 40 printable characters without spaces", ErrInvalid)
+	}
+	if len(code) == 18 && strings.Trim(code, "0123456789") == "" && !gs1.ValidSSCC(code) {
+		return fmt.Errorf("%w: %s is not a valid SSCC-18 (check digit)", ErrInvalid, code)
+	}
+	return nil
+}
+
+// checkNest validates packing child, with tree being the child and every unit
+// nested in it, into parent.
+func checkNest(parent sqlcgen.HandlingUnit, childID uuid.UUID, tree []sqlcgen.HandlingUnit) error {
+	var child *sqlcgen.HandlingUnit
+	for i := range tree {
+		if tree[i].ID == parent.ID {
+			return fmt.Errorf("%w: %s cannot be packed into itself or a unit nested in it", ErrInvalid, parent.Code)
+		}
+		if tree[i].ID == store.UUID(childID) {
+			child = &tree[i]
+		}
+	}
+	switch {
+	case child == nil:
+		return fmt.Errorf("%w: unknown handling unit %s", ErrNotFound, childID)
+	case child.ParentID.Valid:
+		return fmt.Errorf("%w: %s is already packed in another unit", ErrConflict, child.Code)
+	case child.LocationID != parent.LocationID:
+		return fmt.Errorf("%w: %s is not at the location of %s", ErrInvalid, child.Code, parent.Code)
+	}
+	return nil
+}
+
+// checkMovable refuses to move a unit holding allocated stock: the
+// allocation points at the current location.
+func checkMovable(contents []sqlcgen.ListHandlingUnitContentsRow) error {
+	for _, c := range contents {
+		if qty.FromNumeric(c.QtyAllocated).Sign() > 0 {
+			return fmt.Errorf("%w: %s holds %s %s allocated", ErrConflict, c.HuCode, qty.String(qty.FromNumeric(c.QtyAllocated)), c.Sku)
+		}
+	}
+	return nil
+}
+
+func toHandlingUnit(h sqlcgen.HandlingUnit) HandlingUnit {
+	return HandlingUnit{
+		ID: h.ID.String(), Code: h.Code, Type: h.HuType, ParentID: store.OptUUID(h.ParentID), LocationID: h.LocationID.String(),
+		CreatedAt: h.CreatedAt.Time, UpdatedAt: h.UpdatedAt.Time,
+	}
+}
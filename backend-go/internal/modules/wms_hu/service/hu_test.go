@@ -0,0 +1,73 @@
+package service
+
+import (
+	"errors"
+	"testing"
+
+	"erpwms/backend-go/internal/common/qty"
+	"erpwms/backend-go/internal/common/store"
+	"erpwms/backend-go/internal/db/sqlcgen"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+func TestCheckCode(t *testing.T) {
+	for _, code := range []string{"106141411234567897", "LPN-000123", "0614141123"} {
+		if err := checkCode(code); err != nil {
+			t.Fatalf("%s: %v", code, err)
+		}
+	}
+	for _, code := range []string{"106141411234567890", "PAL 1", "caffè"} {
+		if err := checkCode(code); !errors.Is(err, ErrInvalid) {
+			t.Fatalf("%s: got %v", code, err)
+		}
+	}
+}
+
+func TestCheckNest(t *testing.T) {
+	loc := store.UUID(uuid.New())
+	pallet := sqlcgen.HandlingUnit{ID: store.UUID(uuid.New()), Code: "PAL", LocationID: loc}
+	cartonID := uuid.New()
+	carton := sqlcgen.HandlingUnit{ID: store.UUID(cartonID), Code: "CTN", LocationID: loc}
+	if err := checkNest(pallet, cartonID, []sqlcgen.HandlingUnit{carton}); err != nil {
+		t.Fatal(err)
+	}
+
+	// The pallet already sits in the carton's tree: packing would close a cycle.
+	nested := pallet
+	nested.ParentID = carton.ID
+	if err := checkNest(pallet, cartonID, []sqlcgen.HandlingUnit{carton, nested}); !errors.Is(err, ErrInvalid) {
+		t.Fatalf("cycle: got %v", err)
+	}
+
+	elsewhere := carton
+	elsewhere.LocationID = store.UUID(uuid.New())
+	if err := checkNest(pallet, cartonID, []sqlcgen.HandlingUnit{elsewhere}); !errors.Is(err, ErrInvalid) {
+		t.Fatalf("other location: got %v", err)
+	}
+
+	packed := carton
+	packed.ParentID = store.UUID(uuid.New())
+	if err := checkNest(pallet, cartonID, []sqlcgen.HandlingUnit{packed}); !errors.Is(err, ErrConflict) {
+		t.Fatalf("already packed: got %v", err)
+	}
+
+	if err := checkNest(pallet, cartonID, nil); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("missing: got %v", err)
+	}
+}
+
+func TestCheckMovable(t *testing.T) {
+	line := sqlcgen.ListHandlingUnitContentsRow{HuCode: "PAL", Sku: "SKU-1", QtyOnHand: qty.ToNumeric(qty.Zero()), QtyAllocated: qty.ToNumeric(qty.Zero())}
+	if err := checkMovable([]sqlcgen.ListHandlingUnitContentsRow{line}); err != nil {
+		t.Fatal(err)
+	}
+	var n pgtype.Numeric
+	if err := n.Scan("2"); err != nil {
+		t.Fatal(err)
+	}
+	line.QtyAllocated = n
+	if err := checkMovable([]sqlcgen.ListHandlingUnitContentsRow{line}); !errors.Is(err, ErrConflict) {
+		t.Fatalf("got %v", err)
+	}
+}
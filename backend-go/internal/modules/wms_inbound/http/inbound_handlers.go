@@ -0,0 +1,253 @@
+package http
+
+import (
+	"strconv"
+
+	"erpwms/backend-go/internal/common/httperr"
+	"erpwms/backend-go/internal/modules/wms_inbound/service"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type InboundHandlers struct {
+	Service service.InboundService
+}
+
+func (h InboundHandlers) ListSuppliers(c *gin.Context) {
+	limit, offset := page(c)
+	rows, err := h.Service.ListSuppliers(c.Request.Context(), c.Query("include_inactive") == "true", limit, offset)
+	if err != nil {
+		httperr.Write(c, err)
+		return
+	}
+	c.JSON(200, gin.H{"items": rows})
+}
+
+func (h InboundHandlers) CreateSupplier(c *gin.Context) {
+	var in service.SupplierInput
+	if err := c.ShouldBindJSON(&in); err != nil {
+		c.JSON(400, gin.H{"error": "bad request"})
+		return
+	}
+	actor, ok := actorID(c)
+	if !ok {
+		return
+	}
+	sup, err := h.Service.CreateSupplier(c.Request.Context(), in, actor)
+	if err != nil {
+		httperr.Write(c, err)
+		return
+	}
+	c.JSON(201, sup)
+}
+
+func (h InboundHandlers) UpdateSupplier(c *gin.Context) {
+	id, ok := paramID(c)
+	if !ok {
+		return
+	}
+	var in service.SupplierInput
+	if err := c.ShouldBindJSON(&in); err != nil {
+		c.JSON(400, gin.H{"error": "bad request"})
+		return
+	}
+	actor, ok := actorID(c)
+	if !ok {
+		return
+	}
+	sup, err := h.Service.UpdateSupplier(c.Request.Context(), id, in, actor)
+	if err != nil {
+		httperr.Write(c, err)
+		return
+	}
+	c.JSON(200, sup)
+}
+
+func (h InboundHandlers) DeactivateSupplier(c *gin.Context) {
+	id, ok := paramID(c)
+	if !ok {
+		return
+	}
+	actor, ok := actorID(c)
+	if !ok {
+		return
+	}
+	sup, err := h.Service.DeactivateSupplier(c.Request.Context(), id, actor)
+	if err != nil {
+		httperr.Write(c, err)
+		return
+	}
+	c.JSON(200, sup)
+}
+
+func (h InboundHandlers) ListPurchaseOrders(c *gin.Context) {
+	limit, offset := page(c)
+	rows, err := h.Service.ListPurchaseOrders(c.Request.Context(), service.PurchaseOrderFilter{
+		SupplierID:  c.Query("supplier_id"),
+		WarehouseID: c.Query("warehouse_id"),
+		Status:      c.Query("status"),
+		Limit:       limit,
+		Offset:      offset,
+	})
+	if err != nil {
+		httperr.Write(c, err)
+		return
+	}
+	c.JSON(200, gin.H{"items": rows})
+}
+
+func (h InboundHandlers) GetPurchaseOrder(c *gin.Context) {
+	id, ok := paramID(c)
+	if !ok {
+		return
+	}
+	po, err := h.Service.GetPurchaseOrder(c.Request.Context(), id)
+	if err != nil {
+		httperr.Write(c, err)
+		return
+	}
+	c.JSON(200, po)
+}
+
+func (h InboundHandlers) CreatePurchaseOrder(c *gin.Context) {
+	var in service.PurchaseOrderInput
+	if err := c.ShouldBindJSON(&in); err != nil {
+		c.JSON(400, gin.H{"error": "bad request"})
+		return
+	}
+	actor, ok := actorID(c)
+	if !ok {
+		return
+	}
+	po, err := h.Service.CreatePurchaseOrder(c.Request.Context(), in, actor)
+	if err != nil {
+		httperr.Write(c, err)
+		return
+	}
+	c.JSON(201, po)
+}
+
+func (h InboundHandlers) ClosePurchaseOrder(c *gin.Context) {
+	id, ok := paramID(c)
+	if !ok {
+		return
+	}
+	actor, ok := actorID(c)
+	if !ok {
+		return
+	}
+	po, err := h.Service.ClosePurchaseOrder(c.Request.Context(), id, actor)
+	if err != nil {
+		httperr.Write(c, err)
+		return
+	}
+	c.JSON(200, po)
+}
+
+func (h InboundHandlers) CancelPurchaseOrder(c *gin.Context) {
+	id, ok := paramID(c)
+	if !ok {
+		return
+	}
+	actor, ok := actorID(c)
+	if !ok {
+		return
+	}
+	po, err := h.Service.CancelPurchaseOrder(c.Request.Context(), id, actor)
+	if err != nil {
+		httperr.Write(c, err)
+		return
+	}
+	c.JSON(200, po)
+}
+
+func (h InboundHandlers) ListAsns(c *gin.Context) {
+	id, ok := paramID(c)
+	if !ok {
+		return
+	}
+	rows, err := h.Service.ListAsns(c.Request.Context(), id)
+	if err != nil {
+		httperr.Write(c, err)
+		return
+	}
+	c.JSON(200, gin.H{"items": rows})
+}
+
+func (h InboundHandlers) GetAsn(c *gin.Context) {
+	id, ok := paramID(c)
+	if !ok {
+		return
+	}
+	asn, err := h.Service.GetAsn(c.Request.Context(), id)
+	if err != nil {
+		httperr.Write(c, err)
+		return
+	}
+	c.JSON(200, asn)
+}
+
+func (h InboundHandlers) CreateAsn(c *gin.Context) {
+	var in service.AsnInput
+	if err := c.ShouldBindJSON(&in); err != nil {
+		c.JSON(400, gin.H{"error": "bad request"})
+		return
+	}
+	actor, ok := actorID(c)
+	if !ok {
+		return
+	}
+	asn, err := h.Service.CreateAsn(c.Request.Context(), in, actor)
+	if err != nil {
+		httperr.Write(c, err)
+		return
+	}
+	c.JSON(201, asn)
+}
+
+func (h InboundHandlers) Receive(c *gin.Context) {
+	key := c.GetHeader("Idempotency-Key")
+	if key == "" {
+		c.JSON(400, gin.H{"error": "Idempotency-Key required"})
+		return
+	}
+	var req service.ReceiptRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(400, gin.H{"error": "bad request"})
+		return
+	}
+	actor, ok := actorID(c)
+	if !ok {
+		return
+	}
+	resp, err := h.Service.Receive(c.Request.Context(), req, actor, "/api/inbound/receipts", key)
+	if err != nil {
+		httperr.Write(c, err)
+		return
+	}
+	c.JSON(201, resp)
+}
+
+func page(c *gin.Context) (int32, int32) {
+	limit, _ := strconv.ParseInt(c.DefaultQuery("limit", "50"), 10, 32)
+	offset, _ := strconv.ParseInt(c.DefaultQuery("offset", "0"), 10, 32)
+	return int32(limit), int32(offset)
+}
+
+func paramID(c *gin.Context) (uuid.UUID, bool) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(400, gin.H{"error": "invalid id"})
+		return uuid.Nil, false
+	}
+	return id, true
+}
+
+func actorID(c *gin.Context) (uuid.UUID, bool) {
+	uid, err := uuid.Parse(c.GetString("user_id"))
+	if err != nil || uid == uuid.Nil {
+		c.JSON(401, gin.H{"error": "unauthorized"})
+		return uuid.Nil, false
+	}
+	return uid, true
+}
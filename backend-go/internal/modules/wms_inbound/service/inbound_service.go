@@ -0,0 +1,551 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"strings"
+	"time"
+
+	"erpwms/backend-go/internal/common/qty"
+	"erpwms/backend-go/internal/common/store"
+	"erpwms/backend-go/internal/db/sqlcgen"
+	stocksvc "erpwms/backend-go/internal/modules/wms_stock/service"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+var (
+	ErrNotFound = store.ErrNotFound
+	ErrConflict = store.ErrConflict
+	ErrInvalid  = store.ErrInvalid
+)
+
+// PO and ASN states. A PO is receiving from its first receipt until every
+// line is fully received or it is closed by hand.
+const (
+	StatusOpen      = "open"
+	StatusReceiving = "receiving"
+	StatusClosed    = "closed"
+	StatusCancelled = "cancelled"
+	StatusReceived  = "received"
+)
+
+// conflictFields names the field behind each unique constraint.
+var conflictFields = map[string]string{
+	"suppliers_code_key":                     "supplier code",
+	"purchase_orders_po_number_key":          "po number",
+	"purchase_order_lines_po_id_line_no_key": "line number",
+	"asns_supplier_id_asn_number_key":        "asn number for supplier",
+}
+
+// InboundService owns suppliers, purchase orders, ASNs and goods receipts.
+// Receipts book stock through Stock inside the receipt's transaction.
+type InboundService struct {
+	DB      *pgxpool.Pool
+	Queries *sqlcgen.Queries
+	Stock   stocksvc.StockService
+}
+
+type SupplierInput struct {
+	Code string `json:"code"`
+	Name string `json:"name"`
+}
+
+type Supplier struct {
+	ID     string `json:"id"`
+	Code   string `json:"code"`
+	Name   string `json:"name"`
+	Active bool   `json:"active"`
+}
+
+// PurchaseOrderInput quantities are in Uom, or the item's base unit when Uom
+// is empty; they are stored in the base unit. ExpectedOn is YYYY-MM-DD.
+type PurchaseOrderInput struct {
+	PoNumber       string                   `json:"po_number"`
+	SupplierID     string                   `json:"supplier_id"`
+	WarehouseID    string                   `json:"warehouse_id"`
+	ExpectedOn     string                   `json:"expected_on"`
+	OverReceiptPct string                   `json:"over_receipt_pct"`
+	Lines          []PurchaseOrderLineInput `json:"lines"`
+}
+
+type PurchaseOrderLineInput struct {
+	ItemID string `json:"item_id"`
+	Qty    string `json:"qty"`
+	Uom    string `json:"uom,omitempty"`
+}
+
+type PurchaseOrder struct {
+	ID             string              `json:"id"`
+	PoNumber       string              `json:"po_number"`
+	SupplierID     string              `json:"supplier_id"`
+	WarehouseID    string              `json:"warehouse_id"`
+	Status         string              `json:"status"`
+	ExpectedOn     string              `json:"expected_on,omitempty"`
+	OverReceiptPct string              `json:"over_receipt_pct"`
+	CreatedAt      time.Time           `json:"created_at"`
+	ClosedAt       *time.Time          `json:"closed_at,omitempty"`
+	Lines          []PurchaseOrderLine `json:"lines,omitempty"`
+}
+
+// PurchaseOrderLine reports progress in the item's base unit. QtyOver is what
+// arrived beyond QtyOrdered, QtyUnder what is still missing.
+type PurchaseOrderLine struct {
+	ID          string `json:"id"`
+	LineNo      int32  `json:"line_no"`
+	ItemID      string `json:"item_id"`
+	QtyOrdered  string `json:"qty_ordered"`
+	QtyReceived string `json:"qty_received"`
+	QtyOver     string `json:"qty_over"`
+	QtyUnder    string `json:"qty_under"`
+}
+
+type PurchaseOrderFilter struct {
+	SupplierID  string
+	WarehouseID string
+	Status      string
+	Limit       int32
+	Offset      int32
+}
+
+// AsnInput announces what the supplier shipped against a PO. ExpectedAt is
+// RFC 3339.
+type AsnInput struct {
+	AsnNumber       string         `json:"asn_number"`
+	PurchaseOrderID string         `json:"purchase_order_id"`
+	ExpectedAt      string         `json:"expected_at"`
+	Lines           []AsnLineInput `json:"lines"`
+}
+
+type AsnLineInput struct {
+	LineNo    int32  `json:"line_no"`
+	Qty       string `json:"qty"`
+	Uom       string `json:"uom,omitempty"`
+	LotCode   string `json:"lot_code,omitempty"`
+	ExpiresOn string `json:"expires_on,omitempty"`
+}
+
+type Asn struct {
+	ID              string     `json:"id"`
+	AsnNumber       string     `json:"asn_number"`
+	SupplierID      string     `json:"supplier_id"`
+	PurchaseOrderID string     `json:"purchase_order_id"`
+	ExpectedAt      *time.Time `json:"expected_at,omitempty"`
+	Status          string     `json:"status"`
+	Lines           []AsnLine  `json:"lines"`
+}
+
+type AsnLine struct {
+	LineNo    int32  `json:"line_no"`
+	ItemID    string `json:"item_id"`
+	Qty       string `json:"qty"`
+	LotCode   string `json:"lot_code,omitempty"`
+	ExpiresOn string `json:"expires_on,omitempty"`
+}
+
+func (s InboundService) ListSuppliers(ctx context.Context, includeInactive bool, limit, offset int32) ([]Supplier, error) {
+	rows, err := s.Queries.ListSuppliers(ctx, sqlcgen.ListSuppliersParams{IncludeInactive: includeInactive, PageLimit: limit, PageOffset: offset})
+	if err != nil {
+		return nil, err
+	}
+	out := make([]Supplier, 0, len(rows))
+	for _, r := range rows {
+		out = append(out, toSupplier(r))
+	}
+	return out, nil
+}
+
+func (s InboundService) CreateSupplier(ctx context.Context, in SupplierInput, actor uuid.UUID) (Supplier, error) {
+	if err := in.validate(); err != nil {
+		return Supplier{}, err
+	}
+	var out Supplier
+	err := s.mutate(ctx, actor, "supplier.created", "suppliers", func(q *sqlcgen.Queries) (string, any, error) {
+		r, err := q.CreateSupplier(ctx, sqlcgen.CreateSupplierParams{Code: in.Code, Name: in.Name})
+		if err != nil {
+			return "", nil, err
+		}
+		out = toSupplier(r)
+		return out.ID, out, nil
+	})
+	return out, err
+}
+
+func (s InboundService) UpdateSupplier(ctx context.Context, id uuid.UUID, in SupplierInput, actor uuid.UUID) (Supplier, error) {
+	if err := in.validate(); err != nil {
+		return Supplier{}, err
+	}
+	var out Supplier
+	err := s.mutate(ctx, actor, "supplier.updated", "suppliers", func(q *sqlcgen.Queries) (string, any, error) {
+		r, err := q.UpdateSupplier(ctx, sqlcgen.UpdateSupplierParams{ID: store.UUID(id), Code: in.Code, Name: in.Name})
+		if err != nil {
+			return "", nil, err
+		}
+		out = toSupplier(r)
+		return out.ID, out, nil
+	})
+	return out, err
+}
+
+func (s InboundService) DeactivateSupplier(ctx context.Context, id uuid.UUID, actor uuid.UUID) (Supplier, error) {
+	var out Supplier
+	err := s.mutate(ctx, actor, "supplier.deactivated", "suppliers", func(q *sqlcgen.Queries) (string, any, error) {
+		r, err := q.SetSupplierActive(ctx, sqlcgen.SetSupplierActiveParams{ID: store.UUID(id), Active: false})
+		if err != nil {
+			return "", nil, err
+		}
+		out = toSupplier(r)
+		return out.ID, out, nil
+	})
+	return out, err
+}
+
+// CreatePurchaseOrder numbers the lines 1..n in the order given.
+func (s InboundService) CreatePurchaseOrder(ctx context.Context, in PurchaseOrderInput, actor uuid.UUID) (PurchaseOrder, error) {
+	if strings.TrimSpace(in.PoNumber) == "" || len(in.Lines) == 0 {
+		return PurchaseOrder{}, fmt.Errorf("%w: po_number and lines are required", ErrInvalid)
+	}
+	supplierID, err := store.ParseUUID("supplier_id", in.SupplierID)
+	if err != nil {
+		return PurchaseOrder{}, err
+	}
+	warehouseID, err := store.ParseUUID("warehouse_id", in.WarehouseID)
+	if err != nil {
+		return PurchaseOrder{}, err
+	}
+	expectedOn, err := parseDate(in.ExpectedOn)
+	if err != nil {
+		return PurchaseOrder{}, err
+	}
+	pct := qty.Zero()
+	if in.OverReceiptPct != "" {
+		if pct, err = qty.Parse(in.OverReceiptPct); err != nil || pct.Sign() < 0 {
+			return PurchaseOrder{}, fmt.Errorf("%w: over_receipt_pct must be a non-negative decimal", ErrInvalid)
+		}
+	}
+	var out PurchaseOrder
+	err = s.mutate(ctx, actor, "purchase_order.created", "purchase_orders", func(q *sqlcgen.Queries) (string, any, error) {
+		supplier, err := q.GetSupplier(ctx, store.UUID(supplierID))
+		if err != nil {
+			return "", nil, err
+		}
+		if !supplier.Active {
+			return "", nil, fmt.Errorf("%w: supplier %s is inactive", ErrInvalid, supplier.Code)
+		}
+		po, err := q.CreatePurchaseOrder(ctx, sqlcgen.CreatePurchaseOrderParams{
+			PoNumber: in.PoNumber, SupplierID: supplier.ID, WarehouseID: store.UUID(warehouseID), ExpectedOn: expectedOn,
+			OverReceiptPct: qty.ToNumeric(pct), CreatedBy: store.UUID(actor),
+		})
+		if err != nil {
+			return "", nil, err
+		}
+		lines := make([]sqlcgen.PurchaseOrderLine, 0, len(in.Lines))
+		for i, l := range in.Lines {
+			itemID, err := store.ParseUUID("item_id", l.ItemID)
+			if err != nil {
+				return "", nil, err
+			}
+			base, err := s.Stock.BaseQty(ctx, q, itemID, l.Qty, l.Uom)
+			if err != nil {
+				return "", nil, fmt.Errorf("line %d: %w", i+1, err)
+			}
+			if base.Sign() <= 0 {
+				return "", nil, fmt.Errorf("%w: line %d: qty must be positive", ErrInvalid, i+1)
+			}
+			line, err := q.InsertPurchaseOrderLine(ctx, sqlcgen.InsertPurchaseOrderLineParams{PoID: po.ID, LineNo: int32(i + 1), ItemID: store.UUID(itemID), QtyOrdered: qty.ToNumeric(base)})
+			if err != nil {
+				return "", nil, err
+			}
+			lines = append(lines, line)
+		}
+		out = toPurchaseOrder(po, lines)
+		return out.ID, out, nil
+	})
+	return out, err
+}
+
+func (s InboundService) GetPurchaseOrder(ctx context.Context, id uuid.UUID) (PurchaseOrder, error) {
+	po, err := s.Queries.GetPurchaseOrder(ctx, store.UUID(id))
+	if err != nil {
+		return PurchaseOrder{}, store.MapErr(err, conflictFields)
+	}
+	lines, err := s.Queries.ListPurchaseOrderLines(ctx, po.ID)
+	if err != nil {
+		return PurchaseOrder{}, err
+	}
+	return toPurchaseOrder(po, lines), nil
+}
+
+// ListPurchaseOrders returns headers only; fetch one PO for its lines.
+func (s InboundService) ListPurchaseOrders(ctx context.Context, f PurchaseOrderFilter) ([]PurchaseOrder, error) {
+	p := sqlcgen.ListPurchaseOrdersParams{Status: f.Status, PageLimit: f.Limit, PageOffset: f.Offset}
+	for _, u := range []struct {
+		name, v string
+		dst     *pgtype.UUID
+	}{{"supplier_id", f.SupplierID, &p.SupplierID}, {"warehouse_id", f.WarehouseID, &p.WarehouseID}} {
+		if u.v == "" {
+			continue
+		}
+		id, err := store.ParseUUID(u.name, u.v)
+		if err != nil {
+			return nil, err
+		}
+		*u.dst = store.UUID(id)
+	}
+	rows, err := s.Queries.ListPurchaseOrders(ctx, p)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]PurchaseOrder, 0, len(rows))
+	for _, r := range rows {
+		out = append(out, toPurchaseOrder(r, nil))
+	}
+	return out, nil
+}
+
+// ClosePurchaseOrder ends receiving by hand; whatever is still missing stays
+// on the lines as qty_under.
+func (s InboundService) ClosePurchaseOrder(ctx context.Context, id uuid.UUID, actor uuid.UUID) (PurchaseOrder, error) {
+	return s.setPurchaseOrderStatus(ctx, id, StatusClosed, actor)
+}
+
+// CancelPurchaseOrder is only possible before anything was received.
+func (s InboundService) CancelPurchaseOrder(ctx context.Context, id uuid.UUID, actor uuid.UUID) (PurchaseOrder, error) {
+	return s.setPurchaseOrderStatus(ctx, id, StatusCancelled, actor)
+}
+
+func (s InboundService) setPurchaseOrderStatus(ctx context.Context, id uuid.UUID, status string, actor uuid.UUID) (PurchaseOrder, error) {
+	var out PurchaseOrder
+	err := s.mutate(ctx, actor, "purchase_order."+status, "purchase_orders", func(q *sqlcgen.Queries) (string, any, error) {
+		po, err := q.LockPurchaseOrder(ctx, store.UUID(id))
+		if err != nil {
+			return "", nil, err
+		}
+		if po.Status == StatusClosed || po.Status == StatusCancelled {
+			return "", nil, fmt.Errorf("%w: purchase order is %s", ErrInvalid, po.Status)
+		}
+		if status == StatusCancelled {
+			received, err := q.PurchaseOrderHasReceipts(ctx, po.ID)
+			if err != nil {
+				return "", nil, err
+			}
+			if received {
+				return "", nil, fmt.Errorf("%w: purchase order has receipts, close it instead", ErrInvalid)
+			}
+		}
+		if po, err = q.SetPurchaseOrderStatus(ctx, sqlcgen.SetPurchaseOrderStatusParams{ID: po.ID, Status: status}); err != nil {
+			return "", nil, err
+		}
+		lines, err := q.ListPurchaseOrderLines(ctx, po.ID)
+		if err != nil {
+			return "", nil, err
+		}
+		out = toPurchaseOrder(po, lines)
+		return out.ID, out, nil
+	})
+	return out, err
+}
+
+// CreateAsn records an advance shipping notice. Lines refer to PO lines by
+// number; the PO must still be open for receiving.
+func (s InboundService) CreateAsn(ctx context.Context, in AsnInput, actor uuid.UUID) (Asn, error) {
+	if strings.TrimSpace(in.AsnNumber) == "" || len(in.Lines) == 0 {
+		return Asn{}, fmt.Errorf("%w: asn_number and lines are required", ErrInvalid)
+	}
+	poID, err := store.ParseUUID("purchase_order_id", in.PurchaseOrderID)
+	if err != nil {
+		return Asn{}, err
+	}
+	var expectedAt pgtype.Timestamptz
+	if in.ExpectedAt != "" {
+		t, err := time.Parse(time.RFC3339, in.ExpectedAt)
+		if err != nil {
+			return Asn{}, fmt.Errorf("%w: expected_at must be RFC 3339", ErrInvalid)
+		}
+		expectedAt = pgtype.Timestamptz{Time: t, Valid: true}
+	}
+	var out Asn
+	err = s.mutate(ctx, actor, "asn.created", "asns", func(q *sqlcgen.Queries) (string, any, error) {
+		po, err := q.GetPurchaseOrder(ctx, store.UUID(poID))
+		if err != nil {
+			return "", nil, err
+		}
+		if po.Status != StatusOpen && po.Status != StatusReceiving {
+			return "", nil, fmt.Errorf("%w: purchase order is %s", ErrInvalid, po.Status)
+		}
+		poLines, err := q.ListPurchaseOrderLines(ctx, po.ID)
+		if err != nil {
+			return "", nil, err
+		}
+		byNo := linesByNo(poLines)
+		asn, err := q.CreateAsn(ctx, sqlcgen.CreateAsnParams{AsnNumber: in.AsnNumber, SupplierID: po.SupplierID, PoID: po.ID, ExpectedAt: expectedAt, CreatedBy: store.UUID(actor)})
+		if err != nil {
+			return "", nil, err
+		}
+		lines := make([]sqlcgen.AsnLine, 0, len(in.Lines))
+		for i, l := range in.Lines {
+			pl, ok := byNo[l.LineNo]
+			if !ok {
+				return "", nil, fmt.Errorf("%w: line %d: po has no line_no %d", ErrInvalid, i+1, l.LineNo)
+			}
+			base, err := s.Stock.BaseQty(ctx, q, uuid.UUID(pl.ItemID.Bytes), l.Qty, l.Uom)
+			if err != nil {
+				return "", nil, fmt.Errorf("line %d: %w", i+1, err)
+			}
+			if base.Sign() <= 0 {
+				return "", nil, fmt.Errorf("%w: line %d: qty must be positive", ErrInvalid, i+1)
+			}
+			exp, err := parseDate(l.ExpiresOn)
+			if err != nil {
+				return "", nil, err
+			}
+			al, err := q.InsertAsnLine(ctx, sqlcgen.InsertAsnLineParams{AsnID: asn.ID, PoLineID: pl.ID, Qty: qty.ToNumeric(base), LotCode: store.Text(l.LotCode), ExpiresOn: exp})
+			if err != nil {
+				return "", nil, err
+			}
+			lines = append(lines, al)
+		}
+		out = toAsn(asn, lines, poLines)
+		return out.ID, out, nil
+	})
+	return out, err
+}
+
+func (s InboundService) GetAsn(ctx context.Context, id uuid.UUID) (Asn, error) {
+	asn, err := s.Queries.GetAsn(ctx, store.UUID(id))
+	if err != nil {
+		return Asn{}, store.MapErr(err, conflictFields)
+	}
+	lines, err := s.Queries.ListAsnLines(ctx, asn.ID)
+	if err != nil {
+		return Asn{}, err
+	}
+	poLines, err := s.Queries.ListPurchaseOrderLines(ctx, asn.PoID)
+	if err != nil {
+		return Asn{}, err
+	}
+	return toAsn(asn, lines, poLines), nil
+}
+
+func (s InboundService) ListAsns(ctx context.Context, poID uuid.UUID) ([]Asn, error) {
+	rows, err := s.Queries.ListAsnsByPurchaseOrder(ctx, store.UUID(poID))
+	if err != nil {
+		return nil, err
+	}
+	out := make([]Asn, 0, len(rows))
+	for _, r := range rows {
+		out = append(out, toAsn(r, nil, nil))
+	}
+	return out, nil
+}
+
+// mutate runs fn in a transaction with its outbox event and audit entry;
+// see store.Mutate.
+func (s InboundService) mutate(ctx context.Context, actor uuid.UUID, topic, resource string, fn func(q *sqlcgen.Queries) (string, any, error)) error {
+	return store.Mutate(ctx, s.DB, s.Queries, actor, topic, resource, conflictFields, fn)
+}
+
+func (in SupplierInput) validate() error {
+	if strings.TrimSpace(in.Code) == "" || strings.TrimSpace(in.Name) == "" {
+		return fmt.Errorf("%w: code and name are required", ErrInvalid)
+	}
+	return nil
+}
+
+// lineProgress splits received against ordered into what arrived too much and
+// what is still missing; at most one of the two is non-zero.
+func lineProgress(ordered, received *big.Rat) (over, under *big.Rat) {
+	d := qty.Sub(received, ordered)
+	if d.Sign() > 0 {
+		return d, qty.Zero()
+	}
+	return qty.Zero(), qty.Neg(d)
+}
+
+// receiptLimit is the most a line may hold in total: qty_ordered plus the
+// PO's over-receipt tolerance in percent.
+func receiptLimit(ordered, pct *big.Rat) *big.Rat {
+	return qty.Add(ordered, qty.Mul(ordered, qty.Mul(pct, big.NewRat(1, 100))))
+}
+
+// fullyReceived reports whether every line got at least what was ordered.
+func fullyReceived(lines []sqlcgen.PurchaseOrderLine) bool {
+	for _, l := range lines {
+		if qty.FromNumeric(l.QtyReceived).Cmp(qty.FromNumeric(l.QtyOrdered)) < 0 {
+			return false
+		}
+	}
+	return true
+}
+
+func linesByNo(lines []sqlcgen.PurchaseOrderLine) map[int32]sqlcgen.PurchaseOrderLine {
+	m := make(map[int32]sqlcgen.PurchaseOrderLine, len(lines))
+	for _, l := range lines {
+		m[l.LineNo] = l
+	}
+	return m
+}
+
+func toSupplier(r sqlcgen.Supplier) Supplier {
+	return Supplier{ID: r.ID.String(), Code: r.Code, Name: r.Name, Active: r.Active}
+}
+
+func toPurchaseOrder(po sqlcgen.PurchaseOrder, lines []sqlcgen.PurchaseOrderLine) PurchaseOrder {
+	out := PurchaseOrder{
+		ID: po.ID.String(), PoNumber: po.PoNumber, SupplierID: po.SupplierID.String(), WarehouseID: po.WarehouseID.String(),
+		Status: po.Status, ExpectedOn: fmtDate(po.ExpectedOn), OverReceiptPct: qty.String(qty.FromNumeric(po.OverReceiptPct)),
+		CreatedAt: po.CreatedAt.Time,
+	}
+	if po.ClosedAt.Valid {
+		out.ClosedAt = &po.ClosedAt.Time
+	}
+	for _, l := range lines {
+		out.Lines = append(out.Lines, toPurchaseOrderLine(l))
+	}
+	return out
+}
+
+func toPurchaseOrderLine(l sqlcgen.PurchaseOrderLine) PurchaseOrderLine {
+	ordered, received := qty.FromNumeric(l.QtyOrdered), qty.FromNumeric(l.QtyReceived)
+	over, under := lineProgress(ordered, received)
+	return PurchaseOrderLine{
+		ID: l.ID.String(), LineNo: l.LineNo, ItemID: l.ItemID.String(),
+		QtyOrdered: qty.String(ordered), QtyReceived: qty.String(received), QtyOver: qty.String(over), QtyUnder: qty.String(under),
+	}
+}
+
+func toAsn(a sqlcgen.Asn, lines []sqlcgen.AsnLine, poLines []sqlcgen.PurchaseOrderLine) Asn {
+	out := Asn{ID: a.ID.String(), AsnNumber: a.AsnNumber, SupplierID: a.SupplierID.String(), PurchaseOrderID: a.PoID.String(), Status: a.Status, Lines: []AsnLine{}}
+	if a.ExpectedAt.Valid {
+		out.ExpectedAt = &a.ExpectedAt.Time
+	}
+	byID := make(map[pgtype.UUID]sqlcgen.PurchaseOrderLine, len(poLines))
+	for _, pl := range poLines {
+		byID[pl.ID] = pl
+	}
+	for _, l := range lines {
+		pl := byID[l.PoLineID]
+		out.Lines = append(out.Lines, AsnLine{LineNo: pl.LineNo, ItemID: pl.ItemID.String(), Qty: qty.String(qty.FromNumeric(l.Qty)), LotCode: l.LotCode.String, ExpiresOn: fmtDate(l.ExpiresOn)})
+	}
+	return out
+}
+
+func parseDate(v string) (pgtype.Date, error) {
+	if v == "" {
+		return pgtype.Date{}, nil
+	}
+	t, err := time.Parse("2006-01-02", v)
+	if err != nil {
+		return pgtype.Date{}, fmt.Errorf("%w: date %q must be YYYY-MM-DD", ErrInvalid, v)
+	}
+	return pgtype.Date{Time: t, Valid: true}, nil
+}
+
+func fmtDate(d pgtype.Date) string {
+	if !d.Valid {
+		return ""
+	}
+	return d.Time.Format("2006-01-02")
+}
@@ -0,0 +1,49 @@
+package service
+
+import (
+	"math/big"
+	"testing"
+
+	"erpwms/backend-go/internal/common/qty"
+	"erpwms/backend-go/internal/db/sqlcgen"
+)
+
+func TestLineProgress(t *testing.T) {
+	cases := []struct {
+		ordered, received, over, under int64
+	}{
+		{10, 0, 0, 10},
+		{10, 4, 0, 6},
+		{10, 10, 0, 0},
+		{10, 12, 2, 0},
+	}
+	for _, tc := range cases {
+		over, under := lineProgress(big.NewRat(tc.ordered, 1), big.NewRat(tc.received, 1))
+		if over.Cmp(big.NewRat(tc.over, 1)) != 0 || under.Cmp(big.NewRat(tc.under, 1)) != 0 {
+			t.Fatalf("%d/%d: got over %s under %s", tc.received, tc.ordered, over.RatString(), under.RatString())
+		}
+	}
+}
+
+func TestReceiptLimit(t *testing.T) {
+	if got := receiptLimit(big.NewRat(200, 1), big.NewRat(5, 1)); got.Cmp(big.NewRat(210, 1)) != 0 {
+		t.Fatalf("5%% of 200: got %s", got.RatString())
+	}
+	if got := receiptLimit(big.NewRat(7, 1), qty.Zero()); got.Cmp(big.NewRat(7, 1)) != 0 {
+		t.Fatalf("no tolerance: got %s", got.RatString())
+	}
+}
+
+func TestFullyReceived(t *testing.T) {
+	line := func(ordered, received string) sqlcgen.PurchaseOrderLine {
+		o, _ := qty.Parse(ordered)
+		r, _ := qty.Parse(received)
+		return sqlcgen.PurchaseOrderLine{QtyOrdered: qty.ToNumeric(o), QtyReceived: qty.ToNumeric(r)}
+	}
+	if fullyReceived([]sqlcgen.PurchaseOrderLine{line("5", "5"), line("2.5", "2.4")}) {
+		t.Fatal("short line counted as received")
+	}
+	if !fullyReceived([]sqlcgen.PurchaseOrderLine{line("5", "6"), line("2.5", "2.5")}) {
+		t.Fatal("over-received order not complete")
+	}
+}
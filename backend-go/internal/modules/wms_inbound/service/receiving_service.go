@@ -0,0 +1,218 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"erpwms/backend-go/internal/common/idempotency"
+	"erpwms/backend-go/internal/common/qty"
+	"erpwms/backend-go/internal/common/store"
+	"erpwms/backend-go/internal/db/sqlcgen"
+	stocksvc "erpwms/backend-go/internal/modules/wms_stock/service"
+	"github.com/google/uuid"
+)
+
+// RefReceipt is the ledger ref_type of stock booked by a goods receipt; the
+// ref_id is the goods_receipts id.
+const RefReceipt = "receipt"
+
+// DockLocationType is the location type receipts must be booked into.
+const DockLocationType = "dock"
+
+// ReceiptRequest books what arrived for a PO into a dock location. With AsnID
+// and no Lines the ASN is received as announced.
+type ReceiptRequest struct {
+	PurchaseOrderID string        `json:"purchase_order_id"`
+	AsnID           string        `json:"asn_id,omitempty"`
+	DockLocationID  string        `json:"dock_location_id"`
+	Lines           []ReceiptLine `json:"lines,omitempty"`
+}
+
+type ReceiptLine struct {
+	LineNo    int32  `json:"line_no"`
+	Qty       string `json:"qty"`
+	Uom       string `json:"uom,omitempty"`
+	LotCode   string `json:"lot_code,omitempty"`
+	SerialNo  string `json:"serial_no,omitempty"`
+	ExpiresOn string `json:"expires_on,omitempty"`
+}
+
+// ReceiptResponse carries the PO status after the receipt, which is closed
+// once every line is fully received.
+type ReceiptResponse struct {
+	ReceiptID       string         `json:"receipt_id"`
+	PurchaseOrderID string         `json:"purchase_order_id"`
+	Status          string         `json:"status"`
+	Lines           []ReceivedLine `json:"lines"`
+}
+
+// ReceivedLine is the PO line after the receipt plus what this receipt
+// booked on it, in the item's base unit.
+type ReceivedLine struct {
+	PurchaseOrderLine
+	Qty    string `json:"qty"`
+	MoveID string `json:"move_id"`
+}
+
+// Receive posts one goods receipt: a receipt move into the dock per line, the
+// received quantity on the PO line, and the PO status. Over-receipts beyond
+// the PO's tolerance are rejected; everything happens in one transaction.
+func (s InboundService) Receive(ctx context.Context, req ReceiptRequest, actor uuid.UUID, endpoint, idemKey string) (ReceiptResponse, error) {
+	reqHash := idempotency.Hash(req)
+	var prev ReceiptResponse
+	if found, err := idempotency.Replay(ctx, s.Queries, endpoint, idemKey, reqHash, &prev); err != nil || found {
+		return prev, err
+	}
+	poID, err := store.ParseUUID("purchase_order_id", req.PurchaseOrderID)
+	if err != nil {
+		return ReceiptResponse{}, err
+	}
+	dockID, err := store.ParseUUID("dock_location_id", req.DockLocationID)
+	if err != nil {
+		return ReceiptResponse{}, err
+	}
+	var asnID uuid.UUID
+	if req.AsnID != "" {
+		if asnID, err = store.ParseUUID("asn_id", req.AsnID); err != nil {
+			return ReceiptResponse{}, err
+		}
+	} else if len(req.Lines) == 0 {
+		return ReceiptResponse{}, fmt.Errorf("%w: lines or asn_id required", ErrInvalid)
+	}
+
+	tx, err := s.DB.Begin(ctx)
+	if err != nil {
+		return ReceiptResponse{}, err
+	}
+	defer tx.Rollback(ctx)
+	q := s.Queries.WithTx(tx)
+
+	// The PO lock serializes receipts of the same order, so the tolerance
+	// check below sees every earlier receipt.
+	po, err := q.LockPurchaseOrder(ctx, store.UUID(poID))
+	if err != nil {
+		return ReceiptResponse{}, store.MapErr(err, conflictFields)
+	}
+	if po.Status != StatusOpen && po.Status != StatusReceiving {
+		return ReceiptResponse{}, fmt.Errorf("%w: purchase order is %s", ErrInvalid, po.Status)
+	}
+	dock, err := q.GetLocation(ctx, store.UUID(dockID))
+	if err != nil {
+		return ReceiptResponse{}, store.MapErr(err, conflictFields)
+	}
+	if !dock.Active || dock.Type != DockLocationType || dock.WarehouseID != po.WarehouseID {
+		return ReceiptResponse{}, fmt.Errorf("%w: dock_location_id must be an active %s location of the PO's warehouse", ErrInvalid, DockLocationType)
+	}
+	poLines, err := q.ListPurchaseOrderLines(ctx, po.ID)
+	if err != nil {
+		return ReceiptResponse{}, err
+	}
+	byNo := linesByNo(poLines)
+
+	lines := req.Lines
+	if asnID != uuid.Nil {
+		asn, err := q.GetAsn(ctx, store.UUID(asnID))
+		if err != nil {
+			return ReceiptResponse{}, store.MapErr(err, conflictFields)
+		}
+		if asn.PoID != po.ID || asn.Status != StatusOpen {
+			return ReceiptResponse{}, fmt.Errorf("%w: asn is %s or belongs to another purchase order", ErrInvalid, asn.Status)
+		}
+		if len(lines) == 0 {
+			if lines, err = asnReceiptLines(ctx, q, asn, poLines); err != nil {
+				return ReceiptResponse{}, err
+			}
+		}
+		if _, err := q.SetAsnStatus(ctx, sqlcgen.SetAsnStatusParams{ID: asn.ID, Status: StatusReceived}); err != nil {
+			return ReceiptResponse{}, err
+		}
+	}
+
+	requestID, _ := ctx.Value("request_id").(string)
+	receipt, err := q.InsertGoodsReceipt(ctx, sqlcgen.InsertGoodsReceiptParams{PoID: po.ID, AsnID: store.UUID(asnID), DockLocationID: dock.ID, ReceivedBy: store.UUID(actor), RequestID: store.Text(requestID)})
+	if err != nil {
+		return ReceiptResponse{}, err
+	}
+
+	pct := qty.FromNumeric(po.OverReceiptPct)
+	resp := ReceiptResponse{ReceiptID: receipt.ID.String(), PurchaseOrderID: po.ID.String()}
+	for i, l := range lines {
+		pl, ok := byNo[l.LineNo]
+		if !ok {
+			return ReceiptResponse{}, fmt.Errorf("%w: line %d: po has no line_no %d", ErrInvalid, i+1, l.LineNo)
+		}
+		move, base, err := s.Stock.PostMove(ctx, q, stocksvc.MoveReceipt, stocksvc.MoveRequest{
+			ItemID: pl.ItemID.String(), Qty: l.Qty, Uom: l.Uom, ToLocationID: dock.ID.String(), ReasonCode: "RECEIPT",
+			LotCode: l.LotCode, SerialNo: l.SerialNo, ExpiresOn: l.ExpiresOn, RefType: RefReceipt, RefID: receipt.ID.String(),
+		}, actor)
+		if err != nil {
+			return ReceiptResponse{}, fmt.Errorf("line %d: %w", i+1, err)
+		}
+		received := qty.Add(qty.FromNumeric(pl.QtyReceived), base)
+		if limit := receiptLimit(qty.FromNumeric(pl.QtyOrdered), pct); received.Cmp(limit) > 0 {
+			return ReceiptResponse{}, fmt.Errorf("%w: line %d: %s received exceeds the limit of %s", ErrInvalid, i+1, qty.String(received), qty.String(limit))
+		}
+		if pl, err = q.AddPurchaseOrderLineReceived(ctx, sqlcgen.AddPurchaseOrderLineReceivedParams{ID: pl.ID, QtyReceived: qty.ToNumeric(base)}); err != nil {
+			return ReceiptResponse{}, err
+		}
+		byNo[pl.LineNo] = pl
+		if _, err := q.InsertGoodsReceiptLine(ctx, sqlcgen.InsertGoodsReceiptLineParams{ReceiptID: receipt.ID, PoLineID: pl.ID, Qty: qty.ToNumeric(base), LotCode: store.Text(l.LotCode), SerialNo: store.Text(l.SerialNo), MoveID: move.MoveID}); err != nil {
+			return ReceiptResponse{}, err
+		}
+		resp.Lines = append(resp.Lines, ReceivedLine{PurchaseOrderLine: toPurchaseOrderLine(pl), Qty: qty.String(base), MoveID: move.MoveID.String()})
+	}
+
+	current := make([]sqlcgen.PurchaseOrderLine, 0, len(poLines))
+	for _, l := range poLines {
+		current = append(current, byNo[l.LineNo])
+	}
+	status := StatusReceiving
+	if fullyReceived(current) {
+		status = StatusClosed
+	}
+	if po, err = q.SetPurchaseOrderStatus(ctx, sqlcgen.SetPurchaseOrderStatusParams{ID: po.ID, Status: status}); err != nil {
+		return ReceiptResponse{}, err
+	}
+	resp.Status = po.Status
+
+	payload, _ := json.Marshal(map[string]any{
+		"receipt_id": resp.ReceiptID, "purchase_order_id": resp.PurchaseOrderID, "po_number": po.PoNumber, "asn_id": req.AsnID,
+		"dock_location_id": dock.ID.String(), "status": resp.Status, "lines": resp.Lines,
+	})
+	if err := store.Record(ctx, q, actor, "inbound.received", "goods_receipts", resp.ReceiptID, payload); err != nil {
+		return ReceiptResponse{}, err
+	}
+	if status == StatusClosed {
+		closed, _ := json.Marshal(toPurchaseOrder(po, current))
+		if err := store.Record(ctx, q, actor, "purchase_order.closed", "purchase_orders", resp.PurchaseOrderID, closed); err != nil {
+			return ReceiptResponse{}, err
+		}
+	}
+
+	if err := idempotency.Remember(ctx, q, endpoint, idemKey, store.UUID(actor), reqHash, resp); err != nil {
+		return ReceiptResponse{}, err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return ReceiptResponse{}, err
+	}
+	return resp, nil
+}
+
+// asnReceiptLines turns the announced ASN lines into receipt lines. ASN
+// quantities are stored in the base unit.
+func asnReceiptLines(ctx context.Context, q *sqlcgen.Queries, asn sqlcgen.Asn, poLines []sqlcgen.PurchaseOrderLine) ([]ReceiptLine, error) {
+	rows, err := q.ListAsnLines(ctx, asn.ID)
+	if err != nil {
+		return nil, err
+	}
+	lineNo := make(map[[16]byte]int32, len(poLines))
+	for _, pl := range poLines {
+		lineNo[pl.ID.Bytes] = pl.LineNo
+	}
+	out := make([]ReceiptLine, 0, len(rows))
+	for _, r := range rows {
+		out = append(out, ReceiptLine{LineNo: lineNo[r.PoLineID.Bytes], Qty: qty.String(qty.FromNumeric(r.Qty)), LotCode: r.LotCode.String, ExpiresOn: fmtDate(r.ExpiresOn)})
+	}
+	return out, nil
+}
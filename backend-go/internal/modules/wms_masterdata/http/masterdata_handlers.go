@@ -0,0 +1,580 @@
+package http
+
+import (
+	"errors"
+	"strconv"
+
+	"erpwms/backend-go/internal/common/httperr"
+	"erpwms/backend-go/internal/modules/wms_masterdata/service"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type MasterdataHandlers struct {
+	Service service.MasterdataService
+}
+
+func (h MasterdataHandlers) ListWarehouses(c *gin.Context) {
+	rows, err := h.Service.ListWarehouses(c.Request.Context(), listFilter(c))
+	if err != nil {
+		writeErr(c, err)
+		return
+	}
+	c.JSON(200, gin.H{"items": rows})
+}
+
+func (h MasterdataHandlers) GetWarehouse(c *gin.Context) {
+	id, ok := paramID(c)
+	if !ok {
+		return
+	}
+	w, err := h.Service.GetWarehouse(c.Request.Context(), id)
+	if err != nil {
+		writeErr(c, err)
+		return
+	}
+	c.JSON(200, w)
+}
+
+func (h MasterdataHandlers) CreateWarehouse(c *gin.Context) {
+	var in service.WarehouseInput
+	if err := c.ShouldBindJSON(&in); err != nil {
+		c.JSON(400, gin.H{"error": "bad request"})
+		return
+	}
+	actor, ok := actorID(c)
+	if !ok {
+		return
+	}
+	w, err := h.Service.CreateWarehouse(c.Request.Context(), in, actor)
+	if err != nil {
+		writeErr(c, err)
+		return
+	}
+	c.JSON(201, w)
+}
+
+func (h MasterdataHandlers) UpdateWarehouse(c *gin.Context) {
+	id, ok := paramID(c)
+	if !ok {
+		return
+	}
+	var in service.WarehouseInput
+	if err := c.ShouldBindJSON(&in); err != nil {
+		c.JSON(400, gin.H{"error": "bad request"})
+		return
+	}
+	actor, ok := actorID(c)
+	if !ok {
+		return
+	}
+	w, err := h.Service.UpdateWarehouse(c.Request.Context(), id, in, actor)
+	if err != nil {
+		writeErr(c, err)
+		return
+	}
+	c.JSON(200, w)
+}
+
+func (h MasterdataHandlers) DeactivateWarehouse(c *gin.Context) {
+	id, ok := paramID(c)
+	if !ok {
+		return
+	}
+	actor, ok := actorID(c)
+	if !ok {
+		return
+	}
+	w, err := h.Service.DeactivateWarehouse(c.Request.Context(), id, actor)
+	if err != nil {
+		writeErr(c, err)
+		return
+	}
+	c.JSON(200, w)
+}
+
+func (h MasterdataHandlers) ListLocations(c *gin.Context) {
+	rows, err := h.Service.ListLocations(c.Request.Context(), listFilter(c))
+	if err != nil {
+		writeErr(c, err)
+		return
+	}
+	c.JSON(200, gin.H{"items": rows})
+}
+
+func (h MasterdataHandlers) GetLocation(c *gin.Context) {
+	id, ok := paramID(c)
+	if !ok {
+		return
+	}
+	l, err := h.Service.GetLocation(c.Request.Context(), id)
+	if err != nil {
+		writeErr(c, err)
+		return
+	}
+	c.JSON(200, l)
+}
+
+func (h MasterdataHandlers) CreateLocation(c *gin.Context) {
+	var in service.LocationInput
+	if err := c.ShouldBindJSON(&in); err != nil {
+		c.JSON(400, gin.H{"error": "bad request"})
+		return
+	}
+	actor, ok := actorID(c)
+	if !ok {
+		return
+	}
+	l, err := h.Service.CreateLocation(c.Request.Context(), in, actor)
+	if err != nil {
+		writeErr(c, err)
+		return
+	}
+	c.JSON(201, l)
+}
+
+func (h MasterdataHandlers) UpdateLocation(c *gin.Context) {
+	id, ok := paramID(c)
+	if !ok {
+		return
+	}
+	var in service.LocationInput
+	if err := c.ShouldBindJSON(&in); err != nil {
+		c.JSON(400, gin.H{"error": "bad request"})
+		return
+	}
+	actor, ok := actorID(c)
+	if !ok {
+		return
+	}
+	l, err := h.Service.UpdateLocation(c.Request.Context(), id, in, actor)
+	if err != nil {
+		writeErr(c, err)
+		return
+	}
+	c.JSON(200, l)
+}
+
+func (h MasterdataHandlers) DeactivateLocation(c *gin.Context) {
+	id, ok := paramID(c)
+	if !ok {
+		return
+	}
+	actor, ok := actorID(c)
+	if !ok {
+		return
+	}
+	l, err := h.Service.DeactivateLocation(c.Request.Context(), id, actor)
+	if err != nil {
+		writeErr(c, err)
+		return
+	}
+	c.JSON(200, l)
+}
+
+func (h MasterdataHandlers) ListLocationTypePolicies(c *gin.Context) {
+	rows, err := h.Service.ListLocationTypePolicies(c.Request.Context())
+	if err != nil {
+		writeErr(c, err)
+		return
+	}
+	c.JSON(200, gin.H{"items": rows})
+}
+
+func (h MasterdataHandlers) SetLocationTypePolicy(c *gin.Context) {
+	var in service.LocationTypePolicy
+	if err := c.ShouldBindJSON(&in); err != nil {
+		c.JSON(400, gin.H{"error": "bad request"})
+		return
+	}
+	in.Type = c.Param("type")
+	actor, ok := actorID(c)
+	if !ok {
+		return
+	}
+	p, err := h.Service.SetLocationTypePolicy(c.Request.Context(), in, actor)
+	if err != nil {
+		writeErr(c, err)
+		return
+	}
+	c.JSON(200, p)
+}
+
+func (h MasterdataHandlers) ListItems(c *gin.Context) {
+	rows, err := h.Service.ListItems(c.Request.Context(), listFilter(c))
+	if err != nil {
+		writeErr(c, err)
+		return
+	}
+	c.JSON(200, gin.H{"items": rows})
+}
+
+func (h MasterdataHandlers) GetItem(c *gin.Context) {
+	id, ok := paramID(c)
+	if !ok {
+		return
+	}
+	i, err := h.Service.GetItem(c.Request.Context(), id)
+	if err != nil {
+		writeErr(c, err)
+		return
+	}
+	c.JSON(200, i)
+}
+
+func (h MasterdataHandlers) CreateItem(c *gin.Context) {
+	var in service.ItemInput
+	if err := c.ShouldBindJSON(&in); err != nil {
+		c.JSON(400, gin.H{"error": "bad request"})
+		return
+	}
+	actor, ok := actorID(c)
+	if !ok {
+		return
+	}
+	i, err := h.Service.CreateItem(c.Request.Context(), in, actor)
+	if err != nil {
+		writeErr(c, err)
+		return
+	}
+	c.JSON(201, i)
+}
+
+func (h MasterdataHandlers) UpdateItem(c *gin.Context) {
+	id, ok := paramID(c)
+	if !ok {
+		return
+	}
+	var in service.ItemInput
+	if err := c.ShouldBindJSON(&in); err != nil {
+		c.JSON(400, gin.H{"error": "bad request"})
+		return
+	}
+	actor, ok := actorID(c)
+	if !ok {
+		return
+	}
+	i, err := h.Service.UpdateItem(c.Request.Context(), id, in, actor)
+	if err != nil {
+		writeErr(c, err)
+		return
+	}
+	c.JSON(200, i)
+}
+
+func (h MasterdataHandlers) DeactivateItem(c *gin.Context) {
+	id, ok := paramID(c)
+	if !ok {
+		return
+	}
+	actor, ok := actorID(c)
+	if !ok {
+		return
+	}
+	i, err := h.Service.DeactivateItem(c.Request.Context(), id, actor)
+	if err != nil {
+		writeErr(c, err)
+		return
+	}
+	c.JSON(200, i)
+}
+
+func (h MasterdataHandlers) ListLots(c *gin.Context) {
+	id, ok := paramID(c)
+	if !ok {
+		return
+	}
+	rows, err := h.Service.ListLots(c.Request.Context(), id)
+	if err != nil {
+		writeErr(c, err)
+		return
+	}
+	c.JSON(200, gin.H{"items": rows})
+}
+
+func (h MasterdataHandlers) CreateLot(c *gin.Context) {
+	id, ok := paramID(c)
+	if !ok {
+		return
+	}
+	var in service.LotInput
+	if err := c.ShouldBindJSON(&in); err != nil {
+		c.JSON(400, gin.H{"error": "bad request"})
+		return
+	}
+	actor, ok := actorID(c)
+	if !ok {
+		return
+	}
+	l, err := h.Service.CreateLot(c.Request.Context(), id, in, actor)
+	if err != nil {
+		writeErr(c, err)
+		return
+	}
+	c.JSON(201, l)
+}
+
+func (h MasterdataHandlers) ListSerials(c *gin.Context) {
+	id, ok := paramID(c)
+	if !ok {
+		return
+	}
+	rows, err := h.Service.ListSerials(c.Request.Context(), id, listFilter(c))
+	if err != nil {
+		writeErr(c, err)
+		return
+	}
+	c.JSON(200, gin.H{"items": rows})
+}
+
+func (h MasterdataHandlers) RegisterSerial(c *gin.Context) {
+	id, ok := paramID(c)
+	if !ok {
+		return
+	}
+	var in service.SerialInput
+	if err := c.ShouldBindJSON(&in); err != nil {
+		c.JSON(400, gin.H{"error": "bad request"})
+		return
+	}
+	actor, ok := actorID(c)
+	if !ok {
+		return
+	}
+	sr, err := h.Service.RegisterSerial(c.Request.Context(), id, in, actor)
+	if err != nil {
+		writeErr(c, err)
+		return
+	}
+	c.JSON(201, sr)
+}
+
+func (h MasterdataHandlers) ListUoms(c *gin.Context) {
+	rows, err := h.Service.ListUoms(c.Request.Context())
+	if err != nil {
+		writeErr(c, err)
+		return
+	}
+	c.JSON(200, gin.H{"items": rows})
+}
+
+func (h MasterdataHandlers) SaveUom(c *gin.Context) {
+	var in service.Uom
+	if err := c.ShouldBindJSON(&in); err != nil {
+		c.JSON(400, gin.H{"error": "bad request"})
+		return
+	}
+	in.Code = c.Param("code")
+	actor, ok := actorID(c)
+	if !ok {
+		return
+	}
+	u, err := h.Service.SaveUom(c.Request.Context(), in, actor)
+	if err != nil {
+		writeErr(c, err)
+		return
+	}
+	c.JSON(200, u)
+}
+
+func (h MasterdataHandlers) ListItemUoms(c *gin.Context) {
+	id, ok := paramID(c)
+	if !ok {
+		return
+	}
+	rows, err := h.Service.ListItemUoms(c.Request.Context(), id)
+	if err != nil {
+		writeErr(c, err)
+		return
+	}
+	c.JSON(200, gin.H{"items": rows})
+}
+
+func (h MasterdataHandlers) SetItemUom(c *gin.Context) {
+	id, ok := paramID(c)
+	if !ok {
+		return
+	}
+	var in struct {
+		Factor string `json:"factor"`
+	}
+	if err := c.ShouldBindJSON(&in); err != nil {
+		c.JSON(400, gin.H{"error": "bad request"})
+		return
+	}
+	actor, ok := actorID(c)
+	if !ok {
+		return
+	}
+	u, err := h.Service.SetItemUom(c.Request.Context(), id, c.Param("uom"), in.Factor, actor)
+	if err != nil {
+		writeErr(c, err)
+		return
+	}
+	c.JSON(200, u)
+}
+
+func (h MasterdataHandlers) DeleteItemUom(c *gin.Context) {
+	id, ok := paramID(c)
+	if !ok {
+		return
+	}
+	actor, ok := actorID(c)
+	if !ok {
+		return
+	}
+	if err := h.Service.DeleteItemUom(c.Request.Context(), id, c.Param("uom"), actor); err != nil {
+		writeErr(c, err)
+		return
+	}
+	c.Status(204)
+}
+
+func (h MasterdataHandlers) ListItemBarcodes(c *gin.Context) {
+	id, ok := paramID(c)
+	if !ok {
+		return
+	}
+	rows, err := h.Service.ListItemBarcodes(c.Request.Context(), id)
+	if err != nil {
+		writeErr(c, err)
+		return
+	}
+	c.JSON(200, gin.H{"items": rows})
+}
+
+func (h MasterdataHandlers) AddItemBarcode(c *gin.Context) {
+	id, ok := paramID(c)
+	if !ok {
+		return
+	}
+	var in service.ItemBarcodeInput
+	if err := c.ShouldBindJSON(&in); err != nil {
+		c.JSON(400, gin.H{"error": "bad request"})
+		return
+	}
+	actor, ok := actorID(c)
+	if !ok {
+		return
+	}
+	b, err := h.Service.AddItemBarcode(c.Request.Context(), id, in, actor)
+	if err != nil {
+		writeErr(c, err)
+		return
+	}
+	c.JSON(201, b)
+}
+
+func (h MasterdataHandlers) DeleteItemBarcode(c *gin.Context) {
+	id, ok := paramID(c)
+	if !ok {
+		return
+	}
+	actor, ok := actorID(c)
+	if !ok {
+		return
+	}
+	if err := h.Service.DeleteItemBarcode(c.Request.Context(), id, c.Param("barcode"), actor); err != nil {
+		writeErr(c, err)
+		return
+	}
+	c.Status(204)
+}
+
+func (h MasterdataHandlers) ListReasonCodes(c *gin.Context) {
+	rows, err := h.Service.ListReasonCodes(c.Request.Context(), c.Query("move_type"), c.Query("include_inactive") == "true")
+	if err != nil {
+		writeErr(c, err)
+		return
+	}
+	c.JSON(200, gin.H{"items": rows})
+}
+
+func (h MasterdataHandlers) CreateReasonCode(c *gin.Context) {
+	var in service.ReasonCodeInput
+	if err := c.ShouldBindJSON(&in); err != nil {
+		c.JSON(400, gin.H{"error": "bad request"})
+		return
+	}
+	actor, ok := actorID(c)
+	if !ok {
+		return
+	}
+	rc, err := h.Service.CreateReasonCode(c.Request.Context(), in, actor)
+	if err != nil {
+		writeErr(c, err)
+		return
+	}
+	c.JSON(201, rc)
+}
+
+func (h MasterdataHandlers) UpdateReasonCode(c *gin.Context) {
+	var in service.ReasonCodeInput
+	if err := c.ShouldBindJSON(&in); err != nil {
+		c.JSON(400, gin.H{"error": "bad request"})
+		return
+	}
+	actor, ok := actorID(c)
+	if !ok {
+		return
+	}
+	rc, err := h.Service.UpdateReasonCode(c.Request.Context(), c.Param("code"), in, actor)
+	if err != nil {
+		writeErr(c, err)
+		return
+	}
+	c.JSON(200, rc)
+}
+
+func (h MasterdataHandlers) DeactivateReasonCode(c *gin.Context) {
+	actor, ok := actorID(c)
+	if !ok {
+		return
+	}
+	if err := h.Service.DeactivateReasonCode(c.Request.Context(), c.Param("code"), actor); err != nil {
+		writeErr(c, err)
+		return
+	}
+	c.Status(204)
+}
+
+func listFilter(c *gin.Context) service.ListFilter {
+	limit, _ := strconv.ParseInt(c.DefaultQuery("limit", "50"), 10, 32)
+	offset, _ := strconv.ParseInt(c.DefaultQuery("offset", "0"), 10, 32)
+	return service.ListFilter{
+		Q:               c.Query("q"),
+		WarehouseID:     c.Query("warehouse_id"),
+		Type:            c.Query("type"),
+		Path:            c.Query("path"),
+		IncludeInactive: c.Query("include_inactive") == "true",
+		Limit:           int32(limit),
+		Offset:          int32(offset),
+	}
+}
+
+func paramID(c *gin.Context) (uuid.UUID, bool) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(400, gin.H{"error": "invalid id"})
+		return uuid.Nil, false
+	}
+	return id, true
+}
+
+func actorID(c *gin.Context) (uuid.UUID, bool) {
+	uid, err := uuid.Parse(c.GetString("user_id"))
+	if err != nil || uid == uuid.Nil {
+		c.JSON(401, gin.H{"error": "unauthorized"})
+		return uuid.Nil, false
+	}
+	return uid, true
+}
+
+// writeErr is httperr.Write plus ErrInUse, a 409.
+func writeErr(c *gin.Context, err error) {
+	if errors.Is(err, service.ErrInUse) {
+		c.JSON(409, gin.H{"error": err.Error()})
+		return
+	}
+	httperr.Write(c, err)
+}
@@ -0,0 +1,166 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"erpwms/backend-go/internal/common/gs1"
+	"erpwms/backend-go/internal/common/store"
+	"erpwms/backend-go/internal/db/sqlcgen"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+// Barcode types. EAN covers GTIN-8, -12 and -13.
+const (
+	BarcodeEAN      = "ean"
+	BarcodeGTIN14   = "gtin14"
+	BarcodeInternal = "internal"
+)
+
+// ItemBarcode is one code printed on an item in a given unit, e.g. the EAN on
+// the piece in EA and a GTIN-14 on the carton in CT. GTIN is the GTIN-14 form
+// scans of (01) are matched against.
+type ItemBarcode struct {
+	Barcode string `json:"barcode"`
+	GTIN    string `json:"gtin,omitempty"`
+	ItemID  string `json:"item_id"`
+	Type    string `json:"barcode_type"`
+	Uom     string `json:"uom"`
+}
+
+// ItemBarcodeInput leaves Type to be derived from the code and Uom to default
+// to the item's base unit.
+type ItemBarcodeInput struct {
+	Barcode string `json:"barcode"`
+	Type    string `json:"barcode_type"`
+	Uom     string `json:"uom"`
+}
+
+func (s MasterdataService) ListItemBarcodes(ctx context.Context, itemID uuid.UUID) ([]ItemBarcode, error) {
+	rows, err := s.Queries.ListItemBarcodes(ctx, store.UUID(itemID))
+	if err != nil {
+		return nil, err
+	}
+	out := make([]ItemBarcode, 0, len(rows))
+	for _, r := range rows {
+		out = append(out, toItemBarcode(r))
+	}
+	return out, nil
+}
+
+func (s MasterdataService) AddItemBarcode(ctx context.Context, itemID uuid.UUID, in ItemBarcodeInput, actor uuid.UUID) (ItemBarcode, error) {
+	code := strings.TrimSpace(in.Barcode)
+	typ, gtin, err := classifyBarcode(code, in.Type)
+	if err != nil {
+		return ItemBarcode{}, err
+	}
+	var out ItemBarcode
+	err = s.mutate(ctx, actor, "item_barcode.created", "item_barcodes", func(q *sqlcgen.Queries) (string, any, error) {
+		item, err := q.GetItem(ctx, store.UUID(itemID))
+		if err != nil {
+			return "", nil, err
+		}
+		uom := in.Uom
+		if uom == "" {
+			uom = item.Uom
+		}
+		if uom != item.Uom {
+			conv, err := q.GetUomConversion(ctx, sqlcgen.GetUomConversionParams{Uom: uom, ItemID: item.ID})
+			if errors.Is(err, pgx.ErrNoRows) {
+				return "", nil, fmt.Errorf("%w: unknown uom %q", ErrInvalid, uom)
+			}
+			if err != nil {
+				return "", nil, err
+			}
+			if !conv.Factor.Valid {
+				return "", nil, fmt.Errorf("%w: no conversion from %s to %s for this item", ErrInvalid, uom, item.Uom)
+			}
+		}
+		b, err := q.CreateItemBarcode(ctx, sqlcgen.CreateItemBarcodeParams{Barcode: code, Gtin: store.Text(gtin), ItemID: item.ID, BarcodeType: typ, Uom: uom})
+		if err != nil {
+			return "", nil, err
+		}
+		out = toItemBarcode(b)
+		return out.ItemID, out, nil
+	})
+	return out, err
+}
+
+func (s MasterdataService) DeleteItemBarcode(ctx context.Context, itemID uuid.UUID, barcode string, actor uuid.UUID) error {
+	return s.mutate(ctx, actor, "item_barcode.deleted", "item_barcodes", func(q *sqlcgen.Queries) (string, any, error) {
+		n, err := q.DeleteItemBarcode(ctx, sqlcgen.DeleteItemBarcodeParams{ItemID: store.UUID(itemID), Barcode: barcode})
+		if err != nil {
+			return "", nil, err
+		}
+		if n == 0 {
+			return "", nil, ErrNotFound
+		}
+		return itemID.String(), ItemBarcode{ItemID: itemID.String(), Barcode: barcode}, nil
+	})
+}
+
+// registerPrimaryBarcode keeps items.barcode resolvable by scans: it is
+// registered in the base unit unless the item already has it.
+func registerPrimaryBarcode(ctx context.Context, q *sqlcgen.Queries, item sqlcgen.Item) error {
+	code := item.Barcode.String
+	if code == "" {
+		return nil
+	}
+	typ, gtin, err := classifyBarcode(code, "")
+	if err != nil {
+		return err
+	}
+	cur, err := q.GetItemBarcode(ctx, sqlcgen.GetItemBarcodeParams{Barcode: code, Gtin: gtin})
+	switch {
+	case err == nil && cur.ItemID == item.ID:
+		return nil
+	case err == nil:
+		return fmt.Errorf("%w: barcode %s belongs to another item", ErrConflict, code)
+	case !errors.Is(err, pgx.ErrNoRows):
+		return err
+	}
+	_, err = q.CreateItemBarcode(ctx, sqlcgen.CreateItemBarcodeParams{Barcode: code, Gtin: store.Text(gtin), ItemID: item.ID, BarcodeType: typ, Uom: item.Uom})
+	return err
+}
+
+// classifyBarcode derives the type of code when typ is empty and checks an
+// explicit one: EAN and GTIN-14 need a valid GS1 check digit and come back
+// with their GTIN-14 form.
+func classifyBarcode(code, typ string) (string, string, error) {
+	if code == "" || strings.ContainsFunc(code, func(r rune) bool { return r < '!' || r > '~' }) {
+		return "", "", fmt.Errorf("%w: barcode must be printable characters without spaces", ErrInvalid)
+	}
+	gtin, isGTIN := gs1.GTIN14(code)
+	switch typ {
+	case "":
+		switch {
+		case !isGTIN:
+			return BarcodeInternal, "", nil
+		case len(code) == 14:
+			return BarcodeGTIN14, gtin, nil
+		default:
+			return BarcodeEAN, gtin, nil
+		}
+	case BarcodeEAN:
+		if !isGTIN || len(code) == 14 {
+			return "", "", fmt.Errorf("%w: %s is not a valid EAN-8, UPC-A or EAN-13", ErrInvalid, code)
+		}
+		return typ, gtin, nil
+	case BarcodeGTIN14:
+		if !isGTIN || len(code) != 14 {
+			return "", "", fmt.Errorf("%w: %s is not a valid GTIN-14", ErrInvalid, code)
+		}
+		return typ, gtin, nil
+	case BarcodeInternal:
+		// Internal codes never match a (01), even when they look like one.
+		return typ, "", nil
+	}
+	return "", "", fmt.Errorf("%w: barcode_type must be ean, gtin14 or internal", ErrInvalid)
+}
+
+func toItemBarcode(b sqlcgen.ItemBarcode) ItemBarcode {
+	return ItemBarcode{Barcode: b.Barcode, GTIN: b.Gtin.String, ItemID: b.ItemID.String(), Type: b.BarcodeType, Uom: b.Uom}
+}
@@ -0,0 +1,754 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"erpwms/backend-go/internal/common/locpath"
+	"erpwms/backend-go/internal/common/qty"
+	"erpwms/backend-go/internal/common/store"
+	"erpwms/backend-go/internal/db/sqlcgen"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+var (
+	ErrNotFound = store.ErrNotFound
+	ErrConflict = store.ErrConflict
+	ErrInvalid  = store.ErrInvalid
+	ErrInUse    = errors.New("still holds stock")
+)
+
+// conflictFields names the field behind each unique constraint so clients get
+// a usable message instead of the raw constraint name.
+var conflictFields = map[string]string{
+	"warehouses_code_key":             "warehouse code",
+	"locations_warehouse_id_code_key": "location code in warehouse",
+	"items_sku_key":                   "sku",
+	"lots_item_id_lot_code_key":       "lot code for item",
+	"serials_item_id_serial_no_key":   "serial number for item",
+	"reason_codes_pkey":               "reason code",
+	"item_barcodes_pkey":              "barcode",
+	"item_barcodes_gtin_key":          "gtin (the same GTIN in another length)",
+}
+
+type MasterdataService struct {
+	DB      *pgxpool.Pool
+	Queries *sqlcgen.Queries
+}
+
+type WarehouseInput struct {
+	Code               string `json:"code"`
+	Name               string `json:"name"`
+	AllowNegativeStock bool   `json:"allow_negative_stock"`
+}
+
+type Warehouse struct {
+	ID                 string `json:"id"`
+	Code               string `json:"code"`
+	Name               string `json:"name"`
+	AllowNegativeStock bool   `json:"allow_negative_stock"`
+	Active             bool   `json:"active"`
+}
+
+type LocationTypePolicy struct {
+	Type               string `json:"type"`
+	AllowNegativeStock bool   `json:"allow_negative_stock"`
+}
+
+// LocationInput limits are optional: an empty or null limit is unlimited.
+// MaxHU counts top-level handling units, so 0 keeps pallets out of a shelf
+// bin. MixedLots defaults to true.
+type LocationInput struct {
+	WarehouseID string `json:"warehouse_id"`
+	Code        string `json:"code"`
+	Type        string `json:"type"`
+	Path        string `json:"path"`
+	Zone        string `json:"zone"`
+	MaxWeightKg string `json:"max_weight_kg"`
+	MaxVolumeM3 string `json:"max_volume_m3"`
+	MaxHU       *int32 `json:"max_hu"`
+	MaxSkus     *int32 `json:"max_skus"`
+	MixedLots   *bool  `json:"mixed_lots"`
+}
+
+type Location struct {
+	ID          string `json:"id"`
+	WarehouseID string `json:"warehouse_id"`
+	Code        string `json:"code"`
+	Type        string `json:"type"`
+	Path        string `json:"path,omitempty"`
+	Zone        string `json:"zone,omitempty"`
+	MaxWeightKg string `json:"max_weight_kg,omitempty"`
+	MaxVolumeM3 string `json:"max_volume_m3,omitempty"`
+	MaxHU       *int32 `json:"max_hu,omitempty"`
+	MaxSkus     *int32 `json:"max_skus,omitempty"`
+	MixedLots   bool   `json:"mixed_lots"`
+	Active      bool   `json:"active"`
+	FrozenBy    string `json:"frozen_by_count_id,omitempty"`
+}
+
+// locationLimits is LocationInput's capacity in column types.
+type locationLimits struct {
+	weight, volume pgtype.Numeric
+	hu, skus       pgtype.Int4
+	mixedLots      bool
+}
+
+// ItemInput weight and dimensions are per base unit and optional.
+type ItemInput struct {
+	Sku          string `json:"sku"`
+	Name         string `json:"name"`
+	Barcode      string `json:"barcode"`
+	Uom          string `json:"uom"`
+	TrackingMode string `json:"tracking_mode"`
+	ItemClass    string `json:"item_class"`
+	AbcClass     string `json:"abc_class"`
+	WeightKg     string `json:"weight_kg"`
+	LengthCm     string `json:"length_cm"`
+	WidthCm      string `json:"width_cm"`
+	HeightCm     string `json:"height_cm"`
+}
+
+type Item struct {
+	ID           string `json:"id"`
+	Sku          string `json:"sku"`
+	Name         string `json:"name"`
+	Barcode      string `json:"barcode,omitempty"`
+	Uom          string `json:"uom"`
+	TrackingMode string `json:"tracking_mode"`
+	ItemClass    string `json:"item_class,omitempty"`
+	AbcClass     string `json:"abc_class,omitempty"`
+	WeightKg     string `json:"weight_kg,omitempty"`
+	LengthCm     string `json:"length_cm,omitempty"`
+	WidthCm      string `json:"width_cm,omitempty"`
+	HeightCm     string `json:"height_cm,omitempty"`
+	Active       bool   `json:"active"`
+}
+
+// itemMeasures is ItemInput's weight and dimensions in column types.
+type itemMeasures struct {
+	weight, length, width, height pgtype.Numeric
+}
+
+// LotInput dates use YYYY-MM-DD.
+type LotInput struct {
+	LotCode        string `json:"lot_code"`
+	ManufacturedOn string `json:"manufactured_on"`
+	ExpiresOn      string `json:"expires_on"`
+}
+
+type Lot struct {
+	ID             string `json:"id"`
+	ItemID         string `json:"item_id"`
+	LotCode        string `json:"lot_code"`
+	ManufacturedOn string `json:"manufactured_on,omitempty"`
+	ExpiresOn      string `json:"expires_on,omitempty"`
+}
+
+type SerialInput struct {
+	SerialNo string `json:"serial_no"`
+	LotCode  string `json:"lot_code"`
+}
+
+type Serial struct {
+	ID         string `json:"id"`
+	ItemID     string `json:"item_id"`
+	SerialNo   string `json:"serial_no"`
+	LotID      string `json:"lot_id,omitempty"`
+	LocationID string `json:"location_id,omitempty"`
+}
+
+type ListFilter struct {
+	Q               string
+	WarehouseID     string
+	Type            string
+	Path            string
+	IncludeInactive bool
+	Limit           int32
+	Offset          int32
+}
+
+func (s MasterdataService) ListWarehouses(ctx context.Context, f ListFilter) ([]Warehouse, error) {
+	rows, err := s.Queries.ListWarehouses(ctx, sqlcgen.ListWarehousesParams{IncludeInactive: f.IncludeInactive, PageLimit: f.Limit, PageOffset: f.Offset})
+	if err != nil {
+		return nil, err
+	}
+	out := make([]Warehouse, 0, len(rows))
+	for _, r := range rows {
+		out = append(out, toWarehouse(r))
+	}
+	return out, nil
+}
+
+func (s MasterdataService) GetWarehouse(ctx context.Context, id uuid.UUID) (Warehouse, error) {
+	w, err := s.Queries.GetWarehouse(ctx, store.UUID(id))
+	if err != nil {
+		return Warehouse{}, store.MapErr(err, conflictFields)
+	}
+	return toWarehouse(w), nil
+}
+
+func (s MasterdataService) CreateWarehouse(ctx context.Context, in WarehouseInput, actor uuid.UUID) (Warehouse, error) {
+	if err := in.validate(); err != nil {
+		return Warehouse{}, err
+	}
+	var out Warehouse
+	err := s.mutate(ctx, actor, "warehouse.created", "warehouses", func(q *sqlcgen.Queries) (string, any, error) {
+		w, err := q.CreateWarehouse(ctx, sqlcgen.CreateWarehouseParams{Code: in.Code, Name: in.Name, AllowNegativeStock: in.AllowNegativeStock})
+		if err != nil {
+			return "", nil, err
+		}
+		out = toWarehouse(w)
+		return out.ID, out, nil
+	})
+	return out, err
+}
+
+func (s MasterdataService) UpdateWarehouse(ctx context.Context, id uuid.UUID, in WarehouseInput, actor uuid.UUID) (Warehouse, error) {
+	if err := in.validate(); err != nil {
+		return Warehouse{}, err
+	}
+	var out Warehouse
+	err := s.mutate(ctx, actor, "warehouse.updated", "warehouses", func(q *sqlcgen.Queries) (string, any, error) {
+		w, err := q.UpdateWarehouse(ctx, sqlcgen.UpdateWarehouseParams{ID: store.UUID(id), Code: in.Code, Name: in.Name, AllowNegativeStock: in.AllowNegativeStock})
+		if err != nil {
+			return "", nil, err
+		}
+		out = toWarehouse(w)
+		return out.ID, out, nil
+	})
+	return out, err
+}
+
+func (s MasterdataService) DeactivateWarehouse(ctx context.Context, id uuid.UUID, actor uuid.UUID) (Warehouse, error) {
+	var out Warehouse
+	err := s.mutate(ctx, actor, "warehouse.deactivated", "warehouses", func(q *sqlcgen.Queries) (string, any, error) {
+		busy, err := q.WarehouseHasStock(ctx, store.UUID(id))
+		if err != nil {
+			return "", nil, err
+		}
+		if busy {
+			return "", nil, ErrInUse
+		}
+		w, err := q.SetWarehouseActive(ctx, sqlcgen.SetWarehouseActiveParams{ID: store.UUID(id), Active: false})
+		if err != nil {
+			return "", nil, err
+		}
+		out = toWarehouse(w)
+		return out.ID, out, nil
+	})
+	return out, err
+}
+
+func (s MasterdataService) ListLocations(ctx context.Context, f ListFilter) ([]Location, error) {
+	path, err := locpath.Normalize(f.Path)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalid, err)
+	}
+	params := sqlcgen.ListLocationsParams{Type: f.Type, Path: path, IncludeInactive: f.IncludeInactive, PageLimit: f.Limit, PageOffset: f.Offset}
+	if f.WarehouseID != "" {
+		wid, err := uuid.Parse(f.WarehouseID)
+		if err != nil {
+			return nil, fmt.Errorf("%w: warehouse_id", ErrInvalid)
+		}
+		params.WarehouseID = store.UUID(wid)
+	}
+	rows, err := s.Queries.ListLocations(ctx, params)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]Location, 0, len(rows))
+	for _, r := range rows {
+		out = append(out, toLocation(r))
+	}
+	return out, nil
+}
+
+func (s MasterdataService) GetLocation(ctx context.Context, id uuid.UUID) (Location, error) {
+	l, err := s.Queries.GetLocation(ctx, store.UUID(id))
+	if err != nil {
+		return Location{}, store.MapErr(err, conflictFields)
+	}
+	return toLocation(l), nil
+}
+
+func (s MasterdataService) CreateLocation(ctx context.Context, in LocationInput, actor uuid.UUID) (Location, error) {
+	if err := in.validate(); err != nil {
+		return Location{}, err
+	}
+	lim, err := in.limits()
+	if err != nil {
+		return Location{}, err
+	}
+	wid, err := uuid.Parse(in.WarehouseID)
+	if err != nil {
+		return Location{}, fmt.Errorf("%w: warehouse_id", ErrInvalid)
+	}
+	var out Location
+	err = s.mutate(ctx, actor, "location.created", "locations", func(q *sqlcgen.Queries) (string, any, error) {
+		w, err := q.GetWarehouse(ctx, store.UUID(wid))
+		if err != nil {
+			return "", nil, err
+		}
+		if !w.Active {
+			return "", nil, fmt.Errorf("%w: warehouse is inactive", ErrInvalid)
+		}
+		l, err := q.CreateLocation(ctx, sqlcgen.CreateLocationParams{
+			WarehouseID: w.ID, Code: in.Code, Type: in.Type, Path: store.Text(in.Path), Zone: store.Text(in.Zone),
+			MaxWeightKg: lim.weight, MaxVolumeM3: lim.volume, MaxHu: lim.hu, MaxSkus: lim.skus, MixedLots: lim.mixedLots,
+		})
+		if err != nil {
+			return "", nil, err
+		}
+		out = toLocation(l)
+		return out.ID, out, nil
+	})
+	return out, err
+}
+
+// UpdateLocation changes code, type, path, zone and limits. A location never
+// changes warehouse; create a new one instead. Tightened limits only apply to
+// later moves; stock already in the bin stays.
+func (s MasterdataService) UpdateLocation(ctx context.Context, id uuid.UUID, in LocationInput, actor uuid.UUID) (Location, error) {
+	if err := in.validate(); err != nil {
+		return Location{}, err
+	}
+	lim, err := in.limits()
+	if err != nil {
+		return Location{}, err
+	}
+	var out Location
+	err = s.mutate(ctx, actor, "location.updated", "locations", func(q *sqlcgen.Queries) (string, any, error) {
+		l, err := q.UpdateLocation(ctx, sqlcgen.UpdateLocationParams{
+			ID: store.UUID(id), Code: in.Code, Type: in.Type, Path: store.Text(in.Path), Zone: store.Text(in.Zone),
+			MaxWeightKg: lim.weight, MaxVolumeM3: lim.volume, MaxHu: lim.hu, MaxSkus: lim.skus, MixedLots: lim.mixedLots,
+		})
+		if err != nil {
+			return "", nil, err
+		}
+		out = toLocation(l)
+		return out.ID, out, nil
+	})
+	return out, err
+}
+
+func (s MasterdataService) DeactivateLocation(ctx context.Context, id uuid.UUID, actor uuid.UUID) (Location, error) {
+	var out Location
+	err := s.mutate(ctx, actor, "location.deactivated", "locations", func(q *sqlcgen.Queries) (string, any, error) {
+		busy, err := q.LocationHasStock(ctx, store.UUID(id))
+		if err != nil {
+			return "", nil, err
+		}
+		if busy {
+			return "", nil, ErrInUse
+		}
+		l, err := q.SetLocationActive(ctx, sqlcgen.SetLocationActiveParams{ID: store.UUID(id), Active: false})
+		if err != nil {
+			return "", nil, err
+		}
+		out = toLocation(l)
+		return out.ID, out, nil
+	})
+	return out, err
+}
+
+func (s MasterdataService) ListLocationTypePolicies(ctx context.Context) ([]LocationTypePolicy, error) {
+	rows, err := s.Queries.ListLocationTypePolicies(ctx)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]LocationTypePolicy, 0, len(rows))
+	for _, r := range rows {
+		out = append(out, LocationTypePolicy{Type: r.Type, AllowNegativeStock: r.AllowNegativeStock})
+	}
+	return out, nil
+}
+
+// SetLocationTypePolicy controls whether locations of the given type may go
+// below zero on hand.
+func (s MasterdataService) SetLocationTypePolicy(ctx context.Context, in LocationTypePolicy, actor uuid.UUID) (LocationTypePolicy, error) {
+	if strings.TrimSpace(in.Type) == "" {
+		return LocationTypePolicy{}, fmt.Errorf("%w: type is required", ErrInvalid)
+	}
+	var out LocationTypePolicy
+	err := s.mutate(ctx, actor, "location_type_policy.updated", "location_type_policies", func(q *sqlcgen.Queries) (string, any, error) {
+		p, err := q.UpsertLocationTypePolicy(ctx, sqlcgen.UpsertLocationTypePolicyParams{Type: in.Type, AllowNegativeStock: in.AllowNegativeStock})
+		if err != nil {
+			return "", nil, err
+		}
+		out = LocationTypePolicy{Type: p.Type, AllowNegativeStock: p.AllowNegativeStock}
+		return out.Type, out, nil
+	})
+	return out, err
+}
+
+func (s MasterdataService) ListItems(ctx context.Context, f ListFilter) ([]Item, error) {
+	rows, err := s.Queries.ListItems(ctx, sqlcgen.ListItemsParams{Q: f.Q, IncludeInactive: f.IncludeInactive, PageLimit: f.Limit, PageOffset: f.Offset})
+	if err != nil {
+		return nil, err
+	}
+	out := make([]Item, 0, len(rows))
+	for _, r := range rows {
+		out = append(out, toItem(r))
+	}
+	return out, nil
+}
+
+func (s MasterdataService) GetItem(ctx context.Context, id uuid.UUID) (Item, error) {
+	i, err := s.Queries.GetItem(ctx, store.UUID(id))
+	if err != nil {
+		return Item{}, store.MapErr(err, conflictFields)
+	}
+	return toItem(i), nil
+}
+
+func (s MasterdataService) CreateItem(ctx context.Context, in ItemInput, actor uuid.UUID) (Item, error) {
+	if in.TrackingMode == "" {
+		in.TrackingMode = "none"
+	}
+	if err := in.validate(); err != nil {
+		return Item{}, err
+	}
+	m, err := in.measures()
+	if err != nil {
+		return Item{}, err
+	}
+	var out Item
+	err = s.mutate(ctx, actor, "item.created", "items", func(q *sqlcgen.Queries) (string, any, error) {
+		i, err := q.CreateItem(ctx, sqlcgen.CreateItemParams{
+			Sku: in.Sku, Name: in.Name, Barcode: store.Text(in.Barcode), Uom: in.Uom, TrackingMode: in.TrackingMode, ItemClass: store.Text(in.ItemClass), AbcClass: store.Text(in.AbcClass),
+			WeightKg: m.weight, LengthCm: m.length, WidthCm: m.width, HeightCm: m.height,
+		})
+		if err != nil {
+			return "", nil, err
+		}
+		if err := registerPrimaryBarcode(ctx, q, i); err != nil {
+			return "", nil, err
+		}
+		out = toItem(i)
+		return out.ID, out, nil
+	})
+	return out, err
+}
+
+func (s MasterdataService) UpdateItem(ctx context.Context, id uuid.UUID, in ItemInput, actor uuid.UUID) (Item, error) {
+	if in.TrackingMode == "" {
+		in.TrackingMode = "none"
+	}
+	if err := in.validate(); err != nil {
+		return Item{}, err
+	}
+	m, err := in.measures()
+	if err != nil {
+		return Item{}, err
+	}
+	var out Item
+	err = s.mutate(ctx, actor, "item.updated", "items", func(q *sqlcgen.Queries) (string, any, error) {
+		cur, err := q.GetItem(ctx, store.UUID(id))
+		if err != nil {
+			return "", nil, err
+		}
+		if cur.TrackingMode != in.TrackingMode || cur.Uom != in.Uom {
+			// Existing balances would be left without the lot/serial key, or
+			// be read in a different unit than they were booked in.
+			busy, err := q.ItemHasStock(ctx, cur.ID)
+			if err != nil {
+				return "", nil, err
+			}
+			if busy {
+				return "", nil, fmt.Errorf("%w: tracking_mode and uom cannot change", ErrInUse)
+			}
+		}
+		i, err := q.UpdateItem(ctx, sqlcgen.UpdateItemParams{
+			ID: store.UUID(id), Sku: in.Sku, Name: in.Name, Barcode: store.Text(in.Barcode), Uom: in.Uom, TrackingMode: in.TrackingMode, ItemClass: store.Text(in.ItemClass), AbcClass: store.Text(in.AbcClass),
+			WeightKg: m.weight, LengthCm: m.length, WidthCm: m.width, HeightCm: m.height,
+		})
+		if err != nil {
+			return "", nil, err
+		}
+		if err := registerPrimaryBarcode(ctx, q, i); err != nil {
+			return "", nil, err
+		}
+		out = toItem(i)
+		return out.ID, out, nil
+	})
+	return out, err
+}
+
+func (s MasterdataService) DeactivateItem(ctx context.Context, id uuid.UUID, actor uuid.UUID) (Item, error) {
+	var out Item
+	err := s.mutate(ctx, actor, "item.deactivated", "items", func(q *sqlcgen.Queries) (string, any, error) {
+		busy, err := q.ItemHasStock(ctx, store.UUID(id))
+		if err != nil {
+			return "", nil, err
+		}
+		if busy {
+			return "", nil, ErrInUse
+		}
+		i, err := q.SetItemActive(ctx, sqlcgen.SetItemActiveParams{ID: store.UUID(id), Active: false})
+		if err != nil {
+			return "", nil, err
+		}
+		out = toItem(i)
+		return out.ID, out, nil
+	})
+	return out, err
+}
+
+func (s MasterdataService) ListLots(ctx context.Context, itemID uuid.UUID) ([]Lot, error) {
+	rows, err := s.Queries.ListLotsByItem(ctx, store.UUID(itemID))
+	if err != nil {
+		return nil, err
+	}
+	out := make([]Lot, 0, len(rows))
+	for _, r := range rows {
+		out = append(out, toLot(r))
+	}
+	return out, nil
+}
+
+func (s MasterdataService) CreateLot(ctx context.Context, itemID uuid.UUID, in LotInput, actor uuid.UUID) (Lot, error) {
+	if strings.TrimSpace(in.LotCode) == "" {
+		return Lot{}, fmt.Errorf("%w: lot_code is required", ErrInvalid)
+	}
+	mfg, err := parseDate(in.ManufacturedOn)
+	if err != nil {
+		return Lot{}, err
+	}
+	exp, err := parseDate(in.ExpiresOn)
+	if err != nil {
+		return Lot{}, err
+	}
+	var out Lot
+	err = s.mutate(ctx, actor, "lot.created", "lots", func(q *sqlcgen.Queries) (string, any, error) {
+		item, err := q.GetItem(ctx, store.UUID(itemID))
+		if err != nil {
+			return "", nil, err
+		}
+		if item.TrackingMode == "none" {
+			return "", nil, fmt.Errorf("%w: item is not lot or serial tracked", ErrInvalid)
+		}
+		l, err := q.CreateLot(ctx, sqlcgen.CreateLotParams{ItemID: item.ID, LotCode: in.LotCode, ManufacturedOn: mfg, ExpiresOn: exp})
+		if err != nil {
+			return "", nil, err
+		}
+		out = toLot(l)
+		return out.ID, out, nil
+	})
+	return out, err
+}
+
+func (s MasterdataService) ListSerials(ctx context.Context, itemID uuid.UUID, f ListFilter) ([]Serial, error) {
+	rows, err := s.Queries.ListSerialsByItem(ctx, sqlcgen.ListSerialsByItemParams{ItemID: store.UUID(itemID), Limit: f.Limit, Offset: f.Offset})
+	if err != nil {
+		return nil, err
+	}
+	out := make([]Serial, 0, len(rows))
+	for _, r := range rows {
+		out = append(out, toSerial(r))
+	}
+	return out, nil
+}
+
+// RegisterSerial records a serial number before it is put into stock. It has
+// no location until a move places it.
+func (s MasterdataService) RegisterSerial(ctx context.Context, itemID uuid.UUID, in SerialInput, actor uuid.UUID) (Serial, error) {
+	if strings.TrimSpace(in.SerialNo) == "" {
+		return Serial{}, fmt.Errorf("%w: serial_no is required", ErrInvalid)
+	}
+	var out Serial
+	err := s.mutate(ctx, actor, "serial.registered", "serials", func(q *sqlcgen.Queries) (string, any, error) {
+		item, err := q.GetItem(ctx, store.UUID(itemID))
+		if err != nil {
+			return "", nil, err
+		}
+		if item.TrackingMode != "serial" {
+			return "", nil, fmt.Errorf("%w: item is not serial tracked", ErrInvalid)
+		}
+		var lotID pgtype.UUID
+		if in.LotCode != "" {
+			lot, err := q.GetLotByCode(ctx, sqlcgen.GetLotByCodeParams{ItemID: item.ID, LotCode: in.LotCode})
+			if err != nil {
+				return "", nil, err
+			}
+			lotID = lot.ID
+		}
+		sr, err := q.CreateSerial(ctx, sqlcgen.CreateSerialParams{ItemID: item.ID, SerialNo: in.SerialNo, LotID: lotID})
+		if err != nil {
+			return "", nil, err
+		}
+		out = toSerial(sr)
+		return out.ID, out, nil
+	})
+	return out, err
+}
+
+// mutate runs fn in a transaction with its outbox event and audit entry;
+// see store.Mutate.
+func (s MasterdataService) mutate(ctx context.Context, actor uuid.UUID, topic, resource string, fn func(q *sqlcgen.Queries) (string, any, error)) error {
+	return store.Mutate(ctx, s.DB, s.Queries, actor, topic, resource, conflictFields, fn)
+}
+
+func (in WarehouseInput) validate() error {
+	if strings.TrimSpace(in.Code) == "" || strings.TrimSpace(in.Name) == "" {
+		return fmt.Errorf("%w: code and name are required", ErrInvalid)
+	}
+	return nil
+}
+
+// validate checks in and brings its path into ltree form.
+func (in *LocationInput) validate() error {
+	if strings.TrimSpace(in.Code) == "" || strings.TrimSpace(in.Type) == "" {
+		return fmt.Errorf("%w: code and type are required", ErrInvalid)
+	}
+	path, err := locpath.Normalize(in.Path)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalid, err)
+	}
+	in.Path = path
+	return nil
+}
+
+func (in ItemInput) validate() error {
+	if strings.TrimSpace(in.Sku) == "" || strings.TrimSpace(in.Name) == "" || strings.TrimSpace(in.Uom) == "" {
+		return fmt.Errorf("%w: sku, name and uom are required", ErrInvalid)
+	}
+	switch in.TrackingMode {
+	case "none", "lot", "serial":
+	default:
+		return fmt.Errorf("%w: tracking_mode must be none, lot or serial", ErrInvalid)
+	}
+	switch in.AbcClass {
+	case "", "A", "B", "C":
+	default:
+		return fmt.Errorf("%w: abc_class must be A, B or C", ErrInvalid)
+	}
+	return nil
+}
+
+func (in LocationInput) limits() (locationLimits, error) {
+	lim := locationLimits{mixedLots: in.MixedLots == nil || *in.MixedLots}
+	var err error
+	if lim.weight, err = optDecimal("max_weight_kg", in.MaxWeightKg); err != nil {
+		return lim, err
+	}
+	if lim.volume, err = optDecimal("max_volume_m3", in.MaxVolumeM3); err != nil {
+		return lim, err
+	}
+	if in.MaxHU != nil {
+		if *in.MaxHU < 0 {
+			return lim, fmt.Errorf("%w: max_hu must not be negative", ErrInvalid)
+		}
+		lim.hu = pgtype.Int4{Int32: *in.MaxHU, Valid: true}
+	}
+	if in.MaxSkus != nil {
+		if *in.MaxSkus <= 0 {
+			return lim, fmt.Errorf("%w: max_skus must be positive", ErrInvalid)
+		}
+		lim.skus = pgtype.Int4{Int32: *in.MaxSkus, Valid: true}
+	}
+	return lim, nil
+}
+
+func (in ItemInput) measures() (itemMeasures, error) {
+	var m itemMeasures
+	for _, f := range []struct {
+		name, v string
+		dst     *pgtype.Numeric
+	}{{"weight_kg", in.WeightKg, &m.weight}, {"length_cm", in.LengthCm, &m.length}, {"width_cm", in.WidthCm, &m.width}, {"height_cm", in.HeightCm, &m.height}} {
+		n, err := optDecimal(f.name, f.v)
+		if err != nil {
+			return m, err
+		}
+		*f.dst = n
+	}
+	return m, nil
+}
+
+// optDecimal parses an optional positive decimal; empty is NULL.
+func optDecimal(field, v string) (pgtype.Numeric, error) {
+	if strings.TrimSpace(v) == "" {
+		return pgtype.Numeric{}, nil
+	}
+	r, err := qty.Parse(v)
+	if err != nil || r.Sign() <= 0 {
+		return pgtype.Numeric{}, fmt.Errorf("%w: %s must be a positive decimal", ErrInvalid, field)
+	}
+	return qty.ToNumeric(r), nil
+}
+
+func optDecimalString(n pgtype.Numeric) string {
+	if !n.Valid {
+		return ""
+	}
+	return qty.String(qty.FromNumeric(n))
+}
+
+func optInt(n pgtype.Int4) *int32 {
+	if !n.Valid {
+		return nil
+	}
+	return &n.Int32
+}
+
+func toWarehouse(w sqlcgen.Warehouse) Warehouse {
+	return Warehouse{ID: w.ID.String(), Code: w.Code, Name: w.Name, AllowNegativeStock: w.AllowNegativeStock, Active: w.Active}
+}
+
+func toLocation(l sqlcgen.Location) Location {
+	out := Location{
+		ID: l.ID.String(), WarehouseID: l.WarehouseID.String(), Code: l.Code, Type: l.Type, Path: l.Path.String, Zone: l.Zone.String,
+		MaxWeightKg: optDecimalString(l.MaxWeightKg), MaxVolumeM3: optDecimalString(l.MaxVolumeM3), MaxHU: optInt(l.MaxHu), MaxSkus: optInt(l.MaxSkus),
+		MixedLots: l.MixedLots, Active: l.Active,
+	}
+	if l.FrozenByCountID.Valid {
+		out.FrozenBy = l.FrozenByCountID.String()
+	}
+	return out
+}
+
+func toItem(i sqlcgen.Item) Item {
+	return Item{
+		ID: i.ID.String(), Sku: i.Sku, Name: i.Name, Barcode: i.Barcode.String, Uom: i.Uom, TrackingMode: i.TrackingMode, ItemClass: i.ItemClass.String, AbcClass: i.AbcClass.String,
+		WeightKg: optDecimalString(i.WeightKg), LengthCm: optDecimalString(i.LengthCm), WidthCm: optDecimalString(i.WidthCm), HeightCm: optDecimalString(i.HeightCm),
+		Active: i.Active,
+	}
+}
+
+func toLot(l sqlcgen.Lot) Lot {
+	return Lot{ID: l.ID.String(), ItemID: l.ItemID.String(), LotCode: l.LotCode, ManufacturedOn: fmtDate(l.ManufacturedOn), ExpiresOn: fmtDate(l.ExpiresOn)}
+}
+
+func toSerial(sr sqlcgen.Serial) Serial {
+	out := Serial{ID: sr.ID.String(), ItemID: sr.ItemID.String(), SerialNo: sr.SerialNo}
+	if sr.LotID.Valid {
+		out.LotID = sr.LotID.String()
+	}
+	if sr.LocationID.Valid {
+		out.LocationID = sr.LocationID.String()
+	}
+	return out
+}
+
+func parseDate(v string) (pgtype.Date, error) {
+	if v == "" {
+		return pgtype.Date{}, nil
+	}
+	t, err := time.Parse("2006-01-02", v)
+	if err != nil {
+		return pgtype.Date{}, fmt.Errorf("%w: date %q must be YYYY-MM-DD", ErrInvalid, v)
+	}
+	return pgtype.Date{Time: t, Valid: true}, nil
+}
+
+func fmtDate(d pgtype.Date) string {
+	if !d.Valid {
+		return ""
+	}
+	return d.Time.Format("2006-01-02")
+}
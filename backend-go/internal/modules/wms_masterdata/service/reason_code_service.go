@@ -0,0 +1,130 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"erpwms/backend-go/internal/common/store"
+	"erpwms/backend-go/internal/db/sqlcgen"
+	"github.com/google/uuid"
+)
+
+var reasonCodePattern = regexp.MustCompile(`^[A-Z][A-Z0-9_]*$`)
+
+// ReasonCodeInput configures which move types may book under a code, what a
+// booking must carry and, optionally, the permission it needs on top of the
+// endpoint's own.
+type ReasonCodeInput struct {
+	Code              string   `json:"code"`
+	Description       string   `json:"description"`
+	MoveTypes         []string `json:"move_types"`
+	RequiresComment   bool     `json:"requires_comment"`
+	RequiresReference bool     `json:"requires_reference"`
+	Permission        string   `json:"permission"`
+}
+
+type ReasonCode struct {
+	Code              string   `json:"code"`
+	Description       string   `json:"description"`
+	MoveTypes         []string `json:"move_types"`
+	RequiresComment   bool     `json:"requires_comment"`
+	RequiresReference bool     `json:"requires_reference"`
+	Permission        string   `json:"permission,omitempty"`
+	Active            bool     `json:"active"`
+}
+
+// ListReasonCodes returns the active codes, or all of them with
+// includeInactive. A non-empty moveType keeps only codes allowed for it.
+func (s MasterdataService) ListReasonCodes(ctx context.Context, moveType string, includeInactive bool) ([]ReasonCode, error) {
+	rows, err := s.Queries.ListReasonCodes(ctx, sqlcgen.ListReasonCodesParams{MoveType: moveType, IncludeInactive: includeInactive})
+	if err != nil {
+		return nil, err
+	}
+	out := make([]ReasonCode, 0, len(rows))
+	for _, r := range rows {
+		out = append(out, toReasonCode(r))
+	}
+	return out, nil
+}
+
+func (s MasterdataService) CreateReasonCode(ctx context.Context, in ReasonCodeInput, actor uuid.UUID) (ReasonCode, error) {
+	if err := in.validate(); err != nil {
+		return ReasonCode{}, err
+	}
+	var out ReasonCode
+	err := s.mutate(ctx, actor, "reason_code.created", "reason_codes", func(q *sqlcgen.Queries) (string, any, error) {
+		r, err := q.CreateReasonCode(ctx, sqlcgen.CreateReasonCodeParams{
+			Code: in.Code, Description: in.Description, MoveTypes: in.MoveTypes,
+			RequiresComment: in.RequiresComment, RequiresReference: in.RequiresReference, Permission: store.Text(in.Permission),
+		})
+		if err != nil {
+			return "", nil, err
+		}
+		out = toReasonCode(r)
+		return out.Code, out, nil
+	})
+	return out, err
+}
+
+// UpdateReasonCode replaces the settings of code. The code itself is the key
+// stored on ledger rows and cannot be renamed.
+func (s MasterdataService) UpdateReasonCode(ctx context.Context, code string, in ReasonCodeInput, actor uuid.UUID) (ReasonCode, error) {
+	in.Code = code
+	if err := in.validate(); err != nil {
+		return ReasonCode{}, err
+	}
+	var out ReasonCode
+	err := s.mutate(ctx, actor, "reason_code.updated", "reason_codes", func(q *sqlcgen.Queries) (string, any, error) {
+		r, err := q.UpdateReasonCode(ctx, sqlcgen.UpdateReasonCodeParams{
+			Code: in.Code, Description: in.Description, MoveTypes: in.MoveTypes,
+			RequiresComment: in.RequiresComment, RequiresReference: in.RequiresReference, Permission: store.Text(in.Permission),
+		})
+		if err != nil {
+			return "", nil, err
+		}
+		out = toReasonCode(r)
+		return out.Code, out, nil
+	})
+	return out, err
+}
+
+// DeactivateReasonCode keeps the code for historical ledger rows but rejects
+// new bookings under it.
+func (s MasterdataService) DeactivateReasonCode(ctx context.Context, code string, actor uuid.UUID) error {
+	return s.mutate(ctx, actor, "reason_code.deactivated", "reason_codes", func(q *sqlcgen.Queries) (string, any, error) {
+		r, err := q.SetReasonCodeActive(ctx, sqlcgen.SetReasonCodeActiveParams{Code: code, Active: false})
+		if err != nil {
+			return "", nil, err
+		}
+		return r.Code, toReasonCode(r), nil
+	})
+}
+
+func (in ReasonCodeInput) validate() error {
+	if !reasonCodePattern.MatchString(in.Code) {
+		return fmt.Errorf("%w: code must be upper case letters, digits and underscores", ErrInvalid)
+	}
+	if strings.TrimSpace(in.Description) == "" {
+		return fmt.Errorf("%w: description is required", ErrInvalid)
+	}
+	if len(in.MoveTypes) == 0 {
+		return fmt.Errorf("%w: move_types is required", ErrInvalid)
+	}
+	for _, t := range in.MoveTypes {
+		switch t {
+		case "receipt", "issue", "adjustment", "transfer", "status":
+		default:
+			return fmt.Errorf("%w: move_types must be receipt, issue, adjustment, transfer or status", ErrInvalid)
+		}
+	}
+	return nil
+}
+
+func toReasonCode(r sqlcgen.ReasonCode) ReasonCode {
+	return ReasonCode{
+		Code: r.Code, Description: r.Description, MoveTypes: r.MoveTypes, RequiresComment: r.RequiresComment,
+		RequiresReference: r.RequiresReference, Permission: r.Permission.String, Active: r.Active,
+	}
+}
@@ -0,0 +1,111 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"erpwms/backend-go/internal/common/qty"
+	"erpwms/backend-go/internal/common/store"
+	"erpwms/backend-go/internal/db/sqlcgen"
+	"github.com/google/uuid"
+)
+
+// Uom is a catalog unit. Decimals bounds the precision of quantities entered
+// or stored in it.
+type Uom struct {
+	Code     string `json:"code"`
+	Name     string `json:"name"`
+	Decimals int32  `json:"decimals"`
+}
+
+// ItemUom says how many base units of an item one Uom holds, e.g. CT = 12.
+type ItemUom struct {
+	ItemID string `json:"item_id"`
+	Uom    string `json:"uom"`
+	Factor string `json:"factor"`
+}
+
+func (s MasterdataService) ListUoms(ctx context.Context) ([]Uom, error) {
+	rows, err := s.Queries.ListUoms(ctx)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]Uom, 0, len(rows))
+	for _, r := range rows {
+		out = append(out, Uom{Code: r.Code, Name: r.Name, Decimals: r.Decimals})
+	}
+	return out, nil
+}
+
+func (s MasterdataService) SaveUom(ctx context.Context, in Uom, actor uuid.UUID) (Uom, error) {
+	if strings.TrimSpace(in.Code) == "" || strings.TrimSpace(in.Name) == "" {
+		return Uom{}, fmt.Errorf("%w: code and name are required", ErrInvalid)
+	}
+	if in.Decimals < 0 || in.Decimals > 6 {
+		return Uom{}, fmt.Errorf("%w: decimals must be between 0 and 6", ErrInvalid)
+	}
+	var out Uom
+	err := s.mutate(ctx, actor, "uom.updated", "uoms", func(q *sqlcgen.Queries) (string, any, error) {
+		u, err := q.UpsertUom(ctx, sqlcgen.UpsertUomParams{Code: in.Code, Name: in.Name, Decimals: in.Decimals})
+		if err != nil {
+			return "", nil, err
+		}
+		out = Uom{Code: u.Code, Name: u.Name, Decimals: u.Decimals}
+		return out.Code, out, nil
+	})
+	return out, err
+}
+
+func (s MasterdataService) ListItemUoms(ctx context.Context, itemID uuid.UUID) ([]ItemUom, error) {
+	rows, err := s.Queries.ListItemUomConversions(ctx, store.UUID(itemID))
+	if err != nil {
+		return nil, err
+	}
+	out := make([]ItemUom, 0, len(rows))
+	for _, r := range rows {
+		out = append(out, toItemUom(r))
+	}
+	return out, nil
+}
+
+func (s MasterdataService) SetItemUom(ctx context.Context, itemID uuid.UUID, uom, factor string, actor uuid.UUID) (ItemUom, error) {
+	f, err := qty.Parse(factor)
+	if err != nil || f.Sign() <= 0 {
+		return ItemUom{}, fmt.Errorf("%w: factor must be a positive decimal", ErrInvalid)
+	}
+	var out ItemUom
+	err = s.mutate(ctx, actor, "item_uom.updated", "item_uom_conversions", func(q *sqlcgen.Queries) (string, any, error) {
+		item, err := q.GetItem(ctx, store.UUID(itemID))
+		if err != nil {
+			return "", nil, err
+		}
+		if item.Uom == uom {
+			return "", nil, fmt.Errorf("%w: %s is the item's base uom", ErrInvalid, uom)
+		}
+		c, err := q.UpsertItemUomConversion(ctx, sqlcgen.UpsertItemUomConversionParams{ItemID: item.ID, Uom: uom, Factor: qty.ToNumeric(f)})
+		if err != nil {
+			return "", nil, err
+		}
+		out = toItemUom(c)
+		return out.ItemID, out, nil
+	})
+	return out, err
+}
+
+func (s MasterdataService) DeleteItemUom(ctx context.Context, itemID uuid.UUID, uom string, actor uuid.UUID) error {
+	return s.mutate(ctx, actor, "item_uom.deleted", "item_uom_conversions", func(q *sqlcgen.Queries) (string, any, error) {
+		n, err := q.DeleteItemUomConversion(ctx, sqlcgen.DeleteItemUomConversionParams{ItemID: store.UUID(itemID), Uom: uom})
+		if err != nil {
+			return "", nil, err
+		}
+		if n == 0 {
+			return "", nil, ErrNotFound
+		}
+		return itemID.String(), ItemUom{ItemID: itemID.String(), Uom: uom}, nil
+	})
+}
+
+func toItemUom(c sqlcgen.ItemUomConversion) ItemUom {
+	return ItemUom{ItemID: c.ItemID.String(), Uom: c.Uom, Factor: qty.String(qty.FromNumeric(c.Factor))}
+}
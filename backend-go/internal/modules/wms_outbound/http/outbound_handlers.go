@@ -0,0 +1,294 @@
+package http
+
+import (
+	"strconv"
+
+	"erpwms/backend-go/internal/common/httperr"
+	"erpwms/backend-go/internal/modules/wms_outbound/service"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type OutboundHandlers struct {
+	Service service.OutboundService
+}
+
+func (h OutboundHandlers) ListCustomers(c *gin.Context) {
+	limit, offset := page(c)
+	rows, err := h.Service.ListCustomers(c.Request.Context(), c.Query("include_inactive") == "true", limit, offset)
+	if err != nil {
+		httperr.Write(c, err)
+		return
+	}
+	c.JSON(200, gin.H{"items": rows})
+}
+
+func (h OutboundHandlers) CreateCustomer(c *gin.Context) {
+	var in service.CustomerInput
+	if err := c.ShouldBindJSON(&in); err != nil {
+		c.JSON(400, gin.H{"error": "bad request"})
+		return
+	}
+	actor, ok := actorID(c)
+	if !ok {
+		return
+	}
+	cust, err := h.Service.CreateCustomer(c.Request.Context(), in, actor)
+	if err != nil {
+		httperr.Write(c, err)
+		return
+	}
+	c.JSON(201, cust)
+}
+
+func (h OutboundHandlers) UpdateCustomer(c *gin.Context) {
+	id, ok := paramID(c)
+	if !ok {
+		return
+	}
+	var in service.CustomerInput
+	if err := c.ShouldBindJSON(&in); err != nil {
+		c.JSON(400, gin.H{"error": "bad request"})
+		return
+	}
+	actor, ok := actorID(c)
+	if !ok {
+		return
+	}
+	cust, err := h.Service.UpdateCustomer(c.Request.Context(), id, in, actor)
+	if err != nil {
+		httperr.Write(c, err)
+		return
+	}
+	c.JSON(200, cust)
+}
+
+func (h OutboundHandlers) DeactivateCustomer(c *gin.Context) {
+	id, ok := paramID(c)
+	if !ok {
+		return
+	}
+	actor, ok := actorID(c)
+	if !ok {
+		return
+	}
+	cust, err := h.Service.DeactivateCustomer(c.Request.Context(), id, actor)
+	if err != nil {
+		httperr.Write(c, err)
+		return
+	}
+	c.JSON(200, cust)
+}
+
+func (h OutboundHandlers) ListSalesOrders(c *gin.Context) {
+	limit, offset := page(c)
+	rows, err := h.Service.ListSalesOrders(c.Request.Context(), service.SalesOrderFilter{
+		CustomerID:  c.Query("customer_id"),
+		WarehouseID: c.Query("warehouse_id"),
+		WaveID:      c.Query("wave_id"),
+		Status:      c.Query("status"),
+		Carrier:     c.Query("carrier"),
+		Limit:       limit,
+		Offset:      offset,
+	})
+	if err != nil {
+		httperr.Write(c, err)
+		return
+	}
+	c.JSON(200, gin.H{"items": rows})
+}
+
+func (h OutboundHandlers) CreateSalesOrder(c *gin.Context) {
+	var in service.SalesOrderInput
+	if err := c.ShouldBindJSON(&in); err != nil {
+		c.JSON(400, gin.H{"error": "bad request"})
+		return
+	}
+	actor, ok := actorID(c)
+	if !ok {
+		return
+	}
+	so, err := h.Service.CreateSalesOrder(c.Request.Context(), in, actor)
+	if err != nil {
+		httperr.Write(c, err)
+		return
+	}
+	c.JSON(201, so)
+}
+
+func (h OutboundHandlers) GetSalesOrder(c *gin.Context) {
+	id, ok := paramID(c)
+	if !ok {
+		return
+	}
+	so, err := h.Service.GetSalesOrder(c.Request.Context(), id)
+	if err != nil {
+		httperr.Write(c, err)
+		return
+	}
+	c.JSON(200, so)
+}
+
+func (h OutboundHandlers) AllocateSalesOrder(c *gin.Context) {
+	id, ok := paramID(c)
+	if !ok {
+		return
+	}
+	// The body is optional; without one the default strategy applies.
+	var req service.AllocateOrderRequest
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(400, gin.H{"error": "bad request"})
+			return
+		}
+	}
+	actor, ok := actorID(c)
+	if !ok {
+		return
+	}
+	resp, err := h.Service.AllocateSalesOrder(c.Request.Context(), id, req, actor)
+	if err != nil {
+		httperr.Write(c, err)
+		return
+	}
+	c.JSON(200, resp)
+}
+
+func (h OutboundHandlers) CancelSalesOrder(c *gin.Context) {
+	id, ok := paramID(c)
+	if !ok {
+		return
+	}
+	actor, ok := actorID(c)
+	if !ok {
+		return
+	}
+	so, err := h.Service.CancelSalesOrder(c.Request.Context(), id, actor)
+	if err != nil {
+		httperr.Write(c, err)
+		return
+	}
+	c.JSON(200, so)
+}
+
+func (h OutboundHandlers) CreateWaves(c *gin.Context) {
+	var req service.WaveRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(400, gin.H{"error": "bad request"})
+		return
+	}
+	actor, ok := actorID(c)
+	if !ok {
+		return
+	}
+	waves, err := h.Service.CreateWaves(c.Request.Context(), req, actor)
+	if err != nil {
+		httperr.Write(c, err)
+		return
+	}
+	c.JSON(201, gin.H{"items": waves})
+}
+
+func (h OutboundHandlers) ListWaves(c *gin.Context) {
+	limit, offset := page(c)
+	rows, err := h.Service.ListWaves(c.Request.Context(), service.WaveFilter{WarehouseID: c.Query("warehouse_id"), Status: c.Query("status"), Limit: limit, Offset: offset})
+	if err != nil {
+		httperr.Write(c, err)
+		return
+	}
+	c.JSON(200, gin.H{"items": rows})
+}
+
+func (h OutboundHandlers) GetWave(c *gin.Context) {
+	id, ok := paramID(c)
+	if !ok {
+		return
+	}
+	w, err := h.Service.GetWave(c.Request.Context(), id)
+	if err != nil {
+		httperr.Write(c, err)
+		return
+	}
+	c.JSON(200, w)
+}
+
+func (h OutboundHandlers) PickList(c *gin.Context) {
+	id, ok := paramID(c)
+	if !ok {
+		return
+	}
+	rows, err := h.Service.PickList(c.Request.Context(), id, c.Query("status"))
+	if err != nil {
+		httperr.Write(c, err)
+		return
+	}
+	c.JSON(200, gin.H{"items": rows})
+}
+
+func (h OutboundHandlers) CancelWave(c *gin.Context) {
+	id, ok := paramID(c)
+	if !ok {
+		return
+	}
+	actor, ok := actorID(c)
+	if !ok {
+		return
+	}
+	w, err := h.Service.CancelWave(c.Request.Context(), id, actor)
+	if err != nil {
+		httperr.Write(c, err)
+		return
+	}
+	c.JSON(200, w)
+}
+
+func (h OutboundHandlers) ConfirmPick(c *gin.Context) {
+	key := c.GetHeader("Idempotency-Key")
+	if key == "" {
+		c.JSON(400, gin.H{"error": "Idempotency-Key required"})
+		return
+	}
+	id, ok := paramID(c)
+	if !ok {
+		return
+	}
+	var req service.PickConfirmRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(400, gin.H{"error": "bad request"})
+		return
+	}
+	actor, ok := actorID(c)
+	if !ok {
+		return
+	}
+	resp, err := h.Service.ConfirmPick(c.Request.Context(), id, req, actor, "/api/pick-tasks/confirm", key)
+	if err != nil {
+		httperr.Write(c, err)
+		return
+	}
+	c.JSON(200, resp)
+}
+
+func page(c *gin.Context) (int32, int32) {
+	limit, _ := strconv.ParseInt(c.DefaultQuery("limit", "50"), 10, 32)
+	offset, _ := strconv.ParseInt(c.DefaultQuery("offset", "0"), 10, 32)
+	return int32(limit), int32(offset)
+}
+
+func paramID(c *gin.Context) (uuid.UUID, bool) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(400, gin.H{"error": "invalid id"})
+		return uuid.Nil, false
+	}
+	return id, true
+}
+
+func actorID(c *gin.Context) (uuid.UUID, bool) {
+	uid, err := uuid.Parse(c.GetString("user_id"))
+	if err != nil || uid == uuid.Nil {
+		c.JSON(401, gin.H{"error": "unauthorized"})
+		return uuid.Nil, false
+	}
+	return uid, true
+}
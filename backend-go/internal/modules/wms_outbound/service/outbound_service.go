@@ -0,0 +1,474 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"strings"
+	"time"
+
+	"erpwms/backend-go/internal/common/qty"
+	"erpwms/backend-go/internal/common/store"
+	"erpwms/backend-go/internal/db/sqlcgen"
+	stocksvc "erpwms/backend-go/internal/modules/wms_stock/service"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+var (
+	ErrNotFound = store.ErrNotFound
+	ErrConflict = store.ErrConflict
+	ErrInvalid  = store.ErrInvalid
+)
+
+// Sales order states. An order is allocated once every line is fully
+// reserved; only allocated orders are taken into waves. Shipping moves a
+// picked order to shipped once everything picked has left.
+const (
+	StatusOpen      = "open"
+	StatusAllocated = "allocated"
+	StatusPicking   = "picking"
+	StatusPicked    = "picked"
+	StatusShipped   = "shipped"
+	StatusCancelled = "cancelled"
+)
+
+// RefOrderLine is the stock_allocations ref_type of sales order reservations;
+// the ref_id is the sales_order_lines id.
+const RefOrderLine = "sales_order_line"
+
+// conflictFields names the field behind each unique constraint.
+var conflictFields = map[string]string{
+	"customers_code_key":                     "customer code",
+	"sales_orders_order_number_key":          "order number",
+	"sales_order_lines_order_id_line_no_key": "line number",
+}
+
+// OutboundService owns customers, sales orders, waves and pick tasks. Stock
+// is reserved and moved through Stock inside the outbound transaction.
+type OutboundService struct {
+	DB      *pgxpool.Pool
+	Queries *sqlcgen.Queries
+	Stock   stocksvc.StockService
+}
+
+// CustomerInput carries the address printed as consignee on delivery notes.
+type CustomerInput struct {
+	Code       string `json:"code"`
+	Name       string `json:"name"`
+	Address    string `json:"address,omitempty"`
+	City       string `json:"city,omitempty"`
+	PostalCode string `json:"postal_code,omitempty"`
+	Country    string `json:"country,omitempty"`
+	VatNumber  string `json:"vat_number,omitempty"`
+}
+
+type Customer struct {
+	ID         string `json:"id"`
+	Code       string `json:"code"`
+	Name       string `json:"name"`
+	Address    string `json:"address,omitempty"`
+	City       string `json:"city,omitempty"`
+	PostalCode string `json:"postal_code,omitempty"`
+	Country    string `json:"country,omitempty"`
+	VatNumber  string `json:"vat_number,omitempty"`
+	Active     bool   `json:"active"`
+}
+
+// SalesOrderInput quantities are in Uom, or the item's base unit when Uom is
+// empty; they are stored in the base unit. CutoffAt is RFC 3339.
+type SalesOrderInput struct {
+	OrderNumber string                `json:"order_number"`
+	CustomerID  string                `json:"customer_id"`
+	WarehouseID string                `json:"warehouse_id"`
+	Carrier     string                `json:"carrier"`
+	CutoffAt    string                `json:"cutoff_at"`
+	Lines       []SalesOrderLineInput `json:"lines"`
+}
+
+type SalesOrderLineInput struct {
+	ItemID string `json:"item_id"`
+	Qty    string `json:"qty"`
+	Uom    string `json:"uom,omitempty"`
+}
+
+type SalesOrder struct {
+	ID          string           `json:"id"`
+	OrderNumber string           `json:"order_number"`
+	CustomerID  string           `json:"customer_id"`
+	WarehouseID string           `json:"warehouse_id"`
+	Carrier     string           `json:"carrier,omitempty"`
+	CutoffAt    *time.Time       `json:"cutoff_at,omitempty"`
+	Status      string           `json:"status"`
+	WaveID      string           `json:"wave_id,omitempty"`
+	CreatedAt   time.Time        `json:"created_at"`
+	Lines       []SalesOrderLine `json:"lines,omitempty"`
+}
+
+// SalesOrderLine reports progress in the item's base unit. QtyOpen is what
+// still needs an allocation; QtyShort what short picks could not re-allocate.
+type SalesOrderLine struct {
+	ID           string `json:"id"`
+	LineNo       int32  `json:"line_no"`
+	ItemID       string `json:"item_id"`
+	QtyOrdered   string `json:"qty_ordered"`
+	QtyAllocated string `json:"qty_allocated"`
+	QtyPicked    string `json:"qty_picked"`
+	QtyShort     string `json:"qty_short"`
+	QtyShipped   string `json:"qty_shipped"`
+	QtyOpen      string `json:"qty_open"`
+}
+
+type SalesOrderFilter struct {
+	CustomerID  string
+	WarehouseID string
+	WaveID      string
+	Status      string
+	Carrier     string
+	Limit       int32
+	Offset      int32
+}
+
+// AllocateOrderRequest picks the allocation strategy; empty means FEFO.
+type AllocateOrderRequest struct {
+	Strategy string `json:"strategy,omitempty"`
+}
+
+type AllocateOrderResponse struct {
+	SalesOrder
+	Allocations []stocksvc.Allocation `json:"allocations"`
+}
+
+func (s OutboundService) ListCustomers(ctx context.Context, includeInactive bool, limit, offset int32) ([]Customer, error) {
+	rows, err := s.Queries.ListCustomers(ctx, sqlcgen.ListCustomersParams{IncludeInactive: includeInactive, PageLimit: limit, PageOffset: offset})
+	if err != nil {
+		return nil, err
+	}
+	out := make([]Customer, 0, len(rows))
+	for _, r := range rows {
+		out = append(out, toCustomer(r))
+	}
+	return out, nil
+}
+
+func (s OutboundService) CreateCustomer(ctx context.Context, in CustomerInput, actor uuid.UUID) (Customer, error) {
+	if err := in.validate(); err != nil {
+		return Customer{}, err
+	}
+	var out Customer
+	err := s.mutate(ctx, actor, "customer.created", "customers", func(q *sqlcgen.Queries) (string, any, error) {
+		r, err := q.CreateCustomer(ctx, sqlcgen.CreateCustomerParams{
+			Code: in.Code, Name: in.Name, Address: store.Text(in.Address), City: store.Text(in.City), PostalCode: store.Text(in.PostalCode),
+			Country: store.Text(in.Country), VatNumber: store.Text(in.VatNumber),
+		})
+		if err != nil {
+			return "", nil, err
+		}
+		out = toCustomer(r)
+		return out.ID, out, nil
+	})
+	return out, err
+}
+
+func (s OutboundService) UpdateCustomer(ctx context.Context, id uuid.UUID, in CustomerInput, actor uuid.UUID) (Customer, error) {
+	if err := in.validate(); err != nil {
+		return Customer{}, err
+	}
+	var out Customer
+	err := s.mutate(ctx, actor, "customer.updated", "customers", func(q *sqlcgen.Queries) (string, any, error) {
+		r, err := q.UpdateCustomer(ctx, sqlcgen.UpdateCustomerParams{
+			ID: store.UUID(id), Code: in.Code, Name: in.Name, Address: store.Text(in.Address), City: store.Text(in.City), PostalCode: store.Text(in.PostalCode),
+			Country: store.Text(in.Country), VatNumber: store.Text(in.VatNumber),
+		})
+		if err != nil {
+			return "", nil, err
+		}
+		out = toCustomer(r)
+		return out.ID, out, nil
+	})
+	return out, err
+}
+
+func (s OutboundService) DeactivateCustomer(ctx context.Context, id uuid.UUID, actor uuid.UUID) (Customer, error) {
+	var out Customer
+	err := s.mutate(ctx, actor, "customer.deactivated", "customers", func(q *sqlcgen.Queries) (string, any, error) {
+		r, err := q.SetCustomerActive(ctx, sqlcgen.SetCustomerActiveParams{ID: store.UUID(id), Active: false})
+		if err != nil {
+			return "", nil, err
+		}
+		out = toCustomer(r)
+		return out.ID, out, nil
+	})
+	return out, err
+}
+
+// CreateSalesOrder numbers the lines 1..n in the order given.
+func (s OutboundService) CreateSalesOrder(ctx context.Context, in SalesOrderInput, actor uuid.UUID) (SalesOrder, error) {
+	if strings.TrimSpace(in.OrderNumber) == "" || len(in.Lines) == 0 {
+		return SalesOrder{}, fmt.Errorf("%w: order_number and lines are required", ErrInvalid)
+	}
+	customerID, err := store.ParseUUID("customer_id", in.CustomerID)
+	if err != nil {
+		return SalesOrder{}, err
+	}
+	warehouseID, err := store.ParseUUID("warehouse_id", in.WarehouseID)
+	if err != nil {
+		return SalesOrder{}, err
+	}
+	cutoff, err := parseTs("cutoff_at", in.CutoffAt)
+	if err != nil {
+		return SalesOrder{}, err
+	}
+	var out SalesOrder
+	err = s.mutate(ctx, actor, "orders.created", "sales_orders", func(q *sqlcgen.Queries) (string, any, error) {
+		customer, err := q.GetCustomer(ctx, store.UUID(customerID))
+		if err != nil {
+			return "", nil, err
+		}
+		if !customer.Active {
+			return "", nil, fmt.Errorf("%w: customer %s is inactive", ErrInvalid, customer.Code)
+		}
+		so, err := q.CreateSalesOrder(ctx, sqlcgen.CreateSalesOrderParams{
+			OrderNumber: in.OrderNumber, CustomerID: customer.ID, WarehouseID: store.UUID(warehouseID), Carrier: store.Text(in.Carrier),
+			CutoffAt: cutoff, CreatedBy: store.UUID(actor),
+		})
+		if err != nil {
+			return "", nil, err
+		}
+		lines := make([]sqlcgen.SalesOrderLine, 0, len(in.Lines))
+		for i, l := range in.Lines {
+			itemID, err := store.ParseUUID("item_id", l.ItemID)
+			if err != nil {
+				return "", nil, err
+			}
+			base, err := s.Stock.BaseQty(ctx, q, itemID, l.Qty, l.Uom)
+			if err != nil {
+				return "", nil, fmt.Errorf("line %d: %w", i+1, err)
+			}
+			if base.Sign() <= 0 {
+				return "", nil, fmt.Errorf("%w: line %d: qty must be positive", ErrInvalid, i+1)
+			}
+			line, err := q.InsertSalesOrderLine(ctx, sqlcgen.InsertSalesOrderLineParams{OrderID: so.ID, LineNo: int32(i + 1), ItemID: store.UUID(itemID), QtyOrdered: qty.ToNumeric(base)})
+			if err != nil {
+				return "", nil, err
+			}
+			lines = append(lines, line)
+		}
+		out = toSalesOrder(so, lines, nil)
+		return out.ID, out, nil
+	})
+	return out, err
+}
+
+func (s OutboundService) GetSalesOrder(ctx context.Context, id uuid.UUID) (SalesOrder, error) {
+	so, err := s.Queries.GetSalesOrder(ctx, store.UUID(id))
+	if err != nil {
+		return SalesOrder{}, store.MapErr(err, conflictFields)
+	}
+	return s.withLines(ctx, s.Queries, so)
+}
+
+// ListSalesOrders returns headers only; fetch one order for its lines.
+func (s OutboundService) ListSalesOrders(ctx context.Context, f SalesOrderFilter) ([]SalesOrder, error) {
+	p := sqlcgen.ListSalesOrdersParams{Status: f.Status, Carrier: f.Carrier, PageLimit: f.Limit, PageOffset: f.Offset}
+	for _, u := range []struct {
+		name, v string
+		dst     *pgtype.UUID
+	}{{"customer_id", f.CustomerID, &p.CustomerID}, {"warehouse_id", f.WarehouseID, &p.WarehouseID}, {"wave_id", f.WaveID, &p.WaveID}} {
+		if u.v == "" {
+			continue
+		}
+		id, err := store.ParseUUID(u.name, u.v)
+		if err != nil {
+			return nil, err
+		}
+		*u.dst = store.UUID(id)
+	}
+	rows, err := s.Queries.ListSalesOrders(ctx, p)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]SalesOrder, 0, len(rows))
+	for _, r := range rows {
+		out = append(out, toSalesOrder(r, nil, nil))
+	}
+	return out, nil
+}
+
+// AllocateSalesOrder reserves what each line still needs from free stock in
+// the order's warehouse. Lines that cannot be covered keep the rest as
+// qty_open; the order becomes allocated once nothing is open. Calling it
+// again retries only the open quantities.
+func (s OutboundService) AllocateSalesOrder(ctx context.Context, id uuid.UUID, req AllocateOrderRequest, actor uuid.UUID) (AllocateOrderResponse, error) {
+	var out AllocateOrderResponse
+	err := s.mutate(ctx, actor, "orders.allocated", "sales_orders", func(q *sqlcgen.Queries) (string, any, error) {
+		so, err := q.LockSalesOrder(ctx, store.UUID(id))
+		if err != nil {
+			return "", nil, err
+		}
+		if so.Status != StatusOpen && so.Status != StatusAllocated {
+			return "", nil, fmt.Errorf("%w: order is %s", ErrInvalid, so.Status)
+		}
+		lines, err := q.ListSalesOrderLines(ctx, so.ID)
+		if err != nil {
+			return "", nil, err
+		}
+		allocated, err := allocatedByLine(ctx, q, so.ID)
+		if err != nil {
+			return "", nil, err
+		}
+		out.Allocations = []stocksvc.Allocation{}
+		open := false
+		for _, l := range lines {
+			need := lineOpen(l, allocated[l.ID])
+			if need.Sign() == 0 {
+				continue
+			}
+			allocs, short, err := s.Stock.Reserve(ctx, q, stocksvc.AllocateSpec{
+				ItemID: l.ItemID, WarehouseID: so.WarehouseID, Qty: need, RefType: RefOrderLine, RefID: l.ID.String(),
+				Strategy: req.Strategy, AllowPartial: true,
+			}, actor)
+			if err != nil {
+				return "", nil, err
+			}
+			out.Allocations = append(out.Allocations, allocs...)
+			allocated[l.ID] = qty.Add(allocated[l.ID], qty.Sub(need, short))
+			open = open || short.Sign() > 0
+		}
+		if !open && so.Status == StatusOpen {
+			if so, err = q.SetSalesOrderStatus(ctx, sqlcgen.SetSalesOrderStatusParams{ID: so.ID, Status: StatusAllocated}); err != nil {
+				return "", nil, err
+			}
+		}
+		out.SalesOrder = toSalesOrder(so, lines, allocated)
+		return out.ID, out, nil
+	})
+	return out, err
+}
+
+// CancelSalesOrder releases the order's reservations. Orders already in a
+// wave have to leave it first (cancel the wave).
+func (s OutboundService) CancelSalesOrder(ctx context.Context, id uuid.UUID, actor uuid.UUID) (SalesOrder, error) {
+	var out SalesOrder
+	err := s.mutate(ctx, actor, "orders.cancelled", "sales_orders", func(q *sqlcgen.Queries) (string, any, error) {
+		so, err := q.LockSalesOrder(ctx, store.UUID(id))
+		if err != nil {
+			return "", nil, err
+		}
+		if so.Status != StatusOpen && so.Status != StatusAllocated {
+			return "", nil, fmt.Errorf("%w: order is %s", ErrInvalid, so.Status)
+		}
+		lines, err := q.ListSalesOrderLines(ctx, so.ID)
+		if err != nil {
+			return "", nil, err
+		}
+		for _, l := range lines {
+			if _, err := s.Stock.ReleaseByRef(ctx, q, RefOrderLine, l.ID.String(), actor); err != nil {
+				return "", nil, err
+			}
+		}
+		if so, err = q.SetSalesOrderStatus(ctx, sqlcgen.SetSalesOrderStatusParams{ID: so.ID, Status: StatusCancelled}); err != nil {
+			return "", nil, err
+		}
+		out = toSalesOrder(so, lines, nil)
+		return out.ID, out, nil
+	})
+	return out, err
+}
+
+func (s OutboundService) withLines(ctx context.Context, q *sqlcgen.Queries, so sqlcgen.SalesOrder) (SalesOrder, error) {
+	lines, err := q.ListSalesOrderLines(ctx, so.ID)
+	if err != nil {
+		return SalesOrder{}, err
+	}
+	allocated, err := allocatedByLine(ctx, q, so.ID)
+	if err != nil {
+		return SalesOrder{}, err
+	}
+	return toSalesOrder(so, lines, allocated), nil
+}
+
+func allocatedByLine(ctx context.Context, q *sqlcgen.Queries, orderID pgtype.UUID) (map[pgtype.UUID]*big.Rat, error) {
+	rows, err := q.ListSalesOrderLineAllocated(ctx, orderID)
+	if err != nil {
+		return nil, err
+	}
+	m := make(map[pgtype.UUID]*big.Rat, len(rows))
+	for _, r := range rows {
+		m[r.LineID] = qty.FromNumeric(r.QtyAllocated)
+	}
+	return m, nil
+}
+
+// lineOpen is what a line still needs reserved: ordered minus what is
+// allocated, already picked or written off as short. It never goes negative.
+func lineOpen(l sqlcgen.SalesOrderLine, allocated *big.Rat) *big.Rat {
+	if allocated == nil {
+		allocated = qty.Zero()
+	}
+	open := qty.Sub(qty.FromNumeric(l.QtyOrdered), qty.Add(allocated, qty.Add(qty.FromNumeric(l.QtyPicked), qty.FromNumeric(l.QtyShort))))
+	if open.Sign() < 0 {
+		return qty.Zero()
+	}
+	return open
+}
+
+// mutate runs fn in a transaction with its outbox event and audit entry;
+// see store.Mutate.
+func (s OutboundService) mutate(ctx context.Context, actor uuid.UUID, topic, resource string, fn func(q *sqlcgen.Queries) (string, any, error)) error {
+	return store.Mutate(ctx, s.DB, s.Queries, actor, topic, resource, conflictFields, fn)
+}
+
+func (in CustomerInput) validate() error {
+	if strings.TrimSpace(in.Code) == "" || strings.TrimSpace(in.Name) == "" {
+		return fmt.Errorf("%w: code and name are required", ErrInvalid)
+	}
+	return nil
+}
+
+func toCustomer(r sqlcgen.Customer) Customer {
+	return Customer{
+		ID: r.ID.String(), Code: r.Code, Name: r.Name, Address: r.Address.String, City: r.City.String,
+		PostalCode: r.PostalCode.String, Country: r.Country.String, VatNumber: r.VatNumber.String, Active: r.Active,
+	}
+}
+
+// toSalesOrder reports allocations only when allocated is given; headers in
+// lists leave it nil.
+func toSalesOrder(so sqlcgen.SalesOrder, lines []sqlcgen.SalesOrderLine, allocated map[pgtype.UUID]*big.Rat) SalesOrder {
+	out := SalesOrder{
+		ID: so.ID.String(), OrderNumber: so.OrderNumber, CustomerID: so.CustomerID.String(), WarehouseID: so.WarehouseID.String(),
+		Carrier: so.Carrier.String, Status: so.Status, CreatedAt: so.CreatedAt.Time,
+	}
+	if so.CutoffAt.Valid {
+		out.CutoffAt = &so.CutoffAt.Time
+	}
+	if so.WaveID.Valid {
+		out.WaveID = so.WaveID.String()
+	}
+	for _, l := range lines {
+		a := allocated[l.ID]
+		if a == nil {
+			a = qty.Zero()
+		}
+		out.Lines = append(out.Lines, SalesOrderLine{
+			ID: l.ID.String(), LineNo: l.LineNo, ItemID: l.ItemID.String(),
+			QtyOrdered: qty.String(qty.FromNumeric(l.QtyOrdered)), QtyAllocated: qty.String(a),
+			QtyPicked: qty.String(qty.FromNumeric(l.QtyPicked)), QtyShort: qty.String(qty.FromNumeric(l.QtyShort)),
+			QtyShipped: qty.String(qty.FromNumeric(l.QtyShipped)), QtyOpen: qty.String(lineOpen(l, a)),
+		})
+	}
+	return out
+}
+
+func parseTs(field, v string) (pgtype.Timestamptz, error) {
+	if v == "" {
+		return pgtype.Timestamptz{}, nil
+	}
+	t, err := time.Parse(time.RFC3339, v)
+	if err != nil {
+		return pgtype.Timestamptz{}, fmt.Errorf("%w: %s must be RFC 3339", ErrInvalid, field)
+	}
+	return pgtype.Timestamptz{Time: t, Valid: true}, nil
+}
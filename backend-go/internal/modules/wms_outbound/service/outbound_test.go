@@ -0,0 +1,82 @@
+package service
+
+import (
+	"math/big"
+	"strings"
+	"testing"
+	"time"
+
+	"erpwms/backend-go/internal/common/qty"
+	"erpwms/backend-go/internal/common/store"
+	"erpwms/backend-go/internal/db/sqlcgen"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+func order(no, carrier, cutoff string) sqlcgen.SalesOrder {
+	o := sqlcgen.SalesOrder{OrderNumber: no, Carrier: store.Text(carrier)}
+	if cutoff != "" {
+		t, _ := time.Parse(time.RFC3339, cutoff)
+		o.CutoffAt = pgtype.Timestamptz{Time: t, Valid: true}
+	}
+	return o
+}
+
+func groups(gs [][]sqlcgen.SalesOrder) string {
+	var out []string
+	for _, g := range gs {
+		var nos []string
+		for _, o := range g {
+			nos = append(nos, o.OrderNumber)
+		}
+		out = append(out, strings.Join(nos, ","))
+	}
+	return strings.Join(out, " | ")
+}
+
+var orders = []sqlcgen.SalesOrder{
+	order("A", "DHL", "2026-03-02T16:00:00Z"),
+	order("B", "UPS", "2026-03-02T16:00:00Z"),
+	order("C", "DHL", "2026-03-02T12:00:00Z"),
+	order("D", "", ""),
+}
+
+func TestGroupOrdersByCarrier(t *testing.T) {
+	if got := groups(groupOrders(orders, GroupByCarrier)); got != "A,C | B | D" {
+		t.Fatalf("got %q", got)
+	}
+}
+
+func TestGroupOrdersByCutoff(t *testing.T) {
+	if got := groups(groupOrders(orders, GroupByCutoff)); got != "A,B | C | D" {
+		t.Fatalf("got %q", got)
+	}
+}
+
+func TestGroupOrdersNone(t *testing.T) {
+	if got := groups(groupOrders(orders, GroupByNone)); got != "A,B,C,D" {
+		t.Fatalf("got %q", got)
+	}
+}
+
+func TestWaveKeyTakesEarliestCutoff(t *testing.T) {
+	carrier, cutoff := waveKey([]sqlcgen.SalesOrder{orders[0], orders[2]}, GroupByCarrier)
+	if carrier.String != "DHL" || cutoff.Time.Hour() != 12 {
+		t.Fatalf("got %q %v", carrier.String, cutoff.Time)
+	}
+	if carrier, _ := waveKey(orders[:2], GroupByCutoff); carrier.Valid {
+		t.Fatalf("cutoff waves carry no carrier, got %q", carrier.String)
+	}
+}
+
+func TestLineOpen(t *testing.T) {
+	l := sqlcgen.SalesOrderLine{QtyOrdered: qty.ToNumeric(big.NewRat(10, 1)), QtyPicked: qty.ToNumeric(big.NewRat(3, 1)), QtyShort: qty.ToNumeric(big.NewRat(1, 1))}
+	if got := lineOpen(l, big.NewRat(4, 1)); got.Cmp(big.NewRat(2, 1)) != 0 {
+		t.Fatalf("got %s", got.RatString())
+	}
+	if got := lineOpen(l, nil); got.Cmp(big.NewRat(6, 1)) != 0 {
+		t.Fatalf("without allocations: got %s", got.RatString())
+	}
+	if got := lineOpen(l, big.NewRat(9, 1)); got.Sign() != 0 {
+		t.Fatalf("over-allocated: got %s", got.RatString())
+	}
+}
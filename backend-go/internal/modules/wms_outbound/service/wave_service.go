@@ -0,0 +1,511 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"erpwms/backend-go/internal/common/idempotency"
+	"erpwms/backend-go/internal/common/qty"
+	"erpwms/backend-go/internal/common/store"
+	"erpwms/backend-go/internal/db/sqlcgen"
+	stocksvc "erpwms/backend-go/internal/modules/wms_stock/service"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// RefPickTask is the ledger ref_type of pick moves; the ref_id is the
+// pick_tasks id.
+const RefPickTask = "pick_task"
+
+// StagingLocationType is the location type picks are moved into.
+const StagingLocationType = "staging"
+
+// Wave grouping keys.
+const (
+	GroupByCarrier = "carrier"
+	GroupByCutoff  = "cutoff"
+	GroupByNone    = "none"
+)
+
+// Wave and pick task states.
+const (
+	WaveCompleted = "completed"
+	TaskOpen      = "open"
+	TaskPicked    = "picked"
+	TaskShort     = "short"
+)
+
+const maxWaveOrders = 500
+
+// WaveRequest takes the allocated orders of a warehouse that are not in a
+// wave yet, optionally only those of Carrier or with a cutoff at or before
+// CutoffBefore (RFC 3339), and makes one wave per GroupBy key.
+type WaveRequest struct {
+	WarehouseID       string `json:"warehouse_id"`
+	StagingLocationID string `json:"staging_location_id"`
+	GroupBy           string `json:"group_by,omitempty"`
+	Carrier           string `json:"carrier,omitempty"`
+	CutoffBefore      string `json:"cutoff_before,omitempty"`
+	MaxOrders         int32  `json:"max_orders,omitempty"`
+}
+
+type Wave struct {
+	ID                string     `json:"id"`
+	WaveNo            int64      `json:"wave_no"`
+	WarehouseID       string     `json:"warehouse_id"`
+	Carrier           string     `json:"carrier,omitempty"`
+	CutoffAt          *time.Time `json:"cutoff_at,omitempty"`
+	StagingLocationID string     `json:"staging_location_id"`
+	Status            string     `json:"status"`
+	CreatedAt         time.Time  `json:"created_at"`
+	OrderIDs          []string   `json:"order_ids,omitempty"`
+	Tasks             int        `json:"tasks,omitempty"`
+}
+
+type WaveFilter struct {
+	WarehouseID string
+	Status      string
+	Limit       int32
+	Offset      int32
+}
+
+// PickTask is one line of a pick list, walked in Sequence order.
+type PickTask struct {
+	ID           string `json:"id"`
+	Sequence     int32  `json:"sequence"`
+	Status       string `json:"status"`
+	OrderID      string `json:"order_id"`
+	OrderLineID  string `json:"order_line_id"`
+	ItemID       string `json:"item_id"`
+	Sku          string `json:"sku,omitempty"`
+	LocationID   string `json:"location_id"`
+	LocationCode string `json:"location_code,omitempty"`
+	Path         string `json:"path,omitempty"`
+	LotCode      string `json:"lot_code,omitempty"`
+	SerialNo     string `json:"serial_no,omitempty"`
+	Qty          string `json:"qty"`
+	QtyPicked    string `json:"qty_picked"`
+	ShortReason  string `json:"short_reason,omitempty"`
+	MoveID       string `json:"move_id,omitempty"`
+}
+
+// PickConfirmRequest reports what was taken from the bin, in the item's base
+// unit. Anything less than the task quantity is a short pick and needs
+// ShortReason.
+type PickConfirmRequest struct {
+	Qty         string `json:"qty"`
+	ShortReason string `json:"short_reason,omitempty"`
+}
+
+// PickConfirmResponse lists the tasks created by re-allocating a short pick;
+// QtyUnresolved is what could not be re-allocated and stays short on the
+// order line.
+type PickConfirmResponse struct {
+	Task          PickTask   `json:"task"`
+	Reallocated   []PickTask `json:"reallocated"`
+	QtyUnresolved string     `json:"qty_unresolved"`
+	OrderStatus   string     `json:"order_status"`
+	WaveStatus    string     `json:"wave_status"`
+}
+
+// CreateWaves groups the matching orders, moves them to picking and writes
+// their pick tasks sorted by location path.
+func (s OutboundService) CreateWaves(ctx context.Context, req WaveRequest, actor uuid.UUID) ([]Wave, error) {
+	warehouseID, err := store.ParseUUID("warehouse_id", req.WarehouseID)
+	if err != nil {
+		return nil, err
+	}
+	stagingID, err := store.ParseUUID("staging_location_id", req.StagingLocationID)
+	if err != nil {
+		return nil, err
+	}
+	cutoff, err := parseTs("cutoff_before", req.CutoffBefore)
+	if err != nil {
+		return nil, err
+	}
+	if req.GroupBy == "" {
+		req.GroupBy = GroupByCarrier
+	}
+	if req.GroupBy != GroupByCarrier && req.GroupBy != GroupByCutoff && req.GroupBy != GroupByNone {
+		return nil, fmt.Errorf("%w: group_by must be carrier, cutoff or none", ErrInvalid)
+	}
+	if req.MaxOrders <= 0 || req.MaxOrders > maxWaveOrders {
+		req.MaxOrders = maxWaveOrders
+	}
+
+	tx, err := s.DB.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+	q := s.Queries.WithTx(tx)
+
+	staging, err := q.GetLocation(ctx, store.UUID(stagingID))
+	if err != nil {
+		return nil, store.MapErr(err, conflictFields)
+	}
+	if !staging.Active || staging.Type != StagingLocationType || staging.WarehouseID != store.UUID(warehouseID) {
+		return nil, fmt.Errorf("%w: staging_location_id must be an active %s location of the warehouse", ErrInvalid, StagingLocationType)
+	}
+	orders, err := q.ListWaveableOrders(ctx, sqlcgen.ListWaveableOrdersParams{WarehouseID: staging.WarehouseID, Carrier: req.Carrier, CutoffBefore: cutoff, PageLimit: req.MaxOrders})
+	if err != nil {
+		return nil, err
+	}
+	if len(orders) == 0 {
+		return nil, fmt.Errorf("%w: no allocated orders match", ErrInvalid)
+	}
+
+	var out []Wave
+	for _, group := range groupOrders(orders, req.GroupBy) {
+		carrier, cutoffAt := waveKey(group, req.GroupBy)
+		w, err := q.CreateWave(ctx, sqlcgen.CreateWaveParams{WarehouseID: staging.WarehouseID, Carrier: carrier, CutoffAt: cutoffAt, StagingLocationID: staging.ID, CreatedBy: store.UUID(actor)})
+		if err != nil {
+			return nil, err
+		}
+		ids := make([]pgtype.UUID, 0, len(group))
+		for _, o := range group {
+			if err := q.SetSalesOrderWave(ctx, sqlcgen.SetSalesOrderWaveParams{ID: o.ID, WaveID: w.ID}); err != nil {
+				return nil, err
+			}
+			ids = append(ids, o.ID)
+		}
+		tasks, err := insertPickTasks(ctx, q, w.ID, sqlcgen.ListPickAllocationsParams{OrderIds: ids}, 1)
+		if err != nil {
+			return nil, err
+		}
+		wave := toWave(w, group)
+		wave.Tasks = len(tasks)
+		payload, _ := json.Marshal(wave)
+		if err := store.Record(ctx, q, actor, "wave.created", "waves", wave.ID, payload); err != nil {
+			return nil, err
+		}
+		out = append(out, wave)
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (s OutboundService) GetWave(ctx context.Context, id uuid.UUID) (Wave, error) {
+	w, err := s.Queries.GetWave(ctx, store.UUID(id))
+	if err != nil {
+		return Wave{}, store.MapErr(err, conflictFields)
+	}
+	orders, err := s.Queries.ListSalesOrders(ctx, sqlcgen.ListSalesOrdersParams{WaveID: w.ID, PageLimit: maxWaveOrders})
+	if err != nil {
+		return Wave{}, err
+	}
+	return toWave(w, orders), nil
+}
+
+func (s OutboundService) ListWaves(ctx context.Context, f WaveFilter) ([]Wave, error) {
+	p := sqlcgen.ListWavesParams{Status: f.Status, PageLimit: f.Limit, PageOffset: f.Offset}
+	if f.WarehouseID != "" {
+		id, err := store.ParseUUID("warehouse_id", f.WarehouseID)
+		if err != nil {
+			return nil, err
+		}
+		p.WarehouseID = store.UUID(id)
+	}
+	rows, err := s.Queries.ListWaves(ctx, p)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]Wave, 0, len(rows))
+	for _, r := range rows {
+		out = append(out, toWave(r, nil))
+	}
+	return out, nil
+}
+
+// PickList returns the wave's tasks in walking order.
+func (s OutboundService) PickList(ctx context.Context, waveID uuid.UUID, status string) ([]PickTask, error) {
+	if _, err := s.Queries.GetWave(ctx, store.UUID(waveID)); err != nil {
+		return nil, store.MapErr(err, conflictFields)
+	}
+	rows, err := s.Queries.ListPickList(ctx, sqlcgen.ListPickListParams{WaveID: store.UUID(waveID), Status: status})
+	if err != nil {
+		return nil, err
+	}
+	out := make([]PickTask, 0, len(rows))
+	for _, r := range rows {
+		out = append(out, PickTask{
+			ID: r.ID.String(), Sequence: r.Sequence, Status: r.Status, OrderID: r.OrderID.String(), OrderLineID: r.OrderLineID.String(),
+			ItemID: r.ItemID.String(), Sku: r.Sku, LocationID: r.LocationID.String(), LocationCode: r.LocationCode, Path: r.Path.String,
+			LotCode: r.LotCode.String, SerialNo: r.SerialNo.String, Qty: qty.String(qty.FromNumeric(r.Qty)),
+			QtyPicked: qty.String(qty.FromNumeric(r.QtyPicked)), ShortReason: r.ShortReason.String, MoveID: store.OptUUID(r.MoveID),
+		})
+	}
+	return out, nil
+}
+
+// CancelWave is possible until the first pick is confirmed. Its orders go
+// back to allocated and keep their reservations.
+func (s OutboundService) CancelWave(ctx context.Context, id uuid.UUID, actor uuid.UUID) (Wave, error) {
+	var out Wave
+	err := s.mutate(ctx, actor, "wave.cancelled", "waves", func(q *sqlcgen.Queries) (string, any, error) {
+		w, err := q.LockWave(ctx, store.UUID(id))
+		if err != nil {
+			return "", nil, err
+		}
+		if w.Status != StatusPicking {
+			return "", nil, fmt.Errorf("%w: wave is %s", ErrInvalid, w.Status)
+		}
+		picked, err := q.WaveHasConfirmedPicks(ctx, w.ID)
+		if err != nil {
+			return "", nil, err
+		}
+		if picked {
+			return "", nil, fmt.Errorf("%w: wave has confirmed picks", ErrInvalid)
+		}
+		orders, err := q.ListSalesOrders(ctx, sqlcgen.ListSalesOrdersParams{WaveID: w.ID, PageLimit: maxWaveOrders})
+		if err != nil {
+			return "", nil, err
+		}
+		if err := q.CancelOpenPickTasks(ctx, w.ID); err != nil {
+			return "", nil, err
+		}
+		if err := q.DetachWaveOrders(ctx, w.ID); err != nil {
+			return "", nil, err
+		}
+		if w, err = q.SetWaveStatus(ctx, sqlcgen.SetWaveStatusParams{ID: w.ID, Status: StatusCancelled}); err != nil {
+			return "", nil, err
+		}
+		out = toWave(w, orders)
+		return out.ID, out, nil
+	})
+	return out, err
+}
+
+// ConfirmPick moves what was picked from the bin to the wave's staging
+// location and consumes that much of the allocation. On a short pick the
+// rest of the allocation is released and re-allocated from other bins; the
+// new allocations become tasks at the end of the same wave. The order is
+// picked when none of its tasks is open, the wave completed when none of
+// its tasks is.
+func (s OutboundService) ConfirmPick(ctx context.Context, taskID uuid.UUID, req PickConfirmRequest, actor uuid.UUID, endpoint, idemKey string) (PickConfirmResponse, error) {
+	reqHash := idempotency.Hash([]any{taskID.String(), req})
+	var prev PickConfirmResponse
+	if found, err := idempotency.Replay(ctx, s.Queries, endpoint, idemKey, reqHash, &prev); err != nil || found {
+		return prev, err
+	}
+	picked, err := qty.Parse(req.Qty)
+	if err != nil || picked.Sign() < 0 {
+		return PickConfirmResponse{}, fmt.Errorf("%w: qty must be a non-negative decimal", ErrInvalid)
+	}
+
+	tx, err := s.DB.Begin(ctx)
+	if err != nil {
+		return PickConfirmResponse{}, err
+	}
+	defer tx.Rollback(ctx)
+	q := s.Queries.WithTx(tx)
+
+	t, err := q.LockPickTask(ctx, store.UUID(taskID))
+	if err != nil {
+		return PickConfirmResponse{}, store.MapErr(err, conflictFields)
+	}
+	if t.Status != TaskOpen {
+		return PickConfirmResponse{}, fmt.Errorf("%w: task is %s", ErrInvalid, t.Status)
+	}
+	// The wave lock serializes confirmations of one wave, which keeps the
+	// sequence of re-allocated tasks and the completion check consistent.
+	w, err := q.LockWave(ctx, t.WaveID)
+	if err != nil {
+		return PickConfirmResponse{}, err
+	}
+	want := qty.FromNumeric(t.Qty)
+	if picked.Cmp(want) > 0 {
+		return PickConfirmResponse{}, fmt.Errorf("%w: qty exceeds the task quantity %s", ErrInvalid, qty.String(want))
+	}
+	short := qty.Sub(want, picked)
+	if short.Sign() > 0 && strings.TrimSpace(req.ShortReason) == "" {
+		return PickConfirmResponse{}, fmt.Errorf("%w: short_reason is required for a short pick", ErrInvalid)
+	}
+
+	var moveID pgtype.UUID
+	if picked.Sign() > 0 {
+		move, err := s.Stock.ConsumeAllocation(ctx, q, stocksvc.ConsumeRequest{
+			AllocationID: uuid.UUID(t.AllocationID.Bytes), Qty: picked, ToLocationID: uuid.UUID(w.StagingLocationID.Bytes),
+			ReasonCode: "PICK", RefType: RefPickTask, RefID: t.ID.String(),
+		}, actor)
+		if err != nil {
+			return PickConfirmResponse{}, err
+		}
+		moveID = move.MoveID
+	}
+	resp := PickConfirmResponse{Reallocated: []PickTask{}, QtyUnresolved: "0"}
+	status := TaskPicked
+	unresolved := qty.Zero()
+	if short.Sign() > 0 {
+		status = TaskShort
+		if err := s.Stock.ReleaseQty(ctx, q, uuid.UUID(t.AllocationID.Bytes), short, actor); err != nil {
+			return PickConfirmResponse{}, err
+		}
+		allocs, rest, err := s.Stock.Reserve(ctx, q, stocksvc.AllocateSpec{
+			ItemID: t.ItemID, WarehouseID: w.WarehouseID, ExcludeLocationID: t.LocationID, Qty: short,
+			RefType: RefOrderLine, RefID: t.OrderLineID.String(), AllowPartial: true,
+		}, actor)
+		if err != nil {
+			return PickConfirmResponse{}, err
+		}
+		unresolved = rest
+		if len(allocs) > 0 {
+			ids := make([]pgtype.UUID, 0, len(allocs))
+			for _, a := range allocs {
+				id, _ := uuid.Parse(a.ID)
+				ids = append(ids, store.UUID(id))
+			}
+			next, err := q.NextPickSequence(ctx, w.ID)
+			if err != nil {
+				return PickConfirmResponse{}, err
+			}
+			tasks, err := insertPickTasks(ctx, q, w.ID, sqlcgen.ListPickAllocationsParams{AllocationIds: ids}, next)
+			if err != nil {
+				return PickConfirmResponse{}, err
+			}
+			for _, nt := range tasks {
+				resp.Reallocated = append(resp.Reallocated, toPickTask(nt))
+			}
+		}
+		resp.QtyUnresolved = qty.String(unresolved)
+	}
+	if _, err := q.AddSalesOrderLinePicked(ctx, sqlcgen.AddSalesOrderLinePickedParams{ID: t.OrderLineID, QtyPicked: qty.ToNumeric(picked), QtyShort: qty.ToNumeric(unresolved)}); err != nil {
+		return PickConfirmResponse{}, err
+	}
+	if t, err = q.ConfirmPickTask(ctx, sqlcgen.ConfirmPickTaskParams{ID: t.ID, QtyPicked: qty.ToNumeric(picked), Status: status, ShortReason: store.Text(req.ShortReason), MoveID: moveID, PickedBy: store.UUID(actor)}); err != nil {
+		return PickConfirmResponse{}, err
+	}
+	resp.Task = toPickTask(t)
+
+	so, err := q.LockSalesOrder(ctx, t.OrderID)
+	if err != nil {
+		return PickConfirmResponse{}, err
+	}
+	if open, err := q.OrderHasOpenPicks(ctx, so.ID); err != nil {
+		return PickConfirmResponse{}, err
+	} else if !open {
+		if so, err = q.SetSalesOrderStatus(ctx, sqlcgen.SetSalesOrderStatusParams{ID: so.ID, Status: StatusPicked}); err != nil {
+			return PickConfirmResponse{}, err
+		}
+		body, _ := json.Marshal(toSalesOrder(so, nil, nil))
+		if err := store.Record(ctx, q, actor, "orders.picked", "sales_orders", so.ID.String(), body); err != nil {
+			return PickConfirmResponse{}, err
+		}
+	}
+	resp.OrderStatus = so.Status
+	if open, err := q.WaveHasOpenPicks(ctx, w.ID); err != nil {
+		return PickConfirmResponse{}, err
+	} else if !open {
+		if w, err = q.SetWaveStatus(ctx, sqlcgen.SetWaveStatusParams{ID: w.ID, Status: WaveCompleted}); err != nil {
+			return PickConfirmResponse{}, err
+		}
+		body, _ := json.Marshal(toWave(w, nil))
+		if err := store.Record(ctx, q, actor, "wave.completed", "waves", w.ID.String(), body); err != nil {
+			return PickConfirmResponse{}, err
+		}
+	}
+	resp.WaveStatus = w.Status
+
+	payload, _ := json.Marshal(resp)
+	if err := store.Record(ctx, q, actor, "outbound.picked", "pick_tasks", t.ID.String(), payload); err != nil {
+		return PickConfirmResponse{}, err
+	}
+	if err := idempotency.Remember(ctx, q, endpoint, idemKey, store.UUID(actor), reqHash, resp); err != nil {
+		return PickConfirmResponse{}, err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return PickConfirmResponse{}, err
+	}
+	return resp, nil
+}
+
+// insertPickTasks writes one task per active allocation matched by p, in
+// location path order, numbering them from seq.
+func insertPickTasks(ctx context.Context, q *sqlcgen.Queries, waveID pgtype.UUID, p sqlcgen.ListPickAllocationsParams, seq int32) ([]sqlcgen.PickTask, error) {
+	rows, err := q.ListPickAllocations(ctx, p)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]sqlcgen.PickTask, 0, len(rows))
+	for _, r := range rows {
+		t, err := q.InsertPickTask(ctx, sqlcgen.InsertPickTaskParams{
+			WaveID: waveID, OrderID: r.OrderID, OrderLineID: r.OrderLineID, AllocationID: r.AllocationID,
+			ItemID: r.ItemID, LocationID: r.LocationID, Qty: r.Qty, Sequence: seq,
+		})
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, t)
+		seq++
+	}
+	return out, nil
+}
+
+// groupOrders splits orders by the grouping key, keeping the order in which
+// each key first appears and the order of orders within a group.
+func groupOrders(orders []sqlcgen.SalesOrder, by string) [][]sqlcgen.SalesOrder {
+	index := map[string]int{}
+	var out [][]sqlcgen.SalesOrder
+	for _, o := range orders {
+		var key string
+		switch by {
+		case GroupByCarrier:
+			key = o.Carrier.String
+		case GroupByCutoff:
+			if o.CutoffAt.Valid {
+				key = o.CutoffAt.Time.UTC().Format(time.RFC3339)
+			}
+		}
+		i, ok := index[key]
+		if !ok {
+			i = len(out)
+			index[key] = i
+			out = append(out, nil)
+		}
+		out[i] = append(out[i], o)
+	}
+	return out
+}
+
+// waveKey is the carrier and cutoff a wave is labelled with: the carrier
+// when the wave is grouped by it, and the earliest cutoff of its orders.
+func waveKey(group []sqlcgen.SalesOrder, by string) (pgtype.Text, pgtype.Timestamptz) {
+	var carrier pgtype.Text
+	if by == GroupByCarrier {
+		carrier = group[0].Carrier
+	}
+	var cutoff pgtype.Timestamptz
+	for _, o := range group {
+		if o.CutoffAt.Valid && (!cutoff.Valid || o.CutoffAt.Time.Before(cutoff.Time)) {
+			cutoff = o.CutoffAt
+		}
+	}
+	return carrier, cutoff
+}
+
+func toWave(w sqlcgen.Wave, orders []sqlcgen.SalesOrder) Wave {
+	out := Wave{
+		ID: w.ID.String(), WaveNo: w.WaveNo, WarehouseID: w.WarehouseID.String(), Carrier: w.Carrier.String,
+		StagingLocationID: w.StagingLocationID.String(), Status: w.Status, CreatedAt: w.CreatedAt.Time,
+	}
+	if w.CutoffAt.Valid {
+		out.CutoffAt = &w.CutoffAt.Time
+	}
+	for _, o := range orders {
+		out.OrderIDs = append(out.OrderIDs, o.ID.String())
+	}
+	return out
+}
+
+func toPickTask(t sqlcgen.PickTask) PickTask {
+	return PickTask{
+		ID: t.ID.String(), Sequence: t.Sequence, Status: t.Status, OrderID: t.OrderID.String(), OrderLineID: t.OrderLineID.String(),
+		ItemID: t.ItemID.String(), LocationID: t.LocationID.String(), Qty: qty.String(qty.FromNumeric(t.Qty)),
+		QtyPicked: qty.String(qty.FromNumeric(t.QtyPicked)), ShortReason: t.ShortReason.String, MoveID: store.OptUUID(t.MoveID),
+	}
+}
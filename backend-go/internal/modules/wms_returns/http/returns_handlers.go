@@ -0,0 +1,190 @@
+package http
+
+import (
+	"strconv"
+
+	"erpwms/backend-go/internal/common/httperr"
+	"erpwms/backend-go/internal/modules/wms_returns/service"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type ReturnsHandlers struct {
+	Service service.ReturnsService
+}
+
+func (h ReturnsHandlers) ListReasons(c *gin.Context) {
+	rows, err := h.Service.ListReasons(c.Request.Context())
+	if err != nil {
+		httperr.Write(c, err)
+		return
+	}
+	c.JSON(200, gin.H{"items": rows})
+}
+
+func (h ReturnsHandlers) ListRmas(c *gin.Context) {
+	limit, offset := page(c)
+	rows, err := h.Service.ListRmas(c.Request.Context(), service.RmaFilter{
+		ShipmentID: c.Query("shipment_id"), CustomerID: c.Query("customer_id"), WarehouseID: c.Query("warehouse_id"),
+		Status: c.Query("status"), Limit: limit, Offset: offset,
+	})
+	if err != nil {
+		httperr.Write(c, err)
+		return
+	}
+	c.JSON(200, gin.H{"items": rows})
+}
+
+func (h ReturnsHandlers) GetRma(c *gin.Context) {
+	id, ok := paramID(c)
+	if !ok {
+		return
+	}
+	rma, err := h.Service.GetRma(c.Request.Context(), id)
+	if err != nil {
+		httperr.Write(c, err)
+		return
+	}
+	c.JSON(200, rma)
+}
+
+func (h ReturnsHandlers) Authorize(c *gin.Context) {
+	var in service.RmaInput
+	if err := c.ShouldBindJSON(&in); err != nil {
+		c.JSON(400, gin.H{"error": "bad request"})
+		return
+	}
+	actor, ok := actorID(c)
+	if !ok {
+		return
+	}
+	rma, err := h.Service.Authorize(c.Request.Context(), in, actor)
+	if err != nil {
+		httperr.Write(c, err)
+		return
+	}
+	c.JSON(201, rma)
+}
+
+func (h ReturnsHandlers) Receive(c *gin.Context) {
+	key := c.GetHeader("Idempotency-Key")
+	if key == "" {
+		c.JSON(400, gin.H{"error": "Idempotency-Key required"})
+		return
+	}
+	id, ok := paramID(c)
+	if !ok {
+		return
+	}
+	var in service.ReceiveRequest
+	if err := c.ShouldBindJSON(&in); err != nil {
+		c.JSON(400, gin.H{"error": "bad request"})
+		return
+	}
+	actor, ok := actorID(c)
+	if !ok {
+		return
+	}
+	resp, err := h.Service.Receive(c.Request.Context(), id, in, actor, "/api/rmas/receive", key)
+	if err != nil {
+		httperr.Write(c, err)
+		return
+	}
+	c.JSON(200, resp)
+}
+
+func (h ReturnsHandlers) Inspect(c *gin.Context) {
+	key := c.GetHeader("Idempotency-Key")
+	if key == "" {
+		c.JSON(400, gin.H{"error": "Idempotency-Key required"})
+		return
+	}
+	id, ok := paramID(c)
+	if !ok {
+		return
+	}
+	var in service.InspectRequest
+	if err := c.ShouldBindJSON(&in); err != nil {
+		c.JSON(400, gin.H{"error": "bad request"})
+		return
+	}
+	actor, ok := actorID(c)
+	if !ok {
+		return
+	}
+	resp, err := h.Service.Inspect(c.Request.Context(), id, in, actor, "/api/rmas/inspect", key)
+	if err != nil {
+		httperr.Write(c, err)
+		return
+	}
+	c.JSON(200, resp)
+}
+
+func (h ReturnsHandlers) Close(c *gin.Context) {
+	id, ok := paramID(c)
+	if !ok {
+		return
+	}
+	actor, ok := actorID(c)
+	if !ok {
+		return
+	}
+	rma, err := h.Service.Close(c.Request.Context(), id, actor)
+	if err != nil {
+		httperr.Write(c, err)
+		return
+	}
+	c.JSON(200, rma)
+}
+
+func (h ReturnsHandlers) Cancel(c *gin.Context) {
+	id, ok := paramID(c)
+	if !ok {
+		return
+	}
+	actor, ok := actorID(c)
+	if !ok {
+		return
+	}
+	rma, err := h.Service.Cancel(c.Request.Context(), id, actor)
+	if err != nil {
+		httperr.Write(c, err)
+		return
+	}
+	c.JSON(200, rma)
+}
+
+func (h ReturnsHandlers) Report(c *gin.Context) {
+	rep, err := h.Service.Report(c.Request.Context(), service.ReportFilter{
+		WarehouseID: c.Query("warehouse_id"), From: c.Query("from"), To: c.Query("to"),
+	})
+	if err != nil {
+		httperr.Write(c, err)
+		return
+	}
+	c.JSON(200, rep)
+}
+
+func page(c *gin.Context) (int32, int32) {
+	limit, _ := strconv.ParseInt(c.DefaultQuery("limit", "50"), 10, 32)
+	offset, _ := strconv.ParseInt(c.DefaultQuery("offset", "0"), 10, 32)
+	return int32(limit), int32(offset)
+}
+
+func paramID(c *gin.Context) (uuid.UUID, bool) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(400, gin.H{"error": "invalid id"})
+		return uuid.Nil, false
+	}
+	return id, true
+}
+
+func actorID(c *gin.Context) (uuid.UUID, bool) {
+	uid, err := uuid.Parse(c.GetString("user_id"))
+	if err != nil || uid == uuid.Nil {
+		c.JSON(401, gin.H{"error": "unauthorized"})
+		return uuid.Nil, false
+	}
+	return uid, true
+}
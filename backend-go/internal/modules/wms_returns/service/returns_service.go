@@ -0,0 +1,753 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"time"
+
+	"erpwms/backend-go/internal/common/idempotency"
+	"erpwms/backend-go/internal/common/qty"
+	"erpwms/backend-go/internal/common/store"
+	"erpwms/backend-go/internal/db/sqlcgen"
+	stocksvc "erpwms/backend-go/internal/modules/wms_stock/service"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+var (
+	ErrNotFound = store.ErrNotFound
+	ErrConflict = store.ErrConflict
+	ErrInvalid  = store.ErrInvalid
+)
+
+// conflictFields names the field behind each unique constraint.
+var conflictFields = map[string]string{
+	"rma_lines_rma_id_line_no_key": "line number",
+}
+
+// RMA states. An authorized return is received (possibly in several goes),
+// then inspected; it is inspected once everything authorized came back and
+// has a disposition. Close ends a return the customer sent back short.
+const (
+	StatusAuthorized = "authorized"
+	StatusReceived   = "received"
+	StatusInspected  = "inspected"
+	StatusClosed     = "closed"
+	StatusCancelled  = "cancelled"
+)
+
+// Dispositions set at inspection. Restock and refurbish transfer the goods
+// out of the returns location to a bin; scrap and rtv issue them.
+const (
+	DispositionRestock   = "restock"
+	DispositionRefurbish = "refurbish"
+	DispositionScrap     = "scrap"
+	DispositionRtv       = "rtv"
+)
+
+// RefRma marks the ledger lines an RMA books; its ref_id is the RMA.
+const RefRma = "rma"
+
+// returnsLocationTypes are where returned goods may be received.
+var returnsLocationTypes = map[string]bool{"returns": true, "quarantine": true}
+
+// dispositionMoves maps a disposition to the move type and reason code it
+// books.
+var dispositionMoves = map[string]struct{ MoveType, Reason string }{
+	DispositionRestock:   {stocksvc.MoveTransfer, "RMA_RESTOCK"},
+	DispositionRefurbish: {stocksvc.MoveTransfer, "RMA_REFURBISH"},
+	DispositionScrap:     {stocksvc.MoveIssue, "RMA_SCRAP"},
+	DispositionRtv:       {stocksvc.MoveIssue, "RMA_RTV"},
+}
+
+// ReturnsService authorizes, receives and inspects customer returns. Every
+// ledger line goes through Stock with ref_type rma.
+type ReturnsService struct {
+	DB      *pgxpool.Pool
+	Queries *sqlcgen.Queries
+	Stock   stocksvc.StockService
+}
+
+// RmaInput authorizes a return against a confirmed shipment.
+// ReturnsLocationID is a returns or quarantine location of the shipment's
+// warehouse.
+type RmaInput struct {
+	ShipmentID        string         `json:"shipment_id"`
+	ReturnsLocationID string         `json:"returns_location_id"`
+	Comment           string         `json:"comment,omitempty"`
+	Lines             []RmaLineInput `json:"lines"`
+}
+
+// RmaLineInput is a quantity in Uom or the item's base unit. Item, lot and
+// serial must have been shipped on the shipment.
+type RmaLineInput struct {
+	ItemID     string `json:"item_id"`
+	LotCode    string `json:"lot_code,omitempty"`
+	SerialNo   string `json:"serial_no,omitempty"`
+	Qty        string `json:"qty"`
+	Uom        string `json:"uom,omitempty"`
+	ReasonCode string `json:"reason_code"`
+}
+
+type Rma struct {
+	ID                string        `json:"id"`
+	RmaNo             int64         `json:"rma_no"`
+	ShipmentID        string        `json:"shipment_id"`
+	OrderID           string        `json:"order_id"`
+	CustomerID        string        `json:"customer_id"`
+	WarehouseID       string        `json:"warehouse_id"`
+	ReturnsLocationID string        `json:"returns_location_id"`
+	Status            string        `json:"status"`
+	Comment           string        `json:"comment,omitempty"`
+	CreatedAt         time.Time     `json:"created_at"`
+	UpdatedAt         time.Time     `json:"updated_at"`
+	Lines             []RmaLine     `json:"lines,omitempty"`
+	Dispositions      []Disposition `json:"dispositions,omitempty"`
+}
+
+// RmaLine quantities are in the item's base unit.
+type RmaLine struct {
+	LineNo           int32  `json:"line_no"`
+	ItemID           string `json:"item_id"`
+	LotCode          string `json:"lot_code,omitempty"`
+	SerialNo         string `json:"serial_no,omitempty"`
+	ReasonCode       string `json:"reason_code"`
+	QtyAuthorized    string `json:"qty_authorized"`
+	QtyReceived      string `json:"qty_received"`
+	QtyDispositioned string `json:"qty_dispositioned"`
+}
+
+type Disposition struct {
+	ID           string    `json:"id"`
+	LineNo       int32     `json:"line_no"`
+	Disposition  string    `json:"disposition"`
+	Qty          string    `json:"qty"`
+	ToLocationID string    `json:"to_location_id,omitempty"`
+	MoveID       string    `json:"move_id"`
+	Comment      string    `json:"comment,omitempty"`
+	InspectedBy  string    `json:"inspected_by,omitempty"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+type RmaFilter struct {
+	ShipmentID  string
+	CustomerID  string
+	WarehouseID string
+	Status      string
+	Limit       int32
+	Offset      int32
+}
+
+// ReceiveRequest books returned goods into the RMA's returns location.
+type ReceiveRequest struct {
+	Lines []ReceiveLine `json:"lines"`
+}
+
+type ReceiveLine struct {
+	LineNo int32  `json:"line_no"`
+	Qty    string `json:"qty"`
+	Uom    string `json:"uom,omitempty"`
+}
+
+// InspectRequest sets dispositions for received quantities. ToLocationID is
+// required for restock and refurbish and not allowed otherwise.
+type InspectRequest struct {
+	Lines []InspectLine `json:"lines"`
+}
+
+type InspectLine struct {
+	LineNo       int32  `json:"line_no"`
+	Qty          string `json:"qty"`
+	Uom          string `json:"uom,omitempty"`
+	Disposition  string `json:"disposition"`
+	ToLocationID string `json:"to_location_id,omitempty"`
+	Comment      string `json:"comment,omitempty"`
+}
+
+// RmaResponse is the RMA after a receipt or inspection plus the moves it
+// booked.
+type RmaResponse struct {
+	Rma     Rma      `json:"rma"`
+	MoveIDs []string `json:"move_ids"`
+}
+
+type ReturnReason struct {
+	Code        string `json:"code"`
+	Description string `json:"description"`
+}
+
+type ReportFilter struct {
+	WarehouseID string
+	From        string
+	To          string
+}
+
+// Report totals returns by reason and by disposition over a period: reasons
+// by authorization date, dispositions by inspection date.
+type Report struct {
+	Reasons      []ReasonTotal      `json:"reasons"`
+	Dispositions []DispositionTotal `json:"dispositions"`
+}
+
+type ReasonTotal struct {
+	ReasonCode    string `json:"reason_code"`
+	Description   string `json:"description"`
+	Rmas          int64  `json:"rmas"`
+	QtyAuthorized string `json:"qty_authorized"`
+	QtyReceived   string `json:"qty_received"`
+}
+
+type DispositionTotal struct {
+	Disposition string `json:"disposition"`
+	ReasonCode  string `json:"reason_code"`
+	Lines       int64  `json:"lines"`
+	Qty         string `json:"qty"`
+}
+
+func (s ReturnsService) ListReasons(ctx context.Context) ([]ReturnReason, error) {
+	rows, err := s.Queries.ListReturnReasons(ctx)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]ReturnReason, 0, len(rows))
+	for _, r := range rows {
+		out = append(out, ReturnReason{Code: r.Code, Description: r.Description})
+	}
+	return out, nil
+}
+
+// Authorize opens an RMA. Each line may return at most what the shipment
+// shipped of that item, lot and serial, less what other open RMAs of the
+// shipment already authorized.
+func (s ReturnsService) Authorize(ctx context.Context, in RmaInput, actor uuid.UUID) (Rma, error) {
+	if len(in.Lines) == 0 {
+		return Rma{}, fmt.Errorf("%w: lines are required", ErrInvalid)
+	}
+	shipmentID, err := store.ParseUUID("shipment_id", in.ShipmentID)
+	if err != nil {
+		return Rma{}, err
+	}
+	locationID, err := store.ParseUUID("returns_location_id", in.ReturnsLocationID)
+	if err != nil {
+		return Rma{}, err
+	}
+	var out Rma
+	err = s.mutate(ctx, actor, "rma.authorized", func(q *sqlcgen.Queries) (string, any, error) {
+		// The shipment lock serializes authorizations against it.
+		sh, err := q.LockShipment(ctx, store.UUID(shipmentID))
+		if errors.Is(err, pgx.ErrNoRows) {
+			return "", nil, fmt.Errorf("%w: unknown shipment_id", ErrInvalid)
+		}
+		if err != nil {
+			return "", nil, err
+		}
+		if sh.Status != "confirmed" {
+			return "", nil, fmt.Errorf("%w: shipment is %s", ErrInvalid, sh.Status)
+		}
+		loc, err := q.GetLocation(ctx, store.UUID(locationID))
+		if errors.Is(err, pgx.ErrNoRows) {
+			return "", nil, fmt.Errorf("%w: unknown returns_location_id", ErrInvalid)
+		}
+		if err != nil {
+			return "", nil, err
+		}
+		if !loc.Active || !returnsLocationTypes[loc.Type] || loc.WarehouseID != sh.WarehouseID {
+			return "", nil, fmt.Errorf("%w: returns_location_id must be an active returns or quarantine location of the shipment's warehouse", ErrInvalid)
+		}
+		order, err := q.GetSalesOrder(ctx, sh.OrderID)
+		if err != nil {
+			return "", nil, err
+		}
+		content, err := q.ListShipmentContent(ctx, sh.ID)
+		if err != nil {
+			return "", nil, err
+		}
+		authorized, err := q.SumAuthorizedForShipment(ctx, sh.ID)
+		if err != nil {
+			return "", nil, err
+		}
+		open := returnable(content, authorized)
+
+		rma, err := q.CreateRma(ctx, sqlcgen.CreateRmaParams{
+			ShipmentID: sh.ID, OrderID: sh.OrderID, CustomerID: order.CustomerID, WarehouseID: sh.WarehouseID,
+			ReturnsLocationID: loc.ID, Comment: store.Text(in.Comment), CreatedBy: store.UUID(actor),
+		})
+		if err != nil {
+			return "", nil, err
+		}
+		lines := make([]sqlcgen.RmaLine, 0, len(in.Lines))
+		for i, l := range in.Lines {
+			itemID, err := store.ParseUUID("item_id", l.ItemID)
+			if err != nil {
+				return "", nil, err
+			}
+			if l.ReasonCode == "" {
+				return "", nil, fmt.Errorf("%w: line %d: reason_code is required", ErrInvalid, i+1)
+			}
+			base, err := s.Stock.BaseQty(ctx, q, itemID, l.Qty, l.Uom)
+			if err != nil {
+				return "", nil, fmt.Errorf("line %d: %w", i+1, err)
+			}
+			if base.Sign() <= 0 {
+				return "", nil, fmt.Errorf("%w: line %d: qty must be positive", ErrInvalid, i+1)
+			}
+			if err := take(open, returnKey{ItemID: store.UUID(itemID), LotCode: l.LotCode, SerialNo: l.SerialNo}, base); err != nil {
+				return "", nil, fmt.Errorf("line %d: %w", i+1, err)
+			}
+			line, err := q.InsertRmaLine(ctx, sqlcgen.InsertRmaLineParams{
+				RmaID: rma.ID, LineNo: int32(i + 1), ItemID: store.UUID(itemID), LotCode: store.Text(l.LotCode), SerialNo: store.Text(l.SerialNo),
+				ReasonCode: l.ReasonCode, QtyAuthorized: qty.ToNumeric(base),
+			})
+			if err != nil {
+				return "", nil, err
+			}
+			lines = append(lines, line)
+		}
+		out = toRma(rma, lines)
+		return out.ID, out, nil
+	})
+	return out, err
+}
+
+// GetRma returns the RMA with its lines and dispositions.
+func (s ReturnsService) GetRma(ctx context.Context, id uuid.UUID) (Rma, error) {
+	rma, err := s.Queries.GetRma(ctx, store.UUID(id))
+	if err != nil {
+		return Rma{}, store.MapErr(err, conflictFields)
+	}
+	lines, err := s.Queries.ListRmaLines(ctx, rma.ID)
+	if err != nil {
+		return Rma{}, err
+	}
+	rows, err := s.Queries.ListRmaDispositions(ctx, rma.ID)
+	if err != nil {
+		return Rma{}, err
+	}
+	out := toRma(rma, lines)
+	for _, r := range rows {
+		out.Dispositions = append(out.Dispositions, Disposition{
+			ID: r.ID.String(), LineNo: r.LineNo, Disposition: r.Disposition, Qty: qty.String(qty.FromNumeric(r.Qty)),
+			ToLocationID: store.OptUUID(r.ToLocationID), MoveID: r.MoveID.String(), Comment: r.Comment.String,
+			InspectedBy: store.OptUUID(r.InspectedBy), CreatedAt: r.CreatedAt.Time,
+		})
+	}
+	return out, nil
+}
+
+func (s ReturnsService) ListRmas(ctx context.Context, f RmaFilter) ([]Rma, error) {
+	p := sqlcgen.ListRmasParams{Status: f.Status, PageLimit: f.Limit, PageOffset: f.Offset}
+	for _, v := range []struct {
+		field, value string
+		dst          *pgtype.UUID
+	}{
+		{"shipment_id", f.ShipmentID, &p.ShipmentID},
+		{"customer_id", f.CustomerID, &p.CustomerID},
+		{"warehouse_id", f.WarehouseID, &p.WarehouseID},
+	} {
+		if v.value == "" {
+			continue
+		}
+		id, err := store.ParseUUID(v.field, v.value)
+		if err != nil {
+			return nil, err
+		}
+		*v.dst = store.UUID(id)
+	}
+	rows, err := s.Queries.ListRmas(ctx, p)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]Rma, 0, len(rows))
+	for _, r := range rows {
+		out = append(out, toRma(r, nil))
+	}
+	return out, nil
+}
+
+// Receive books returned goods into the returns location as receipts with
+// reason RETURN. Lot and serial are the ones authorized on the line.
+func (s ReturnsService) Receive(ctx context.Context, id uuid.UUID, req ReceiveRequest, actor uuid.UUID, endpoint, idemKey string) (RmaResponse, error) {
+	reqHash := idempotency.Hash([]any{id.String(), req})
+	var prev RmaResponse
+	if found, err := idempotency.Replay(ctx, s.Queries, endpoint, idemKey, reqHash, &prev); err != nil || found {
+		return prev, err
+	}
+	if len(req.Lines) == 0 {
+		return RmaResponse{}, fmt.Errorf("%w: lines are required", ErrInvalid)
+	}
+
+	tx, err := s.DB.Begin(ctx)
+	if err != nil {
+		return RmaResponse{}, err
+	}
+	defer tx.Rollback(ctx)
+	q := s.Queries.WithTx(tx)
+
+	rma, err := q.LockRma(ctx, store.UUID(id))
+	if err != nil {
+		return RmaResponse{}, store.MapErr(err, conflictFields)
+	}
+	if rma.Status != StatusAuthorized && rma.Status != StatusReceived {
+		return RmaResponse{}, fmt.Errorf("%w: rma is %s", ErrInvalid, rma.Status)
+	}
+	resp := RmaResponse{MoveIDs: []string{}}
+	for i, l := range req.Lines {
+		line, err := q.GetRmaLineByNo(ctx, sqlcgen.GetRmaLineByNoParams{RmaID: rma.ID, LineNo: l.LineNo})
+		if err != nil {
+			return RmaResponse{}, fmt.Errorf("line %d: %w", i+1, store.MapErr(err, conflictFields))
+		}
+		amount, err := s.lineQty(ctx, q, line, l.Qty, l.Uom)
+		if err != nil {
+			return RmaResponse{}, fmt.Errorf("line %d: %w", i+1, err)
+		}
+		open := qty.Sub(qty.FromNumeric(line.QtyAuthorized), qty.FromNumeric(line.QtyReceived))
+		if amount.Cmp(open) > 0 {
+			return RmaResponse{}, fmt.Errorf("%w: line %d: only %s left to receive", ErrInvalid, l.LineNo, qty.String(open))
+		}
+		move, _, err := s.Stock.PostMove(ctx, q, stocksvc.MoveReceipt, stocksvc.MoveRequest{
+			ItemID: line.ItemID.String(), Qty: qty.String(amount), ToLocationID: rma.ReturnsLocationID.String(), ReasonCode: "RETURN",
+			LotCode: line.LotCode.String, SerialNo: line.SerialNo.String, RefType: RefRma, RefID: rma.ID.String(),
+		}, actor)
+		if err != nil {
+			return RmaResponse{}, fmt.Errorf("line %d: %w", i+1, err)
+		}
+		if _, err := q.AddRmaLineReceived(ctx, sqlcgen.AddRmaLineReceivedParams{ID: line.ID, Qty: qty.ToNumeric(amount)}); err != nil {
+			return RmaResponse{}, err
+		}
+		resp.MoveIDs = append(resp.MoveIDs, move.MoveID.String())
+	}
+	if rma.Status == StatusAuthorized {
+		if rma, err = q.SetRmaStatus(ctx, sqlcgen.SetRmaStatusParams{ID: rma.ID, Status: StatusReceived}); err != nil {
+			return RmaResponse{}, err
+		}
+	}
+	return s.finish(ctx, tx, q, rma, resp, actor, "rma.received", endpoint, idemKey, reqHash)
+}
+
+// Inspect sets dispositions on received quantities and books their moves
+// out of the returns location. The RMA is inspected once every authorized
+// quantity was received and dispositioned.
+func (s ReturnsService) Inspect(ctx context.Context, id uuid.UUID, req InspectRequest, actor uuid.UUID, endpoint, idemKey string) (RmaResponse, error) {
+	reqHash := idempotency.Hash([]any{id.String(), req})
+	var prev RmaResponse
+	if found, err := idempotency.Replay(ctx, s.Queries, endpoint, idemKey, reqHash, &prev); err != nil || found {
+		return prev, err
+	}
+	if len(req.Lines) == 0 {
+		return RmaResponse{}, fmt.Errorf("%w: lines are required", ErrInvalid)
+	}
+
+	tx, err := s.DB.Begin(ctx)
+	if err != nil {
+		return RmaResponse{}, err
+	}
+	defer tx.Rollback(ctx)
+	q := s.Queries.WithTx(tx)
+
+	rma, err := q.LockRma(ctx, store.UUID(id))
+	if err != nil {
+		return RmaResponse{}, store.MapErr(err, conflictFields)
+	}
+	if rma.Status != StatusReceived {
+		return RmaResponse{}, fmt.Errorf("%w: rma is %s", ErrInvalid, rma.Status)
+	}
+	resp := RmaResponse{MoveIDs: []string{}}
+	for i, l := range req.Lines {
+		dm, err := checkDisposition(l)
+		if err != nil {
+			return RmaResponse{}, fmt.Errorf("line %d: %w", i+1, err)
+		}
+		line, err := q.GetRmaLineByNo(ctx, sqlcgen.GetRmaLineByNoParams{RmaID: rma.ID, LineNo: l.LineNo})
+		if err != nil {
+			return RmaResponse{}, fmt.Errorf("line %d: %w", i+1, store.MapErr(err, conflictFields))
+		}
+		var toID pgtype.UUID
+		if l.ToLocationID != "" {
+			if toID, err = s.binOf(ctx, q, l.ToLocationID, rma.WarehouseID); err != nil {
+				return RmaResponse{}, fmt.Errorf("line %d: %w", i+1, err)
+			}
+		}
+		amount, err := s.lineQty(ctx, q, line, l.Qty, l.Uom)
+		if err != nil {
+			return RmaResponse{}, fmt.Errorf("line %d: %w", i+1, err)
+		}
+		open := qty.Sub(qty.FromNumeric(line.QtyReceived), qty.FromNumeric(line.QtyDispositioned))
+		if amount.Cmp(open) > 0 {
+			return RmaResponse{}, fmt.Errorf("%w: line %d: only %s received and not yet dispositioned", ErrInvalid, l.LineNo, qty.String(open))
+		}
+		move, _, err := s.Stock.PostMove(ctx, q, dm.MoveType, stocksvc.MoveRequest{
+			ItemID: line.ItemID.String(), Qty: qty.String(amount), FromLocationID: rma.ReturnsLocationID.String(), ToLocationID: l.ToLocationID,
+			ReasonCode: dm.Reason, LotCode: line.LotCode.String, SerialNo: line.SerialNo.String, Comment: l.Comment,
+			RefType: RefRma, RefID: rma.ID.String(),
+		}, actor)
+		if err != nil {
+			return RmaResponse{}, fmt.Errorf("line %d: %w", i+1, err)
+		}
+		if _, err := q.InsertRmaDisposition(ctx, sqlcgen.InsertRmaDispositionParams{
+			RmaID: rma.ID, LineID: line.ID, Disposition: l.Disposition, Qty: qty.ToNumeric(amount), ToLocationID: toID,
+			MoveID: move.MoveID, Comment: store.Text(l.Comment), InspectedBy: store.UUID(actor),
+		}); err != nil {
+			return RmaResponse{}, err
+		}
+		if _, err := q.AddRmaLineDispositioned(ctx, sqlcgen.AddRmaLineDispositionedParams{ID: line.ID, Qty: qty.ToNumeric(amount)}); err != nil {
+			return RmaResponse{}, err
+		}
+		resp.MoveIDs = append(resp.MoveIDs, move.MoveID.String())
+	}
+	lines, err := q.ListRmaLines(ctx, rma.ID)
+	if err != nil {
+		return RmaResponse{}, err
+	}
+	if fullyInspected(lines) {
+		if rma, err = q.SetRmaStatus(ctx, sqlcgen.SetRmaStatusParams{ID: rma.ID, Status: StatusInspected}); err != nil {
+			return RmaResponse{}, err
+		}
+	}
+	return s.finish(ctx, tx, q, rma, resp, actor, "rma.inspected", endpoint, idemKey, reqHash)
+}
+
+// Close ends a return the customer sent back short. Everything received must
+// have a disposition first, so nothing is left in the returns location.
+func (s ReturnsService) Close(ctx context.Context, id uuid.UUID, actor uuid.UUID) (Rma, error) {
+	var out Rma
+	err := s.mutate(ctx, actor, "rma.closed", func(q *sqlcgen.Queries) (string, any, error) {
+		rma, err := q.LockRma(ctx, store.UUID(id))
+		if err != nil {
+			return "", nil, err
+		}
+		if rma.Status != StatusReceived {
+			return "", nil, fmt.Errorf("%w: rma is %s", ErrInvalid, rma.Status)
+		}
+		lines, err := q.ListRmaLines(ctx, rma.ID)
+		if err != nil {
+			return "", nil, err
+		}
+		for _, l := range lines {
+			if qty.FromNumeric(l.QtyDispositioned).Cmp(qty.FromNumeric(l.QtyReceived)) < 0 {
+				return "", nil, fmt.Errorf("%w: line %d has received goods without a disposition", ErrInvalid, l.LineNo)
+			}
+		}
+		if rma, err = q.SetRmaStatus(ctx, sqlcgen.SetRmaStatusParams{ID: rma.ID, Status: StatusClosed}); err != nil {
+			return "", nil, err
+		}
+		out = toRma(rma, lines)
+		return out.ID, out, nil
+	})
+	return out, err
+}
+
+// Cancel withdraws an authorization nothing was received on yet.
+func (s ReturnsService) Cancel(ctx context.Context, id uuid.UUID, actor uuid.UUID) (Rma, error) {
+	var out Rma
+	err := s.mutate(ctx, actor, "rma.cancelled", func(q *sqlcgen.Queries) (string, any, error) {
+		rma, err := q.LockRma(ctx, store.UUID(id))
+		if err != nil {
+			return "", nil, err
+		}
+		if rma.Status != StatusAuthorized {
+			return "", nil, fmt.Errorf("%w: rma is %s", ErrInvalid, rma.Status)
+		}
+		if rma, err = q.SetRmaStatus(ctx, sqlcgen.SetRmaStatusParams{ID: rma.ID, Status: StatusCancelled}); err != nil {
+			return "", nil, err
+		}
+		out = toRma(rma, nil)
+		return out.ID, out, nil
+	})
+	return out, err
+}
+
+func (s ReturnsService) Report(ctx context.Context, f ReportFilter) (Report, error) {
+	var warehouseID pgtype.UUID
+	if f.WarehouseID != "" {
+		id, err := store.ParseUUID("warehouse_id", f.WarehouseID)
+		if err != nil {
+			return Report{}, err
+		}
+		warehouseID = store.UUID(id)
+	}
+	from, err := parseTs("from", f.From)
+	if err != nil {
+		return Report{}, err
+	}
+	to, err := parseTs("to", f.To)
+	if err != nil {
+		return Report{}, err
+	}
+	reasons, err := s.Queries.ReportReturnReasons(ctx, sqlcgen.ReportReturnReasonsParams{WarehouseID: warehouseID, FromTs: from, ToTs: to})
+	if err != nil {
+		return Report{}, err
+	}
+	dispositions, err := s.Queries.ReportRmaDispositions(ctx, sqlcgen.ReportRmaDispositionsParams{WarehouseID: warehouseID, FromTs: from, ToTs: to})
+	if err != nil {
+		return Report{}, err
+	}
+	out := Report{Reasons: make([]ReasonTotal, 0, len(reasons)), Dispositions: make([]DispositionTotal, 0, len(dispositions))}
+	for _, r := range reasons {
+		out.Reasons = append(out.Reasons, ReasonTotal{
+			ReasonCode: r.ReasonCode, Description: r.Description, Rmas: r.Rmas,
+			QtyAuthorized: qty.String(qty.FromNumeric(r.QtyAuthorized)), QtyReceived: qty.String(qty.FromNumeric(r.QtyReceived)),
+		})
+	}
+	for _, r := range dispositions {
+		out.Dispositions = append(out.Dispositions, DispositionTotal{
+			Disposition: r.Disposition, ReasonCode: r.ReasonCode, Lines: r.Lines, Qty: qty.String(qty.FromNumeric(r.Qty)),
+		})
+	}
+	return out, nil
+}
+
+// lineQty converts a quantity into the line item's base unit.
+func (s ReturnsService) lineQty(ctx context.Context, q *sqlcgen.Queries, line sqlcgen.RmaLine, amount, uom string) (*big.Rat, error) {
+	base, err := s.Stock.BaseQty(ctx, q, uuid.UUID(line.ItemID.Bytes), amount, uom)
+	if err != nil {
+		return nil, err
+	}
+	if base.Sign() <= 0 {
+		return nil, fmt.Errorf("%w: qty must be positive", ErrInvalid)
+	}
+	return base, nil
+}
+
+// binOf checks a disposition target: an active location of the RMA's
+// warehouse other than a returns, quarantine or in-transit one.
+func (s ReturnsService) binOf(ctx context.Context, q *sqlcgen.Queries, v string, warehouseID pgtype.UUID) (pgtype.UUID, error) {
+	id, err := store.ParseUUID("to_location_id", v)
+	if err != nil {
+		return pgtype.UUID{}, err
+	}
+	loc, err := q.GetLocation(ctx, store.UUID(id))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return pgtype.UUID{}, fmt.Errorf("%w: unknown to_location_id", ErrInvalid)
+	}
+	if err != nil {
+		return pgtype.UUID{}, err
+	}
+	if !loc.Active || returnsLocationTypes[loc.Type] || loc.Type == "in_transit" || loc.WarehouseID != warehouseID {
+		return pgtype.UUID{}, fmt.Errorf("%w: to_location_id must be an active bin of the rma's warehouse", ErrInvalid)
+	}
+	return loc.ID, nil
+}
+
+// finish records the event and audit entry, remembers the response for the
+// idempotency key and commits.
+func (s ReturnsService) finish(ctx context.Context, tx pgx.Tx, q *sqlcgen.Queries, rma sqlcgen.Rma, resp RmaResponse, actor uuid.UUID, topic, endpoint, idemKey, reqHash string) (RmaResponse, error) {
+	lines, err := q.ListRmaLines(ctx, rma.ID)
+	if err != nil {
+		return RmaResponse{}, err
+	}
+	resp.Rma = toRma(rma, lines)
+	payload, _ := json.Marshal(resp)
+	if err := store.Record(ctx, q, actor, topic, "rmas", rma.ID.String(), payload); err != nil {
+		return RmaResponse{}, err
+	}
+	if err := idempotency.Remember(ctx, q, endpoint, idemKey, store.UUID(actor), reqHash, resp); err != nil {
+		return RmaResponse{}, err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return RmaResponse{}, err
+	}
+	return resp, nil
+}
+
+// mutate runs fn in a transaction with its outbox event and audit entry
+// on rmas; see store.Mutate.
+func (s ReturnsService) mutate(ctx context.Context, actor uuid.UUID, topic string, fn func(q *sqlcgen.Queries) (string, any, error)) error {
+	return store.Mutate(ctx, s.DB, s.Queries, actor, topic, "rmas", conflictFields, fn)
+}
+
+// checkDisposition validates an inspection line and returns the move it
+// books.
+func checkDisposition(l InspectLine) (struct{ MoveType, Reason string }, error) {
+	dm, ok := dispositionMoves[l.Disposition]
+	if !ok {
+		return dm, fmt.Errorf("%w: disposition must be restock, refurbish, scrap or rtv", ErrInvalid)
+	}
+	if (dm.MoveType == stocksvc.MoveTransfer) != (l.ToLocationID != "") {
+		return dm, fmt.Errorf("%w: to_location_id is required for restock and refurbish and not allowed for %s", ErrInvalid, l.Disposition)
+	}
+	return dm, nil
+}
+
+// returnKey is what an RMA line is matched to on the shipment.
+type returnKey struct {
+	ItemID   pgtype.UUID
+	LotCode  string
+	SerialNo string
+}
+
+// returnable is what the shipment shipped per item, lot and serial less
+// what earlier RMAs already authorized.
+func returnable(content []sqlcgen.ListShipmentContentRow, authorized []sqlcgen.SumAuthorizedForShipmentRow) map[returnKey]*big.Rat {
+	out := map[returnKey]*big.Rat{}
+	for _, c := range content {
+		k := returnKey{ItemID: c.ItemID, LotCode: c.LotCode.String, SerialNo: c.SerialNo.String}
+		if out[k] == nil {
+			out[k] = qty.Zero()
+		}
+		out[k] = qty.Add(out[k], qty.FromNumeric(c.Qty))
+	}
+	for _, a := range authorized {
+		k := returnKey{ItemID: a.ItemID, LotCode: a.LotCode.String, SerialNo: a.SerialNo.String}
+		if out[k] != nil {
+			out[k] = qty.Sub(out[k], qty.FromNumeric(a.Qty))
+		}
+	}
+	return out
+}
+
+// take consumes amount from what is still returnable under key, so two lines
+// of one RMA cannot authorize the same goods twice.
+func take(open map[returnKey]*big.Rat, key returnKey, amount *big.Rat) error {
+	have, ok := open[key]
+	if !ok {
+		return fmt.Errorf("%w: item, lot and serial were not shipped on this shipment", ErrInvalid)
+	}
+	if amount.Cmp(have) > 0 {
+		return fmt.Errorf("%w: only %s left to return", ErrInvalid, qty.String(have))
+	}
+	open[key] = qty.Sub(have, amount)
+	return nil
+}
+
+func fullyInspected(lines []sqlcgen.RmaLine) bool {
+	for _, l := range lines {
+		if qty.FromNumeric(l.QtyReceived).Cmp(qty.FromNumeric(l.QtyAuthorized)) < 0 ||
+			qty.FromNumeric(l.QtyDispositioned).Cmp(qty.FromNumeric(l.QtyReceived)) < 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// toRma maps an RMA; lines is nil for list views.
+func toRma(r sqlcgen.Rma, lines []sqlcgen.RmaLine) Rma {
+	out := Rma{
+		ID: r.ID.String(), RmaNo: r.RmaNo, ShipmentID: r.ShipmentID.String(), OrderID: r.OrderID.String(), CustomerID: r.CustomerID.String(),
+		WarehouseID: r.WarehouseID.String(), ReturnsLocationID: r.ReturnsLocationID.String(), Status: r.Status, Comment: r.Comment.String,
+		CreatedAt: r.CreatedAt.Time, UpdatedAt: r.UpdatedAt.Time,
+	}
+	for _, l := range lines {
+		out.Lines = append(out.Lines, RmaLine{
+			LineNo: l.LineNo, ItemID: l.ItemID.String(), LotCode: l.LotCode.String, SerialNo: l.SerialNo.String, ReasonCode: l.ReasonCode,
+			QtyAuthorized: qty.String(qty.FromNumeric(l.QtyAuthorized)), QtyReceived: qty.String(qty.FromNumeric(l.QtyReceived)),
+			QtyDispositioned: qty.String(qty.FromNumeric(l.QtyDispositioned)),
+		})
+	}
+	return out
+}
+
+func parseTs(field, v string) (pgtype.Timestamptz, error) {
+	if v == "" {
+		return pgtype.Timestamptz{}, nil
+	}
+	t, err := time.Parse(time.RFC3339, v)
+	if err != nil {
+		return pgtype.Timestamptz{}, fmt.Errorf("%w: %s must be RFC 3339", ErrInvalid, field)
+	}
+	return pgtype.Timestamptz{Time: t, Valid: true}, nil
+}
@@ -0,0 +1,80 @@
+package service
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+
+	"erpwms/backend-go/internal/common/qty"
+	"erpwms/backend-go/internal/common/store"
+	"erpwms/backend-go/internal/db/sqlcgen"
+	stocksvc "erpwms/backend-go/internal/modules/wms_stock/service"
+	"github.com/google/uuid"
+)
+
+func TestReturnableLessAuthorized(t *testing.T) {
+	item := store.UUID(uuid.New())
+	content := []sqlcgen.ListShipmentContentRow{
+		{ItemID: item, LotCode: store.Text("L1"), Qty: qty.ToNumeric(big.NewRat(3, 1))},
+		{ItemID: item, LotCode: store.Text("L1"), Qty: qty.ToNumeric(big.NewRat(2, 1))}, // second carton
+		{ItemID: item, LotCode: store.Text("L2"), Qty: qty.ToNumeric(big.NewRat(4, 1))},
+	}
+	authorized := []sqlcgen.SumAuthorizedForShipmentRow{{ItemID: item, LotCode: store.Text("L1"), Qty: qty.ToNumeric(big.NewRat(1, 1))}}
+	open := returnable(content, authorized)
+
+	l1 := returnKey{ItemID: item, LotCode: "L1"}
+	if err := take(open, l1, big.NewRat(4, 1)); err != nil {
+		t.Fatal(err)
+	}
+	if err := take(open, l1, big.NewRat(1, 1)); !errors.Is(err, ErrInvalid) {
+		t.Fatalf("lot L1 fully authorized: got %v", err)
+	}
+	if err := take(open, returnKey{ItemID: item, LotCode: "L3"}, big.NewRat(1, 1)); !errors.Is(err, ErrInvalid) {
+		t.Fatalf("lot never shipped: got %v", err)
+	}
+	if err := take(open, returnKey{ItemID: item, LotCode: "L2"}, big.NewRat(4, 1)); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestCheckDisposition(t *testing.T) {
+	for _, tc := range []struct {
+		disposition, to string
+		moveType        string
+		ok              bool
+	}{
+		{DispositionRestock, "bin", stocksvc.MoveTransfer, true},
+		{DispositionRestock, "", "", false},
+		{DispositionRefurbish, "bin", stocksvc.MoveTransfer, true},
+		{DispositionScrap, "", stocksvc.MoveIssue, true},
+		{DispositionScrap, "bin", "", false},
+		{DispositionRtv, "", stocksvc.MoveIssue, true},
+		{"resell", "", "", false},
+	} {
+		dm, err := checkDisposition(InspectLine{Disposition: tc.disposition, ToLocationID: tc.to})
+		if (err == nil) != tc.ok {
+			t.Fatalf("%s to=%q: got %v", tc.disposition, tc.to, err)
+		}
+		if err == nil && dm.MoveType != tc.moveType {
+			t.Fatalf("%s: move type %s", tc.disposition, dm.MoveType)
+		}
+	}
+}
+
+func TestFullyInspected(t *testing.T) {
+	line := func(authorized, received, dispositioned int64) sqlcgen.RmaLine {
+		return sqlcgen.RmaLine{
+			QtyAuthorized: qty.ToNumeric(big.NewRat(authorized, 1)), QtyReceived: qty.ToNumeric(big.NewRat(received, 1)),
+			QtyDispositioned: qty.ToNumeric(big.NewRat(dispositioned, 1)),
+		}
+	}
+	if !fullyInspected([]sqlcgen.RmaLine{line(2, 2, 2), line(1, 1, 1)}) {
+		t.Fatal("everything received and dispositioned")
+	}
+	if fullyInspected([]sqlcgen.RmaLine{line(2, 2, 1)}) {
+		t.Fatal("one received unit has no disposition")
+	}
+	if fullyInspected([]sqlcgen.RmaLine{line(2, 1, 1)}) {
+		t.Fatal("one authorized unit was not received")
+	}
+}
@@ -0,0 +1,25 @@
+package http
+
+import (
+	"erpwms/backend-go/internal/common/httperr"
+	"erpwms/backend-go/internal/modules/wms_scan/service"
+	"github.com/gin-gonic/gin"
+)
+
+type ScanHandlers struct {
+	Service service.ScanService
+}
+
+func (h ScanHandlers) Resolve(c *gin.Context) {
+	var in service.ResolveRequest
+	if err := c.ShouldBindJSON(&in); err != nil {
+		c.JSON(400, gin.H{"error": "bad request"})
+		return
+	}
+	res, err := h.Service.Resolve(c.Request.Context(), in)
+	if err != nil {
+		httperr.Write(c, err)
+		return
+	}
+	c.JSON(200, res)
+}
@@ -0,0 +1,308 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"erpwms/backend-go/internal/common/gs1"
+	"erpwms/backend-go/internal/common/qty"
+	"erpwms/backend-go/internal/common/store"
+	"erpwms/backend-go/internal/db/sqlcgen"
+	stocksvc "erpwms/backend-go/internal/modules/wms_stock/service"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+var (
+	ErrNotFound = store.ErrNotFound
+	ErrInvalid  = store.ErrInvalid
+)
+
+// ScanService turns what a handheld read into the records it stands for. It
+// only reads; booking the result is up to the client.
+type ScanService struct {
+	Queries *sqlcgen.Queries
+	Stock   stocksvc.StockService
+}
+
+// ResolveRequest scopes location codes to WarehouseID, since the same bin
+// code may exist in several warehouses.
+type ResolveRequest struct {
+	Scan        string `json:"scan"`
+	WarehouseID string `json:"warehouse_id,omitempty"`
+}
+
+// Resolution is everything a scan matched. Kind is "gs1" for element strings
+// and "code" for plain barcodes, handling unit and location codes. Qty is in
+// Uom, the unit of the scanned barcode; BaseQty in the item's base unit.
+type Resolution struct {
+	Scan         string             `json:"scan"`
+	Kind         string             `json:"kind"`
+	Elements     []gs1.Element      `json:"elements,omitempty"`
+	Item         *ItemMatch         `json:"item,omitempty"`
+	Qty          string             `json:"qty,omitempty"`
+	Uom          string             `json:"uom,omitempty"`
+	BaseQty      string             `json:"base_qty,omitempty"`
+	Lot          *LotMatch          `json:"lot,omitempty"`
+	ExpiresOn    string             `json:"expires_on,omitempty"`
+	Serial       *SerialMatch       `json:"serial,omitempty"`
+	HandlingUnit *HandlingUnitMatch `json:"handling_unit,omitempty"`
+	Location     *LocationMatch     `json:"location,omitempty"`
+	Warnings     []string           `json:"warnings,omitempty"`
+}
+
+type ItemMatch struct {
+	ID           string `json:"id"`
+	Sku          string `json:"sku"`
+	Name         string `json:"name"`
+	BaseUom      string `json:"base_uom"`
+	TrackingMode string `json:"tracking_mode"`
+	Barcode      string `json:"barcode"`
+	BarcodeType  string `json:"barcode_type"`
+}
+
+// LotMatch has an ID when the lot is already registered for the item.
+type LotMatch struct {
+	ID        string `json:"id,omitempty"`
+	Code      string `json:"code"`
+	ExpiresOn string `json:"expires_on,omitempty"`
+}
+
+// SerialMatch has an ID and the current location when the serial is
+// registered.
+type SerialMatch struct {
+	ID         string `json:"id,omitempty"`
+	SerialNo   string `json:"serial_no"`
+	LocationID string `json:"location_id,omitempty"`
+}
+
+type HandlingUnitMatch struct {
+	ID           string `json:"id"`
+	Code         string `json:"code"`
+	Type         string `json:"hu_type"`
+	ParentID     string `json:"parent_id,omitempty"`
+	LocationID   string `json:"location_id"`
+	LocationCode string `json:"location_code"`
+	WarehouseID  string `json:"warehouse_id"`
+}
+
+type LocationMatch struct {
+	ID            string `json:"id"`
+	Code          string `json:"code"`
+	Type          string `json:"type"`
+	WarehouseID   string `json:"warehouse_id"`
+	WarehouseCode string `json:"warehouse_code"`
+	Active        bool   `json:"active"`
+}
+
+// Resolve reads a scan as a GS1 element string when it says so (symbology
+// identifier, brackets or FNC1). Anything else is first looked up as a
+// barcode, SSCC/LPN and location code, and only read as an unbracketed
+// element string when none of those match.
+func (s ScanService) Resolve(ctx context.Context, req ResolveRequest) (Resolution, error) {
+	raw := strings.TrimSpace(req.Scan)
+	if raw == "" {
+		return Resolution{}, fmt.Errorf("%w: scan is required", ErrInvalid)
+	}
+	var wh pgtype.UUID
+	if req.WarehouseID != "" {
+		id, err := store.ParseUUID("warehouse_id", req.WarehouseID)
+		if err != nil {
+			return Resolution{}, err
+		}
+		wh = store.UUID(id)
+	}
+	if gs1.HasAIs(raw) {
+		m, err := gs1.Parse(raw)
+		if err != nil {
+			return Resolution{}, fmt.Errorf("%w: %v", ErrInvalid, err)
+		}
+		return s.fromGS1(ctx, raw, m)
+	}
+	res, err := s.fromCode(ctx, raw, wh)
+	if !errors.Is(err, ErrNotFound) {
+		return res, err
+	}
+	if m, perr := gs1.Parse(raw); perr == nil {
+		return s.fromGS1(ctx, raw, m)
+	}
+	return Resolution{}, err
+}
+
+func (s ScanService) fromCode(ctx context.Context, raw string, wh pgtype.UUID) (Resolution, error) {
+	res := Resolution{Scan: raw, Kind: "code"}
+	gtin, _ := gs1.GTIN14(raw)
+	found, err := s.matchItem(ctx, &res, raw, gtin)
+	if err != nil {
+		return Resolution{}, err
+	}
+	if found {
+		if err := s.setQty(ctx, &res, 1); err != nil {
+			return Resolution{}, err
+		}
+	}
+	if _, err := s.matchHandlingUnit(ctx, &res, raw); err != nil {
+		return Resolution{}, err
+	}
+	locs, err := s.Queries.FindLocationsByCode(ctx, sqlcgen.FindLocationsByCodeParams{Code: raw, WarehouseID: wh})
+	if err != nil {
+		return Resolution{}, err
+	}
+	switch {
+	case len(locs) == 1:
+		l := locs[0]
+		res.Location = &LocationMatch{ID: l.ID.String(), Code: l.Code, Type: l.Type, WarehouseID: l.WarehouseID.String(), WarehouseCode: l.WarehouseCode, Active: l.Active}
+	case len(locs) > 1:
+		if res.Item == nil && res.HandlingUnit == nil {
+			return Resolution{}, fmt.Errorf("%w: location %s exists in %d warehouses; pass warehouse_id", ErrInvalid, raw, len(locs))
+		}
+		res.Warnings = append(res.Warnings, fmt.Sprintf("location %s exists in %d warehouses", raw, len(locs)))
+	}
+	if res.Item == nil && res.HandlingUnit == nil && res.Location == nil {
+		return Resolution{}, fmt.Errorf("%w: no item, handling unit or location matches %s", ErrNotFound, raw)
+	}
+	return res, nil
+}
+
+// fromGS1 resolves what the AIs identify. Unknown GTINs and SSCCs are
+// reported as warnings next to the parsed data, and lots and serials not yet
+// registered come back without an ID, so the client can still receive
+// against them.
+func (s ScanService) fromGS1(ctx context.Context, raw string, m gs1.Message) (Resolution, error) {
+	res := Resolution{Scan: raw, Kind: "gs1", Elements: m.Elements}
+	if !m.Expiry.IsZero() {
+		res.ExpiresOn = m.Expiry.Format("2006-01-02")
+	}
+	gtin := m.GTIN
+	if gtin == "" {
+		gtin = m.Content
+	}
+	if gtin != "" {
+		found, err := s.matchItem(ctx, &res, gtin, gtin)
+		if err != nil {
+			return Resolution{}, err
+		}
+		if !found {
+			res.Warnings = append(res.Warnings, "unknown GTIN "+gtin)
+		} else if n := scanQty(m); n > 0 {
+			if err := s.setQty(ctx, &res, n); err != nil {
+				return Resolution{}, err
+			}
+		}
+	}
+	if m.Lot != "" {
+		res.Lot = &LotMatch{Code: m.Lot}
+		if res.Item != nil {
+			lot, err := s.Queries.GetLotByCode(ctx, sqlcgen.GetLotByCodeParams{ItemID: pgUUIDString(res.Item.ID), LotCode: m.Lot})
+			switch {
+			case err == nil:
+				res.Lot.ID = lot.ID.String()
+				if lot.ExpiresOn.Valid {
+					res.Lot.ExpiresOn = lot.ExpiresOn.Time.Format("2006-01-02")
+				}
+				if res.ExpiresOn != "" && res.Lot.ExpiresOn != "" && res.ExpiresOn != res.Lot.ExpiresOn {
+					res.Warnings = append(res.Warnings, fmt.Sprintf("lot %s expires on %s, the label says %s", m.Lot, res.Lot.ExpiresOn, res.ExpiresOn))
+				}
+			case !errors.Is(err, pgx.ErrNoRows):
+				return Resolution{}, err
+			}
+		}
+	}
+	if m.Serial != "" {
+		res.Serial = &SerialMatch{SerialNo: m.Serial}
+		if res.Item != nil {
+			sr, err := s.Queries.FindSerial(ctx, sqlcgen.FindSerialParams{ItemID: pgUUIDString(res.Item.ID), SerialNo: m.Serial})
+			switch {
+			case err == nil:
+				res.Serial.ID = sr.ID.String()
+				if sr.LocationID.Valid {
+					res.Serial.LocationID = sr.LocationID.String()
+				}
+			case !errors.Is(err, pgx.ErrNoRows):
+				return Resolution{}, err
+			}
+		}
+	}
+	if m.SSCC != "" {
+		found, err := s.matchHandlingUnit(ctx, &res, m.SSCC)
+		if err != nil {
+			return Resolution{}, err
+		}
+		if !found {
+			res.Warnings = append(res.Warnings, "unknown SSCC "+m.SSCC)
+		}
+	}
+	return res, nil
+}
+
+// scanQty is the quantity a GS1 scan stands for in the unit of its GTIN:
+// the (37) count, or one of the trade item an (01) identifies. A bare (02)
+// names the content without saying how much.
+func scanQty(m gs1.Message) int64 {
+	if m.Count > 0 {
+		return m.Count
+	}
+	if m.GTIN != "" {
+		return 1
+	}
+	return 0
+}
+
+func (s ScanService) matchItem(ctx context.Context, res *Resolution, code, gtin string) (bool, error) {
+	b, err := s.Queries.FindItemByBarcode(ctx, sqlcgen.FindItemByBarcodeParams{Code: code, Gtin: gtin})
+	if errors.Is(err, pgx.ErrNoRows) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	res.Item = &ItemMatch{ID: b.ItemID.String(), Sku: b.Sku, Name: b.Name, BaseUom: b.BaseUom, TrackingMode: b.TrackingMode, Barcode: b.Barcode, BarcodeType: b.BarcodeType}
+	res.Uom = b.Uom
+	if !b.Active {
+		res.Warnings = append(res.Warnings, "item "+b.Sku+" is inactive")
+	}
+	return true, nil
+}
+
+func (s ScanService) matchHandlingUnit(ctx context.Context, res *Resolution, code string) (bool, error) {
+	hu, err := s.Queries.GetHandlingUnitByCode(ctx, code)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	res.HandlingUnit = &HandlingUnitMatch{
+		ID: hu.ID.String(), Code: hu.Code, Type: hu.HuType, LocationID: hu.LocationID.String(), LocationCode: hu.LocationCode, WarehouseID: hu.WarehouseID.String(),
+	}
+	if hu.ParentID.Valid {
+		res.HandlingUnit.ParentID = hu.ParentID.String()
+	}
+	return true, nil
+}
+
+// setQty sets Qty in the barcode's unit and converts it to the base unit; a
+// missing conversion only costs BaseQty and a warning.
+func (s ScanService) setQty(ctx context.Context, res *Resolution, n int64) error {
+	res.Qty = strconv.FormatInt(n, 10)
+	itemID, _ := uuid.Parse(res.Item.ID)
+	base, err := s.Stock.BaseQty(ctx, s.Queries, itemID, res.Qty, res.Uom)
+	if errors.Is(err, stocksvc.ErrInvalidMove) {
+		res.Warnings = append(res.Warnings, err.Error())
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	res.BaseQty = qty.String(base)
+	return nil
+}
+
+func pgUUIDString(v string) pgtype.UUID {
+	id, err := uuid.Parse(v)
+	return pgtype.UUID{Bytes: id, Valid: err == nil}
+}
@@ -0,0 +1,41 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"erpwms/backend-go/internal/common/gs1"
+)
+
+func TestScanQty(t *testing.T) {
+	for scan, want := range map[string]int64{
+		"(01)09501101530003":                             1,
+		"(01)09501101530003(37)12":                       12,
+		"(00)106141411234567897(02)09501101530003":       0,
+		"(00)106141411234567897(02)09501101530003(37)48": 48,
+		"(00)106141411234567897":                         0,
+	} {
+		m, err := gs1.Parse(scan)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := scanQty(m); got != want {
+			t.Fatalf("%s: got %d, want %d", scan, got, want)
+		}
+	}
+}
+
+func TestResolveRejectsBadInput(t *testing.T) {
+	// Both fail before any lookup, so no database is needed.
+	for name, req := range map[string]ResolveRequest{
+		"empty":          {Scan: "  "},
+		"bad warehouse":  {Scan: "A-01-01", WarehouseID: "main"},
+		"bad element":    {Scan: "]C10109501101530004"},
+		"unsupported AI": {Scan: "(99)X"},
+	} {
+		if _, err := (ScanService{}).Resolve(context.Background(), req); !errors.Is(err, ErrInvalid) {
+			t.Fatalf("%s: got %v", name, err)
+		}
+	}
+}
@@ -0,0 +1,208 @@
+package http
+
+import (
+	"strconv"
+
+	"erpwms/backend-go/internal/common/httperr"
+	"erpwms/backend-go/internal/modules/wms_shipping/service"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type ShippingHandlers struct {
+	Service service.ShippingService
+}
+
+func (h ShippingHandlers) ListShipments(c *gin.Context) {
+	limit, offset := page(c)
+	rows, err := h.Service.ListShipments(c.Request.Context(), service.ShipmentFilter{
+		OrderID: c.Query("order_id"), WarehouseID: c.Query("warehouse_id"), Status: c.Query("status"), Limit: limit, Offset: offset,
+	})
+	if err != nil {
+		httperr.Write(c, err)
+		return
+	}
+	c.JSON(200, gin.H{"items": rows})
+}
+
+func (h ShippingHandlers) GetShipment(c *gin.Context) {
+	id, ok := paramID(c)
+	if !ok {
+		return
+	}
+	sh, err := h.Service.GetShipment(c.Request.Context(), id)
+	if err != nil {
+		httperr.Write(c, err)
+		return
+	}
+	c.JSON(200, sh)
+}
+
+func (h ShippingHandlers) CreateShipment(c *gin.Context) {
+	var in service.ShipmentInput
+	if err := c.ShouldBindJSON(&in); err != nil {
+		c.JSON(400, gin.H{"error": "bad request"})
+		return
+	}
+	actor, ok := actorID(c)
+	if !ok {
+		return
+	}
+	sh, err := h.Service.CreateShipment(c.Request.Context(), in, actor)
+	if err != nil {
+		httperr.Write(c, err)
+		return
+	}
+	c.JSON(201, sh)
+}
+
+func (h ShippingHandlers) AddCarton(c *gin.Context) {
+	id, ok := paramID(c)
+	if !ok {
+		return
+	}
+	// The body is optional; an empty one opens a carton without a weight.
+	var in service.CartonInput
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&in); err != nil {
+			c.JSON(400, gin.H{"error": "bad request"})
+			return
+		}
+	}
+	actor, ok := actorID(c)
+	if !ok {
+		return
+	}
+	carton, err := h.Service.AddCarton(c.Request.Context(), id, in, actor)
+	if err != nil {
+		httperr.Write(c, err)
+		return
+	}
+	c.JSON(201, carton)
+}
+
+func (h ShippingHandlers) Pack(c *gin.Context) {
+	id, ok := paramID(c)
+	if !ok {
+		return
+	}
+	cartonNo, err := strconv.ParseInt(c.Param("carton_no"), 10, 32)
+	if err != nil {
+		c.JSON(400, gin.H{"error": "invalid carton_no"})
+		return
+	}
+	var in service.PackInput
+	if err := c.ShouldBindJSON(&in); err != nil {
+		c.JSON(400, gin.H{"error": "bad request"})
+		return
+	}
+	actor, ok := actorID(c)
+	if !ok {
+		return
+	}
+	line, err := h.Service.Pack(c.Request.Context(), id, int32(cartonNo), in, actor)
+	if err != nil {
+		httperr.Write(c, err)
+		return
+	}
+	c.JSON(201, line)
+}
+
+func (h ShippingHandlers) Unpack(c *gin.Context) {
+	id, ok := paramID(c)
+	if !ok {
+		return
+	}
+	lineID, err := uuid.Parse(c.Param("line_id"))
+	if err != nil {
+		c.JSON(400, gin.H{"error": "invalid line_id"})
+		return
+	}
+	actor, ok := actorID(c)
+	if !ok {
+		return
+	}
+	if err := h.Service.Unpack(c.Request.Context(), id, lineID, actor); err != nil {
+		httperr.Write(c, err)
+		return
+	}
+	c.Status(204)
+}
+
+func (h ShippingHandlers) CancelShipment(c *gin.Context) {
+	id, ok := paramID(c)
+	if !ok {
+		return
+	}
+	actor, ok := actorID(c)
+	if !ok {
+		return
+	}
+	sh, err := h.Service.CancelShipment(c.Request.Context(), id, actor)
+	if err != nil {
+		httperr.Write(c, err)
+		return
+	}
+	c.JSON(200, sh)
+}
+
+func (h ShippingHandlers) ConfirmShipment(c *gin.Context) {
+	key := c.GetHeader("Idempotency-Key")
+	if key == "" {
+		c.JSON(400, gin.H{"error": "Idempotency-Key required"})
+		return
+	}
+	id, ok := paramID(c)
+	if !ok {
+		return
+	}
+	actor, ok := actorID(c)
+	if !ok {
+		return
+	}
+	sh, err := h.Service.ConfirmShipment(c.Request.Context(), id, actor, "/api/shipments/confirm", key)
+	if err != nil {
+		httperr.Write(c, err)
+		return
+	}
+	c.JSON(200, sh)
+}
+
+// DeliveryNote renders the DDT as a printable A4 page; browsers save it as
+// PDF through their print dialog.
+func (h ShippingHandlers) DeliveryNote(c *gin.Context) {
+	id, ok := paramID(c)
+	if !ok {
+		return
+	}
+	note, err := h.Service.GetDeliveryNote(c.Request.Context(), id)
+	if err != nil {
+		httperr.Write(c, err)
+		return
+	}
+	c.HTML(200, "pages/ddt.html", note)
+}
+
+func page(c *gin.Context) (int32, int32) {
+	limit, _ := strconv.ParseInt(c.DefaultQuery("limit", "50"), 10, 32)
+	offset, _ := strconv.ParseInt(c.DefaultQuery("offset", "0"), 10, 32)
+	return int32(limit), int32(offset)
+}
+
+func paramID(c *gin.Context) (uuid.UUID, bool) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(400, gin.H{"error": "invalid id"})
+		return uuid.Nil, false
+	}
+	return id, true
+}
+
+func actorID(c *gin.Context) (uuid.UUID, bool) {
+	uid, err := uuid.Parse(c.GetString("user_id"))
+	if err != nil || uid == uuid.Nil {
+		c.JSON(401, gin.H{"error": "unauthorized"})
+		return uuid.Nil, false
+	}
+	return uid, true
+}
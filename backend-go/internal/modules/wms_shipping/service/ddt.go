@@ -0,0 +1,79 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"time"
+
+	"erpwms/backend-go/internal/common/qty"
+	"erpwms/backend-go/internal/common/store"
+	"github.com/google/uuid"
+)
+
+// DeliveryNote is what the DDT template prints: the confirmed shipment plus
+// the sender warehouse, the order reference and the parcel totals.
+type DeliveryNote struct {
+	Shipment
+	Date          time.Time
+	WarehouseCode string
+	WarehouseName string
+	OrderNumber   string
+	CustomerCode  string
+	Parcels       int
+	TotalWeightKg string
+}
+
+// GetDeliveryNote is only available once the shipment is confirmed; before
+// that it has no DDT number and its content may still change.
+func (s ShippingService) GetDeliveryNote(ctx context.Context, id uuid.UUID) (DeliveryNote, error) {
+	sh, err := s.Queries.GetShipment(ctx, store.UUID(id))
+	if err != nil {
+		return DeliveryNote{}, store.MapErr(err, conflictFields)
+	}
+	if sh.Status != StatusConfirmed {
+		return DeliveryNote{}, fmt.Errorf("%w: shipment is %s, the DDT exists once it is confirmed", ErrConflict, sh.Status)
+	}
+	full, err := s.withContent(ctx, s.Queries, sh)
+	if err != nil {
+		return DeliveryNote{}, err
+	}
+	wh, err := s.Queries.GetWarehouse(ctx, sh.WarehouseID)
+	if err != nil {
+		return DeliveryNote{}, err
+	}
+	so, err := s.Queries.GetSalesOrder(ctx, sh.OrderID)
+	if err != nil {
+		return DeliveryNote{}, err
+	}
+	customer, err := s.Queries.GetCustomer(ctx, so.CustomerID)
+	if err != nil {
+		return DeliveryNote{}, err
+	}
+	weight, known := totalWeight(full.Cartons)
+	note := DeliveryNote{
+		Shipment: full, Date: sh.ConfirmedAt.Time.In(ddtZone), WarehouseCode: wh.Code, WarehouseName: wh.Name,
+		OrderNumber: so.OrderNumber, CustomerCode: customer.Code, Parcels: len(full.Cartons),
+	}
+	if known {
+		note.TotalWeightKg = qty.String(weight)
+	}
+	return note, nil
+}
+
+// totalWeight sums the carton weights; it is only known when every carton
+// was weighed.
+func totalWeight(cartons []Carton) (*big.Rat, bool) {
+	sum := qty.Zero()
+	for _, c := range cartons {
+		if c.WeightKg == "" {
+			return nil, false
+		}
+		w, err := qty.Parse(c.WeightKg)
+		if err != nil {
+			return nil, false
+		}
+		sum = qty.Add(sum, w)
+	}
+	return sum, len(cartons) > 0
+}
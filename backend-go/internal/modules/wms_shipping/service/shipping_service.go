@@ -0,0 +1,609 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+	"time"
+	_ "time/tzdata"
+
+	"erpwms/backend-go/internal/common/idempotency"
+	"erpwms/backend-go/internal/common/qty"
+	"erpwms/backend-go/internal/common/store"
+	"erpwms/backend-go/internal/db/sqlcgen"
+	outboundsvc "erpwms/backend-go/internal/modules/wms_outbound/service"
+	stocksvc "erpwms/backend-go/internal/modules/wms_stock/service"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+var (
+	ErrNotFound = store.ErrNotFound
+	ErrConflict = store.ErrConflict
+	ErrInvalid  = store.ErrInvalid
+)
+
+// Shipment states. Only open shipments can be packed; confirming books the
+// stock out and assigns the DDT number.
+const (
+	StatusOpen      = "open"
+	StatusConfirmed = "confirmed"
+	StatusCancelled = "cancelled"
+)
+
+// RefShipment is the stock_ledger ref_type of shipment issues; the ref_id is
+// the shipments id.
+const RefShipment = "shipment"
+
+// DefaultTransportReason is the causale del trasporto of a plain sale.
+const DefaultTransportReason = "Vendita"
+
+// ddtZone decides which calendar year a DDT is numbered in.
+var ddtZone = loadZone("Europe/Rome")
+
+var conflictFields = map[string]string{
+	"shipment_cartons_shipment_id_carton_no_key": "carton number",
+}
+
+// ShippingService packs picked sales orders into cartons and confirms
+// shipments, issuing the stock from the wave's staging location.
+type ShippingService struct {
+	DB      *pgxpool.Pool
+	Queries *sqlcgen.Queries
+	Stock   stocksvc.StockService
+}
+
+// Address is a DDT consignee. Empty fields in a ShipmentInput fall back to
+// the customer's master data.
+type Address struct {
+	Name       string `json:"name"`
+	Address    string `json:"address,omitempty"`
+	City       string `json:"city,omitempty"`
+	PostalCode string `json:"postal_code,omitempty"`
+	Country    string `json:"country,omitempty"`
+	VatNumber  string `json:"vat_number,omitempty"`
+}
+
+// ShipmentInput opens a shipment for a picked order. Carrier defaults to the
+// order's, TransportReason to DefaultTransportReason.
+type ShipmentInput struct {
+	OrderID         string  `json:"order_id"`
+	Carrier         string  `json:"carrier,omitempty"`
+	TransportReason string  `json:"transport_reason,omitempty"`
+	ShipTo          Address `json:"ship_to"`
+}
+
+type CartonInput struct {
+	WeightKg string `json:"weight_kg,omitempty"`
+}
+
+// PackInput puts qty of an order line into a carton. Qty is in Uom, or the
+// item's base unit when Uom is empty.
+type PackInput struct {
+	LineNo   int32  `json:"line_no"`
+	Qty      string `json:"qty"`
+	Uom      string `json:"uom,omitempty"`
+	LotCode  string `json:"lot_code,omitempty"`
+	SerialNo string `json:"serial_no,omitempty"`
+}
+
+type ShipmentFilter struct {
+	OrderID     string
+	WarehouseID string
+	Status      string
+	Limit       int32
+	Offset      int32
+}
+
+type Shipment struct {
+	ID                string     `json:"id"`
+	OrderID           string     `json:"order_id"`
+	WarehouseID       string     `json:"warehouse_id"`
+	StagingLocationID string     `json:"staging_location_id"`
+	Carrier           string     `json:"carrier,omitempty"`
+	TransportReason   string     `json:"transport_reason"`
+	ShipTo            Address    `json:"ship_to"`
+	Status            string     `json:"status"`
+	DdtNumber         string     `json:"ddt_number,omitempty"`
+	ConfirmedAt       *time.Time `json:"confirmed_at,omitempty"`
+	CreatedAt         time.Time  `json:"created_at"`
+	Cartons           []Carton   `json:"cartons,omitempty"`
+}
+
+type Carton struct {
+	CartonNo int32        `json:"carton_no"`
+	WeightKg string       `json:"weight_kg,omitempty"`
+	Lines    []CartonLine `json:"lines"`
+}
+
+// CartonLine quantities are in the item's base unit. MoveID is set once the
+// shipment is confirmed.
+type CartonLine struct {
+	ID          string `json:"id"`
+	OrderLineID string `json:"order_line_id"`
+	LineNo      int32  `json:"line_no"`
+	ItemID      string `json:"item_id"`
+	Sku         string `json:"sku"`
+	ItemName    string `json:"item_name"`
+	Uom         string `json:"uom"`
+	Qty         string `json:"qty"`
+	LotCode     string `json:"lot_code,omitempty"`
+	SerialNo    string `json:"serial_no,omitempty"`
+	MoveID      string `json:"move_id,omitempty"`
+}
+
+func (s ShippingService) CreateShipment(ctx context.Context, in ShipmentInput, actor uuid.UUID) (Shipment, error) {
+	orderID, err := store.ParseUUID("order_id", in.OrderID)
+	if err != nil {
+		return Shipment{}, err
+	}
+	var out Shipment
+	err = s.mutate(ctx, actor, "shipment.created", func(q *sqlcgen.Queries) (string, any, error) {
+		so, err := q.LockSalesOrder(ctx, store.UUID(orderID))
+		if err != nil {
+			return "", nil, err
+		}
+		if so.Status != outboundsvc.StatusPicked {
+			return "", nil, fmt.Errorf("%w: order is %s", ErrInvalid, so.Status)
+		}
+		w, err := q.GetWave(ctx, so.WaveID)
+		if err != nil {
+			return "", nil, err
+		}
+		customer, err := q.GetCustomer(ctx, so.CustomerID)
+		if err != nil {
+			return "", nil, err
+		}
+		to := shipTo(customer, in.ShipTo)
+		carrier := in.Carrier
+		if carrier == "" {
+			carrier = so.Carrier.String
+		}
+		reason := strings.TrimSpace(in.TransportReason)
+		if reason == "" {
+			reason = DefaultTransportReason
+		}
+		sh, err := q.CreateShipment(ctx, sqlcgen.CreateShipmentParams{
+			OrderID: so.ID, WarehouseID: so.WarehouseID, StagingLocationID: w.StagingLocationID, Carrier: store.Text(carrier),
+			TransportReason: reason, ShipToName: to.Name, ShipToAddress: store.Text(to.Address), ShipToCity: store.Text(to.City),
+			ShipToPostalCode: store.Text(to.PostalCode), ShipToCountry: store.Text(to.Country), ShipToVatNumber: store.Text(to.VatNumber),
+			CreatedBy: store.UUID(actor),
+		})
+		if err != nil {
+			return "", nil, err
+		}
+		out = toShipment(sh, nil)
+		return out.ID, out, nil
+	})
+	return out, err
+}
+
+func (s ShippingService) GetShipment(ctx context.Context, id uuid.UUID) (Shipment, error) {
+	sh, err := s.Queries.GetShipment(ctx, store.UUID(id))
+	if err != nil {
+		return Shipment{}, store.MapErr(err, conflictFields)
+	}
+	return s.withContent(ctx, s.Queries, sh)
+}
+
+// ListShipments returns headers only; fetch one shipment for its cartons.
+func (s ShippingService) ListShipments(ctx context.Context, f ShipmentFilter) ([]Shipment, error) {
+	p := sqlcgen.ListShipmentsParams{Status: f.Status, PageLimit: f.Limit, PageOffset: f.Offset}
+	for _, u := range []struct {
+		name, v string
+		dst     *pgtype.UUID
+	}{{"order_id", f.OrderID, &p.OrderID}, {"warehouse_id", f.WarehouseID, &p.WarehouseID}} {
+		if u.v == "" {
+			continue
+		}
+		id, err := store.ParseUUID(u.name, u.v)
+		if err != nil {
+			return nil, err
+		}
+		*u.dst = store.UUID(id)
+	}
+	rows, err := s.Queries.ListShipments(ctx, p)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]Shipment, 0, len(rows))
+	for _, r := range rows {
+		out = append(out, toShipment(r, nil))
+	}
+	return out, nil
+}
+
+// AddCarton opens the next carton of an open shipment, numbered 1..n.
+func (s ShippingService) AddCarton(ctx context.Context, id uuid.UUID, in CartonInput, actor uuid.UUID) (Carton, error) {
+	var weight pgtype.Numeric
+	if in.WeightKg != "" {
+		v, err := qty.Parse(in.WeightKg)
+		if err != nil || v.Sign() < 0 {
+			return Carton{}, fmt.Errorf("%w: weight_kg must be a non-negative decimal", ErrInvalid)
+		}
+		weight = qty.ToNumeric(v)
+	}
+	var out Carton
+	err := s.mutate(ctx, actor, "shipment.carton_added", func(q *sqlcgen.Queries) (string, any, error) {
+		sh, err := lockOpen(ctx, q, id)
+		if err != nil {
+			return "", nil, err
+		}
+		next, err := q.NextCartonNo(ctx, sh.ID)
+		if err != nil {
+			return "", nil, err
+		}
+		c, err := q.InsertShipmentCarton(ctx, sqlcgen.InsertShipmentCartonParams{ShipmentID: sh.ID, CartonNo: next, WeightKg: weight})
+		if err != nil {
+			return "", nil, err
+		}
+		out = toCarton(c)
+		return sh.ID.String(), out, nil
+	})
+	return out, err
+}
+
+// Pack puts part of an order line into a carton. Across all open and
+// confirmed shipments of the order a line never holds more than was picked.
+func (s ShippingService) Pack(ctx context.Context, id uuid.UUID, cartonNo int32, in PackInput, actor uuid.UUID) (CartonLine, error) {
+	var out CartonLine
+	err := s.mutate(ctx, actor, "shipment.packed", func(q *sqlcgen.Queries) (string, any, error) {
+		sh, err := lockOpen(ctx, q, id)
+		if err != nil {
+			return "", nil, err
+		}
+		// The order lock serializes packing across shipments of one order.
+		if _, err := q.LockSalesOrder(ctx, sh.OrderID); err != nil {
+			return "", nil, err
+		}
+		c, err := q.GetShipmentCarton(ctx, sqlcgen.GetShipmentCartonParams{ShipmentID: sh.ID, CartonNo: cartonNo})
+		if errors.Is(err, pgx.ErrNoRows) {
+			return "", nil, fmt.Errorf("%w: carton %d", ErrNotFound, cartonNo)
+		}
+		if err != nil {
+			return "", nil, err
+		}
+		line, err := q.GetSalesOrderLineByNo(ctx, sqlcgen.GetSalesOrderLineByNoParams{OrderID: sh.OrderID, LineNo: in.LineNo})
+		if errors.Is(err, pgx.ErrNoRows) {
+			return "", nil, fmt.Errorf("%w: order has no line %d", ErrInvalid, in.LineNo)
+		}
+		if err != nil {
+			return "", nil, err
+		}
+		item, err := q.GetItem(ctx, line.ItemID)
+		if err != nil {
+			return "", nil, err
+		}
+		amount, err := s.Stock.BaseQty(ctx, q, uuid.UUID(line.ItemID.Bytes), in.Qty, in.Uom)
+		if err != nil {
+			return "", nil, err
+		}
+		if err := checkTracking(item.TrackingMode, amount, in.LotCode, in.SerialNo); err != nil {
+			return "", nil, err
+		}
+		packed, err := q.SumPackedForOrderLine(ctx, line.ID)
+		if err != nil {
+			return "", nil, err
+		}
+		if free := packable(qty.FromNumeric(line.QtyPicked), qty.FromNumeric(packed)); amount.Cmp(free) > 0 {
+			return "", nil, fmt.Errorf("%w: line %d has %s picked and not yet packed", ErrInvalid, in.LineNo, qty.String(free))
+		}
+		r, err := q.InsertShipmentCartonLine(ctx, sqlcgen.InsertShipmentCartonLineParams{
+			CartonID: c.ID, OrderLineID: line.ID, ItemID: line.ItemID, Qty: qty.ToNumeric(amount),
+			LotCode: store.Text(in.LotCode), SerialNo: store.Text(in.SerialNo),
+		})
+		if err != nil {
+			return "", nil, err
+		}
+		out = CartonLine{
+			ID: r.ID.String(), OrderLineID: line.ID.String(), LineNo: line.LineNo, ItemID: line.ItemID.String(),
+			Sku: item.Sku, ItemName: item.Name, Uom: item.Uom, Qty: qty.String(amount), LotCode: in.LotCode, SerialNo: in.SerialNo,
+		}
+		return sh.ID.String(), map[string]any{"shipment_id": sh.ID.String(), "carton_no": cartonNo, "line": out}, nil
+	})
+	return out, err
+}
+
+// Unpack takes a carton line out of an open shipment.
+func (s ShippingService) Unpack(ctx context.Context, id, lineID uuid.UUID, actor uuid.UUID) error {
+	return s.mutate(ctx, actor, "shipment.unpacked", func(q *sqlcgen.Queries) (string, any, error) {
+		sh, err := lockOpen(ctx, q, id)
+		if err != nil {
+			return "", nil, err
+		}
+		r, err := q.DeleteShipmentCartonLine(ctx, sqlcgen.DeleteShipmentCartonLineParams{ID: store.UUID(lineID), ShipmentID: sh.ID})
+		if err != nil {
+			return "", nil, err
+		}
+		return sh.ID.String(), map[string]any{"shipment_id": sh.ID.String(), "line_id": r.ID.String(), "qty": qty.String(qty.FromNumeric(r.Qty))}, nil
+	})
+}
+
+// CancelShipment drops an open shipment; its packed quantities become
+// packable again.
+func (s ShippingService) CancelShipment(ctx context.Context, id uuid.UUID, actor uuid.UUID) (Shipment, error) {
+	var out Shipment
+	err := s.mutate(ctx, actor, "shipment.cancelled", func(q *sqlcgen.Queries) (string, any, error) {
+		sh, err := lockOpen(ctx, q, id)
+		if err != nil {
+			return "", nil, err
+		}
+		if sh, err = q.SetShipmentStatus(ctx, sqlcgen.SetShipmentStatusParams{ID: sh.ID, Status: StatusCancelled}); err != nil {
+			return "", nil, err
+		}
+		out = toShipment(sh, nil)
+		return out.ID, out, nil
+	})
+	return out, err
+}
+
+// ConfirmShipment issues every packed line from the staging location, takes
+// the next DDT number of the warehouse and year, and emits shipment.confirmed
+// with the full packing content. The order becomes shipped once everything
+// picked has been shipped.
+func (s ShippingService) ConfirmShipment(ctx context.Context, id uuid.UUID, actor uuid.UUID, endpoint, idemKey string) (Shipment, error) {
+	reqHash := idempotency.Hash(id.String())
+	var prev Shipment
+	if found, err := idempotency.Replay(ctx, s.Queries, endpoint, idemKey, reqHash, &prev); err != nil || found {
+		return prev, err
+	}
+
+	tx, err := s.DB.Begin(ctx)
+	if err != nil {
+		return Shipment{}, err
+	}
+	defer tx.Rollback(ctx)
+	q := s.Queries.WithTx(tx)
+
+	sh, err := lockOpen(ctx, q, id)
+	if err != nil {
+		return Shipment{}, store.MapErr(err, conflictFields)
+	}
+	so, err := q.LockSalesOrder(ctx, sh.OrderID)
+	if err != nil {
+		return Shipment{}, err
+	}
+	cartons, err := q.ListShipmentCartons(ctx, sh.ID)
+	if err != nil {
+		return Shipment{}, err
+	}
+	content, err := q.ListShipmentContent(ctx, sh.ID)
+	if err != nil {
+		return Shipment{}, err
+	}
+	if err := checkPacked(cartons, content); err != nil {
+		return Shipment{}, err
+	}
+
+	for i, l := range content {
+		move, _, err := s.Stock.PostMove(ctx, q, stocksvc.MoveIssue, stocksvc.MoveRequest{
+			ItemID: l.ItemID.String(), Qty: qty.String(qty.FromNumeric(l.Qty)), FromLocationID: sh.StagingLocationID.String(),
+			ReasonCode: "SHIPMENT", LotCode: l.LotCode.String, SerialNo: l.SerialNo.String, RefType: RefShipment, RefID: sh.ID.String(),
+		}, actor)
+		if err != nil {
+			return Shipment{}, fmt.Errorf("carton %d line %d: %w", l.CartonNo, l.LineNo, err)
+		}
+		if err := q.SetShipmentCartonLineMove(ctx, sqlcgen.SetShipmentCartonLineMoveParams{ID: l.ID, MoveID: move.MoveID}); err != nil {
+			return Shipment{}, err
+		}
+		content[i].MoveID = move.MoveID
+		if _, err := q.AddSalesOrderLineShipped(ctx, sqlcgen.AddSalesOrderLineShippedParams{ID: l.OrderLineID, QtyShipped: l.Qty}); err != nil {
+			return Shipment{}, err
+		}
+	}
+
+	year := ddtYear(time.Now())
+	n, err := q.NextDdtNumber(ctx, sqlcgen.NextDdtNumberParams{WarehouseID: sh.WarehouseID, Year: year})
+	if err != nil {
+		return Shipment{}, err
+	}
+	if sh, err = q.ConfirmShipment(ctx, sqlcgen.ConfirmShipmentParams{
+		ID: sh.ID, DdtYear: pgtype.Int4{Int32: year, Valid: true}, DdtNumber: pgtype.Int4{Int32: n, Valid: true}, ConfirmedBy: store.UUID(actor),
+	}); err != nil {
+		return Shipment{}, err
+	}
+	out := toShipment(sh, buildCartons(cartons, content))
+
+	lines, err := q.ListSalesOrderLines(ctx, so.ID)
+	if err != nil {
+		return Shipment{}, err
+	}
+	if fullyShipped(lines) {
+		if so, err = q.SetSalesOrderStatus(ctx, sqlcgen.SetSalesOrderStatusParams{ID: so.ID, Status: outboundsvc.StatusShipped}); err != nil {
+			return Shipment{}, err
+		}
+		body, _ := json.Marshal(map[string]any{"id": so.ID.String(), "order_number": so.OrderNumber, "status": so.Status})
+		if err := store.Record(ctx, q, actor, "orders.shipped", "sales_orders", so.ID.String(), body); err != nil {
+			return Shipment{}, err
+		}
+	}
+
+	payload, _ := json.Marshal(map[string]any{"shipment": out, "order_number": so.OrderNumber})
+	if err := store.Record(ctx, q, actor, "shipment.confirmed", "shipments", out.ID, payload); err != nil {
+		return Shipment{}, err
+	}
+	if err := idempotency.Remember(ctx, q, endpoint, idemKey, store.UUID(actor), reqHash, out); err != nil {
+		return Shipment{}, err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return Shipment{}, err
+	}
+	return out, nil
+}
+
+func (s ShippingService) withContent(ctx context.Context, q *sqlcgen.Queries, sh sqlcgen.Shipment) (Shipment, error) {
+	cartons, err := q.ListShipmentCartons(ctx, sh.ID)
+	if err != nil {
+		return Shipment{}, err
+	}
+	content, err := q.ListShipmentContent(ctx, sh.ID)
+	if err != nil {
+		return Shipment{}, err
+	}
+	return toShipment(sh, buildCartons(cartons, content)), nil
+}
+
+// mutate runs fn in a transaction with its outbox event and audit entry
+// on shipments; see store.Mutate.
+func (s ShippingService) mutate(ctx context.Context, actor uuid.UUID, topic string, fn func(q *sqlcgen.Queries) (string, any, error)) error {
+	return store.Mutate(ctx, s.DB, s.Queries, actor, topic, "shipments", conflictFields, fn)
+}
+
+func lockOpen(ctx context.Context, q *sqlcgen.Queries, id uuid.UUID) (sqlcgen.Shipment, error) {
+	sh, err := q.LockShipment(ctx, store.UUID(id))
+	if err != nil {
+		return sqlcgen.Shipment{}, err
+	}
+	if sh.Status != StatusOpen {
+		return sqlcgen.Shipment{}, fmt.Errorf("%w: shipment is %s", ErrInvalid, sh.Status)
+	}
+	return sh, nil
+}
+
+// shipTo takes each non-empty override over the customer's address.
+func shipTo(c sqlcgen.Customer, in Address) Address {
+	pick := func(v string, fallback pgtype.Text) string {
+		if v = strings.TrimSpace(v); v != "" {
+			return v
+		}
+		return fallback.String
+	}
+	return Address{
+		Name:       pick(in.Name, pgtype.Text{String: c.Name, Valid: true}),
+		Address:    pick(in.Address, c.Address),
+		City:       pick(in.City, c.City),
+		PostalCode: pick(in.PostalCode, c.PostalCode),
+		Country:    pick(in.Country, c.Country),
+		VatNumber:  pick(in.VatNumber, c.VatNumber),
+	}
+}
+
+// checkTracking asks for the lot or serial a tracked item is issued by.
+func checkTracking(mode string, amount *big.Rat, lotCode, serialNo string) error {
+	if amount.Sign() <= 0 {
+		return fmt.Errorf("%w: qty must be positive", ErrInvalid)
+	}
+	switch mode {
+	case "lot":
+		if lotCode == "" {
+			return fmt.Errorf("%w: lot_code is required for a lot-tracked item", ErrInvalid)
+		}
+	case "serial":
+		if serialNo == "" || amount.Cmp(big.NewRat(1, 1)) != 0 {
+			return fmt.Errorf("%w: a serial-tracked item is packed one serial_no at a time", ErrInvalid)
+		}
+	}
+	return nil
+}
+
+// packable is what a line can still take: picked minus what open and
+// confirmed shipments already hold. It never goes negative.
+func packable(picked, packed *big.Rat) *big.Rat {
+	free := qty.Sub(picked, packed)
+	if free.Sign() < 0 {
+		return qty.Zero()
+	}
+	return free
+}
+
+// checkPacked refuses to confirm an empty shipment or one with an empty
+// carton, which would print a wrong parcel count on the DDT.
+func checkPacked(cartons []sqlcgen.ShipmentCarton, content []sqlcgen.ListShipmentContentRow) error {
+	if len(content) == 0 {
+		return fmt.Errorf("%w: nothing is packed", ErrInvalid)
+	}
+	used := make(map[int32]bool, len(cartons))
+	for _, l := range content {
+		used[l.CartonNo] = true
+	}
+	for _, c := range cartons {
+		if !used[c.CartonNo] {
+			return fmt.Errorf("%w: carton %d is empty", ErrInvalid, c.CartonNo)
+		}
+	}
+	return nil
+}
+
+// fullyShipped reports whether every picked quantity has left. Lines with
+// nothing picked (all short) do not hold the order back.
+func fullyShipped(lines []sqlcgen.SalesOrderLine) bool {
+	for _, l := range lines {
+		if qty.FromNumeric(l.QtyShipped).Cmp(qty.FromNumeric(l.QtyPicked)) < 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// ddtYear is the calendar year a DDT issued at t is numbered in, taken in
+// Italian local time so a shipment confirmed just after midnight on New
+// Year's Day starts the new series.
+func ddtYear(t time.Time) int32 {
+	return int32(t.In(ddtZone).Year())
+}
+
+// FormatDdtNumber renders a DDT number the way it is printed, "n/yyyy".
+func FormatDdtNumber(n, year int32) string {
+	return fmt.Sprintf("%d/%d", n, year)
+}
+
+func loadZone(name string) *time.Location {
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		return time.UTC
+	}
+	return loc
+}
+
+func buildCartons(cartons []sqlcgen.ShipmentCarton, content []sqlcgen.ListShipmentContentRow) []Carton {
+	out := make([]Carton, 0, len(cartons))
+	idx := make(map[int32]int, len(cartons))
+	for _, c := range cartons {
+		idx[c.CartonNo] = len(out)
+		out = append(out, toCarton(c))
+	}
+	for _, l := range content {
+		i, ok := idx[l.CartonNo]
+		if !ok {
+			continue
+		}
+		out[i].Lines = append(out[i].Lines, CartonLine{
+			ID: l.ID.String(), OrderLineID: l.OrderLineID.String(), LineNo: l.LineNo, ItemID: l.ItemID.String(),
+			Sku: l.Sku, ItemName: l.ItemName, Uom: l.Uom, Qty: qty.String(qty.FromNumeric(l.Qty)),
+			LotCode: l.LotCode.String, SerialNo: l.SerialNo.String, MoveID: store.OptUUID(l.MoveID),
+		})
+	}
+	return out
+}
+
+func toCarton(c sqlcgen.ShipmentCarton) Carton {
+	out := Carton{CartonNo: c.CartonNo, Lines: []CartonLine{}}
+	if c.WeightKg.Valid {
+		out.WeightKg = qty.String(qty.FromNumeric(c.WeightKg))
+	}
+	return out
+}
+
+func toShipment(sh sqlcgen.Shipment, cartons []Carton) Shipment {
+	out := Shipment{
+		ID: sh.ID.String(), OrderID: sh.OrderID.String(), WarehouseID: sh.WarehouseID.String(),
+		StagingLocationID: sh.StagingLocationID.String(), Carrier: sh.Carrier.String, TransportReason: sh.TransportReason,
+		ShipTo: Address{
+			Name: sh.ShipToName, Address: sh.ShipToAddress.String, City: sh.ShipToCity.String,
+			PostalCode: sh.ShipToPostalCode.String, Country: sh.ShipToCountry.String, VatNumber: sh.ShipToVatNumber.String,
+		},
+		Status: sh.Status, CreatedAt: sh.CreatedAt.Time, Cartons: cartons,
+	}
+	if sh.DdtNumber.Valid {
+		out.DdtNumber = FormatDdtNumber(sh.DdtNumber.Int32, sh.DdtYear.Int32)
+	}
+	if sh.ConfirmedAt.Valid {
+		out.ConfirmedAt = &sh.ConfirmedAt.Time
+	}
+	return out
+}
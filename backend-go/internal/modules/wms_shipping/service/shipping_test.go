@@ -0,0 +1,107 @@
+package service
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+	"time"
+
+	"erpwms/backend-go/internal/common/qty"
+	"erpwms/backend-go/internal/common/store"
+	"erpwms/backend-go/internal/db/sqlcgen"
+)
+
+func TestDdtYearUsesItalianTime(t *testing.T) {
+	// 23:30 UTC on New Year's Eve is already 00:30 on 1 January in Rome.
+	if got := ddtYear(time.Date(2025, 12, 31, 23, 30, 0, 0, time.UTC)); got != 2026 {
+		t.Fatalf("got %d", got)
+	}
+	if got := ddtYear(time.Date(2025, 12, 31, 22, 30, 0, 0, time.UTC)); got != 2025 {
+		t.Fatalf("got %d", got)
+	}
+}
+
+func TestFormatDdtNumber(t *testing.T) {
+	if got := FormatDdtNumber(42, 2026); got != "42/2026" {
+		t.Fatalf("got %q", got)
+	}
+}
+
+func TestPackable(t *testing.T) {
+	if got := packable(big.NewRat(10, 1), big.NewRat(4, 1)); got.Cmp(big.NewRat(6, 1)) != 0 {
+		t.Fatalf("got %s", got.RatString())
+	}
+	if got := packable(big.NewRat(3, 1), big.NewRat(5, 1)); got.Sign() != 0 {
+		t.Fatalf("over-packed: got %s", got.RatString())
+	}
+}
+
+func TestCheckTracking(t *testing.T) {
+	one, two := big.NewRat(1, 1), big.NewRat(2, 1)
+	for _, tc := range []struct {
+		mode, lot, serial string
+		amount            *big.Rat
+		ok                bool
+	}{
+		{"none", "", "", two, true},
+		{"none", "", "", qty.Zero(), false},
+		{"lot", "", "", two, false},
+		{"lot", "L1", "", two, true},
+		{"serial", "", "S1", one, true},
+		{"serial", "", "S1", two, false},
+		{"serial", "", "", one, false},
+	} {
+		err := checkTracking(tc.mode, tc.amount, tc.lot, tc.serial)
+		if (err == nil) != tc.ok {
+			t.Fatalf("%s lot=%q serial=%q qty=%s: got %v", tc.mode, tc.lot, tc.serial, tc.amount.RatString(), err)
+		}
+		if err != nil && !errors.Is(err, ErrInvalid) {
+			t.Fatalf("want ErrInvalid, got %v", err)
+		}
+	}
+}
+
+func TestCheckPackedRejectsEmptyCarton(t *testing.T) {
+	cartons := []sqlcgen.ShipmentCarton{{CartonNo: 1}, {CartonNo: 2}}
+	if err := checkPacked(cartons, nil); !errors.Is(err, ErrInvalid) {
+		t.Fatalf("empty shipment: got %v", err)
+	}
+	content := []sqlcgen.ListShipmentContentRow{{CartonNo: 1}}
+	if err := checkPacked(cartons, content); !errors.Is(err, ErrInvalid) {
+		t.Fatalf("empty carton 2: got %v", err)
+	}
+	content = append(content, sqlcgen.ListShipmentContentRow{CartonNo: 2})
+	if err := checkPacked(cartons, content); err != nil {
+		t.Fatalf("got %v", err)
+	}
+}
+
+func TestFullyShipped(t *testing.T) {
+	line := func(picked, shipped int64) sqlcgen.SalesOrderLine {
+		return sqlcgen.SalesOrderLine{QtyPicked: qty.ToNumeric(big.NewRat(picked, 1)), QtyShipped: qty.ToNumeric(big.NewRat(shipped, 1))}
+	}
+	if !fullyShipped([]sqlcgen.SalesOrderLine{line(5, 5), line(0, 0)}) {
+		t.Fatal("all picked quantities shipped")
+	}
+	if fullyShipped([]sqlcgen.SalesOrderLine{line(5, 5), line(3, 1)}) {
+		t.Fatal("line 2 still has 2 to ship")
+	}
+}
+
+func TestShipToOverridesCustomer(t *testing.T) {
+	c := sqlcgen.Customer{Name: "Rossi Srl", Address: store.Text("Via Roma 1"), City: store.Text("Milano"), Country: store.Text("IT")}
+	got := shipTo(c, Address{City: "Torino", PostalCode: "10100"})
+	want := Address{Name: "Rossi Srl", Address: "Via Roma 1", City: "Torino", PostalCode: "10100", Country: "IT"}
+	if got != want {
+		t.Fatalf("got %+v", got)
+	}
+}
+
+func TestTotalWeightNeedsEveryCarton(t *testing.T) {
+	if w, ok := totalWeight([]Carton{{WeightKg: "1.5"}, {WeightKg: "2"}}); !ok || w.Cmp(big.NewRat(7, 2)) != 0 {
+		t.Fatalf("got %v %v", w, ok)
+	}
+	if _, ok := totalWeight([]Carton{{WeightKg: "1.5"}, {}}); ok {
+		t.Fatal("an unweighed carton makes the total unknown")
+	}
+}
@@ -0,0 +1,190 @@
+package http
+
+import (
+	"errors"
+	"strconv"
+
+	"erpwms/backend-go/internal/modules/wms_stock/service"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+func (h StockHandlers) SuggestPutaway(c *gin.Context) {
+	var req service.SuggestRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(400, gin.H{"error": "bad request"})
+		return
+	}
+	sug, err := h.Service.SuggestPutaway(c.Request.Context(), req)
+	if err != nil {
+		writePutawayErr(c, err)
+		return
+	}
+	c.JSON(200, sug)
+}
+
+func (h StockHandlers) ListPutawayRules(c *gin.Context) {
+	rows, err := h.Service.ListPutawayRules(c.Request.Context(), c.Query("warehouse_id"), c.Query("include_inactive") == "true")
+	if err != nil {
+		writePutawayErr(c, err)
+		return
+	}
+	c.JSON(200, gin.H{"items": rows})
+}
+
+func (h StockHandlers) CreatePutawayRule(c *gin.Context) {
+	var in service.PutawayRuleInput
+	if err := c.ShouldBindJSON(&in); err != nil {
+		c.JSON(400, gin.H{"error": "bad request"})
+		return
+	}
+	uid, ok := actorID(c)
+	if !ok {
+		return
+	}
+	r, err := h.Service.CreatePutawayRule(c.Request.Context(), in, uid)
+	if err != nil {
+		writePutawayErr(c, err)
+		return
+	}
+	c.JSON(201, r)
+}
+
+func (h StockHandlers) UpdatePutawayRule(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(400, gin.H{"error": "invalid id"})
+		return
+	}
+	var in service.PutawayRuleInput
+	if err := c.ShouldBindJSON(&in); err != nil {
+		c.JSON(400, gin.H{"error": "bad request"})
+		return
+	}
+	uid, ok := actorID(c)
+	if !ok {
+		return
+	}
+	r, err := h.Service.UpdatePutawayRule(c.Request.Context(), id, in, uid)
+	if err != nil {
+		writePutawayErr(c, err)
+		return
+	}
+	c.JSON(200, r)
+}
+
+func (h StockHandlers) DeactivatePutawayRule(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(400, gin.H{"error": "invalid id"})
+		return
+	}
+	uid, ok := actorID(c)
+	if !ok {
+		return
+	}
+	r, err := h.Service.DeactivatePutawayRule(c.Request.Context(), id, uid)
+	if err != nil {
+		writePutawayErr(c, err)
+		return
+	}
+	c.JSON(200, r)
+}
+
+func (h StockHandlers) ListPutawayTasks(c *gin.Context) {
+	limit, _ := strconv.ParseInt(c.DefaultQuery("limit", "100"), 10, 32)
+	offset, _ := strconv.ParseInt(c.DefaultQuery("offset", "0"), 10, 32)
+	rows, err := h.Service.ListPutawayTasks(c.Request.Context(), service.PutawayTaskFilter{
+		WarehouseID: c.Query("warehouse_id"),
+		Status:      c.Query("status"),
+		Limit:       int32(limit),
+		Offset:      int32(offset),
+	})
+	if err != nil {
+		writePutawayErr(c, err)
+		return
+	}
+	c.JSON(200, gin.H{"items": rows})
+}
+
+func (h StockHandlers) CreatePutawayTask(c *gin.Context) {
+	var req service.PutawayTaskRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(400, gin.H{"error": "bad request"})
+		return
+	}
+	uid, ok := actorID(c)
+	if !ok {
+		return
+	}
+	t, err := h.Service.CreatePutawayTask(c.Request.Context(), req, uid)
+	if err != nil {
+		writePutawayErr(c, err)
+		return
+	}
+	c.JSON(201, t)
+}
+
+func (h StockHandlers) ConfirmPutawayTask(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(400, gin.H{"error": "invalid id"})
+		return
+	}
+	// The body is optional; to_location_id overrides the suggested bin.
+	var req struct {
+		ToLocationID string `json:"to_location_id"`
+	}
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(400, gin.H{"error": "bad request"})
+			return
+		}
+	}
+	uid, ok := actorID(c)
+	if !ok {
+		return
+	}
+	t, err := h.Service.ConfirmPutawayTask(c.Request.Context(), id, req.ToLocationID, uid)
+	if err != nil {
+		writePutawayErr(c, err)
+		return
+	}
+	c.JSON(200, t)
+}
+
+func (h StockHandlers) CancelPutawayTask(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(400, gin.H{"error": "invalid id"})
+		return
+	}
+	uid, ok := actorID(c)
+	if !ok {
+		return
+	}
+	t, err := h.Service.CancelPutawayTask(c.Request.Context(), id, uid)
+	if err != nil {
+		writePutawayErr(c, err)
+		return
+	}
+	c.JSON(200, t)
+}
+
+func writePutawayErr(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, service.ErrNoPutawayLocation):
+		c.JSON(422, gin.H{"error": "no_putaway_location"})
+	case errors.Is(err, service.ErrInvalidFilter):
+		c.JSON(400, gin.H{"error": err.Error()})
+	case errors.Is(err, service.ErrInvalidMove), errors.Is(err, service.ErrNotFound), errors.Is(err, service.ErrForbidden):
+		writeMoveErr(c, err)
+	default:
+		var short *service.InsufficientStockError
+		if errors.As(err, &short) {
+			writeMoveErr(c, err)
+			return
+		}
+		c.JSON(500, gin.H{"error": "db"})
+	}
+}
@@ -0,0 +1,145 @@
+package http
+
+import (
+	"strconv"
+
+	"erpwms/backend-go/internal/modules/wms_stock/service"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+func (h StockHandlers) ListReplenishmentSettings(c *gin.Context) {
+	rows, err := h.Service.ListReplenishmentSettings(c.Request.Context(), c.Query("warehouse_id"), c.Query("item_id"), c.Query("include_inactive") == "true")
+	if err != nil {
+		writePutawayErr(c, err)
+		return
+	}
+	c.JSON(200, gin.H{"items": rows})
+}
+
+func (h StockHandlers) CreateReplenishmentSetting(c *gin.Context) {
+	var in service.ReplenishmentSettingInput
+	if err := c.ShouldBindJSON(&in); err != nil {
+		c.JSON(400, gin.H{"error": "bad request"})
+		return
+	}
+	uid, ok := actorID(c)
+	if !ok {
+		return
+	}
+	r, err := h.Service.CreateReplenishmentSetting(c.Request.Context(), in, uid)
+	if err != nil {
+		writePutawayErr(c, err)
+		return
+	}
+	c.JSON(201, r)
+}
+
+func (h StockHandlers) UpdateReplenishmentSetting(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(400, gin.H{"error": "invalid id"})
+		return
+	}
+	var in service.ReplenishmentSettingInput
+	if err := c.ShouldBindJSON(&in); err != nil {
+		c.JSON(400, gin.H{"error": "bad request"})
+		return
+	}
+	uid, ok := actorID(c)
+	if !ok {
+		return
+	}
+	r, err := h.Service.UpdateReplenishmentSetting(c.Request.Context(), id, in, uid)
+	if err != nil {
+		writePutawayErr(c, err)
+		return
+	}
+	c.JSON(200, r)
+}
+
+func (h StockHandlers) DeactivateReplenishmentSetting(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(400, gin.H{"error": "invalid id"})
+		return
+	}
+	uid, ok := actorID(c)
+	if !ok {
+		return
+	}
+	r, err := h.Service.DeactivateReplenishmentSetting(c.Request.Context(), id, uid)
+	if err != nil {
+		writePutawayErr(c, err)
+		return
+	}
+	c.JSON(200, r)
+}
+
+// PlanReplenishment runs the planner now instead of waiting for the worker.
+// When a pick face fails the answer is an error, but the tasks planned for
+// the other faces are kept.
+func (h StockHandlers) PlanReplenishment(c *gin.Context) {
+	uid, ok := actorID(c)
+	if !ok {
+		return
+	}
+	run, err := h.Service.PlanReplenishment(c.Request.Context(), c.Query("warehouse_id"), uid)
+	if err != nil {
+		writePutawayErr(c, err)
+		return
+	}
+	c.JSON(200, run)
+}
+
+func (h StockHandlers) ListReplenishmentTasks(c *gin.Context) {
+	limit, _ := strconv.ParseInt(c.DefaultQuery("limit", "100"), 10, 32)
+	offset, _ := strconv.ParseInt(c.DefaultQuery("offset", "0"), 10, 32)
+	rows, err := h.Service.ListReplenishmentTasks(c.Request.Context(), service.ReplenishmentTaskFilter{
+		WarehouseID: c.Query("warehouse_id"),
+		Status:      c.Query("status"),
+		Limit:       int32(limit),
+		Offset:      int32(offset),
+	})
+	if err != nil {
+		writePutawayErr(c, err)
+		return
+	}
+	c.JSON(200, gin.H{"items": rows})
+}
+
+func (h StockHandlers) ConfirmReplenishmentTask(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(400, gin.H{"error": "invalid id"})
+		return
+	}
+	uid, ok := actorID(c)
+	if !ok {
+		return
+	}
+	t, err := h.Service.ConfirmReplenishmentTask(c.Request.Context(), id, uid)
+	if err != nil {
+		writePutawayErr(c, err)
+		return
+	}
+	c.JSON(200, t)
+}
+
+func (h StockHandlers) CancelReplenishmentTask(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(400, gin.H{"error": "invalid id"})
+		return
+	}
+	uid, ok := actorID(c)
+	if !ok {
+		return
+	}
+	t, err := h.Service.CancelReplenishmentTask(c.Request.Context(), id, uid)
+	if err != nil {
+		writePutawayErr(c, err)
+		return
+	}
+	c.JSON(200, t)
+}
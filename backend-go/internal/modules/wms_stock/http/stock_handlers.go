@@ -1,8 +1,12 @@
 package http
 
 import (
+	"context"
+	"errors"
 	"strconv"
+	"time"
 
+	"erpwms/backend-go/internal/common/locpath"
 	"erpwms/backend-go/internal/db/sqlcgen"
 	"erpwms/backend-go/internal/modules/wms_stock/service"
 	"github.com/gin-gonic/gin"
@@ -17,10 +21,41 @@ type StockHandlers struct {
 func (h StockHandlers) ListBalances(c *gin.Context) {
 	limit, _ := strconv.ParseInt(c.DefaultQuery("limit", "50"), 10, 32)
 	offset, _ := strconv.ParseInt(c.DefaultQuery("offset", "0"), 10, 32)
+	path, err := locpath.Normalize(c.Query("path"))
+	if err != nil {
+		c.JSON(400, gin.H{"error": err.Error()})
+		return
+	}
+	if v := c.Query("as_of"); v != "" {
+		asOf, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			c.JSON(400, gin.H{"error": "as_of must be RFC 3339"})
+			return
+		}
+		rows, err := h.Service.BalancesAsOf(c.Request.Context(), asOf, service.BalanceFilter{
+			Q:         c.Query("q"),
+			Warehouse: c.Query("warehouse"),
+			Location:  c.Query("location"),
+			Status:    c.Query("status"),
+			HU:        c.Query("hu"),
+			Path:      path,
+			Limit:     int32(limit),
+			Offset:    int32(offset),
+		})
+		if err != nil {
+			c.JSON(500, gin.H{"error": "db"})
+			return
+		}
+		c.JSON(200, gin.H{"items": rows, "as_of": asOf})
+		return
+	}
 	rows, err := h.Queries.ListStockBalances(c.Request.Context(), sqlcgen.ListStockBalancesParams{
 		Column1: c.Query("q"),
 		Column2: c.Query("warehouse"),
 		Column3: c.Query("location"),
+		Column4: c.Query("status"),
+		Column5: c.Query("hu"),
+		Column6: path,
 		Limit:   int32(limit),
 		Offset:  int32(offset),
 	})
@@ -31,7 +66,55 @@ func (h StockHandlers) ListBalances(c *gin.Context) {
 	c.JSON(200, gin.H{"items": rows})
 }
 
+func (h StockHandlers) ListLedger(c *gin.Context) {
+	limit, _ := strconv.ParseInt(c.DefaultQuery("limit", "100"), 10, 32)
+	page, err := h.Service.ListLedger(c.Request.Context(), service.LedgerFilter{
+		ItemID:      c.Query("item_id"),
+		LocationID:  c.Query("location_id"),
+		WarehouseID: c.Query("warehouse_id"),
+		ReasonCode:  c.Query("reason_code"),
+		ActorID:     c.Query("actor_user_id"),
+		From:        c.Query("from"),
+		To:          c.Query("to"),
+		RefType:     c.Query("ref_type"),
+		RefID:       c.Query("ref_id"),
+		Cursor:      c.Query("cursor"),
+		Limit:       int32(limit),
+	})
+	if err != nil {
+		if errors.Is(err, service.ErrInvalidFilter) {
+			c.JSON(400, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(500, gin.H{"error": "db"})
+		return
+	}
+	c.JSON(200, page)
+}
+
 func (h StockHandlers) Move(c *gin.Context) {
+	h.book(c, h.Service.MoveStock, "/api/stock/moves")
+}
+
+func (h StockHandlers) Receive(c *gin.Context) {
+	h.book(c, h.Service.Receive, "/api/stock/receipts")
+}
+
+func (h StockHandlers) Issue(c *gin.Context) {
+	h.book(c, h.Service.Issue, "/api/stock/issues")
+}
+
+func (h StockHandlers) Adjust(c *gin.Context) {
+	h.book(c, h.Service.Adjust, "/api/stock/adjustments")
+}
+
+func (h StockHandlers) ChangeStatus(c *gin.Context) {
+	h.book(c, h.Service.ChangeStatus, "/api/stock/status-changes")
+}
+
+type bookFunc func(ctx context.Context, req service.MoveRequest, actor uuid.UUID, endpoint, idemKey string) (service.MoveResponse, error)
+
+func (h StockHandlers) book(c *gin.Context, fn bookFunc, endpoint string) {
 	key := c.GetHeader("Idempotency-Key")
 	if key == "" {
 		c.JSON(400, gin.H{"error": "Idempotency-Key required"})
@@ -42,15 +125,221 @@ func (h StockHandlers) Move(c *gin.Context) {
 		c.JSON(400, gin.H{"error": "bad request"})
 		return
 	}
+	uid, ok := actorID(c)
+	if !ok {
+		return
+	}
+	resp, err := fn(c.Request.Context(), req, uid, endpoint, key)
+	if err != nil {
+		writeMoveErr(c, err)
+		return
+	}
+	c.JSON(200, resp)
+}
+
+func (h StockHandlers) MoveBatch(c *gin.Context) {
+	key := c.GetHeader("Idempotency-Key")
+	if key == "" {
+		c.JSON(400, gin.H{"error": "Idempotency-Key required"})
+		return
+	}
+	var req service.BatchMoveRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(400, gin.H{"error": "bad request"})
+		return
+	}
+	uid, ok := actorID(c)
+	if !ok {
+		return
+	}
+	resp, err := h.Service.MoveBatch(c.Request.Context(), req, uid, "/api/stock/move-batches", key)
+	if err != nil {
+		writeMoveErr(c, err)
+		return
+	}
+	c.JSON(200, resp)
+}
+
+func (h StockHandlers) Reverse(c *gin.Context) {
+	key := c.GetHeader("Idempotency-Key")
+	if key == "" {
+		c.JSON(400, gin.H{"error": "Idempotency-Key required"})
+		return
+	}
+	moveID, err := uuid.Parse(c.Param("move_id"))
+	if err != nil {
+		c.JSON(400, gin.H{"error": "invalid move_id"})
+		return
+	}
+	// The body is optional; an empty one reverses without a comment.
+	var req service.ReverseRequest
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(400, gin.H{"error": "bad request"})
+			return
+		}
+	}
+	uid, ok := actorID(c)
+	if !ok {
+		return
+	}
+	resp, err := h.Service.Reverse(c.Request.Context(), moveID, req, uid, "/api/stock/moves/reverse", key)
+	if err != nil {
+		writeMoveErr(c, err)
+		return
+	}
+	c.JSON(200, resp)
+}
+
+func (h StockHandlers) Allocate(c *gin.Context) {
+	key := c.GetHeader("Idempotency-Key")
+	if key == "" {
+		c.JSON(400, gin.H{"error": "Idempotency-Key required"})
+		return
+	}
+	var req service.AllocateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(400, gin.H{"error": "bad request"})
+		return
+	}
+	uid, ok := actorID(c)
+	if !ok {
+		return
+	}
+	resp, err := h.Service.Allocate(c.Request.Context(), req, uid, "/api/stock/allocations", key)
+	if err != nil {
+		writeMoveErr(c, err)
+		return
+	}
+	c.JSON(201, resp)
+}
+
+func (h StockHandlers) ListAllocations(c *gin.Context) {
+	refType, refID := c.Query("ref_type"), c.Query("ref_id")
+	if refType == "" || refID == "" {
+		c.JSON(400, gin.H{"error": "ref_type and ref_id required"})
+		return
+	}
+	rows, err := h.Service.ListAllocations(c.Request.Context(), refType, refID, c.Query("include_closed") == "true")
+	if err != nil {
+		c.JSON(500, gin.H{"error": "db"})
+		return
+	}
+	c.JSON(200, gin.H{"items": rows})
+}
+
+func (h StockHandlers) ReleaseAllocation(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(400, gin.H{"error": "invalid id"})
+		return
+	}
+	uid, ok := actorID(c)
+	if !ok {
+		return
+	}
+	a, err := h.Service.ReleaseAllocation(c.Request.Context(), id, uid)
+	if err != nil {
+		writeMoveErr(c, err)
+		return
+	}
+	c.JSON(200, a)
+}
+
+func (h StockHandlers) TransferAllocation(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(400, gin.H{"error": "invalid id"})
+		return
+	}
+	var req service.TransferAllocationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(400, gin.H{"error": "bad request"})
+		return
+	}
+	uid, ok := actorID(c)
+	if !ok {
+		return
+	}
+	rows, err := h.Service.TransferAllocation(c.Request.Context(), id, req, uid)
+	if err != nil {
+		writeMoveErr(c, err)
+		return
+	}
+	c.JSON(200, gin.H{"items": rows})
+}
+
+// LocationTree lists the children of a node of the location tree with their
+// stock totals.
+func (h StockHandlers) LocationTree(c *gin.Context) {
+	rows, err := h.Service.LocationTree(c.Request.Context(), treeFilter(c))
+	if err != nil {
+		writeMoveErr(c, err)
+		return
+	}
+	c.JSON(200, gin.H{"items": rows})
+}
+
+// Rollup totals stock per item and status at or below a node.
+func (h StockHandlers) Rollup(c *gin.Context) {
+	rows, err := h.Service.Rollup(c.Request.Context(), treeFilter(c))
+	if err != nil {
+		writeMoveErr(c, err)
+		return
+	}
+	c.JSON(200, gin.H{"items": rows})
+}
+
+func treeFilter(c *gin.Context) service.TreeFilter {
+	return service.TreeFilter{WarehouseID: c.Query("warehouse_id"), Path: c.Query("path"), ItemID: c.Query("item_id"), Sku: c.Query("sku")}
+}
+
+// ZoneUtilization reports per zone how full the warehouse's locations are.
+func (h StockHandlers) ZoneUtilization(c *gin.Context) {
+	wh, err := uuid.Parse(c.Query("warehouse_id"))
+	if err != nil {
+		c.JSON(400, gin.H{"error": "warehouse_id required"})
+		return
+	}
+	rows, err := h.Service.ZoneUtilization(c.Request.Context(), wh, c.Query("zone"))
+	if err != nil {
+		writeMoveErr(c, err)
+		return
+	}
+	c.JSON(200, gin.H{"items": rows})
+}
+
+func actorID(c *gin.Context) (uuid.UUID, bool) {
 	uid, err := uuid.Parse(c.GetString("user_id"))
 	if err != nil || uid == uuid.Nil {
 		c.JSON(401, gin.H{"error": "unauthorized"})
+		return uuid.Nil, false
+	}
+	return uid, true
+}
+
+func writeMoveErr(c *gin.Context, err error) {
+	var rejected *service.BatchRejectedError
+	if errors.As(err, &rejected) {
+		c.JSON(422, gin.H{"error": "batch_rejected", "lines": rejected.Lines})
 		return
 	}
-	resp, err := h.Service.MoveStock(c.Request.Context(), req, uid, "/api/stock/moves", key)
-	if err != nil {
-		c.JSON(409, gin.H{"error": err.Error()})
+	var short *service.InsufficientStockError
+	if errors.As(err, &short) {
+		c.JSON(422, gin.H{"error": "insufficient_stock", "detail": short})
 		return
 	}
-	c.JSON(200, resp)
+	if errors.Is(err, service.ErrNotFound) {
+		c.JSON(404, gin.H{"error": err.Error()})
+		return
+	}
+	if errors.Is(err, service.ErrForbidden) {
+		c.JSON(403, gin.H{"error": err.Error()})
+		return
+	}
+	if errors.Is(err, service.ErrInvalidMove) {
+		c.JSON(400, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(409, gin.H{"error": err.Error()})
 }
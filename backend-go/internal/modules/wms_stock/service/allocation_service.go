@@ -0,0 +1,526 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"sort"
+	"time"
+
+	"erpwms/backend-go/internal/common/qty"
+	"erpwms/backend-go/internal/db/sqlcgen"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// Allocation strategies. FEFO is the default because it is the only one that
+// keeps short-dated lots from expiring on the shelf.
+const (
+	StrategyFEFO            = "fefo"
+	StrategyFIFO            = "fifo"
+	StrategyFewestLocations = "fewest_locations"
+)
+
+type AllocateRequest struct {
+	ItemID       string `json:"item_id"`
+	Qty          string `json:"qty"`
+	RefType      string `json:"ref_type"`
+	RefID        string `json:"ref_id"`
+	Strategy     string `json:"strategy,omitempty"`
+	WarehouseID  string `json:"warehouse_id,omitempty"`
+	AllowPartial bool   `json:"allow_partial,omitempty"`
+	Uom          string `json:"uom,omitempty"`
+}
+
+type AllocateResponse struct {
+	Allocations []Allocation `json:"allocations"`
+	Requested   string       `json:"requested"`
+	Allocated   string       `json:"allocated"`
+	Short       string       `json:"short"`
+}
+
+type TransferAllocationRequest struct {
+	RefType string `json:"ref_type"`
+	RefID   string `json:"ref_id"`
+	Qty     string `json:"qty,omitempty"`
+}
+
+type Allocation struct {
+	ID         string `json:"id"`
+	ItemID     string `json:"item_id"`
+	LocationID string `json:"location_id"`
+	LotID      string `json:"lot_id,omitempty"`
+	SerialID   string `json:"serial_id,omitempty"`
+	HuID       string `json:"hu_id,omitempty"`
+	Qty        string `json:"qty"`
+	RefType    string `json:"ref_type"`
+	RefID      string `json:"ref_id"`
+	Strategy   string `json:"strategy"`
+	Status     string `json:"status"`
+}
+
+// candidate is a balance row with free quantity the planner may reserve.
+type candidate struct {
+	LocationID   pgtype.UUID
+	LotID        pgtype.UUID
+	SerialID     pgtype.UUID
+	HuID         pgtype.UUID
+	LocationCode string
+	ExpiresOn    pgtype.Date
+	ReceivedAt   time.Time
+	Free         *big.Rat
+	// LotCode and SerialNo are only filled for replenishment sources, whose
+	// tasks name the lot and serial to pick.
+	LotCode  string
+	SerialNo string
+}
+
+type pick struct {
+	From candidate
+	Qty  *big.Rat
+}
+
+// Allocate reserves qty of an item for a demand reference, choosing source
+// bins and lots by the requested strategy.
+func (s StockService) Allocate(ctx context.Context, req AllocateRequest, actor uuid.UUID, endpoint, idemKey string) (AllocateResponse, error) {
+	reqHash := hashReq(req)
+	var prev AllocateResponse
+	if found, err := s.replay(ctx, endpoint, idemKey, reqHash, &prev); err != nil || found {
+		return prev, err
+	}
+	if req.RefType == "" || req.RefID == "" {
+		return AllocateResponse{}, fmt.Errorf("%w: ref_type and ref_id are required", ErrInvalidMove)
+	}
+	itemID, err := scanUUID(req.ItemID)
+	if err != nil {
+		return AllocateResponse{}, err
+	}
+	var warehouseID pgtype.UUID
+	if req.WarehouseID != "" {
+		if warehouseID, err = scanUUID(req.WarehouseID); err != nil {
+			return AllocateResponse{}, err
+		}
+	}
+	want, err := qty.Parse(req.Qty)
+	if err != nil {
+		return AllocateResponse{}, err
+	}
+	if want.Sign() <= 0 {
+		return AllocateResponse{}, fmt.Errorf("%w: qty must be positive", ErrInvalidMove)
+	}
+	actorID, _ := scanUUID(actor.String())
+
+	tx, err := s.DB.Begin(ctx)
+	if err != nil {
+		return AllocateResponse{}, err
+	}
+	defer tx.Rollback(ctx)
+	q := s.Queries.WithTx(tx)
+
+	if want, _, err = toBaseQty(ctx, q, itemID, want, req.Uom); err != nil {
+		return AllocateResponse{}, err
+	}
+	allocs, short, err := s.allocate(ctx, q, AllocateSpec{
+		ItemID: itemID, WarehouseID: warehouseID, Qty: want, RefType: req.RefType, RefID: req.RefID,
+		Strategy: req.Strategy, AllowPartial: req.AllowPartial,
+	}, actorID)
+	if err != nil {
+		return AllocateResponse{}, err
+	}
+	resp := AllocateResponse{Allocations: allocs, Requested: qty.String(want), Allocated: qty.String(qty.Sub(want, short)), Short: qty.String(short)}
+	if err := s.record(ctx, q, actorID, "stock.allocated", "stock_allocations", req.RefType+":"+req.RefID, resp); err != nil {
+		return AllocateResponse{}, err
+	}
+	if err := remember(ctx, q, endpoint, idemKey, actorID, reqHash, resp); err != nil {
+		return AllocateResponse{}, err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return AllocateResponse{}, err
+	}
+	return resp, nil
+}
+
+// ReleaseAllocation returns the reserved quantity to free stock.
+func (s StockService) ReleaseAllocation(ctx context.Context, id uuid.UUID, actor uuid.UUID) (Allocation, error) {
+	actorID, _ := scanUUID(actor.String())
+	tx, err := s.DB.Begin(ctx)
+	if err != nil {
+		return Allocation{}, err
+	}
+	defer tx.Rollback(ctx)
+	q := s.Queries.WithTx(tx)
+
+	a, err := lockActiveAllocation(ctx, q, pgUUID(id))
+	if err != nil {
+		return Allocation{}, err
+	}
+	if err := closeAllocation(ctx, q, a, qty.FromNumeric(a.Qty), "released", actorID); err != nil {
+		return Allocation{}, err
+	}
+	a.Status = "released"
+	out := toAllocation(a)
+	if err := s.record(ctx, q, actorID, "stock.allocation_released", "stock_allocations", out.ID, out); err != nil {
+		return Allocation{}, err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return Allocation{}, err
+	}
+	return out, nil
+}
+
+// TransferAllocation hands all or part of a reservation to another demand
+// reference without touching stock_balance; the reserved bins stay the same.
+func (s StockService) TransferAllocation(ctx context.Context, id uuid.UUID, req TransferAllocationRequest, actor uuid.UUID) ([]Allocation, error) {
+	if req.RefType == "" || req.RefID == "" {
+		return nil, fmt.Errorf("%w: ref_type and ref_id are required", ErrInvalidMove)
+	}
+	actorID, _ := scanUUID(actor.String())
+	requestID, _ := ctx.Value("request_id").(string)
+	tx, err := s.DB.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+	q := s.Queries.WithTx(tx)
+
+	a, err := lockActiveAllocation(ctx, q, pgUUID(id))
+	if err != nil {
+		return nil, err
+	}
+	held := qty.FromNumeric(a.Qty)
+	move := held
+	if req.Qty != "" {
+		if move, err = qty.Parse(req.Qty); err != nil {
+			return nil, err
+		}
+		if move.Sign() <= 0 || move.Cmp(held) > 0 {
+			return nil, fmt.Errorf("%w: qty must be between 0 and %s", ErrInvalidMove, qty.String(held))
+		}
+	}
+
+	var out []Allocation
+	if move.Cmp(held) == 0 {
+		if err := q.SetStockAllocationRef(ctx, sqlcgen.SetStockAllocationRefParams{ID: a.ID, RefType: req.RefType, RefID: req.RefID}); err != nil {
+			return nil, err
+		}
+		if err := q.InsertStockAllocationLog(ctx, sqlcgen.InsertStockAllocationLogParams{AllocationID: a.ID, Action: "transferred", Qty: a.Qty, RefType: req.RefType, RefID: req.RefID, ActorUserID: actorID, RequestID: txt(requestID)}); err != nil {
+			return nil, err
+		}
+		a.RefType, a.RefID = req.RefType, req.RefID
+		out = append(out, toAllocation(a))
+	} else {
+		rest := qty.ToNumeric(qty.Sub(held, move))
+		if err := q.SetStockAllocationQty(ctx, sqlcgen.SetStockAllocationQtyParams{ID: a.ID, Qty: rest}); err != nil {
+			return nil, err
+		}
+		if err := q.InsertStockAllocationLog(ctx, sqlcgen.InsertStockAllocationLogParams{AllocationID: a.ID, Action: "transferred_out", Qty: qty.ToNumeric(move), RefType: req.RefType, RefID: req.RefID, ActorUserID: actorID, RequestID: txt(requestID)}); err != nil {
+			return nil, err
+		}
+		split, err := q.InsertStockAllocation(ctx, splitAllocation(a, move, req.RefType, req.RefID, actorID))
+		if err != nil {
+			return nil, err
+		}
+		if err := q.InsertStockAllocationLog(ctx, sqlcgen.InsertStockAllocationLogParams{AllocationID: split.ID, Action: "transferred_in", Qty: split.Qty, RefType: a.RefType, RefID: a.RefID, ActorUserID: actorID, RequestID: txt(requestID)}); err != nil {
+			return nil, err
+		}
+		a.Qty = rest
+		out = append(out, toAllocation(a), toAllocation(split))
+	}
+	if err := s.record(ctx, q, actorID, "stock.allocation_transferred", "stock_allocations", a.ID.String(), map[string]any{"from_ref_type": a.RefType, "allocations": out}); err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (s StockService) ListAllocations(ctx context.Context, refType, refID string, includeClosed bool) ([]Allocation, error) {
+	rows, err := s.Queries.ListStockAllocationsByRef(ctx, sqlcgen.ListStockAllocationsByRefParams{RefType: refType, RefID: refID, IncludeClosed: includeClosed})
+	if err != nil {
+		return nil, err
+	}
+	out := make([]Allocation, 0, len(rows))
+	for _, r := range rows {
+		out = append(out, toAllocation(r))
+	}
+	return out, nil
+}
+
+// AllocateSpec is what to reserve, in the item's base unit. Stock at
+// ExcludeLocationID is never chosen.
+type AllocateSpec struct {
+	ItemID            pgtype.UUID
+	WarehouseID       pgtype.UUID
+	ExcludeLocationID pgtype.UUID
+	Qty               *big.Rat
+	RefType           string
+	RefID             string
+	Strategy          string
+	AllowPartial      bool
+}
+
+// ConsumeRequest picks Qty of an allocation: that much moves from the
+// allocation's bin to ToLocationID and the reservation is used up by it.
+type ConsumeRequest struct {
+	AllocationID uuid.UUID
+	Qty          *big.Rat
+	ToLocationID uuid.UUID
+	ReasonCode   string
+	RefType      string
+	RefID        string
+}
+
+// Reserve allocates inside the caller's transaction, for modules whose own
+// documents carry demand (sales orders, ...). Outbox and audit are left to
+// the caller.
+func (s StockService) Reserve(ctx context.Context, q *sqlcgen.Queries, spec AllocateSpec, actor uuid.UUID) ([]Allocation, *big.Rat, error) {
+	return s.allocate(ctx, q, spec, pgUUID(actor))
+}
+
+// ConsumeAllocation books the transfer of a pick inside the caller's
+// transaction and marks the picked quantity of the allocation consumed. The
+// reservation is lifted before the move so the move may take the stock it
+// held. Outbox and audit are left to the caller.
+func (s StockService) ConsumeAllocation(ctx context.Context, q *sqlcgen.Queries, req ConsumeRequest, actor uuid.UUID) (sqlcgen.StockLedger, error) {
+	actorID := pgUUID(actor)
+	a, err := lockActiveAllocation(ctx, q, pgUUID(req.AllocationID))
+	if err != nil {
+		return sqlcgen.StockLedger{}, err
+	}
+	if req.Qty.Sign() <= 0 || req.Qty.Cmp(qty.FromNumeric(a.Qty)) > 0 {
+		return sqlcgen.StockLedger{}, fmt.Errorf("%w: qty must be between 0 and %s", ErrInvalidMove, qty.String(qty.FromNumeric(a.Qty)))
+	}
+	tr, err := q.GetStockAllocationTracking(ctx, a.ID)
+	if err != nil {
+		return sqlcgen.StockLedger{}, err
+	}
+	if err := closeAllocation(ctx, q, a, req.Qty, "consumed", actorID); err != nil {
+		return sqlcgen.StockLedger{}, err
+	}
+	return s.post(ctx, q, consumePosting(a, tr, req, actorID))
+}
+
+// splitAllocation is the new allocation that takes move of a over to another
+// reference. It reserves the same balance row as a, handling unit included.
+func splitAllocation(a sqlcgen.StockAllocation, move *big.Rat, refType, refID string, actorID pgtype.UUID) sqlcgen.InsertStockAllocationParams {
+	return sqlcgen.InsertStockAllocationParams{
+		ItemID: a.ItemID, LocationID: a.LocationID, LotID: a.LotID, SerialID: a.SerialID, HuID: a.HuID,
+		Qty: qty.ToNumeric(move), RefType: refType, RefID: refID, Strategy: a.Strategy, CreatedBy: actorID,
+	}
+}
+
+// consumePosting is the pick transfer of a consumed allocation: out of the
+// balance row it reserved, handling unit included.
+func consumePosting(a sqlcgen.StockAllocation, tr sqlcgen.GetStockAllocationTrackingRow, req ConsumeRequest, actorID pgtype.UUID) posting {
+	return posting{
+		MoveType: MoveTransfer, ItemID: a.ItemID, Qty: req.Qty, From: a.LocationID, To: pgUUID(req.ToLocationID),
+		ReasonCode: req.ReasonCode, RefType: req.RefType, RefID: req.RefID, LotCode: tr.LotCode.String, SerialNo: tr.SerialNo.String, ActorID: actorID,
+		Status: StatusAvailable, FromHU: a.HuID,
+	}
+}
+
+// ReleaseQty gives amount of an active allocation back to free stock inside
+// the caller's transaction.
+func (s StockService) ReleaseQty(ctx context.Context, q *sqlcgen.Queries, id uuid.UUID, amount *big.Rat, actor uuid.UUID) error {
+	a, err := lockActiveAllocation(ctx, q, pgUUID(id))
+	if err != nil {
+		return err
+	}
+	if amount.Sign() <= 0 || amount.Cmp(qty.FromNumeric(a.Qty)) > 0 {
+		return fmt.Errorf("%w: qty must be between 0 and %s", ErrInvalidMove, qty.String(qty.FromNumeric(a.Qty)))
+	}
+	return closeAllocation(ctx, q, a, amount, "released", pgUUID(actor))
+}
+
+// ReleaseByRef releases every active allocation of a demand reference inside
+// the caller's transaction and returns them as they were.
+func (s StockService) ReleaseByRef(ctx context.Context, q *sqlcgen.Queries, refType, refID string, actor uuid.UUID) ([]Allocation, error) {
+	rows, err := q.LockActiveStockAllocationsByRef(ctx, sqlcgen.LockActiveStockAllocationsByRefParams{RefType: refType, RefID: refID})
+	if err != nil {
+		return nil, err
+	}
+	out := make([]Allocation, 0, len(rows))
+	for _, a := range rows {
+		if err := closeAllocation(ctx, q, a, qty.FromNumeric(a.Qty), "released", pgUUID(actor)); err != nil {
+			return nil, err
+		}
+		out = append(out, toAllocation(a))
+	}
+	return out, nil
+}
+
+// allocate plans and books allocations inside the caller's transaction and
+// returns the quantity it could not cover. Without AllowPartial a shortfall
+// is an InsufficientStockError and nothing is booked.
+func (s StockService) allocate(ctx context.Context, q *sqlcgen.Queries, spec AllocateSpec, actorID pgtype.UUID) ([]Allocation, *big.Rat, error) {
+	strategy := spec.Strategy
+	if strategy == "" {
+		strategy = StrategyFEFO
+	}
+	switch strategy {
+	case StrategyFEFO, StrategyFIFO, StrategyFewestLocations:
+	default:
+		return nil, nil, fmt.Errorf("%w: unknown strategy %q", ErrInvalidMove, strategy)
+	}
+	rows, err := q.ListAllocationCandidates(ctx, sqlcgen.ListAllocationCandidatesParams{ItemID: spec.ItemID, WarehouseID: spec.WarehouseID, ExcludeLocationID: spec.ExcludeLocationID})
+	if err != nil {
+		return nil, nil, err
+	}
+	cands := make([]candidate, 0, len(rows))
+	for _, r := range rows {
+		cands = append(cands, candidate{
+			LocationID: r.LocationID, LotID: r.LotID, SerialID: r.SerialID, HuID: r.HuID, LocationCode: r.LocationCode,
+			ExpiresOn: r.ExpiresOn, ReceivedAt: r.ReceivedAt.Time,
+			Free: qty.Sub(qty.FromNumeric(r.QtyOnHand), qty.FromNumeric(r.QtyAllocated)),
+		})
+	}
+	picks, short := planAllocation(cands, spec.Qty, strategy)
+	if short.Sign() > 0 && !spec.AllowPartial {
+		return nil, nil, &InsufficientStockError{ItemID: spec.ItemID.String(), Available: qty.String(qty.Sub(spec.Qty, short)), Requested: qty.String(spec.Qty)}
+	}
+
+	out := make([]Allocation, 0, len(picks))
+	for _, p := range picks {
+		a, err := bookPick(ctx, q, spec.ItemID, p, spec.RefType, spec.RefID, strategy, actorID)
+		if err != nil {
+			return nil, nil, err
+		}
+		out = append(out, toAllocation(a))
+	}
+	return out, short, nil
+}
+
+// bookPick reserves one planned pick for a demand reference.
+func bookPick(ctx context.Context, q *sqlcgen.Queries, itemID pgtype.UUID, p pick, refType, refID, strategy string, actorID pgtype.UUID) (sqlcgen.StockAllocation, error) {
+	requestID, _ := ctx.Value("request_id").(string)
+	n := qty.ToNumeric(p.Qty)
+	a, err := q.InsertStockAllocation(ctx, sqlcgen.InsertStockAllocationParams{
+		ItemID: itemID, LocationID: p.From.LocationID, LotID: p.From.LotID, SerialID: p.From.SerialID,
+		Qty: n, RefType: refType, RefID: refID, Strategy: strategy, CreatedBy: actorID, HuID: p.From.HuID,
+	})
+	if err != nil {
+		return a, err
+	}
+	if err := q.UpsertStockBalanceDelta(ctx, sqlcgen.UpsertStockBalanceDeltaParams{ItemID: itemID, LocationID: p.From.LocationID, LotID: p.From.LotID, SerialID: p.From.SerialID, Status: StatusAvailable, HuID: p.From.HuID, QtyOnHand: mustNumeric("0"), QtyAllocated: n}); err != nil {
+		return a, err
+	}
+	return a, q.InsertStockAllocationLog(ctx, sqlcgen.InsertStockAllocationLogParams{AllocationID: a.ID, Action: "allocated", Qty: n, RefType: refType, RefID: refID, ActorUserID: actorID, RequestID: txt(requestID)})
+}
+
+// closeAllocation gives amount back to free stock and marks the allocation
+// with status once nothing of it is left.
+func closeAllocation(ctx context.Context, q *sqlcgen.Queries, a sqlcgen.StockAllocation, amount *big.Rat, status string, actorID pgtype.UUID) error {
+	requestID, _ := ctx.Value("request_id").(string)
+	if err := q.UpsertStockBalanceDelta(ctx, releaseDelta(a, amount)); err != nil {
+		return err
+	}
+	rest := qty.Sub(qty.FromNumeric(a.Qty), amount)
+	if rest.Sign() > 0 {
+		if err := q.SetStockAllocationQty(ctx, sqlcgen.SetStockAllocationQtyParams{ID: a.ID, Qty: qty.ToNumeric(rest)}); err != nil {
+			return err
+		}
+	} else if err := q.SetStockAllocationStatus(ctx, sqlcgen.SetStockAllocationStatusParams{ID: a.ID, Status: status}); err != nil {
+		return err
+	}
+	return q.InsertStockAllocationLog(ctx, sqlcgen.InsertStockAllocationLogParams{AllocationID: a.ID, Action: status, Qty: qty.ToNumeric(amount), RefType: a.RefType, RefID: a.RefID, ActorUserID: actorID, RequestID: txt(requestID)})
+}
+
+// releaseDelta lowers qty_allocated of the balance row a reserved by amount.
+func releaseDelta(a sqlcgen.StockAllocation, amount *big.Rat) sqlcgen.UpsertStockBalanceDeltaParams {
+	return sqlcgen.UpsertStockBalanceDeltaParams{ItemID: a.ItemID, LocationID: a.LocationID, LotID: a.LotID, SerialID: a.SerialID, Status: StatusAvailable, HuID: a.HuID, QtyOnHand: mustNumeric("0"), QtyAllocated: qty.ToNumeric(qty.Neg(amount))}
+}
+
+func lockActiveAllocation(ctx context.Context, q *sqlcgen.Queries, id pgtype.UUID) (sqlcgen.StockAllocation, error) {
+	a, err := q.LockStockAllocation(ctx, id)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return a, ErrNotFound
+	}
+	if err != nil {
+		return a, err
+	}
+	if a.Status != "active" {
+		return a, fmt.Errorf("%w: allocation is %s", ErrInvalidMove, a.Status)
+	}
+	return a, nil
+}
+
+// planAllocation orders candidates by strategy and takes from them until want
+// is covered. It returns the picks and whatever could not be covered.
+func planAllocation(cands []candidate, want *big.Rat, strategy string) ([]pick, *big.Rat) {
+	sorted := append([]candidate(nil), cands...)
+	switch strategy {
+	case StrategyFIFO:
+		sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].ReceivedAt.Before(sorted[j].ReceivedAt) })
+	case StrategyFewestLocations:
+		sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].Free.Cmp(sorted[j].Free) > 0 })
+		// One bin covering everything wins; the smallest such bin keeps the
+		// bigger ones whole for later orders.
+		for i := len(sorted) - 1; i >= 0; i-- {
+			if sorted[i].Free.Cmp(want) >= 0 {
+				return []pick{{From: sorted[i], Qty: new(big.Rat).Set(want)}}, qty.Zero()
+			}
+		}
+	default:
+		sort.SliceStable(sorted, func(i, j int) bool {
+			a, b := sorted[i].ExpiresOn, sorted[j].ExpiresOn
+			if a.Valid != b.Valid {
+				return a.Valid
+			}
+			if a.Valid && !a.Time.Equal(b.Time) {
+				return a.Time.Before(b.Time)
+			}
+			return sorted[i].ReceivedAt.Before(sorted[j].ReceivedAt)
+		})
+	}
+	remaining := new(big.Rat).Set(want)
+	var picks []pick
+	for _, c := range sorted {
+		if remaining.Sign() <= 0 {
+			break
+		}
+		take := new(big.Rat).Set(qty.Min(c.Free, remaining))
+		picks = append(picks, pick{From: c, Qty: take})
+		remaining = qty.Sub(remaining, take)
+	}
+	return picks, remaining
+}
+
+// record writes the outbox event and audit entry for a stock change inside
+// the caller's transaction. Changes without an actor were made by a
+// background job and are audited as the system's.
+func (s StockService) record(ctx context.Context, q *sqlcgen.Queries, actorID pgtype.UUID, topic, resource, resourceID string, body any) error {
+	requestID, _ := ctx.Value("request_id").(string)
+	payload, _ := json.Marshal(body)
+	if _, err := q.InsertOutboxEvent(ctx, sqlcgen.InsertOutboxEventParams{Topic: topic, Payload: payload}); err != nil {
+		return err
+	}
+	actorType := "user"
+	if !actorID.Valid {
+		actorType = "system"
+	}
+	_ = q.InsertAuditLog(ctx, sqlcgen.InsertAuditLogParams{ActorUserID: actorID, ActorType: actorType, Action: topic, Resource: resource, ResourceID: txt(resourceID), Status: "ok", RequestID: txt(requestID), Metadata: payload})
+	return nil
+}
+
+func toAllocation(a sqlcgen.StockAllocation) Allocation {
+	out := Allocation{
+		ID: a.ID.String(), ItemID: a.ItemID.String(), LocationID: a.LocationID.String(),
+		Qty: qty.String(qty.FromNumeric(a.Qty)), RefType: a.RefType, RefID: a.RefID, Strategy: a.Strategy, Status: a.Status,
+	}
+	if a.LotID.Valid {
+		out.LotID = a.LotID.String()
+	}
+	if a.SerialID.Valid {
+		out.SerialID = a.SerialID.String()
+	}
+	if a.HuID.Valid {
+		out.HuID = a.HuID.String()
+	}
+	return out
+}
+
+func pgUUID(id uuid.UUID) pgtype.UUID { return pgtype.UUID{Bytes: id, Valid: id != uuid.Nil} }
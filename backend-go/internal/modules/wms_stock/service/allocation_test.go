@@ -0,0 +1,103 @@
+package service
+
+import (
+	"math/big"
+	"testing"
+	"time"
+
+	"erpwms/backend-go/internal/common/qty"
+	"erpwms/backend-go/internal/db/sqlcgen"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+func cand(code string, free int64, expires string, received string) candidate {
+	c := candidate{LocationCode: code, Free: big.NewRat(free, 1)}
+	if expires != "" {
+		t, _ := time.Parse("2006-01-02", expires)
+		c.ExpiresOn = pgtype.Date{Time: t, Valid: true}
+	}
+	c.ReceivedAt, _ = time.Parse("2006-01-02", received)
+	return c
+}
+
+func codes(picks []pick) string {
+	s := ""
+	for _, p := range picks {
+		s += p.From.LocationCode + ":" + p.Qty.RatString() + " "
+	}
+	return s
+}
+
+func TestPlanAllocationFEFO(t *testing.T) {
+	cands := []candidate{
+		cand("A", 5, "", "2024-01-01"),
+		cand("B", 5, "2025-06-01", "2024-03-01"),
+		cand("C", 5, "2025-01-01", "2024-04-01"),
+	}
+	picks, short := planAllocation(cands, big.NewRat(8, 1), StrategyFEFO)
+	if got := codes(picks); got != "C:5 B:3 " || short.Sign() != 0 {
+		t.Fatalf("got %q short %s", got, short.RatString())
+	}
+}
+
+func TestPlanAllocationFIFO(t *testing.T) {
+	cands := []candidate{
+		cand("A", 5, "2025-01-01", "2024-03-01"),
+		cand("B", 5, "2026-01-01", "2024-01-01"),
+	}
+	picks, _ := planAllocation(cands, big.NewRat(6, 1), StrategyFIFO)
+	if got := codes(picks); got != "B:5 A:1 " {
+		t.Fatalf("got %q", got)
+	}
+}
+
+func TestPlanAllocationFewestLocations(t *testing.T) {
+	cands := []candidate{
+		cand("A", 3, "", "2024-01-01"),
+		cand("B", 20, "", "2024-01-01"),
+		cand("C", 8, "", "2024-01-01"),
+	}
+	picks, _ := planAllocation(cands, big.NewRat(7, 1), StrategyFewestLocations)
+	if got := codes(picks); got != "C:7 " {
+		t.Fatalf("single bin: got %q", got)
+	}
+	picks, _ = planAllocation(cands, big.NewRat(25, 1), StrategyFewestLocations)
+	if got := codes(picks); got != "B:20 C:5 " {
+		t.Fatalf("largest first: got %q", got)
+	}
+}
+
+func TestPlanAllocationShort(t *testing.T) {
+	picks, short := planAllocation([]candidate{cand("A", 2, "", "2024-01-01")}, big.NewRat(5, 1), StrategyFEFO)
+	if len(picks) != 1 || short.Cmp(big.NewRat(3, 1)) != 0 {
+		t.Fatalf("got %d picks short %s", len(picks), short.RatString())
+	}
+}
+
+// A partial transfer of an allocation out of a handling unit must keep
+// reserving, consuming and releasing the unit's balance row, not the loose
+// stock at the same location.
+func TestSplitHandlingUnitAllocation(t *testing.T) {
+	hu := pgUUID(uuid.New())
+	a := sqlcgen.StockAllocation{
+		ID: pgUUID(uuid.New()), ItemID: pgUUID(uuid.New()), LocationID: pgUUID(uuid.New()), HuID: hu,
+		Qty: qty.ToNumeric(big.NewRat(10, 1)), RefType: "sales_order", RefID: "SO-1", Strategy: StrategyFEFO, Status: "active",
+	}
+	p := splitAllocation(a, big.NewRat(4, 1), "pick_task", "T-1", pgtype.UUID{})
+	if p.HuID != hu || p.LocationID != a.LocationID || qty.String(qty.FromNumeric(p.Qty)) != "4" {
+		t.Fatalf("split %+v", p)
+	}
+	split := sqlcgen.StockAllocation{ID: pgUUID(uuid.New()), ItemID: p.ItemID, LocationID: p.LocationID, LotID: p.LotID, SerialID: p.SerialID, HuID: p.HuID, Qty: p.Qty, RefType: p.RefType, RefID: p.RefID}
+	if got := toAllocation(split); got.HuID != hu.String() {
+		t.Fatalf("allocation json %+v", got)
+	}
+	post := consumePosting(split, sqlcgen.GetStockAllocationTrackingRow{}, ConsumeRequest{Qty: big.NewRat(4, 1), ToLocationID: uuid.New()}, pgtype.UUID{})
+	if post.FromHU != hu || post.From != a.LocationID {
+		t.Fatalf("consume takes from %v in %v", post.From, post.FromHU)
+	}
+	d := releaseDelta(split, big.NewRat(4, 1))
+	if d.HuID != hu || qty.String(qty.FromNumeric(d.QtyAllocated)) != "-4" {
+		t.Fatalf("release %+v", d)
+	}
+}
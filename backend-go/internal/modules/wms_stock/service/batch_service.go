@@ -0,0 +1,155 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"erpwms/backend-go/internal/common/qty"
+	"erpwms/backend-go/internal/db/sqlcgen"
+	"github.com/google/uuid"
+)
+
+// maxBatchLines bounds a batch so one request cannot hold balance locks for
+// an unbounded time.
+const maxBatchLines = 500
+
+// BatchMoveRequest is a document header plus the transfer lines to book
+// under it. A line's ReasonCode overrides the header's.
+type BatchMoveRequest struct {
+	RefType    string        `json:"ref_type"`
+	RefID      string        `json:"ref_id"`
+	ReasonCode string        `json:"reason_code"`
+	Lines      []MoveRequest `json:"lines"`
+}
+
+type BatchMoveResponse struct {
+	RefType string   `json:"ref_type"`
+	RefID   string   `json:"ref_id"`
+	MoveIDs []string `json:"move_ids"`
+	Status  string   `json:"status"`
+}
+
+// LineError explains why one line of a batch was rejected. Line is 1-based.
+type LineError struct {
+	Line   int    `json:"line"`
+	Error  string `json:"error"`
+	Detail any    `json:"detail,omitempty"`
+}
+
+// BatchRejectedError is returned when any line fails validation; nothing of
+// the batch is booked.
+type BatchRejectedError struct {
+	Lines []LineError `json:"lines"`
+}
+
+func (e *BatchRejectedError) Error() string {
+	return fmt.Sprintf("batch rejected: %d line(s) invalid", len(e.Lines))
+}
+
+// MoveBatch books all lines of req as transfers in one transaction. Lines are
+// applied in order, so a later line sees the stock moved by earlier ones.
+func (s StockService) MoveBatch(ctx context.Context, req BatchMoveRequest, actor uuid.UUID, endpoint, idemKey string) (BatchMoveResponse, error) {
+	reqHash := hashReq(req)
+	var prev BatchMoveResponse
+	if found, err := s.replay(ctx, endpoint, idemKey, reqHash, &prev); err != nil || found {
+		return prev, err
+	}
+	if req.RefType == "" || req.RefID == "" {
+		return BatchMoveResponse{}, fmt.Errorf("%w: ref_type and ref_id are required", ErrInvalidMove)
+	}
+	if err := checkRefType(req.RefType); err != nil {
+		return BatchMoveResponse{}, err
+	}
+	if len(req.Lines) == 0 || len(req.Lines) > maxBatchLines {
+		return BatchMoveResponse{}, fmt.Errorf("%w: a batch carries 1 to %d lines", ErrInvalidMove, maxBatchLines)
+	}
+	actorID, _ := scanUUID(actor.String())
+
+	var rejected []LineError
+	postings := make([]posting, len(req.Lines))
+	for i, line := range req.Lines {
+		if line.ReasonCode == "" {
+			line.ReasonCode = req.ReasonCode
+		}
+		if err := checkSides(MoveTransfer, line); err != nil {
+			rejected = append(rejected, lineError(i, err))
+			continue
+		}
+		p, err := newPosting(MoveTransfer, line, actorID)
+		if err != nil {
+			rejected = append(rejected, lineError(i, err))
+			continue
+		}
+		if line.RefType != "" || line.RefID != "" {
+			rejected = append(rejected, LineError{Line: i + 1, Error: "ref_type and ref_id belong to the batch header"})
+			continue
+		}
+		p.RefType, p.RefID = req.RefType, req.RefID
+		postings[i] = p
+	}
+	if len(rejected) > 0 {
+		return BatchMoveResponse{}, &BatchRejectedError{Lines: rejected}
+	}
+
+	tx, err := s.DB.Begin(ctx)
+	if err != nil {
+		return BatchMoveResponse{}, err
+	}
+	defer tx.Rollback(ctx)
+	q := s.Queries.WithTx(tx)
+
+	resp := BatchMoveResponse{RefType: req.RefType, RefID: req.RefID, Status: "ok"}
+	lines := make([]map[string]any, 0, len(postings))
+	for i, p := range postings {
+		// Validation failures happen before anything is written for the line,
+		// so the transaction stays usable and every bad line gets reported.
+		var baseUom string
+		if p.Qty, baseUom, err = toBaseQty(ctx, q, p.ItemID, p.Qty, req.Lines[i].Uom); err != nil {
+			rejected = append(rejected, lineError(i, err))
+			continue
+		}
+		move, err := s.post(ctx, q, p)
+		var short *InsufficientStockError
+		if errors.Is(err, ErrInvalidMove) || errors.Is(err, ErrForbidden) || errors.As(err, &short) {
+			rejected = append(rejected, lineError(i, err))
+			continue
+		}
+		if err != nil {
+			return BatchMoveResponse{}, err
+		}
+		resp.MoveIDs = append(resp.MoveIDs, move.MoveID.String())
+		lines = append(lines, map[string]any{
+			"move_id": move.MoveID.String(), "item_id": req.Lines[i].ItemID, "qty": qty.String(p.Qty), "uom": baseUom,
+			"from_location_id": req.Lines[i].FromLocationID, "to_location_id": req.Lines[i].ToLocationID,
+			"lot_code": p.LotCode, "serial_no": p.SerialNo, "reason_code": p.ReasonCode, "comment": p.Comment,
+		})
+	}
+	if len(rejected) > 0 {
+		return BatchMoveResponse{}, &BatchRejectedError{Lines: rejected}
+	}
+
+	payload, _ := json.Marshal(map[string]any{"ref_type": req.RefType, "ref_id": req.RefID, "lines": lines})
+	if _, err := q.InsertOutboxEvent(ctx, sqlcgen.InsertOutboxEventParams{Topic: "stock.batch_moved", Payload: payload}); err != nil {
+		return BatchMoveResponse{}, err
+	}
+	requestID, _ := ctx.Value("request_id").(string)
+	_ = q.InsertAuditLog(ctx, sqlcgen.InsertAuditLogParams{ActorUserID: actorID, ActorType: "user", Action: "stock.move_batch", Resource: "stock_ledger", ResourceID: txt(req.RefType + ":" + req.RefID), Status: "ok", RequestID: txt(requestID), Metadata: payload})
+
+	if err := remember(ctx, q, endpoint, idemKey, actorID, reqHash, resp); err != nil {
+		return BatchMoveResponse{}, err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return BatchMoveResponse{}, err
+	}
+	return resp, nil
+}
+
+func lineError(i int, err error) LineError {
+	var short *InsufficientStockError
+	if errors.As(err, &short) {
+		return LineError{Line: i + 1, Error: "insufficient_stock", Detail: short}
+	}
+	return LineError{Line: i + 1, Error: err.Error()}
+}
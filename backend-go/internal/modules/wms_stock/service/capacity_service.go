@@ -0,0 +1,195 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"slices"
+	"strings"
+
+	"erpwms/backend-go/internal/common/qty"
+	"erpwms/backend-go/internal/db/sqlcgen"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// PermCapacityOverride lets a supervisor book into a location past its
+// limits by setting override_capacity.
+const PermCapacityOverride = "wms.stock.capacity_override"
+
+// ErrCapacity is returned when a move would overfill its destination. It
+// wraps ErrInvalidMove, so handlers answer 400 with the reason.
+var ErrCapacity = fmt.Errorf("%w: capacity exceeded", ErrInvalidMove)
+
+// checkCapacity rejects a posting that would take its destination past
+// max_weight_kg, max_volume_m3 or max_skus, or put a second lot of an item
+// into a location that does not mix lots. Adjustments, reversals and
+// bookings that stay in the same location record what is already there and
+// are not checked. Items without a weight or dimensions count as zero.
+func checkCapacity(ctx context.Context, q *sqlcgen.Queries, p posting, key stockKey) error {
+	if !p.To.Valid || p.To == p.From || p.MoveType == MoveAdjustment || p.RefType == RefReversal {
+		return nil
+	}
+	lim, err := q.GetLocationLimits(ctx, p.To)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return fmt.Errorf("%w: unknown to_location_id", ErrInvalidMove)
+	}
+	if err != nil {
+		return err
+	}
+	if !lim.MaxWeightKg.Valid && !lim.MaxVolumeM3.Valid && !lim.MaxSkus.Valid && lim.MixedLots {
+		return nil
+	}
+	// Concurrent bookings into the same limited bin serialize on its row, so
+	// two of them cannot both fit into the last free space.
+	if _, err := q.LockLocationLimits(ctx, p.To); err != nil {
+		return err
+	}
+	use, err := q.GetLocationUsage(ctx, sqlcgen.GetLocationUsageParams{ItemID: key.ItemID, LotID: key.LotID, LocationID: p.To})
+	if err != nil {
+		return err
+	}
+	m, err := q.GetItemMeasures(ctx, key.ItemID)
+	if err != nil {
+		return err
+	}
+	if reason := capacityViolation(lim, use, m, p.Qty); reason != "" {
+		return overrideCapacity(ctx, q, p.ActorID, p.OverrideCapacity, reason)
+	}
+	return nil
+}
+
+// capacityViolation says why adding n of the item to a location holding use
+// breaks lim, or returns "" when it fits.
+func capacityViolation(lim sqlcgen.GetLocationLimitsRow, use sqlcgen.GetLocationUsageRow, m sqlcgen.GetItemMeasuresRow, n *big.Rat) string {
+	var reasons []string
+	if lim.MaxWeightKg.Valid && m.WeightKg.Valid {
+		after := new(big.Rat).Add(qty.FromNumeric(use.WeightKg), new(big.Rat).Mul(n, qty.FromNumeric(m.WeightKg)))
+		if limit := qty.FromNumeric(lim.MaxWeightKg); after.Cmp(limit) > 0 {
+			reasons = append(reasons, fmt.Sprintf("weight would reach %s kg of %s kg", after.FloatString(3), qty.String(limit)))
+		}
+	}
+	if lim.MaxVolumeM3.Valid && m.VolumeM3.Valid {
+		after := new(big.Rat).Add(qty.FromNumeric(use.VolumeM3), new(big.Rat).Mul(n, qty.FromNumeric(m.VolumeM3)))
+		if limit := qty.FromNumeric(lim.MaxVolumeM3); after.Cmp(limit) > 0 {
+			reasons = append(reasons, fmt.Sprintf("volume would reach %s m3 of %s m3", after.FloatString(3), qty.String(limit)))
+		}
+	}
+	if lim.MaxSkus.Valid && !use.HoldsItem && use.Skus >= lim.MaxSkus.Int32 {
+		reasons = append(reasons, fmt.Sprintf("already holds %d of at most %d SKUs", use.Skus, lim.MaxSkus.Int32))
+	}
+	if !lim.MixedLots && use.HoldsOtherLot {
+		reasons = append(reasons, "holds another lot of the item and does not mix lots")
+	}
+	if len(reasons) == 0 {
+		return ""
+	}
+	return fmt.Sprintf("location %s %s", lim.Code, strings.Join(reasons, "; "))
+}
+
+// overrideCapacity lets the booking through when the caller asked to override
+// and holds PermCapacityOverride.
+func overrideCapacity(ctx context.Context, q *sqlcgen.Queries, actorID pgtype.UUID, override bool, reason string) error {
+	if !override {
+		return fmt.Errorf("%w: %s", ErrCapacity, reason)
+	}
+	perms, err := q.ListPermissionsByUserID(ctx, actorID)
+	if err != nil {
+		return err
+	}
+	if !slices.Contains(perms, PermCapacityOverride) {
+		return fmt.Errorf("%w: %s; overriding requires %s", ErrForbidden, reason, PermCapacityOverride)
+	}
+	return nil
+}
+
+// CheckHandlingUnitRoom verifies inside the caller's transaction that one
+// more top-level handling unit fits into the location's max_hu. The weight
+// and volume of the unit's content are checked by the moves that carry it.
+func (s StockService) CheckHandlingUnitRoom(ctx context.Context, q *sqlcgen.Queries, locationID pgtype.UUID, actor uuid.UUID, override bool) error {
+	lim, err := q.GetLocationLimits(ctx, locationID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return fmt.Errorf("%w: unknown location", ErrInvalidMove)
+	}
+	if err != nil {
+		return err
+	}
+	if !lim.MaxHu.Valid {
+		return nil
+	}
+	if _, err := q.LockLocationLimits(ctx, locationID); err != nil {
+		return err
+	}
+	use, err := q.GetLocationUsage(ctx, sqlcgen.GetLocationUsageParams{LocationID: locationID})
+	if err != nil {
+		return err
+	}
+	if use.HandlingUnits >= lim.MaxHu.Int32 {
+		reason := fmt.Sprintf("location %s already holds %d of at most %d handling units", lim.Code, use.HandlingUnits, lim.MaxHu.Int32)
+		return overrideCapacity(ctx, q, pgUUID(actor), override, reason)
+	}
+	return nil
+}
+
+// ZoneUtilization is the fill level of one zone. The percentages compare the
+// load of the locations that have a limit with the sum of those limits, and
+// are empty when no location in the zone has one.
+type ZoneUtilization struct {
+	Zone              string `json:"zone"`
+	Locations         int32  `json:"locations"`
+	OccupiedLocations int32  `json:"occupied_locations"`
+	WeightKg          string `json:"weight_kg"`
+	MaxWeightKg       string `json:"max_weight_kg,omitempty"`
+	WeightPct         string `json:"weight_pct,omitempty"`
+	VolumeM3          string `json:"volume_m3"`
+	MaxVolumeM3       string `json:"max_volume_m3,omitempty"`
+	VolumePct         string `json:"volume_pct,omitempty"`
+	HandlingUnits     int32  `json:"handling_units"`
+	MaxHU             *int32 `json:"max_hu,omitempty"`
+	HandlingUnitPct   string `json:"hu_pct,omitempty"`
+}
+
+// ZoneUtilization reports the active storage locations of a warehouse per
+// zone, or only zone when it is set. Locations without a zone are grouped
+// under "".
+func (s StockService) ZoneUtilization(ctx context.Context, warehouseID uuid.UUID, zone string) ([]ZoneUtilization, error) {
+	if warehouseID == uuid.Nil {
+		return nil, fmt.Errorf("%w: warehouse_id is required", ErrInvalidMove)
+	}
+	rows, err := s.Queries.ListZoneUtilization(ctx, sqlcgen.ListZoneUtilizationParams{WarehouseID: pgUUID(warehouseID), Zone: strings.TrimSpace(zone)})
+	if err != nil {
+		return nil, err
+	}
+	out := make([]ZoneUtilization, 0, len(rows))
+	for _, r := range rows {
+		z := ZoneUtilization{
+			Zone: r.Zone, Locations: r.Locations, OccupiedLocations: r.OccupiedLocations,
+			WeightKg: qty.String(qty.FromNumeric(r.WeightKg)), VolumeM3: qty.String(qty.FromNumeric(r.VolumeM3)), HandlingUnits: r.HandlingUnits,
+		}
+		if r.MaxWeightKg.Valid {
+			z.MaxWeightKg = qty.String(qty.FromNumeric(r.MaxWeightKg))
+			z.WeightPct = percent(qty.FromNumeric(r.LimitedWeightKg), qty.FromNumeric(r.MaxWeightKg))
+		}
+		if r.MaxVolumeM3.Valid {
+			z.MaxVolumeM3 = qty.String(qty.FromNumeric(r.MaxVolumeM3))
+			z.VolumePct = percent(qty.FromNumeric(r.LimitedVolumeM3), qty.FromNumeric(r.MaxVolumeM3))
+		}
+		if r.MaxHu.Valid {
+			maxHU := r.MaxHu.Int32
+			z.MaxHU = &maxHU
+			z.HandlingUnitPct = percent(big.NewRat(int64(r.LimitedHandlingUnits), 1), big.NewRat(int64(r.MaxHu.Int32), 1))
+		}
+		out = append(out, z)
+	}
+	return out, nil
+}
+
+// percent formats used/limit with one decimal; a zero limit yields "".
+func percent(used, limit *big.Rat) string {
+	if limit.Sign() <= 0 {
+		return ""
+	}
+	return new(big.Rat).Mul(new(big.Rat).Quo(used, limit), big.NewRat(100, 1)).FloatString(1)
+}
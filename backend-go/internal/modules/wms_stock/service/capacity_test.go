@@ -0,0 +1,61 @@
+package service
+
+import (
+	"math/big"
+	"strings"
+	"testing"
+
+	"erpwms/backend-go/internal/db/sqlcgen"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+func TestCapacityViolation(t *testing.T) {
+	box := sqlcgen.GetItemMeasuresRow{WeightKg: mustNumeric("12.5"), VolumeM3: mustNumeric("0.05")}
+	bin := sqlcgen.GetLocationLimitsRow{Code: "A-01-01", MaxWeightKg: mustNumeric("100"), MaxVolumeM3: mustNumeric("0.5"), MaxSkus: pgtype.Int4{Int32: 2, Valid: true}, MixedLots: true}
+	held := sqlcgen.GetLocationUsageRow{WeightKg: mustNumeric("50"), VolumeM3: mustNumeric("0.2"), Skus: 1, HoldsItem: true}
+
+	if got := capacityViolation(bin, held, box, big.NewRat(4, 1)); got != "" {
+		t.Fatalf("4 boxes fill the bin exactly: %s", got)
+	}
+	if got := capacityViolation(bin, held, box, big.NewRat(5, 1)); !strings.Contains(got, "weight would reach 112.500 kg of 100 kg") || strings.Contains(got, "volume") {
+		t.Fatalf("got %q", got)
+	}
+	if got := capacityViolation(bin, held, box, big.NewRat(7, 1)); !strings.Contains(got, "weight") || !strings.Contains(got, "volume would reach 0.550 m3") {
+		t.Fatalf("got %q", got)
+	}
+
+	full := held
+	full.Skus, full.HoldsItem = 2, false
+	if got := capacityViolation(bin, full, box, big.NewRat(1, 1)); !strings.Contains(got, "2 of at most 2 SKUs") {
+		t.Fatalf("got %q", got)
+	}
+	full.HoldsItem = true
+	if got := capacityViolation(bin, full, box, big.NewRat(1, 1)); got != "" {
+		t.Fatalf("a SKU already in the bin does not count twice: %s", got)
+	}
+
+	single := bin
+	single.MixedLots = false
+	other := held
+	other.HoldsOtherLot = true
+	if got := capacityViolation(single, other, box, big.NewRat(1, 1)); !strings.HasPrefix(got, "location A-01-01 ") || !strings.Contains(got, "does not mix lots") {
+		t.Fatalf("got %q", got)
+	}
+	if got := capacityViolation(bin, other, box, big.NewRat(1, 1)); got != "" {
+		t.Fatalf("mixed lots allowed: %s", got)
+	}
+
+	// An item without a weight or dimensions is not held to the limits.
+	if got := capacityViolation(bin, held, sqlcgen.GetItemMeasuresRow{}, big.NewRat(1000, 1)); got != "" {
+		t.Fatalf("got %q", got)
+	}
+}
+
+func TestPercent(t *testing.T) {
+	if got := percent(big.NewRat(1, 3), big.NewRat(1, 1)); got != "33.3" {
+		t.Fatalf("got %s", got)
+	}
+	if got := percent(big.NewRat(5, 1), new(big.Rat)); got != "" {
+		t.Fatalf("got %s", got)
+	}
+}
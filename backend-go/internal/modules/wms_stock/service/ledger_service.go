@@ -0,0 +1,168 @@
+package service
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"erpwms/backend-go/internal/common/qty"
+	"erpwms/backend-go/internal/db/sqlcgen"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// ErrInvalidFilter wraps malformed query parameters of read endpoints.
+var ErrInvalidFilter = errors.New("invalid filter")
+
+// LedgerFilter narrows GET /api/stock/ledger. Empty fields do not filter.
+// From is inclusive and To exclusive, both RFC 3339. Cursor is the NextCursor
+// of the previous page.
+type LedgerFilter struct {
+	ItemID      string
+	LocationID  string
+	WarehouseID string
+	ReasonCode  string
+	ActorID     string
+	From        string
+	To          string
+	RefType     string
+	RefID       string
+	Cursor      string
+	Limit       int32
+}
+
+// LedgerEntry is one stock_ledger row, newest first. RunningBalance is the
+// on-hand quantity at the filtered location right after the move and is only
+// set when both an item and a location are selected.
+type LedgerEntry struct {
+	MoveID           string    `json:"move_id"`
+	Ts               time.Time `json:"ts"`
+	MoveType         string    `json:"move_type"`
+	ItemID           string    `json:"item_id"`
+	Sku              string    `json:"sku"`
+	Qty              string    `json:"qty"`
+	FromLocationID   string    `json:"from_location_id,omitempty"`
+	FromLocationCode string    `json:"from_location_code,omitempty"`
+	ToLocationID     string    `json:"to_location_id,omitempty"`
+	ToLocationCode   string    `json:"to_location_code,omitempty"`
+	ReasonCode       string    `json:"reason_code"`
+	RefType          string    `json:"ref_type,omitempty"`
+	RefID            string    `json:"ref_id,omitempty"`
+	ActorUserID      string    `json:"actor_user_id,omitempty"`
+	RequestID        string    `json:"request_id,omitempty"`
+	LotCode          string    `json:"lot_code,omitempty"`
+	SerialNo         string    `json:"serial_no,omitempty"`
+	Status           string    `json:"status"`
+	ToStatus         string    `json:"to_status,omitempty"`
+	Comment          string    `json:"comment,omitempty"`
+	FromHuCode       string    `json:"from_hu_code,omitempty"`
+	ToHuCode         string    `json:"to_hu_code,omitempty"`
+	RunningBalance   string    `json:"running_balance,omitempty"`
+}
+
+type LedgerPage struct {
+	Items      []LedgerEntry `json:"items"`
+	NextCursor string        `json:"next_cursor,omitempty"`
+}
+
+func (s StockService) ListLedger(ctx context.Context, f LedgerFilter) (LedgerPage, error) {
+	if f.Limit <= 0 || f.Limit > 500 {
+		f.Limit = 100
+	}
+	p := sqlcgen.ListStockLedgerParams{ReasonCode: f.ReasonCode, RefType: f.RefType, RefID: f.RefID, PageLimit: f.Limit}
+	var err error
+	for _, u := range []struct {
+		v   string
+		dst *pgtype.UUID
+	}{{f.ItemID, &p.ItemID}, {f.LocationID, &p.LocationID}, {f.WarehouseID, &p.WarehouseID}, {f.ActorID, &p.ActorUserID}} {
+		if u.v == "" {
+			continue
+		}
+		if *u.dst, err = scanUUID(u.v); err != nil {
+			return LedgerPage{}, fmt.Errorf("%w: %v", ErrInvalidFilter, err)
+		}
+	}
+	if p.FromTs, err = parseTs(f.From); err != nil {
+		return LedgerPage{}, err
+	}
+	if p.ToTs, err = parseTs(f.To); err != nil {
+		return LedgerPage{}, err
+	}
+	if f.Cursor != "" {
+		if p.AfterTs, p.AfterID, err = decodeCursor(f.Cursor); err != nil {
+			return LedgerPage{}, err
+		}
+	}
+
+	rows, err := s.Queries.ListStockLedger(ctx, p)
+	if err != nil {
+		return LedgerPage{}, err
+	}
+	page := LedgerPage{Items: make([]LedgerEntry, 0, len(rows))}
+	for _, r := range rows {
+		e := LedgerEntry{
+			MoveID: r.MoveID.String(), Ts: r.Ts.Time, MoveType: r.MoveType, ItemID: r.ItemID.String(), Sku: r.Sku,
+			Qty: qty.String(qty.FromNumeric(r.Qty)), FromLocationCode: r.FromLocationCode.String, ToLocationCode: r.ToLocationCode.String,
+			ReasonCode: r.ReasonCode, RefType: r.RefType.String, RefID: r.RefID.String, RequestID: r.RequestID.String,
+			LotCode: r.LotCode.String, SerialNo: r.SerialNo.String, Status: r.Status, ToStatus: r.ToStatus.String, Comment: r.Comment.String,
+			FromHuCode: r.FromHuCode.String, ToHuCode: r.ToHuCode.String,
+		}
+		if r.FromLocationID.Valid {
+			e.FromLocationID = r.FromLocationID.String()
+		}
+		if r.ToLocationID.Valid {
+			e.ToLocationID = r.ToLocationID.String()
+		}
+		if r.ActorUserID.Valid {
+			e.ActorUserID = r.ActorUserID.String()
+		}
+		if r.RunningBalance.Valid {
+			e.RunningBalance = qty.String(qty.FromNumeric(r.RunningBalance))
+		}
+		page.Items = append(page.Items, e)
+	}
+	if len(rows) == int(f.Limit) {
+		last := rows[len(rows)-1]
+		page.NextCursor = encodeCursor(last.Ts.Time, last.MoveID)
+	}
+	return page, nil
+}
+
+// The cursor is the (ts, move_id) of the last row, opaque to clients.
+func encodeCursor(ts time.Time, id pgtype.UUID) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(ts.UTC().Format(time.RFC3339Nano) + "|" + id.String()))
+}
+
+func decodeCursor(c string) (pgtype.Timestamptz, pgtype.UUID, error) {
+	bad := fmt.Errorf("%w: invalid cursor", ErrInvalidFilter)
+	raw, err := base64.RawURLEncoding.DecodeString(c)
+	if err != nil {
+		return pgtype.Timestamptz{}, pgtype.UUID{}, bad
+	}
+	tsPart, idPart, ok := strings.Cut(string(raw), "|")
+	if !ok {
+		return pgtype.Timestamptz{}, pgtype.UUID{}, bad
+	}
+	ts, err := time.Parse(time.RFC3339Nano, tsPart)
+	if err != nil {
+		return pgtype.Timestamptz{}, pgtype.UUID{}, bad
+	}
+	id, err := scanUUID(idPart)
+	if err != nil {
+		return pgtype.Timestamptz{}, pgtype.UUID{}, bad
+	}
+	return pgtype.Timestamptz{Time: ts, Valid: true}, id, nil
+}
+
+func parseTs(v string) (pgtype.Timestamptz, error) {
+	if v == "" {
+		return pgtype.Timestamptz{}, nil
+	}
+	t, err := time.Parse(time.RFC3339, v)
+	if err != nil {
+		return pgtype.Timestamptz{}, fmt.Errorf("%w: timestamp %q must be RFC 3339", ErrInvalidFilter, v)
+	}
+	return pgtype.Timestamptz{Time: t, Valid: true}, nil
+}
@@ -0,0 +1,27 @@
+package service
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestLedgerCursorRoundTrip(t *testing.T) {
+	id, _ := scanUUID("6f1c2a4e-8d7b-4c3a-9e5f-0a1b2c3d4e5f")
+	ts := time.Date(2024, 1, 31, 23, 59, 59, 123456000, time.UTC)
+	gotTs, gotID, err := decodeCursor(encodeCursor(ts, id))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !gotTs.Time.Equal(ts) || gotID != id {
+		t.Fatalf("got %v %v", gotTs.Time, gotID)
+	}
+}
+
+func TestLedgerCursorRejectsGarbage(t *testing.T) {
+	for _, c := range []string{"!!", "bm90LWEtY3Vyc29y"} {
+		if _, _, err := decodeCursor(c); !errors.Is(err, ErrInvalidFilter) {
+			t.Fatalf("%q: expected ErrInvalidFilter, got %v", c, err)
+		}
+	}
+}
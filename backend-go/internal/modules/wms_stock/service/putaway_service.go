@@ -0,0 +1,597 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"sort"
+	"strings"
+
+	"erpwms/backend-go/internal/common/locpath"
+	"erpwms/backend-go/internal/common/qty"
+	"erpwms/backend-go/internal/db/sqlcgen"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// Putaway strategies, see putaway_rules.strategy.
+const (
+	PutawayConsolidate = "consolidate"
+	PutawayEmpty       = "empty"
+	PutawayAny         = "any"
+)
+
+// RefPutawayTask is the ledger ref_type of moves that confirm a putaway task.
+const RefPutawayTask = "putaway_task"
+
+// ErrNoPutawayLocation is returned when no rule yields a bin for the whole
+// quantity.
+var ErrNoPutawayLocation = errors.New("no putaway location")
+
+// PutawayRuleInput filters are optional; empty ones match anything.
+type PutawayRuleInput struct {
+	WarehouseID  string `json:"warehouse_id"`
+	Name         string `json:"name"`
+	Priority     int32  `json:"priority"`
+	ItemID       string `json:"item_id"`
+	ItemClass    string `json:"item_class"`
+	LocationType string `json:"location_type"`
+	PathPrefix   string `json:"path_prefix"`
+	Strategy     string `json:"strategy"`
+	MaxQty       string `json:"max_qty"`
+}
+
+type PutawayRule struct {
+	ID           string `json:"id"`
+	WarehouseID  string `json:"warehouse_id"`
+	Name         string `json:"name"`
+	Priority     int32  `json:"priority"`
+	ItemID       string `json:"item_id,omitempty"`
+	ItemClass    string `json:"item_class,omitempty"`
+	LocationType string `json:"location_type,omitempty"`
+	PathPrefix   string `json:"path_prefix,omitempty"`
+	Strategy     string `json:"strategy"`
+	MaxQty       string `json:"max_qty,omitempty"`
+	Active       bool   `json:"active"`
+}
+
+// SuggestRequest asks where to put Qty (in Uom, or the base unit) of an item
+// currently at FromLocationID, typically a dock.
+type SuggestRequest struct {
+	ItemID         string `json:"item_id"`
+	Qty            string `json:"qty"`
+	Uom            string `json:"uom,omitempty"`
+	FromLocationID string `json:"from_location_id"`
+}
+
+type SuggestedLocation struct {
+	LocationID   string `json:"location_id"`
+	LocationCode string `json:"location_code"`
+	Path         string `json:"path,omitempty"`
+	ItemQty      string `json:"item_qty"`
+}
+
+// Suggestion is the first bin of the first rule that has room for the whole
+// quantity; Alternatives are the next bins of that same rule.
+type Suggestion struct {
+	SuggestedLocation
+	RuleID       string              `json:"rule_id"`
+	RuleName     string              `json:"rule_name"`
+	Strategy     string              `json:"strategy"`
+	Qty          string              `json:"qty"`
+	Alternatives []SuggestedLocation `json:"alternatives"`
+}
+
+// PutawayTaskRequest creates a task to move stock from a dock into storage.
+// Without ToLocationID the target is suggested by the rules.
+type PutawayTaskRequest struct {
+	ItemID         string `json:"item_id"`
+	Qty            string `json:"qty"`
+	Uom            string `json:"uom,omitempty"`
+	FromLocationID string `json:"from_location_id"`
+	ToLocationID   string `json:"to_location_id,omitempty"`
+	LotCode        string `json:"lot_code,omitempty"`
+	SerialNo       string `json:"serial_no,omitempty"`
+	RefType        string `json:"ref_type,omitempty"`
+	RefID          string `json:"ref_id,omitempty"`
+}
+
+type PutawayTask struct {
+	ID             string `json:"id"`
+	WarehouseID    string `json:"warehouse_id"`
+	ItemID         string `json:"item_id"`
+	Qty            string `json:"qty"`
+	LotCode        string `json:"lot_code,omitempty"`
+	SerialNo       string `json:"serial_no,omitempty"`
+	FromLocationID string `json:"from_location_id"`
+	ToLocationID   string `json:"to_location_id"`
+	RuleID         string `json:"rule_id,omitempty"`
+	RefType        string `json:"ref_type,omitempty"`
+	RefID          string `json:"ref_id,omitempty"`
+	Status         string `json:"status"`
+	MoveID         string `json:"move_id,omitempty"`
+}
+
+type PutawayTaskFilter struct {
+	WarehouseID string
+	Status      string
+	Limit       int32
+	Offset      int32
+}
+
+// putawayCandidate is a storage bin with what it holds (or is about to hold
+// through open tasks) of the item and in total.
+type putawayCandidate struct {
+	LocationID   pgtype.UUID
+	LocationCode string
+	Path         string
+	ItemQty      *big.Rat
+	TotalQty     *big.Rat
+}
+
+func (s StockService) ListPutawayRules(ctx context.Context, warehouseID string, includeInactive bool) ([]PutawayRule, error) {
+	p := sqlcgen.ListPutawayRulesParams{IncludeInactive: includeInactive}
+	if warehouseID != "" {
+		id, err := scanUUID(warehouseID)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidFilter, err)
+		}
+		p.WarehouseID = id
+	}
+	rows, err := s.Queries.ListPutawayRules(ctx, p)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]PutawayRule, 0, len(rows))
+	for _, r := range rows {
+		out = append(out, toPutawayRule(r))
+	}
+	return out, nil
+}
+
+func (s StockService) CreatePutawayRule(ctx context.Context, in PutawayRuleInput, actor uuid.UUID) (PutawayRule, error) {
+	warehouseID, err := scanUUID(in.WarehouseID)
+	if err != nil {
+		return PutawayRule{}, fmt.Errorf("%w: warehouse_id: %v", ErrInvalidMove, err)
+	}
+	p, err := in.params()
+	if err != nil {
+		return PutawayRule{}, err
+	}
+	return s.saveRule(ctx, actor, "putaway_rule.created", func(q *sqlcgen.Queries) (sqlcgen.PutawayRule, error) {
+		return q.CreatePutawayRule(ctx, sqlcgen.CreatePutawayRuleParams{
+			WarehouseID: warehouseID, Name: p.Name, Priority: p.Priority, ItemID: p.ItemID, ItemClass: p.ItemClass,
+			LocationType: p.LocationType, PathPrefix: p.PathPrefix, Strategy: p.Strategy, MaxQty: p.MaxQty,
+		})
+	})
+}
+
+// UpdatePutawayRule replaces the rule's settings; its warehouse is fixed.
+func (s StockService) UpdatePutawayRule(ctx context.Context, id uuid.UUID, in PutawayRuleInput, actor uuid.UUID) (PutawayRule, error) {
+	p, err := in.params()
+	if err != nil {
+		return PutawayRule{}, err
+	}
+	p.ID = pgUUID(id)
+	return s.saveRule(ctx, actor, "putaway_rule.updated", func(q *sqlcgen.Queries) (sqlcgen.PutawayRule, error) {
+		return q.UpdatePutawayRule(ctx, p)
+	})
+}
+
+func (s StockService) DeactivatePutawayRule(ctx context.Context, id uuid.UUID, actor uuid.UUID) (PutawayRule, error) {
+	return s.saveRule(ctx, actor, "putaway_rule.deactivated", func(q *sqlcgen.Queries) (sqlcgen.PutawayRule, error) {
+		return q.SetPutawayRuleActive(ctx, sqlcgen.SetPutawayRuleActiveParams{ID: pgUUID(id), Active: false})
+	})
+}
+
+func (s StockService) saveRule(ctx context.Context, actor uuid.UUID, topic string, fn func(q *sqlcgen.Queries) (sqlcgen.PutawayRule, error)) (PutawayRule, error) {
+	tx, err := s.DB.Begin(ctx)
+	if err != nil {
+		return PutawayRule{}, err
+	}
+	defer tx.Rollback(ctx)
+	q := s.Queries.WithTx(tx)
+
+	r, err := fn(q)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return PutawayRule{}, ErrNotFound
+	}
+	if err != nil {
+		return PutawayRule{}, err
+	}
+	out := toPutawayRule(r)
+	if err := s.record(ctx, q, pgUUID(actor), topic, "putaway_rules", out.ID, out); err != nil {
+		return PutawayRule{}, err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return PutawayRule{}, err
+	}
+	return out, nil
+}
+
+// SuggestPutaway runs the putaway rules of the source location's warehouse.
+func (s StockService) SuggestPutaway(ctx context.Context, req SuggestRequest) (Suggestion, error) {
+	itemID, err := scanUUID(req.ItemID)
+	if err != nil {
+		return Suggestion{}, fmt.Errorf("%w: item_id: %v", ErrInvalidMove, err)
+	}
+	from, err := scanUUID(req.FromLocationID)
+	if err != nil {
+		return Suggestion{}, fmt.Errorf("%w: from_location_id: %v", ErrInvalidMove, err)
+	}
+	base, err := s.BaseQty(ctx, s.Queries, uuid.UUID(itemID.Bytes), req.Qty, req.Uom)
+	if err != nil {
+		return Suggestion{}, err
+	}
+	return s.suggest(ctx, s.Queries, itemID, from, base)
+}
+
+func (s StockService) suggest(ctx context.Context, q *sqlcgen.Queries, itemID, from pgtype.UUID, want *big.Rat) (Suggestion, error) {
+	if want.Sign() <= 0 {
+		return Suggestion{}, fmt.Errorf("%w: qty must be positive", ErrInvalidMove)
+	}
+	item, err := q.GetItem(ctx, itemID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return Suggestion{}, fmt.Errorf("%w: unknown item", ErrInvalidMove)
+	}
+	if err != nil {
+		return Suggestion{}, err
+	}
+	src, err := q.GetLocation(ctx, from)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return Suggestion{}, fmt.Errorf("%w: unknown from_location_id", ErrInvalidMove)
+	}
+	if err != nil {
+		return Suggestion{}, err
+	}
+	measures, err := q.GetItemMeasures(ctx, item.ID)
+	if err != nil {
+		return Suggestion{}, err
+	}
+	rules, err := q.ListMatchingPutawayRules(ctx, sqlcgen.ListMatchingPutawayRulesParams{WarehouseID: src.WarehouseID, ItemID: item.ID, ItemClass: item.ItemClass})
+	if err != nil {
+		return Suggestion{}, err
+	}
+	for _, r := range rules {
+		rows, err := q.ListPutawayCandidates(ctx, sqlcgen.ListPutawayCandidatesParams{
+			ItemID: item.ID, WarehouseID: src.WarehouseID, LocationType: r.LocationType.String, PathPrefix: r.PathPrefix.String, ExcludeID: src.ID,
+		})
+		if err != nil {
+			return Suggestion{}, err
+		}
+		cands := make([]putawayCandidate, 0, len(rows))
+		for _, c := range rows {
+			cands = append(cands, putawayCandidate{LocationID: c.LocationID, LocationCode: c.LocationCode, Path: c.Path.String, ItemQty: qty.FromNumeric(c.ItemQty), TotalQty: qty.FromNumeric(c.TotalQty)})
+		}
+		var maxQty *big.Rat
+		if r.MaxQty.Valid {
+			maxQty = qty.FromNumeric(r.MaxQty)
+		}
+		ranked, err := firstFitting(rankPutaway(cands, r.Strategy, maxQty, want), 6, func(c putawayCandidate) (bool, error) {
+			return putawayFits(ctx, q, c, item.ID, measures, want)
+		})
+		if err != nil {
+			return Suggestion{}, err
+		}
+		if len(ranked) == 0 {
+			continue
+		}
+		out := Suggestion{SuggestedLocation: toSuggested(ranked[0]), RuleID: r.ID.String(), RuleName: r.Name, Strategy: r.Strategy, Qty: qty.String(want), Alternatives: []SuggestedLocation{}}
+		for _, c := range ranked[1:] {
+			out.Alternatives = append(out.Alternatives, toSuggested(c))
+		}
+		return out, nil
+	}
+	return Suggestion{}, ErrNoPutawayLocation
+}
+
+// rankPutaway keeps the bins the strategy allows and that have room for want
+// below maxQty (nil means unbounded), best first: consolidation prefers the
+// bin already holding the most of the item, the others keep path order.
+func rankPutaway(cands []putawayCandidate, strategy string, maxQty, want *big.Rat) []putawayCandidate {
+	var out []putawayCandidate
+	for _, c := range cands {
+		switch strategy {
+		case PutawayConsolidate:
+			if c.ItemQty.Sign() <= 0 {
+				continue
+			}
+		case PutawayEmpty:
+			if c.TotalQty.Sign() != 0 {
+				continue
+			}
+		}
+		if maxQty != nil && qty.Add(c.ItemQty, want).Cmp(maxQty) > 0 {
+			continue
+		}
+		out = append(out, c)
+	}
+	if strategy == PutawayConsolidate {
+		sort.SliceStable(out, func(i, j int) bool { return out[i].ItemQty.Cmp(out[j].ItemQty) > 0 })
+	}
+	return out
+}
+
+// firstFitting returns up to n of the ranked bins that fits accepts, keeping
+// their order, so only as many bins as needed are looked up.
+func firstFitting(ranked []putawayCandidate, n int, fits func(putawayCandidate) (bool, error)) ([]putawayCandidate, error) {
+	var out []putawayCandidate
+	for _, c := range ranked {
+		if len(out) == n {
+			break
+		}
+		ok, err := fits(c)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			out = append(out, c)
+		}
+	}
+	return out, nil
+}
+
+// putawayFits says whether want of the item stays within the bin's weight,
+// volume and SKU limits. The lot is not known yet, so mixing lots is left to
+// the capacity check of the confirming move.
+func putawayFits(ctx context.Context, q *sqlcgen.Queries, c putawayCandidate, itemID pgtype.UUID, m sqlcgen.GetItemMeasuresRow, want *big.Rat) (bool, error) {
+	lim, err := q.GetLocationLimits(ctx, c.LocationID)
+	if err != nil {
+		return false, err
+	}
+	if !lim.MaxWeightKg.Valid && !lim.MaxVolumeM3.Valid && !lim.MaxSkus.Valid {
+		return true, nil
+	}
+	use, err := q.GetLocationUsage(ctx, sqlcgen.GetLocationUsageParams{ItemID: itemID, LocationID: c.LocationID})
+	if err != nil {
+		return false, err
+	}
+	lim.MixedLots = true
+	return capacityViolation(lim, use, m, want) == "", nil
+}
+
+func (s StockService) CreatePutawayTask(ctx context.Context, req PutawayTaskRequest, actor uuid.UUID) (PutawayTask, error) {
+	itemID, err := scanUUID(req.ItemID)
+	if err != nil {
+		return PutawayTask{}, fmt.Errorf("%w: item_id: %v", ErrInvalidMove, err)
+	}
+	from, err := scanUUID(req.FromLocationID)
+	if err != nil {
+		return PutawayTask{}, fmt.Errorf("%w: from_location_id: %v", ErrInvalidMove, err)
+	}
+	actorID := pgUUID(actor)
+	tx, err := s.DB.Begin(ctx)
+	if err != nil {
+		return PutawayTask{}, err
+	}
+	defer tx.Rollback(ctx)
+	q := s.Queries.WithTx(tx)
+
+	base, err := s.BaseQty(ctx, q, uuid.UUID(itemID.Bytes), req.Qty, req.Uom)
+	if err != nil {
+		return PutawayTask{}, err
+	}
+	src, err := q.GetLocation(ctx, from)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return PutawayTask{}, fmt.Errorf("%w: unknown from_location_id", ErrInvalidMove)
+	}
+	if err != nil {
+		return PutawayTask{}, err
+	}
+	var to, ruleID pgtype.UUID
+	if req.ToLocationID != "" {
+		if to, err = scanUUID(req.ToLocationID); err != nil {
+			return PutawayTask{}, fmt.Errorf("%w: to_location_id: %v", ErrInvalidMove, err)
+		}
+		dst, err := q.GetLocation(ctx, to)
+		if errors.Is(err, pgx.ErrNoRows) {
+			return PutawayTask{}, fmt.Errorf("%w: unknown to_location_id", ErrInvalidMove)
+		}
+		if err != nil {
+			return PutawayTask{}, err
+		}
+		if err := checkPutawayTarget(src, dst); err != nil {
+			return PutawayTask{}, err
+		}
+	} else {
+		sug, err := s.suggest(ctx, q, itemID, from, base)
+		if err != nil {
+			return PutawayTask{}, err
+		}
+		to, _ = scanUUID(sug.LocationID)
+		ruleID, _ = scanUUID(sug.RuleID)
+	}
+	t, err := q.CreatePutawayTask(ctx, sqlcgen.CreatePutawayTaskParams{
+		WarehouseID: src.WarehouseID, ItemID: itemID, Qty: qty.ToNumeric(base), LotCode: txt(req.LotCode), SerialNo: txt(req.SerialNo),
+		FromLocationID: src.ID, ToLocationID: to, RuleID: ruleID, RefType: txt(req.RefType), RefID: txt(req.RefID), CreatedBy: actorID,
+	})
+	if err != nil {
+		return PutawayTask{}, err
+	}
+	out := toPutawayTask(t)
+	if err := s.record(ctx, q, actorID, "putaway.task_created", "putaway_tasks", out.ID, out); err != nil {
+		return PutawayTask{}, err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return PutawayTask{}, err
+	}
+	return out, nil
+}
+
+// checkPutawayTarget rejects an explicit target that is not an active bin of
+// the source location's warehouse, or is the source itself.
+func checkPutawayTarget(src, dst sqlcgen.Location) error {
+	if !dst.Active || dst.WarehouseID != src.WarehouseID || dst.ID == src.ID {
+		return fmt.Errorf("%w: to_location_id must be another active bin of warehouse %s", ErrInvalidMove, src.WarehouseID.String())
+	}
+	return nil
+}
+
+func (s StockService) ListPutawayTasks(ctx context.Context, f PutawayTaskFilter) ([]PutawayTask, error) {
+	if f.Limit <= 0 || f.Limit > 500 {
+		f.Limit = 100
+	}
+	p := sqlcgen.ListPutawayTasksParams{Status: f.Status, PageLimit: f.Limit, PageOffset: f.Offset}
+	if f.WarehouseID != "" {
+		id, err := scanUUID(f.WarehouseID)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidFilter, err)
+		}
+		p.WarehouseID = id
+	}
+	rows, err := s.Queries.ListPutawayTasks(ctx, p)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]PutawayTask, 0, len(rows))
+	for _, r := range rows {
+		out = append(out, toPutawayTask(r))
+	}
+	return out, nil
+}
+
+// ConfirmPutawayTask books the transfer from the dock to the task's bin, or
+// to toLocationID when the operator used a different one.
+func (s StockService) ConfirmPutawayTask(ctx context.Context, id uuid.UUID, toLocationID string, actor uuid.UUID) (PutawayTask, error) {
+	actorID := pgUUID(actor)
+	tx, err := s.DB.Begin(ctx)
+	if err != nil {
+		return PutawayTask{}, err
+	}
+	defer tx.Rollback(ctx)
+	q := s.Queries.WithTx(tx)
+
+	t, err := lockOpenPutawayTask(ctx, q, pgUUID(id))
+	if err != nil {
+		return PutawayTask{}, err
+	}
+	to := t.ToLocationID
+	if toLocationID != "" {
+		if to, err = scanUUID(toLocationID); err != nil {
+			return PutawayTask{}, fmt.Errorf("%w: to_location_id: %v", ErrInvalidMove, err)
+		}
+	}
+	move, _, err := s.PostMove(ctx, q, MoveTransfer, MoveRequest{
+		ItemID: t.ItemID.String(), Qty: qty.String(qty.FromNumeric(t.Qty)), FromLocationID: t.FromLocationID.String(), ToLocationID: to.String(),
+		ReasonCode: "PUTAWAY", LotCode: t.LotCode.String, SerialNo: t.SerialNo.String, RefType: RefPutawayTask, RefID: t.ID.String(),
+	}, actor)
+	if err != nil {
+		return PutawayTask{}, err
+	}
+	if t, err = q.ConfirmPutawayTask(ctx, sqlcgen.ConfirmPutawayTaskParams{ID: t.ID, ToLocationID: to, MoveID: move.MoveID, ConfirmedBy: actorID}); err != nil {
+		return PutawayTask{}, err
+	}
+	out := toPutawayTask(t)
+	if err := s.record(ctx, q, actorID, "putaway.task_confirmed", "putaway_tasks", out.ID, out); err != nil {
+		return PutawayTask{}, err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return PutawayTask{}, err
+	}
+	return out, nil
+}
+
+func (s StockService) CancelPutawayTask(ctx context.Context, id uuid.UUID, actor uuid.UUID) (PutawayTask, error) {
+	actorID := pgUUID(actor)
+	tx, err := s.DB.Begin(ctx)
+	if err != nil {
+		return PutawayTask{}, err
+	}
+	defer tx.Rollback(ctx)
+	q := s.Queries.WithTx(tx)
+
+	t, err := lockOpenPutawayTask(ctx, q, pgUUID(id))
+	if err != nil {
+		return PutawayTask{}, err
+	}
+	if t, err = q.CancelPutawayTask(ctx, t.ID); err != nil {
+		return PutawayTask{}, err
+	}
+	out := toPutawayTask(t)
+	if err := s.record(ctx, q, actorID, "putaway.task_cancelled", "putaway_tasks", out.ID, out); err != nil {
+		return PutawayTask{}, err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return PutawayTask{}, err
+	}
+	return out, nil
+}
+
+func lockOpenPutawayTask(ctx context.Context, q *sqlcgen.Queries, id pgtype.UUID) (sqlcgen.PutawayTask, error) {
+	t, err := q.LockPutawayTask(ctx, id)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return t, ErrNotFound
+	}
+	if err != nil {
+		return t, err
+	}
+	if t.Status != "open" {
+		return t, fmt.Errorf("%w: task is %s", ErrInvalidMove, t.Status)
+	}
+	return t, nil
+}
+
+// params validates the input into UpdatePutawayRuleParams; callers set ID or
+// copy the fields into the create params.
+func (in PutawayRuleInput) params() (sqlcgen.UpdatePutawayRuleParams, error) {
+	p := sqlcgen.UpdatePutawayRuleParams{
+		Name: in.Name, Priority: in.Priority, ItemClass: txt(in.ItemClass), LocationType: txt(in.LocationType),
+		PathPrefix: txt(in.PathPrefix), Strategy: in.Strategy,
+	}
+	if strings.TrimSpace(in.Name) == "" {
+		return p, fmt.Errorf("%w: name is required", ErrInvalidMove)
+	}
+	switch in.Strategy {
+	case PutawayConsolidate, PutawayEmpty, PutawayAny:
+	default:
+		return p, fmt.Errorf("%w: strategy must be consolidate, empty or any", ErrInvalidMove)
+	}
+	prefix, err := locpath.Normalize(in.PathPrefix)
+	if err != nil {
+		return p, fmt.Errorf("%w: path_prefix: %v", ErrInvalidMove, err)
+	}
+	p.PathPrefix = txt(prefix)
+	if p.Priority == 0 {
+		p.Priority = 100
+	}
+	if in.ItemID != "" {
+		id, err := scanUUID(in.ItemID)
+		if err != nil {
+			return p, fmt.Errorf("%w: item_id: %v", ErrInvalidMove, err)
+		}
+		p.ItemID = id
+	}
+	if in.MaxQty != "" {
+		m, err := qty.Parse(in.MaxQty)
+		if err != nil || m.Sign() <= 0 {
+			return p, fmt.Errorf("%w: max_qty must be positive", ErrInvalidMove)
+		}
+		p.MaxQty = qty.ToNumeric(m)
+	}
+	return p, nil
+}
+
+func toPutawayRule(r sqlcgen.PutawayRule) PutawayRule {
+	out := PutawayRule{
+		ID: r.ID.String(), WarehouseID: r.WarehouseID.String(), Name: r.Name, Priority: r.Priority, ItemID: optUUID(r.ItemID),
+		ItemClass: r.ItemClass.String, LocationType: r.LocationType.String, PathPrefix: r.PathPrefix.String, Strategy: r.Strategy, Active: r.Active,
+	}
+	if r.MaxQty.Valid {
+		out.MaxQty = qty.String(qty.FromNumeric(r.MaxQty))
+	}
+	return out
+}
+
+func toPutawayTask(t sqlcgen.PutawayTask) PutawayTask {
+	return PutawayTask{
+		ID: t.ID.String(), WarehouseID: t.WarehouseID.String(), ItemID: t.ItemID.String(), Qty: qty.String(qty.FromNumeric(t.Qty)),
+		LotCode: t.LotCode.String, SerialNo: t.SerialNo.String, FromLocationID: t.FromLocationID.String(), ToLocationID: t.ToLocationID.String(),
+		RuleID: optUUID(t.RuleID), RefType: t.RefType.String, RefID: t.RefID.String, Status: t.Status, MoveID: optUUID(t.MoveID),
+	}
+}
+
+func toSuggested(c putawayCandidate) SuggestedLocation {
+	return SuggestedLocation{LocationID: c.LocationID.String(), LocationCode: c.LocationCode, Path: c.Path, ItemQty: qty.String(c.ItemQty)}
+}
@@ -0,0 +1,82 @@
+package service
+
+import (
+	"errors"
+	"math/big"
+	"strings"
+	"testing"
+
+	"erpwms/backend-go/internal/db/sqlcgen"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+func bin(code string, item, total int64) putawayCandidate {
+	return putawayCandidate{LocationCode: code, ItemQty: big.NewRat(item, 1), TotalQty: big.NewRat(total, 1)}
+}
+
+func binCodes(cs []putawayCandidate) string {
+	var out []string
+	for _, c := range cs {
+		out = append(out, c.LocationCode)
+	}
+	return strings.Join(out, " ")
+}
+
+var bins = []putawayCandidate{bin("A", 0, 0), bin("B", 4, 10), bin("C", 0, 7), bin("D", 9, 9)}
+
+func TestRankPutawayConsolidate(t *testing.T) {
+	if got := binCodes(rankPutaway(bins, PutawayConsolidate, nil, big.NewRat(5, 1))); got != "D B" {
+		t.Fatalf("got %q", got)
+	}
+}
+
+func TestRankPutawayConsolidateMaxQty(t *testing.T) {
+	if got := binCodes(rankPutaway(bins, PutawayConsolidate, big.NewRat(12, 1), big.NewRat(5, 1))); got != "B" {
+		t.Fatalf("got %q", got)
+	}
+}
+
+func TestRankPutawayEmpty(t *testing.T) {
+	if got := binCodes(rankPutaway(bins, PutawayEmpty, nil, big.NewRat(5, 1))); got != "A" {
+		t.Fatalf("got %q", got)
+	}
+}
+
+func TestRankPutawayAnyKeepsPathOrder(t *testing.T) {
+	if got := binCodes(rankPutaway(bins, PutawayAny, big.NewRat(6, 1), big.NewRat(5, 1))); got != "A C" {
+		t.Fatalf("got %q", got)
+	}
+}
+
+func TestCheckPutawayTarget(t *testing.T) {
+	wh, other := pgtype.UUID{Bytes: [16]byte{1}, Valid: true}, pgtype.UUID{Bytes: [16]byte{2}, Valid: true}
+	src := sqlcgen.Location{ID: pgtype.UUID{Bytes: [16]byte{10}, Valid: true}, WarehouseID: wh, Active: true}
+	dst := sqlcgen.Location{ID: pgtype.UUID{Bytes: [16]byte{11}, Valid: true}, WarehouseID: wh, Active: true}
+	if err := checkPutawayTarget(src, dst); err != nil {
+		t.Fatal(err)
+	}
+	inactive := dst
+	inactive.Active = false
+	elsewhere := dst
+	elsewhere.WarehouseID = other
+	for _, bad := range []sqlcgen.Location{inactive, elsewhere, src} {
+		if err := checkPutawayTarget(src, bad); !errors.Is(err, ErrInvalidMove) {
+			t.Fatalf("got %v", err)
+		}
+	}
+}
+
+func TestFirstFitting(t *testing.T) {
+	noC := func(c putawayCandidate) (bool, error) { return c.LocationCode != "C", nil }
+	got, err := firstFitting(bins, 2, noC)
+	if err != nil || binCodes(got) != "A B" {
+		t.Fatalf("got %q, %v", binCodes(got), err)
+	}
+	if got, _ := firstFitting(bins[1:], 6, noC); binCodes(got) != "B D" {
+		t.Fatalf("got %q", binCodes(got))
+	}
+	boom := errors.New("boom")
+	if _, err := firstFitting(bins, 6, func(putawayCandidate) (bool, error) { return false, boom }); !errors.Is(err, boom) {
+		t.Fatalf("got %v", err)
+	}
+}
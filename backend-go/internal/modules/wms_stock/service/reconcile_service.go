@@ -0,0 +1,173 @@
+package service
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"io"
+	"sort"
+	"time"
+
+	"erpwms/backend-go/internal/common/qty"
+	"erpwms/backend-go/internal/db/sqlcgen"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// Drift is a stock_balance row that disagrees with what stock_ledger and the
+// active allocations say it should hold.
+type Drift struct {
+	ItemID            string `json:"item_id"`
+	Sku               string `json:"sku"`
+	LocationID        string `json:"location_id"`
+	LocationCode      string `json:"location_code"`
+	LotID             string `json:"lot_id,omitempty"`
+	SerialID          string `json:"serial_id,omitempty"`
+	Status            string `json:"status"`
+	HuID              string `json:"hu_id,omitempty"`
+	ExpectedOnHand    string `json:"expected_on_hand"`
+	ActualOnHand      string `json:"actual_on_hand"`
+	ExpectedAllocated string `json:"expected_allocated"`
+	ActualAllocated   string `json:"actual_allocated"`
+}
+
+// StaleSnapshot is a checkpoint that misses ledger rows committed after it
+// was taken.
+type StaleSnapshot struct {
+	SnapshotID   string    `json:"snapshot_id"`
+	TakenAt      time.Time `json:"taken_at"`
+	DriftedLines int32     `json:"drifted_lines"`
+}
+
+type ReconcileReport struct {
+	CheckedAt      time.Time       `json:"checked_at"`
+	Fixed          bool            `json:"fixed"`
+	Drift          []Drift         `json:"drift"`
+	StaleSnapshots []StaleSnapshot `json:"stale_snapshots"`
+}
+
+// ReconcileStatus is the latest reconciliation as seen by /health/stock.
+type ReconcileStatus struct {
+	RanAt      time.Time `json:"ran_at"`
+	DriftCount int32     `json:"drift_count"`
+	Fixed      bool      `json:"fixed"`
+}
+
+// Reconcile recomputes balances from the ledger and reports every row that
+// drifted, and every checkpoint of the last SnapshotCheckWindow that the
+// ledger no longer adds up to. With fix set it also rewrites those rows,
+// holding a lock on stock_balance so no move lands between detection and
+// repair, drops the stale checkpoints and every later one built on them, and
+// writes the diff to audit_log. Every run is recorded for the health signal.
+func (s StockService) Reconcile(ctx context.Context, fix bool) (ReconcileReport, error) {
+	tx, err := s.DB.Begin(ctx)
+	if err != nil {
+		return ReconcileReport{}, err
+	}
+	defer tx.Rollback(ctx)
+	q := s.Queries.WithTx(tx)
+
+	if fix {
+		if err := q.LockStockBalanceTable(ctx); err != nil {
+			return ReconcileReport{}, err
+		}
+	}
+	rows, err := q.ListBalanceDrift(ctx)
+	if err != nil {
+		return ReconcileReport{}, err
+	}
+	now := time.Now().UTC()
+	stale, err := q.ListStaleSnapshots(ctx, pgtype.Timestamptz{Time: now.Add(-SnapshotCheckWindow), Valid: true})
+	if err != nil {
+		return ReconcileReport{}, err
+	}
+	report := ReconcileReport{CheckedAt: now, Drift: make([]Drift, 0, len(rows)), StaleSnapshots: make([]StaleSnapshot, 0, len(stale))}
+	for _, r := range rows {
+		report.Drift = append(report.Drift, toDrift(r))
+	}
+	for _, r := range stale {
+		report.StaleSnapshots = append(report.StaleSnapshots, StaleSnapshot{SnapshotID: r.SnapshotID.String(), TakenAt: r.TakenAt.Time, DriftedLines: r.DriftedLines})
+	}
+	report.Fixed = fix && report.HasDrift()
+	body, _ := json.Marshal(report.Drift)
+	requestID, _ := ctx.Value("request_id").(string)
+
+	if report.Fixed && len(stale) > 0 {
+		// Later checkpoints were built on the first stale one; as-of queries
+		// fall back to the checkpoint before it.
+		if _, err := q.DeleteStockBalanceSnapshotsFrom(ctx, stale[0].TakenAt); err != nil {
+			return ReconcileReport{}, err
+		}
+		staleBody, _ := json.Marshal(report.StaleSnapshots)
+		_ = q.InsertAuditLog(ctx, sqlcgen.InsertAuditLogParams{ActorType: "system", Action: "stock.reconcile.snapshots_dropped", Resource: "stock_balance_snapshots", Status: "ok", RequestID: txt(requestID), Metadata: staleBody})
+	}
+	if report.Fixed && len(rows) > 0 {
+		// Lower balances first so a serial that drifted between bins leaves
+		// the old one before uq_stock_balance_serial_on_hand sees the new.
+		fixes := append([]sqlcgen.ListBalanceDriftRow(nil), rows...)
+		sort.SliceStable(fixes, func(i, j int) bool { return lowers(fixes[i]) && !lowers(fixes[j]) })
+		for _, r := range fixes {
+			if err := q.SetStockBalance(ctx, sqlcgen.SetStockBalanceParams{ItemID: r.ItemID, LocationID: r.LocationID, LotID: r.LotID, SerialID: r.SerialID, Status: r.Status, HuID: r.HuID, QtyOnHand: r.ExpectedOnHand, QtyAllocated: r.ExpectedAllocated}); err != nil {
+				return ReconcileReport{}, err
+			}
+		}
+		_ = q.InsertAuditLog(ctx, sqlcgen.InsertAuditLogParams{ActorType: "system", Action: "stock.reconcile.fix", Resource: "stock_balance", Status: "ok", RequestID: txt(requestID), Metadata: body})
+	}
+	if _, err := q.InsertStockReconcileRun(ctx, sqlcgen.InsertStockReconcileRunParams{DriftCount: int32(len(rows) + len(stale)), Fixed: report.Fixed, Report: body}); err != nil {
+		return ReconcileReport{}, err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return ReconcileReport{}, err
+	}
+	return report, nil
+}
+
+// HasDrift reports whether any balance row or checkpoint disagrees with the
+// ledger.
+func (r ReconcileReport) HasDrift() bool {
+	return len(r.Drift) > 0 || len(r.StaleSnapshots) > 0
+}
+
+// LastReconcile returns ErrNotFound until the first run.
+func (s StockService) LastReconcile(ctx context.Context) (ReconcileStatus, error) {
+	run, err := s.Queries.GetLatestStockReconcileRun(ctx)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return ReconcileStatus{}, ErrNotFound
+	}
+	if err != nil {
+		return ReconcileStatus{}, err
+	}
+	return ReconcileStatus{RanAt: run.RanAt.Time, DriftCount: run.DriftCount, Fixed: run.Fixed}, nil
+}
+
+// WriteCSV writes the drift rows with a header line.
+func (r ReconcileReport) WriteCSV(w io.Writer) error {
+	cw := csv.NewWriter(w)
+	_ = cw.Write([]string{"item_id", "sku", "location_id", "location_code", "lot_id", "serial_id", "status", "hu_id", "expected_on_hand", "actual_on_hand", "expected_allocated", "actual_allocated"})
+	for _, d := range r.Drift {
+		_ = cw.Write([]string{d.ItemID, d.Sku, d.LocationID, d.LocationCode, d.LotID, d.SerialID, d.Status, d.HuID, d.ExpectedOnHand, d.ActualOnHand, d.ExpectedAllocated, d.ActualAllocated})
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+func toDrift(r sqlcgen.ListBalanceDriftRow) Drift {
+	return Drift{
+		ItemID: r.ItemID.String(), Sku: r.Sku, LocationID: r.LocationID.String(), LocationCode: r.LocationCode,
+		LotID: optUUID(r.LotID), SerialID: optUUID(r.SerialID), Status: r.Status, HuID: optUUID(r.HuID),
+		ExpectedOnHand: qty.String(qty.FromNumeric(r.ExpectedOnHand)), ActualOnHand: qty.String(qty.FromNumeric(r.ActualOnHand)),
+		ExpectedAllocated: qty.String(qty.FromNumeric(r.ExpectedAllocated)), ActualAllocated: qty.String(qty.FromNumeric(r.ActualAllocated)),
+	}
+}
+
+func lowers(r sqlcgen.ListBalanceDriftRow) bool {
+	return qty.FromNumeric(r.ExpectedOnHand).Cmp(qty.FromNumeric(r.ActualOnHand)) < 0
+}
+
+func optUUID(u pgtype.UUID) string {
+	if !u.Valid {
+		return ""
+	}
+	return u.String()
+}
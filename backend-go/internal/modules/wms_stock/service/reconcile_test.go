@@ -0,0 +1,35 @@
+package service
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestReconcileWriteCSV(t *testing.T) {
+	row := Drift{ItemID: testItem, Sku: "SKU-1", LocationID: testBin, LocationCode: "A-01", Status: StatusAvailable, ExpectedOnHand: "5", ActualOnHand: "4", ExpectedAllocated: "0", ActualAllocated: "0"}
+	held := row
+	held.Status, held.HuID = StatusQuarantine, testItem
+	var b strings.Builder
+	if err := (ReconcileReport{Drift: []Drift{row, held}}).WriteCSV(&b); err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSpace(b.String()), "\n")
+	if len(lines) != 3 || !strings.HasPrefix(lines[0], "item_id,sku,location_id,location_code,lot_id,serial_id,status,hu_id,") {
+		t.Fatalf("got %q", b.String())
+	}
+	if lines[1] == lines[2] || !strings.Contains(lines[2], ","+StatusQuarantine+","+testItem+",") {
+		t.Fatalf("rows differing by status and unit must differ: %q", b.String())
+	}
+}
+
+func TestReconcileHasDrift(t *testing.T) {
+	if (ReconcileReport{Drift: []Drift{}, StaleSnapshots: []StaleSnapshot{}}).HasDrift() {
+		t.Fatal("empty report has no drift")
+	}
+	if !(ReconcileReport{Drift: []Drift{{ItemID: testItem}}}).HasDrift() {
+		t.Fatal("balance drift")
+	}
+	if !(ReconcileReport{StaleSnapshots: []StaleSnapshot{{SnapshotID: testItem, DriftedLines: 1}}}).HasDrift() {
+		t.Fatal("a stale snapshot is drift")
+	}
+}
@@ -0,0 +1,491 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"erpwms/backend-go/internal/common/locpath"
+	"erpwms/backend-go/internal/common/qty"
+	"erpwms/backend-go/internal/db/sqlcgen"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// RefReplenishmentTask is the ref_type of the allocations that hold the
+// source stock of a replenishment task and of the moves that confirm it.
+const RefReplenishmentTask = "replenishment_task"
+
+// ReplenishmentSettingInput sets up min/max replenishment of a pick face.
+// Exactly one of MaxQty (refill up to max) and FixedQty (refill by whole
+// multiples of it) is set. Quantities are in the item's base unit.
+type ReplenishmentSettingInput struct {
+	LocationID string `json:"location_id"`
+	ItemID     string `json:"item_id"`
+	MinQty     string `json:"min_qty"`
+	MaxQty     string `json:"max_qty,omitempty"`
+	FixedQty   string `json:"fixed_qty,omitempty"`
+	SourcePath string `json:"source_path,omitempty"`
+}
+
+type ReplenishmentSetting struct {
+	ID          string `json:"id"`
+	WarehouseID string `json:"warehouse_id"`
+	ItemID      string `json:"item_id"`
+	LocationID  string `json:"location_id"`
+	MinQty      string `json:"min_qty"`
+	MaxQty      string `json:"max_qty,omitempty"`
+	FixedQty    string `json:"fixed_qty,omitempty"`
+	SourcePath  string `json:"source_path,omitempty"`
+	Active      bool   `json:"active"`
+}
+
+type ReplenishmentTask struct {
+	ID             string `json:"id"`
+	SettingID      string `json:"setting_id"`
+	WarehouseID    string `json:"warehouse_id"`
+	ItemID         string `json:"item_id"`
+	Qty            string `json:"qty"`
+	LotCode        string `json:"lot_code,omitempty"`
+	SerialNo       string `json:"serial_no,omitempty"`
+	FromLocationID string `json:"from_location_id"`
+	ToLocationID   string `json:"to_location_id"`
+	Status         string `json:"status"`
+	MoveID         string `json:"move_id,omitempty"`
+}
+
+type ReplenishmentTaskFilter struct {
+	WarehouseID string
+	Status      string
+	Limit       int32
+	Offset      int32
+}
+
+// ReplenishmentShortage is what a pick face still lacks after the planner
+// took all the reserve stock it could find.
+type ReplenishmentShortage struct {
+	SettingID  string `json:"setting_id"`
+	ItemID     string `json:"item_id"`
+	LocationID string `json:"location_id"`
+	Qty        string `json:"qty"`
+}
+
+// ReplenishmentRun summarizes one planner run.
+type ReplenishmentRun struct {
+	Settings  int                     `json:"settings"`
+	Tasks     []ReplenishmentTask     `json:"tasks"`
+	Shortages []ReplenishmentShortage `json:"shortages"`
+}
+
+func (s StockService) ListReplenishmentSettings(ctx context.Context, warehouseID, itemID string, includeInactive bool) ([]ReplenishmentSetting, error) {
+	p := sqlcgen.ListReplenishmentSettingsParams{IncludeInactive: includeInactive}
+	var err error
+	if warehouseID != "" {
+		if p.WarehouseID, err = scanUUID(warehouseID); err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidFilter, err)
+		}
+	}
+	if itemID != "" {
+		if p.ItemID, err = scanUUID(itemID); err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidFilter, err)
+		}
+	}
+	rows, err := s.Queries.ListReplenishmentSettings(ctx, p)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]ReplenishmentSetting, 0, len(rows))
+	for _, r := range rows {
+		out = append(out, toReplenishmentSetting(r))
+	}
+	return out, nil
+}
+
+// CreateReplenishmentSetting makes location_id the pick face of the item; its
+// warehouse is the location's.
+func (s StockService) CreateReplenishmentSetting(ctx context.Context, in ReplenishmentSettingInput, actor uuid.UUID) (ReplenishmentSetting, error) {
+	locationID, err := scanUUID(in.LocationID)
+	if err != nil {
+		return ReplenishmentSetting{}, fmt.Errorf("%w: location_id: %v", ErrInvalidMove, err)
+	}
+	itemID, err := scanUUID(in.ItemID)
+	if err != nil {
+		return ReplenishmentSetting{}, fmt.Errorf("%w: item_id: %v", ErrInvalidMove, err)
+	}
+	p, err := in.params()
+	if err != nil {
+		return ReplenishmentSetting{}, err
+	}
+	return s.saveSetting(ctx, actor, "replenishment_setting.created", func(q *sqlcgen.Queries) (sqlcgen.ReplenishmentSetting, error) {
+		loc, err := q.GetLocation(ctx, locationID)
+		if errors.Is(err, pgx.ErrNoRows) {
+			return sqlcgen.ReplenishmentSetting{}, fmt.Errorf("%w: unknown location_id", ErrInvalidMove)
+		}
+		if err != nil {
+			return sqlcgen.ReplenishmentSetting{}, err
+		}
+		if !loc.Active {
+			return sqlcgen.ReplenishmentSetting{}, fmt.Errorf("%w: location %s is inactive", ErrInvalidMove, loc.Code)
+		}
+		if _, err := q.GetItem(ctx, itemID); errors.Is(err, pgx.ErrNoRows) {
+			return sqlcgen.ReplenishmentSetting{}, fmt.Errorf("%w: unknown item_id", ErrInvalidMove)
+		} else if err != nil {
+			return sqlcgen.ReplenishmentSetting{}, err
+		}
+		return q.CreateReplenishmentSetting(ctx, sqlcgen.CreateReplenishmentSettingParams{
+			WarehouseID: loc.WarehouseID, ItemID: itemID, LocationID: loc.ID,
+			MinQty: p.MinQty, MaxQty: p.MaxQty, FixedQty: p.FixedQty, SourcePath: p.SourcePath,
+		})
+	})
+}
+
+// UpdateReplenishmentSetting replaces the levels and source path; the item
+// and pick face are fixed.
+func (s StockService) UpdateReplenishmentSetting(ctx context.Context, id uuid.UUID, in ReplenishmentSettingInput, actor uuid.UUID) (ReplenishmentSetting, error) {
+	p, err := in.params()
+	if err != nil {
+		return ReplenishmentSetting{}, err
+	}
+	p.ID = pgUUID(id)
+	return s.saveSetting(ctx, actor, "replenishment_setting.updated", func(q *sqlcgen.Queries) (sqlcgen.ReplenishmentSetting, error) {
+		return q.UpdateReplenishmentSetting(ctx, p)
+	})
+}
+
+// DeactivateReplenishmentSetting stops planning for the pick face. Open
+// tasks stay and are confirmed or cancelled as usual.
+func (s StockService) DeactivateReplenishmentSetting(ctx context.Context, id uuid.UUID, actor uuid.UUID) (ReplenishmentSetting, error) {
+	return s.saveSetting(ctx, actor, "replenishment_setting.deactivated", func(q *sqlcgen.Queries) (sqlcgen.ReplenishmentSetting, error) {
+		return q.SetReplenishmentSettingActive(ctx, sqlcgen.SetReplenishmentSettingActiveParams{ID: pgUUID(id), Active: false})
+	})
+}
+
+func (s StockService) saveSetting(ctx context.Context, actor uuid.UUID, topic string, fn func(q *sqlcgen.Queries) (sqlcgen.ReplenishmentSetting, error)) (ReplenishmentSetting, error) {
+	tx, err := s.DB.Begin(ctx)
+	if err != nil {
+		return ReplenishmentSetting{}, err
+	}
+	defer tx.Rollback(ctx)
+	q := s.Queries.WithTx(tx)
+
+	r, err := fn(q)
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+		return ReplenishmentSetting{}, fmt.Errorf("%w: the item already has a replenishment setting at this location", ErrInvalidMove)
+	}
+	if errors.Is(err, pgx.ErrNoRows) {
+		return ReplenishmentSetting{}, ErrNotFound
+	}
+	if err != nil {
+		return ReplenishmentSetting{}, err
+	}
+	out := toReplenishmentSetting(r)
+	if err := s.record(ctx, q, pgUUID(actor), topic, "replenishment_settings", out.ID, out); err != nil {
+		return ReplenishmentSetting{}, err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return ReplenishmentSetting{}, err
+	}
+	return out, nil
+}
+
+// PlanReplenishment checks every active pick face of the warehouse (all
+// warehouses when warehouseID is empty) and creates tasks for those below
+// their minimum. Each pick face is planned in its own transaction; one that
+// fails does not stop the others and its error is returned with the run.
+// actor is uuid.Nil when the worker plans.
+func (s StockService) PlanReplenishment(ctx context.Context, warehouseID string, actor uuid.UUID) (ReplenishmentRun, error) {
+	run := ReplenishmentRun{Tasks: []ReplenishmentTask{}, Shortages: []ReplenishmentShortage{}}
+	var wh pgtype.UUID
+	if warehouseID != "" {
+		var err error
+		if wh, err = scanUUID(warehouseID); err != nil {
+			return run, fmt.Errorf("%w: warehouse_id: %v", ErrInvalidMove, err)
+		}
+	}
+	ids, err := s.Queries.ListActiveReplenishmentSettingIDs(ctx, wh)
+	if err != nil {
+		return run, err
+	}
+	var errs []error
+	for _, id := range ids {
+		tasks, short, err := s.replenish(ctx, id, pgUUID(actor))
+		if err != nil {
+			errs = append(errs, fmt.Errorf("replenishment setting %s: %w", id.String(), err))
+			continue
+		}
+		run.Settings++
+		run.Tasks = append(run.Tasks, tasks...)
+		if short != nil {
+			run.Shortages = append(run.Shortages, *short)
+		}
+	}
+	return run, errors.Join(errs...)
+}
+
+// replenish plans one pick face. Its setting row stays locked until the
+// tasks are committed, so concurrent runs skip the face rather than plan it
+// twice. The source stock of every task is allocated to the task, FEFO.
+func (s StockService) replenish(ctx context.Context, settingID, actorID pgtype.UUID) ([]ReplenishmentTask, *ReplenishmentShortage, error) {
+	tx, err := s.DB.Begin(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer tx.Rollback(ctx)
+	q := s.Queries.WithTx(tx)
+
+	st, err := q.LockReplenishmentSetting(ctx, settingID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		// Deactivated meanwhile, or being planned by another run.
+		return nil, nil, nil
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	lvl, err := q.GetReplenishmentLevel(ctx, sqlcgen.GetReplenishmentLevelParams{ItemID: st.ItemID, LocationID: st.LocationID})
+	if err != nil {
+		return nil, nil, err
+	}
+	level := qty.Add(qty.Sub(qty.FromNumeric(lvl.QtyOnHand), qty.FromNumeric(lvl.QtyAllocated)), qty.FromNumeric(lvl.QtyInbound))
+	var maxQty, fixedQty *big.Rat
+	if st.MaxQty.Valid {
+		maxQty = qty.FromNumeric(st.MaxQty)
+	}
+	if st.FixedQty.Valid {
+		fixedQty = qty.FromNumeric(st.FixedQty)
+	}
+	need := replenishQty(qty.FromNumeric(st.MinQty), maxQty, fixedQty, level)
+	if need.Sign() <= 0 {
+		return nil, nil, nil
+	}
+
+	rows, err := q.ListReplenishmentSources(ctx, sqlcgen.ListReplenishmentSourcesParams{ItemID: st.ItemID, WarehouseID: st.WarehouseID, SourcePath: st.SourcePath.String})
+	if err != nil {
+		return nil, nil, err
+	}
+	cands := make([]candidate, 0, len(rows))
+	for _, r := range rows {
+		cands = append(cands, candidate{
+			LocationID: r.LocationID, LotID: r.LotID, SerialID: r.SerialID, HuID: r.HuID, LocationCode: r.LocationCode,
+			ExpiresOn: r.ExpiresOn, ReceivedAt: r.ReceivedAt.Time,
+			Free:    qty.Sub(qty.FromNumeric(r.QtyOnHand), qty.FromNumeric(r.QtyAllocated)),
+			LotCode: r.LotCode.String, SerialNo: r.SerialNo.String,
+		})
+	}
+	picks, short := planAllocation(cands, need, StrategyFEFO)
+
+	out := make([]ReplenishmentTask, 0, len(picks))
+	for _, p := range picks {
+		t, err := q.CreateReplenishmentTask(ctx, sqlcgen.CreateReplenishmentTaskParams{
+			SettingID: st.ID, WarehouseID: st.WarehouseID, ItemID: st.ItemID, Qty: qty.ToNumeric(p.Qty),
+			LotCode: txt(p.From.LotCode), SerialNo: txt(p.From.SerialNo), FromLocationID: p.From.LocationID, ToLocationID: st.LocationID, CreatedBy: actorID,
+		})
+		if err != nil {
+			return nil, nil, err
+		}
+		if _, err := bookPick(ctx, q, st.ItemID, p, RefReplenishmentTask, t.ID.String(), StrategyFEFO, actorID); err != nil {
+			return nil, nil, err
+		}
+		task := toReplenishmentTask(t)
+		if err := s.record(ctx, q, actorID, "replenishment.task_created", "replenishment_tasks", task.ID, task); err != nil {
+			return nil, nil, err
+		}
+		out = append(out, task)
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return nil, nil, err
+	}
+	if short.Sign() <= 0 {
+		return out, nil, nil
+	}
+	return out, &ReplenishmentShortage{SettingID: st.ID.String(), ItemID: st.ItemID.String(), LocationID: st.LocationID.String(), Qty: qty.String(short)}, nil
+}
+
+// replenishQty is how much to bring to a pick face at level (free stock plus
+// open tasks): nothing while level is at least min, otherwise up to max, or
+// the fewest multiples of fixed that lift it back to min. Exactly one of max
+// and fixed is non-nil.
+func replenishQty(minQty, maxQty, fixedQty, level *big.Rat) *big.Rat {
+	if level.Cmp(minQty) >= 0 {
+		return qty.Zero()
+	}
+	if maxQty != nil {
+		return qty.Sub(maxQty, level)
+	}
+	gap := new(big.Rat).Quo(qty.Sub(minQty, level), fixedQty)
+	n := new(big.Int).Quo(gap.Num(), gap.Denom())
+	if !gap.IsInt() {
+		n.Add(n, big.NewInt(1))
+	}
+	return new(big.Rat).Mul(new(big.Rat).SetInt(n), fixedQty)
+}
+
+func (s StockService) ListReplenishmentTasks(ctx context.Context, f ReplenishmentTaskFilter) ([]ReplenishmentTask, error) {
+	if f.Limit <= 0 || f.Limit > 500 {
+		f.Limit = 100
+	}
+	p := sqlcgen.ListReplenishmentTasksParams{Status: f.Status, PageLimit: f.Limit, PageOffset: f.Offset}
+	if f.WarehouseID != "" {
+		id, err := scanUUID(f.WarehouseID)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidFilter, err)
+		}
+		p.WarehouseID = id
+	}
+	rows, err := s.Queries.ListReplenishmentTasks(ctx, p)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]ReplenishmentTask, 0, len(rows))
+	for _, r := range rows {
+		out = append(out, toReplenishmentTask(r))
+	}
+	return out, nil
+}
+
+// ConfirmReplenishmentTask consumes the task's allocation with a transfer from
+// the reserve location into the pick face.
+func (s StockService) ConfirmReplenishmentTask(ctx context.Context, id uuid.UUID, actor uuid.UUID) (ReplenishmentTask, error) {
+	actorID := pgUUID(actor)
+	tx, err := s.DB.Begin(ctx)
+	if err != nil {
+		return ReplenishmentTask{}, err
+	}
+	defer tx.Rollback(ctx)
+	q := s.Queries.WithTx(tx)
+
+	t, err := lockOpenReplenishmentTask(ctx, q, pgUUID(id))
+	if err != nil {
+		return ReplenishmentTask{}, err
+	}
+	allocs, err := q.LockActiveStockAllocationsByRef(ctx, sqlcgen.LockActiveStockAllocationsByRefParams{RefType: RefReplenishmentTask, RefID: t.ID.String()})
+	if err != nil {
+		return ReplenishmentTask{}, err
+	}
+	// The planner books one allocation per task; if it was released or
+	// shrunk since, the task no longer says what to pick.
+	if len(allocs) != 1 || qty.FromNumeric(allocs[0].Qty).Cmp(qty.FromNumeric(t.Qty)) != 0 {
+		return ReplenishmentTask{}, fmt.Errorf("%w: the task's source stock is no longer reserved; cancel it and plan again", ErrInvalidMove)
+	}
+	move, err := s.ConsumeAllocation(ctx, q, ConsumeRequest{
+		AllocationID: uuid.UUID(allocs[0].ID.Bytes), Qty: qty.FromNumeric(t.Qty), ToLocationID: uuid.UUID(t.ToLocationID.Bytes),
+		ReasonCode: "REPLENISH", RefType: RefReplenishmentTask, RefID: t.ID.String(),
+	}, actor)
+	if err != nil {
+		return ReplenishmentTask{}, err
+	}
+	if t, err = q.ConfirmReplenishmentTask(ctx, sqlcgen.ConfirmReplenishmentTaskParams{ID: t.ID, MoveID: move.MoveID, ConfirmedBy: actorID}); err != nil {
+		return ReplenishmentTask{}, err
+	}
+	out := toReplenishmentTask(t)
+	if err := s.record(ctx, q, actorID, "replenishment.task_confirmed", "replenishment_tasks", out.ID, out); err != nil {
+		return ReplenishmentTask{}, err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return ReplenishmentTask{}, err
+	}
+	return out, nil
+}
+
+// CancelReplenishmentTask gives the reserved source stock back to free stock.
+// The next planner run creates a new task if the pick face still needs one.
+func (s StockService) CancelReplenishmentTask(ctx context.Context, id uuid.UUID, actor uuid.UUID) (ReplenishmentTask, error) {
+	actorID := pgUUID(actor)
+	tx, err := s.DB.Begin(ctx)
+	if err != nil {
+		return ReplenishmentTask{}, err
+	}
+	defer tx.Rollback(ctx)
+	q := s.Queries.WithTx(tx)
+
+	t, err := lockOpenReplenishmentTask(ctx, q, pgUUID(id))
+	if err != nil {
+		return ReplenishmentTask{}, err
+	}
+	if _, err := s.ReleaseByRef(ctx, q, RefReplenishmentTask, t.ID.String(), actor); err != nil {
+		return ReplenishmentTask{}, err
+	}
+	if t, err = q.CancelReplenishmentTask(ctx, t.ID); err != nil {
+		return ReplenishmentTask{}, err
+	}
+	out := toReplenishmentTask(t)
+	if err := s.record(ctx, q, actorID, "replenishment.task_cancelled", "replenishment_tasks", out.ID, out); err != nil {
+		return ReplenishmentTask{}, err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return ReplenishmentTask{}, err
+	}
+	return out, nil
+}
+
+func lockOpenReplenishmentTask(ctx context.Context, q *sqlcgen.Queries, id pgtype.UUID) (sqlcgen.ReplenishmentTask, error) {
+	t, err := q.LockReplenishmentTask(ctx, id)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return t, ErrNotFound
+	}
+	if err != nil {
+		return t, err
+	}
+	if t.Status != "open" {
+		return t, fmt.Errorf("%w: task is %s", ErrInvalidMove, t.Status)
+	}
+	return t, nil
+}
+
+// params validates the levels into UpdateReplenishmentSettingParams; callers
+// set ID or copy the fields into the create params.
+func (in ReplenishmentSettingInput) params() (sqlcgen.UpdateReplenishmentSettingParams, error) {
+	var p sqlcgen.UpdateReplenishmentSettingParams
+	minQty, err := qty.Parse(in.MinQty)
+	if err != nil || minQty.Sign() < 0 {
+		return p, fmt.Errorf("%w: min_qty must be zero or positive", ErrInvalidMove)
+	}
+	p.MinQty = qty.ToNumeric(minQty)
+	if (in.MaxQty == "") == (in.FixedQty == "") {
+		return p, fmt.Errorf("%w: set exactly one of max_qty and fixed_qty", ErrInvalidMove)
+	}
+	if in.MaxQty != "" {
+		m, err := qty.Parse(in.MaxQty)
+		if err != nil || m.Cmp(minQty) <= 0 {
+			return p, fmt.Errorf("%w: max_qty must be greater than min_qty", ErrInvalidMove)
+		}
+		p.MaxQty = qty.ToNumeric(m)
+	} else {
+		f, err := qty.Parse(in.FixedQty)
+		if err != nil || f.Sign() <= 0 {
+			return p, fmt.Errorf("%w: fixed_qty must be positive", ErrInvalidMove)
+		}
+		p.FixedQty = qty.ToNumeric(f)
+	}
+	path, err := locpath.Normalize(in.SourcePath)
+	if err != nil {
+		return p, fmt.Errorf("%w: source_path: %v", ErrInvalidMove, err)
+	}
+	p.SourcePath = txt(path)
+	return p, nil
+}
+
+func toReplenishmentSetting(r sqlcgen.ReplenishmentSetting) ReplenishmentSetting {
+	out := ReplenishmentSetting{
+		ID: r.ID.String(), WarehouseID: r.WarehouseID.String(), ItemID: r.ItemID.String(), LocationID: r.LocationID.String(),
+		MinQty: qty.String(qty.FromNumeric(r.MinQty)), SourcePath: r.SourcePath.String, Active: r.Active,
+	}
+	if r.MaxQty.Valid {
+		out.MaxQty = qty.String(qty.FromNumeric(r.MaxQty))
+	}
+	if r.FixedQty.Valid {
+		out.FixedQty = qty.String(qty.FromNumeric(r.FixedQty))
+	}
+	return out
+}
+
+func toReplenishmentTask(t sqlcgen.ReplenishmentTask) ReplenishmentTask {
+	return ReplenishmentTask{
+		ID: t.ID.String(), SettingID: t.SettingID.String(), WarehouseID: t.WarehouseID.String(), ItemID: t.ItemID.String(),
+		Qty: qty.String(qty.FromNumeric(t.Qty)), LotCode: t.LotCode.String, SerialNo: t.SerialNo.String,
+		FromLocationID: t.FromLocationID.String(), ToLocationID: t.ToLocationID.String(), Status: t.Status, MoveID: optUUID(t.MoveID),
+	}
+}
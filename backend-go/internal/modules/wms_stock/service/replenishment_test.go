@@ -0,0 +1,52 @@
+package service
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+)
+
+func TestReplenishQty(t *testing.T) {
+	r := func(n int64) *big.Rat { return big.NewRat(n, 1) }
+	cases := []struct {
+		name            string
+		min, max, fixed *big.Rat
+		level, want     *big.Rat
+	}{
+		{"at min", r(10), r(50), nil, r(10), r(0)},
+		{"up to max", r(10), r(50), nil, r(4), r(46)},
+		{"negative level", r(10), r(50), nil, r(-3), r(53)},
+		{"one fixed lot", r(10), nil, r(24), r(9), r(24)},
+		{"whole fixed lots", r(60), nil, r(24), r(9), r(72)},
+		{"exact fixed lots", r(57), nil, r(24), r(9), r(48)},
+		{"fractional", r(1), nil, big.NewRat(1, 2), big.NewRat(1, 4), big.NewRat(1, 1)},
+		{"zero min", r(0), r(5), nil, r(0), r(0)},
+	}
+	for _, c := range cases {
+		if got := replenishQty(c.min, c.max, c.fixed, c.level); got.Cmp(c.want) != 0 {
+			t.Errorf("%s: got %s, want %s", c.name, got.RatString(), c.want.RatString())
+		}
+	}
+}
+
+func TestReplenishmentSettingParams(t *testing.T) {
+	p, err := ReplenishmentSettingInput{MinQty: "10", MaxQty: "40", SourcePath: "/RES/A/"}.params()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !p.MaxQty.Valid || p.FixedQty.Valid || p.SourcePath.String != "RES.A" {
+		t.Fatalf("got %+v", p)
+	}
+	for _, in := range []ReplenishmentSettingInput{
+		{MinQty: "10"},
+		{MinQty: "10", MaxQty: "40", FixedQty: "5"},
+		{MinQty: "10", MaxQty: "10"},
+		{MinQty: "-1", MaxQty: "10"},
+		{MinQty: "1", FixedQty: "0"},
+		{MinQty: "1", FixedQty: "6", SourcePath: "A/B-1"},
+	} {
+		if _, err := in.params(); !errors.Is(err, ErrInvalidMove) {
+			t.Errorf("%+v: got %v", in, err)
+		}
+	}
+}
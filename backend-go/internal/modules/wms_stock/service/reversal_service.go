@@ -0,0 +1,180 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"erpwms/backend-go/internal/common/qty"
+	"erpwms/backend-go/internal/db/sqlcgen"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+// RefReversal marks a compensating ledger line; its ref_id is the move it
+// undoes.
+const RefReversal = "reversal"
+
+// RefCount marks the adjustments a count session posts on approval; its
+// ref_id is the session. Only those may touch the locations it froze.
+const RefCount = "count"
+
+// RefTransfer marks the legs a transfer order books; its ref_id is the order.
+// Only those may move stock into or out of an in-transit location.
+const RefTransfer = "transfer_order"
+
+// RefHandlingUnit marks the lines of a handling unit pack, unpack or move; its
+// ref_id is the unit. Only those may carry a unit to another location.
+const RefHandlingUnit = "handling_unit"
+
+// reservedRefTypes are the ref_types whose ledger lines only their owning
+// flow books, mapped to that flow. The generic move endpoints reject them so
+// no line claims a document it was not booked by; module ref_types are
+// listed by value because their constants live in the owning module.
+var reservedRefTypes = map[string]string{
+	RefReversal:          "reversals",
+	RefCount:             "count approvals",
+	RefTransfer:          "transfer orders",
+	RefHandlingUnit:      "handling unit operations",
+	RefPutawayTask:       "putaway tasks",
+	RefReplenishmentTask: "replenishment tasks",
+	"receipt":            "goods receipts",
+	"pick_task":          "pick tasks",
+	"shipment":           "shipment confirmations",
+	"rma":                "returns",
+}
+
+func checkRefType(refType string) error {
+	if owner, ok := reservedRefTypes[refType]; ok {
+		return fmt.Errorf("%w: ref_type %s is reserved for %s", ErrInvalidMove, refType, owner)
+	}
+	return nil
+}
+
+// irreversibleRefTypes are reserved ref_types whose moves are kept in step
+// with their document: reversing one alone would leave the document claiming
+// stock that is gone. Those are corrected through the document instead.
+var irreversibleRefTypes = map[string]bool{
+	"receipt":            true,
+	"shipment":           true,
+	"pick_task":          true,
+	RefPutawayTask:       true,
+	"rma":                true,
+	RefReplenishmentTask: true,
+	// Count approval is the controlled correction; undoing it line by line
+	// would bypass the session it was approved in.
+	RefCount: true,
+}
+
+func checkReversible(refType string) error {
+	if irreversibleRefTypes[refType] {
+		return fmt.Errorf("%w: the move was booked by %s and cannot be reversed on its own", ErrInvalidMove, reservedRefTypes[refType])
+	}
+	return nil
+}
+
+// ErrAlreadyReversed is returned for a move that already has a reversal or is
+// itself one.
+var ErrAlreadyReversed = errors.New("move already reversed")
+
+// inverseMoveType keeps the ledger's side rules intact: undoing a receipt
+// takes stock out, undoing an issue puts it back. A status change is undone
+// by the opposite status change.
+var inverseMoveType = map[string]string{
+	MoveReceipt:    MoveIssue,
+	MoveIssue:      MoveReceipt,
+	MoveAdjustment: MoveAdjustment,
+	MoveTransfer:   MoveTransfer,
+	MoveStatus:     MoveStatus,
+}
+
+type ReverseRequest struct {
+	Comment string `json:"comment,omitempty"`
+}
+
+type ReverseResponse struct {
+	MoveID         string `json:"move_id"`
+	ReversedMoveID string `json:"reversed_move_id"`
+	Status         string `json:"status"`
+}
+
+// Reverse books the compensating line for moveID: same item, lot, serial and
+// qty with source and destination swapped. The stock must still be free at
+// the original destination, and a move can be reversed only once.
+func (s StockService) Reverse(ctx context.Context, moveID uuid.UUID, req ReverseRequest, actor uuid.UUID, endpoint, idemKey string) (ReverseResponse, error) {
+	reqHash := hashReq(struct {
+		MoveID string `json:"move_id"`
+		ReverseRequest
+	}{moveID.String(), req})
+	var prev ReverseResponse
+	if found, err := s.replay(ctx, endpoint, idemKey, reqHash, &prev); err != nil || found {
+		return prev, err
+	}
+	actorID, _ := scanUUID(actor.String())
+
+	tx, err := s.DB.Begin(ctx)
+	if err != nil {
+		return ReverseResponse{}, err
+	}
+	defer tx.Rollback(ctx)
+	q := s.Queries.WithTx(tx)
+
+	// The row lock serializes concurrent reversals of the same move;
+	// uq_stock_ledger_reversal backs this up.
+	orig, err := q.LockStockLedgerMove(ctx, pgUUID(moveID))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return ReverseResponse{}, ErrNotFound
+	}
+	if err != nil {
+		return ReverseResponse{}, err
+	}
+	if orig.RefType.String == RefReversal {
+		return ReverseResponse{}, fmt.Errorf("%w: %s is itself a reversal", ErrAlreadyReversed, moveID)
+	}
+	if err := checkReversible(orig.RefType.String); err != nil {
+		return ReverseResponse{}, err
+	}
+	prior, err := q.GetReversalOf(ctx, txt(moveID.String()))
+	if err == nil {
+		return ReverseResponse{}, fmt.Errorf("%w: reversed by %s", ErrAlreadyReversed, prior.String())
+	}
+	if !errors.Is(err, pgx.ErrNoRows) {
+		return ReverseResponse{}, err
+	}
+
+	p := posting{
+		MoveType: inverseMoveType[orig.MoveType], ItemID: orig.ItemID, Qty: qty.FromNumeric(orig.Qty),
+		From: orig.ToLocationID, To: orig.FromLocationID, ReasonCode: "REVERSAL", Comment: req.Comment,
+		RefType: RefReversal, RefID: moveID.String(), LotCode: orig.LotCode.String, SerialNo: orig.SerialNo.String,
+		ActorID: actorID, Status: orig.Status, FromHU: orig.ToHuID, ToHU: orig.FromHuID,
+	}
+	if orig.MoveType == MoveStatus {
+		p.Status, p.ToStatus = orig.ToStatus.String, orig.Status
+	}
+	move, err := s.post(ctx, q, p)
+	if err != nil {
+		return ReverseResponse{}, err
+	}
+
+	payload, _ := json.Marshal(map[string]any{
+		"move_id": move.MoveID.String(), "reversed_move_id": moveID.String(), "move_type": p.MoveType,
+		"item_id": orig.ItemID.String(), "qty": qty.String(p.Qty),
+		"from_location_id": optUUID(p.From), "to_location_id": optUUID(p.To),
+		"lot_code": p.LotCode, "serial_no": p.SerialNo, "status": p.Status, "to_status": p.ToStatus, "comment": p.Comment,
+	})
+	if _, err := q.InsertOutboxEvent(ctx, sqlcgen.InsertOutboxEventParams{Topic: "stock.move_reversed", Payload: payload}); err != nil {
+		return ReverseResponse{}, err
+	}
+	requestID, _ := ctx.Value("request_id").(string)
+	_ = q.InsertAuditLog(ctx, sqlcgen.InsertAuditLogParams{ActorUserID: actorID, ActorType: "user", Action: "stock.move_reverse", Resource: "stock_ledger", ResourceID: txt(moveID.String()), Status: "ok", RequestID: txt(requestID), Metadata: payload})
+
+	resp := ReverseResponse{MoveID: move.MoveID.String(), ReversedMoveID: moveID.String(), Status: "ok"}
+	if err := remember(ctx, q, endpoint, idemKey, actorID, reqHash, resp); err != nil {
+		return ReverseResponse{}, err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return ReverseResponse{}, err
+	}
+	return resp, nil
+}
@@ -0,0 +1,47 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"slices"
+
+	"github.com/google/uuid"
+)
+
+// Stock statuses, part of the stock_balance key. Only available stock can be
+// allocated or picked; the others stay on hand in their bin until a status
+// change releases them.
+const (
+	StatusAvailable  = "available"
+	StatusQuarantine = "quarantine"
+	StatusDamaged    = "damaged"
+	StatusBlocked    = "blocked"
+)
+
+var stockStatuses = []string{StatusAvailable, StatusQuarantine, StatusDamaged, StatusBlocked}
+
+// ChangeStatus moves stock at LocationID from Status (available when empty)
+// to ToStatus without moving it physically, e.g. to put a lot on quality hold.
+// The reason code and comment record why; the reason codes for status moves
+// carry the wms.stock.status permission.
+func (s StockService) ChangeStatus(ctx context.Context, req MoveRequest, actor uuid.UUID, endpoint, idemKey string) (MoveResponse, error) {
+	return s.book(ctx, MoveStatus, req, actor, endpoint, idemKey)
+}
+
+// checkStatus validates the statuses of a posting: both must be known and a
+// status change must actually change something.
+func checkStatus(p posting) error {
+	if !slices.Contains(stockStatuses, p.Status) {
+		return fmt.Errorf("%w: unknown status %q", ErrInvalidMove, p.Status)
+	}
+	if p.ToStatus == "" {
+		return nil
+	}
+	if !slices.Contains(stockStatuses, p.ToStatus) {
+		return fmt.Errorf("%w: unknown to_status %q", ErrInvalidMove, p.ToStatus)
+	}
+	if p.ToStatus == p.Status {
+		return fmt.Errorf("%w: stock is already %s", ErrInvalidMove, p.Status)
+	}
+	return nil
+}
@@ -0,0 +1,89 @@
+package service
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+const testItem = "6f1c2a4e-8d7b-4c3a-9e5f-0a1b2c3d4e5f"
+const testBin = "0a1b2c3d-4e5f-4c3a-9e5f-6f1c2a4e8d7b"
+
+func TestStatusChangeStaysInPlace(t *testing.T) {
+	req := MoveRequest{ItemID: testItem, Qty: "4", LocationID: testBin, ReasonCode: "QC_HOLD", ToStatus: StatusQuarantine}
+	if err := checkSides(MoveStatus, req); err != nil {
+		t.Fatal(err)
+	}
+	p, err := newPosting(MoveStatus, req, pgtype.UUID{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !p.From.Valid || p.From != p.To {
+		t.Fatalf("from %v to %v", p.From, p.To)
+	}
+	if p.Status != StatusAvailable || p.toStatus() != StatusQuarantine {
+		t.Fatalf("status %s -> %s", p.Status, p.toStatus())
+	}
+}
+
+func TestStatusChangeValidation(t *testing.T) {
+	for name, tc := range map[string]struct {
+		moveType string
+		req      MoveRequest
+	}{
+		"no to_status":      {MoveStatus, MoveRequest{ItemID: testItem, Qty: "1", LocationID: testBin}},
+		"two locations":     {MoveStatus, MoveRequest{ItemID: testItem, Qty: "1", FromLocationID: testBin, ToLocationID: testBin, ToStatus: StatusBlocked}},
+		"to_status on move": {MoveTransfer, MoveRequest{ItemID: testItem, Qty: "1", FromLocationID: testBin, ToLocationID: testBin, ToStatus: StatusBlocked}},
+		"same status":       {MoveStatus, MoveRequest{ItemID: testItem, Qty: "1", LocationID: testBin, Status: StatusDamaged, ToStatus: StatusDamaged}},
+		"unknown status":    {MoveStatus, MoveRequest{ItemID: testItem, Qty: "1", LocationID: testBin, Status: "hold", ToStatus: StatusAvailable}},
+		"unknown to_status": {MoveStatus, MoveRequest{ItemID: testItem, Qty: "1", LocationID: testBin, ToStatus: "scrapped"}},
+	} {
+		err := checkSides(tc.moveType, tc.req)
+		if err == nil {
+			_, err = newPosting(tc.moveType, tc.req, pgtype.UUID{})
+		}
+		if !errors.Is(err, ErrInvalidMove) {
+			t.Fatalf("%s: got %v", name, err)
+		}
+	}
+}
+
+func TestMoveKeepsStatus(t *testing.T) {
+	// Moving quarantined stock to a QC bin keeps it quarantined.
+	p, err := newPosting(MoveTransfer, MoveRequest{ItemID: testItem, Qty: "2", FromLocationID: testBin, ToLocationID: testItem, Status: StatusQuarantine}, pgtype.UUID{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p.Status != StatusQuarantine || p.toStatus() != StatusQuarantine {
+		t.Fatalf("status %s -> %s", p.Status, p.toStatus())
+	}
+}
+
+func TestReservedRefTypes(t *testing.T) {
+	for _, ref := range []string{RefReversal, RefCount, RefTransfer, RefHandlingUnit, "receipt", "putaway_task", "pick_task", "shipment", "rma", "replenishment_task"} {
+		req := MoveRequest{ItemID: testItem, Qty: "1", FromLocationID: testBin, ToLocationID: testItem, RefType: ref, RefID: "x"}
+		if _, err := newPosting(MoveTransfer, req, pgtype.UUID{}); !errors.Is(err, ErrInvalidMove) {
+			t.Errorf("%s: got %v", ref, err)
+		}
+		if _, err := parsePosting(MoveTransfer, req, pgtype.UUID{}); err != nil {
+			t.Errorf("%s: module callers keep their ref_type: %v", ref, err)
+		}
+	}
+	if _, err := newPosting(MoveTransfer, MoveRequest{ItemID: testItem, Qty: "1", FromLocationID: testBin, ToLocationID: testItem, RefType: "sales_order", RefID: "x"}, pgtype.UUID{}); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestCheckReversible(t *testing.T) {
+	for _, ref := range []string{"receipt", "shipment", "pick_task", RefPutawayTask, "rma", RefReplenishmentTask, RefCount} {
+		if err := checkReversible(ref); !errors.Is(err, ErrInvalidMove) {
+			t.Errorf("%s: got %v", ref, err)
+		}
+	}
+	for _, ref := range []string{"", "sales_order"} {
+		if err := checkReversible(ref); err != nil {
+			t.Errorf("%s: %v", ref, err)
+		}
+	}
+}
@@ -2,12 +2,16 @@ package service
 
 import (
 	"context"
-	"crypto/sha256"
-	"encoding/hex"
 	"encoding/json"
 	"errors"
 	"fmt"
+	"math/big"
+	"slices"
+	"strings"
+	"time"
 
+	"erpwms/backend-go/internal/common/idempotency"
+	"erpwms/backend-go/internal/common/qty"
 	"erpwms/backend-go/internal/db/sqlcgen"
 	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5"
@@ -20,12 +24,53 @@ type StockService struct {
 	Queries *sqlcgen.Queries
 }
 
+// Move types recorded in stock_ledger.move_type. Receipts have no source,
+// issues no destination and adjustments touch a single location. Status moves
+// keep stock where it is and change its status.
+const (
+	MoveReceipt    = "receipt"
+	MoveIssue      = "issue"
+	MoveAdjustment = "adjustment"
+	MoveTransfer   = "transfer"
+	MoveStatus     = "status"
+)
+
+// moveTopics maps each move type to its outbox topic and audit action.
+var moveTopics = map[string][2]string{
+	MoveReceipt:    {"stock.received", "stock.receive"},
+	MoveIssue:      {"stock.issued", "stock.issue"},
+	MoveAdjustment: {"stock.adjusted", "stock.adjust"},
+	MoveTransfer:   {"stock.moved", "stock.move"},
+	MoveStatus:     {"stock.status_changed", "stock.status_change"},
+}
+
+// MoveRequest is the body of every stock booking endpoint. Receipts set only
+// ToLocationID, issues only FromLocationID, and adjustments set LocationID
+// with a signed Qty. Qty is in Uom, or the item's base unit when Uom is empty.
+// Status is the stock status moved (available when empty); status changes set
+// LocationID and ToStatus. HuID is the handling unit the stock is taken from
+// (or adjusted in) and ToHuID the one it is put into; loose stock has neither.
 type MoveRequest struct {
 	ItemID         string `json:"item_id"`
 	Qty            string `json:"qty"`
 	FromLocationID string `json:"from_location_id"`
 	ToLocationID   string `json:"to_location_id"`
 	ReasonCode     string `json:"reason_code"`
+	LotCode        string `json:"lot_code,omitempty"`
+	SerialNo       string `json:"serial_no,omitempty"`
+	LocationID     string `json:"location_id,omitempty"`
+	ExpiresOn      string `json:"expires_on,omitempty"`
+	Uom            string `json:"uom,omitempty"`
+	Comment        string `json:"comment,omitempty"`
+	RefType        string `json:"ref_type,omitempty"`
+	RefID          string `json:"ref_id,omitempty"`
+	Status         string `json:"status,omitempty"`
+	ToStatus       string `json:"to_status,omitempty"`
+	HuID           string `json:"hu_id,omitempty"`
+	ToHuID         string `json:"to_hu_id,omitempty"`
+	// OverrideCapacity books past the destination's limits; it needs
+	// PermCapacityOverride.
+	OverrideCapacity bool `json:"override_capacity,omitempty"`
 }
 
 type MoveResponse struct {
@@ -33,43 +78,181 @@ type MoveResponse struct {
 	Status string `json:"status"`
 }
 
-func (s StockService) MoveStock(ctx context.Context, req MoveRequest, actor uuid.UUID, endpoint, idemKey string) (MoveResponse, error) {
-	reqHash, _ := hashReq(req)
-	existing, err := s.Queries.GetIdempotency(ctx, sqlcgen.GetIdempotencyParams{Key: idemKey, Endpoint: endpoint})
-	if err == nil {
-		if existing.RequestHash != reqHash {
-			return MoveResponse{}, errors.New("idempotency conflict")
-		}
-		var r MoveResponse
-		_ = json.Unmarshal(existing.ResponseJson, &r)
-		return r, nil
-	}
-	if !errors.Is(err, pgx.ErrNoRows) {
-		return MoveResponse{}, err
+var (
+	ErrNotFound            = errors.New("not found")
+	ErrIdempotencyConflict = idempotency.ErrConflict
+	ErrForbidden           = errors.New("forbidden")
+)
+
+// ErrInvalidMove wraps request problems the caller can fix, such as a missing
+// lot code on a lot-tracked item.
+var ErrInvalidMove = errors.New("invalid move")
+
+// stockKey identifies a stock_balance row apart from its location and status.
+// LotID and SerialID stay NULL for untracked items.
+type stockKey struct {
+	ItemID   pgtype.UUID
+	LotID    pgtype.UUID
+	SerialID pgtype.UUID
+}
+
+// InsufficientStockError is returned when a move would take more than is free
+// (on hand minus allocated) at the source location.
+type InsufficientStockError struct {
+	ItemID     string `json:"item_id"`
+	LocationID string `json:"location_id"`
+	Available  string `json:"available"`
+	Requested  string `json:"requested"`
+}
+
+func (e *InsufficientStockError) Error() string {
+	return fmt.Sprintf("insufficient stock: %s available, %s requested", e.Available, e.Requested)
+}
+
+// posting is one ledger line together with the balance changes it implies.
+type posting struct {
+	MoveType   string
+	ItemID     pgtype.UUID
+	Qty        *big.Rat
+	From       pgtype.UUID
+	To         pgtype.UUID
+	ReasonCode string
+	Comment    string
+	RefType    string
+	RefID      string
+	LotCode    string
+	SerialNo   string
+	ExpiresOn  pgtype.Date
+	ActorID    pgtype.UUID
+	Status     string
+	ToStatus   string
+	FromHU     pgtype.UUID
+	ToHU       pgtype.UUID
+	// OverrideCapacity lets checkCapacity pass an overfull destination.
+	OverrideCapacity bool
+}
+
+// toStatus is the status the stock has at the destination.
+func (p posting) toStatus() string {
+	if p.ToStatus != "" {
+		return p.ToStatus
 	}
+	return p.Status
+}
 
-	itemID, err := scanUUID(req.ItemID)
-	if err != nil {
-		return MoveResponse{}, err
+// MoveStock transfers stock between two locations.
+func (s StockService) MoveStock(ctx context.Context, req MoveRequest, actor uuid.UUID, endpoint, idemKey string) (MoveResponse, error) {
+	return s.book(ctx, MoveTransfer, req, actor, endpoint, idemKey)
+}
+
+// Receive books stock into a location from outside the warehouse. Unknown lot
+// codes and serial numbers are registered on the way in.
+func (s StockService) Receive(ctx context.Context, req MoveRequest, actor uuid.UUID, endpoint, idemKey string) (MoveResponse, error) {
+	return s.book(ctx, MoveReceipt, req, actor, endpoint, idemKey)
+}
+
+// Issue books stock out of a location, e.g. for consumption or scrap.
+func (s StockService) Issue(ctx context.Context, req MoveRequest, actor uuid.UUID, endpoint, idemKey string) (MoveResponse, error) {
+	return s.book(ctx, MoveIssue, req, actor, endpoint, idemKey)
+}
+
+// Adjust corrects the quantity at one location by a signed delta. The ledger
+// keeps qty positive and records the direction through from/to.
+func (s StockService) Adjust(ctx context.Context, req MoveRequest, actor uuid.UUID, endpoint, idemKey string) (MoveResponse, error) {
+	return s.book(ctx, MoveAdjustment, req, actor, endpoint, idemKey)
+}
+
+// PostMove books one move inside the caller's transaction, for modules whose
+// own documents drive stock (goods receipts, picks, ...). The request is
+// validated and converted like on the booking endpoints, but the caller may
+// use its own reserved ref_type; outbox and audit are left to the caller. The
+// returned quantity is in the item's base unit.
+func (s StockService) PostMove(ctx context.Context, q *sqlcgen.Queries, moveType string, req MoveRequest, actor uuid.UUID) (sqlcgen.StockLedger, *big.Rat, error) {
+	if err := checkSides(moveType, req); err != nil {
+		return sqlcgen.StockLedger{}, nil, err
 	}
-	fromID, err := scanUUID(req.FromLocationID)
+	p, err := parsePosting(moveType, req, pgUUID(actor))
 	if err != nil {
-		return MoveResponse{}, err
+		return sqlcgen.StockLedger{}, nil, err
 	}
-	toID, err := scanUUID(req.ToLocationID)
+	if p.Qty, _, err = toBaseQty(ctx, q, p.ItemID, p.Qty, req.Uom); err != nil {
+		return sqlcgen.StockLedger{}, nil, err
+	}
+	move, err := s.post(ctx, q, p)
 	if err != nil {
-		return MoveResponse{}, err
+		return sqlcgen.StockLedger{}, nil, err
 	}
-	qty, err := scanNumeric(req.Qty)
+	return move, p.Qty, nil
+}
+
+// BaseQty parses amount in uom (the item's base unit when empty) and converts
+// it to the base unit with the same precision rules as a booking.
+func (s StockService) BaseQty(ctx context.Context, q *sqlcgen.Queries, itemID uuid.UUID, amount, uom string) (*big.Rat, error) {
+	v, err := qty.Parse(amount)
 	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidMove, err)
+	}
+	base, _, err := toBaseQty(ctx, q, pgUUID(itemID), v, uom)
+	return base, err
+}
+
+// checkSides enforces which locations a move type may set: receipts have no
+// source, issues no destination and adjustments and status changes use
+// LocationID alone. Only status changes take a to_status, and only moves with
+// a destination side of their own take a to_hu_id.
+func checkSides(moveType string, req MoveRequest) error {
+	from, to, loc := req.FromLocationID != "", req.ToLocationID != "", req.LocationID != ""
+	if moveType != MoveStatus && req.ToStatus != "" {
+		return fmt.Errorf("%w: to_status is only for status changes", ErrInvalidMove)
+	}
+	if moveType == MoveReceipt && req.HuID != "" {
+		return fmt.Errorf("%w: receipts take to_hu_id, not hu_id", ErrInvalidMove)
+	}
+	if req.ToHuID != "" && moveType != MoveReceipt && moveType != MoveTransfer {
+		return fmt.Errorf("%w: to_hu_id is only for receipts and transfers", ErrInvalidMove)
+	}
+	switch moveType {
+	case MoveTransfer:
+		if !from || !to || loc {
+			return fmt.Errorf("%w: transfers need from_location_id and to_location_id", ErrInvalidMove)
+		}
+	case MoveReceipt:
+		if from || !to || loc {
+			return fmt.Errorf("%w: receipts need to_location_id only", ErrInvalidMove)
+		}
+	case MoveIssue:
+		if !from || to || loc {
+			return fmt.Errorf("%w: issues need from_location_id only", ErrInvalidMove)
+		}
+	case MoveAdjustment:
+		if !loc || from || to {
+			return fmt.Errorf("%w: adjustments need location_id only", ErrInvalidMove)
+		}
+	case MoveStatus:
+		if !loc || from || to || req.ToStatus == "" {
+			return fmt.Errorf("%w: status changes need location_id and to_status", ErrInvalidMove)
+		}
+	default:
+		return fmt.Errorf("%w: unknown move type %q", ErrInvalidMove, moveType)
+	}
+	return nil
+}
+
+func (s StockService) book(ctx context.Context, moveType string, req MoveRequest, actor uuid.UUID, endpoint, idemKey string) (MoveResponse, error) {
+	reqHash := hashReq(req)
+	var prev MoveResponse
+	if found, err := s.replay(ctx, endpoint, idemKey, reqHash, &prev); err != nil || found {
+		return prev, err
+	}
+	if err := checkSides(moveType, req); err != nil {
 		return MoveResponse{}, err
 	}
-	negQty, err := scanNumeric("-" + req.Qty)
+
+	actorID, _ := scanUUID(actor.String())
+	p, err := newPosting(moveType, req, actorID)
 	if err != nil {
 		return MoveResponse{}, err
 	}
-	actorID, _ := scanUUID(actor.String())
-	requestID, _ := ctx.Value("request_id").(string)
 
 	tx, err := s.DB.Begin(ctx)
 	if err != nil {
@@ -78,26 +261,30 @@ func (s StockService) MoveStock(ctx context.Context, req MoveRequest, actor uuid
 	defer tx.Rollback(ctx)
 	q := s.Queries.WithTx(tx)
 
-	move, err := q.InsertStockLedgerMove(ctx, sqlcgen.InsertStockLedgerMoveParams{ItemID: itemID, Qty: qty, FromLocationID: fromID, ToLocationID: toID, ReasonCode: req.ReasonCode, ActorUserID: actorID, RequestID: txt(requestID)})
-	if err != nil {
-		return MoveResponse{}, err
-	}
-	if err := q.UpsertStockBalanceDelta(ctx, sqlcgen.UpsertStockBalanceDeltaParams{ItemID: itemID, LocationID: fromID, QtyOnHand: negQty, QtyAllocated: mustNumeric("0")}); err != nil {
+	var baseUom string
+	if p.Qty, baseUom, err = toBaseQty(ctx, q, p.ItemID, p.Qty, req.Uom); err != nil {
 		return MoveResponse{}, err
 	}
-	if err := q.UpsertStockBalanceDelta(ctx, sqlcgen.UpsertStockBalanceDeltaParams{ItemID: itemID, LocationID: toID, QtyOnHand: qty, QtyAllocated: mustNumeric("0")}); err != nil {
+	move, err := s.post(ctx, q, p)
+	if err != nil {
 		return MoveResponse{}, err
 	}
-
-	payload, _ := json.Marshal(map[string]any{"move_id": move.MoveID.String(), "item_id": req.ItemID, "qty": req.Qty})
-	if _, err := q.InsertOutboxEvent(ctx, sqlcgen.InsertOutboxEventParams{Topic: "stock.moved", Payload: payload}); err != nil {
+	payload, _ := json.Marshal(map[string]any{
+		"move_id": move.MoveID.String(), "move_type": moveType, "item_id": req.ItemID, "qty": qty.String(p.Qty), "uom": baseUom,
+		"from_location_id": req.FromLocationID, "to_location_id": req.ToLocationID, "location_id": req.LocationID,
+		"lot_code": req.LotCode, "serial_no": req.SerialNo, "status": p.Status, "to_status": p.ToStatus,
+		"reason_code": p.ReasonCode, "comment": p.Comment, "hu_id": req.HuID, "to_hu_id": req.ToHuID,
+		"capacity_override": req.OverrideCapacity,
+	})
+	topics := moveTopics[moveType]
+	if _, err := q.InsertOutboxEvent(ctx, sqlcgen.InsertOutboxEventParams{Topic: topics[0], Payload: payload}); err != nil {
 		return MoveResponse{}, err
 	}
-	_ = q.InsertAuditLog(ctx, sqlcgen.InsertAuditLogParams{ActorUserID: actorID, ActorType: "user", Action: "stock.move", Resource: "stock_ledger", ResourceID: txt(move.MoveID.String()), Status: "ok", RequestID: txt(requestID), Metadata: payload})
+	requestID, _ := ctx.Value("request_id").(string)
+	_ = q.InsertAuditLog(ctx, sqlcgen.InsertAuditLogParams{ActorUserID: p.ActorID, ActorType: "user", Action: topics[1], Resource: "stock_ledger", ResourceID: txt(move.MoveID.String()), Status: "ok", RequestID: txt(requestID), Metadata: payload})
 
 	resp := MoveResponse{MoveID: move.MoveID.String(), Status: "ok"}
-	respJSON, _ := json.Marshal(resp)
-	if err := q.InsertIdempotency(ctx, sqlcgen.InsertIdempotencyParams{Key: idemKey, Endpoint: endpoint, ActorUserID: actorID, RequestHash: reqHash, ResponseJson: respJSON}); err != nil {
+	if err := remember(ctx, q, endpoint, idemKey, p.ActorID, reqHash, resp); err != nil {
 		return MoveResponse{}, err
 	}
 	if err := tx.Commit(ctx); err != nil {
@@ -106,15 +293,405 @@ func (s StockService) MoveStock(ctx context.Context, req MoveRequest, actor uuid
 	return resp, nil
 }
 
-func hashReq(v any) (string, error) {
-	b, err := json.Marshal(v)
+// newPosting validates a request from the generic move endpoints and turns
+// it into a posting. Their ref_type may not be one of reservedRefTypes.
+func newPosting(moveType string, req MoveRequest, actorID pgtype.UUID) (posting, error) {
+	if err := checkRefType(req.RefType); err != nil {
+		return posting{}, err
+	}
+	return parsePosting(moveType, req, actorID)
+}
+
+// parsePosting validates the shape of a request and turns it into a posting.
+// Quantities are still in the request's unit; see toBaseQty.
+func parsePosting(moveType string, req MoveRequest, actorID pgtype.UUID) (posting, error) {
+	p := posting{
+		MoveType: moveType, ReasonCode: req.ReasonCode, Comment: req.Comment, RefType: req.RefType, RefID: req.RefID,
+		LotCode: req.LotCode, SerialNo: req.SerialNo, ActorID: actorID, Status: req.Status, ToStatus: req.ToStatus,
+		OverrideCapacity: req.OverrideCapacity,
+	}
+	if p.Status == "" {
+		p.Status = StatusAvailable
+	}
+	if err := checkStatus(p); err != nil {
+		return p, err
+	}
+	var err error
+	if p.ItemID, err = scanUUID(req.ItemID); err != nil {
+		return p, fmt.Errorf("%w: %v", ErrInvalidMove, err)
+	}
+	if p.Qty, err = qty.Parse(req.Qty); err != nil {
+		return p, fmt.Errorf("%w: %v", ErrInvalidMove, err)
+	}
+	if req.HuID != "" {
+		if p.FromHU, err = scanUUID(req.HuID); err != nil {
+			return p, fmt.Errorf("%w: %v", ErrInvalidMove, err)
+		}
+	}
+	if req.ToHuID != "" {
+		if p.ToHU, err = scanUUID(req.ToHuID); err != nil {
+			return p, fmt.Errorf("%w: %v", ErrInvalidMove, err)
+		}
+	}
+	switch moveType {
+	case MoveStatus:
+		loc, err := scanUUID(req.LocationID)
+		if err != nil {
+			return p, fmt.Errorf("%w: %v", ErrInvalidMove, err)
+		}
+		p.From, p.To = loc, loc
+		p.ToHU = p.FromHU
+	case MoveAdjustment:
+		loc, err := scanUUID(req.LocationID)
+		if err != nil {
+			return p, fmt.Errorf("%w: %v", ErrInvalidMove, err)
+		}
+		if p.Qty.Sign() < 0 {
+			p.From, p.Qty = loc, qty.Neg(p.Qty)
+		} else {
+			p.To, p.ToHU, p.FromHU = loc, p.FromHU, pgtype.UUID{}
+		}
+	default:
+		if req.FromLocationID != "" {
+			if p.From, err = scanUUID(req.FromLocationID); err != nil {
+				return p, fmt.Errorf("%w: %v", ErrInvalidMove, err)
+			}
+		}
+		if req.ToLocationID != "" {
+			if p.To, err = scanUUID(req.ToLocationID); err != nil {
+				return p, fmt.Errorf("%w: %v", ErrInvalidMove, err)
+			}
+		}
+	}
+	if p.Qty.Sign() <= 0 {
+		return p, fmt.Errorf("%w: qty must not be zero", ErrInvalidMove)
+	}
+	if req.ExpiresOn != "" {
+		t, err := time.Parse("2006-01-02", req.ExpiresOn)
+		if err != nil {
+			return p, fmt.Errorf("%w: expires_on must be YYYY-MM-DD", ErrInvalidMove)
+		}
+		p.ExpiresOn = pgtype.Date{Time: t, Valid: true}
+	}
+	return p, nil
+}
+
+// post writes one ledger line and the matching balance deltas inside the
+// caller's transaction. Outbox and audit are left to the caller.
+func (s StockService) post(ctx context.Context, q *sqlcgen.Queries, p posting) (sqlcgen.StockLedger, error) {
+	requestID, _ := ctx.Value("request_id").(string)
+	if err := checkReason(ctx, q, p); err != nil {
+		return sqlcgen.StockLedger{}, err
+	}
+	if err := checkFrozen(ctx, q, p); err != nil {
+		return sqlcgen.StockLedger{}, err
+	}
+	if err := checkTransit(ctx, q, p); err != nil {
+		return sqlcgen.StockLedger{}, err
+	}
+	if err := checkHandlingUnits(ctx, q, p); err != nil {
+		return sqlcgen.StockLedger{}, err
+	}
+	key, serial, err := resolveTracking(ctx, q, p)
 	if err != nil {
-		return "", err
+		return sqlcgen.StockLedger{}, err
+	}
+	if err := checkCapacity(ctx, q, p, key); err != nil {
+		return sqlcgen.StockLedger{}, err
+	}
+	if p.From.Valid {
+		if err := ensureAvailable(ctx, q, key, p.From, p.FromHU, p.Status, p.Qty); err != nil {
+			return sqlcgen.StockLedger{}, err
+		}
 	}
-	h := sha256.Sum256(b)
-	return hex.EncodeToString(h[:]), nil
+	move, err := q.InsertStockLedgerMove(ctx, sqlcgen.InsertStockLedgerMoveParams{
+		ItemID: p.ItemID, Qty: qty.ToNumeric(p.Qty), FromLocationID: p.From, ToLocationID: p.To, ReasonCode: p.ReasonCode,
+		RefType: txt(p.RefType), RefID: txt(p.RefID), ActorUserID: p.ActorID, RequestID: txt(requestID),
+		LotID: key.LotID, SerialID: key.SerialID, MoveType: p.MoveType, Comment: txt(p.Comment),
+		Status: p.Status, ToStatus: txt(p.ToStatus), FromHuID: p.FromHU, ToHuID: p.ToHU,
+	})
+	if err != nil {
+		return sqlcgen.StockLedger{}, err
+	}
+	// Source before destination: a serial must leave its bin before
+	// uq_stock_balance_serial_on_hand lets it appear in the next one.
+	if p.From.Valid {
+		if err := q.UpsertStockBalanceDelta(ctx, sqlcgen.UpsertStockBalanceDeltaParams{ItemID: p.ItemID, LocationID: p.From, LotID: key.LotID, SerialID: key.SerialID, Status: p.Status, HuID: p.FromHU, QtyOnHand: qty.ToNumeric(qty.Neg(p.Qty)), QtyAllocated: mustNumeric("0")}); err != nil {
+			return sqlcgen.StockLedger{}, err
+		}
+	}
+	if p.To.Valid {
+		if err := q.UpsertStockBalanceDelta(ctx, sqlcgen.UpsertStockBalanceDeltaParams{ItemID: p.ItemID, LocationID: p.To, LotID: key.LotID, SerialID: key.SerialID, Status: p.toStatus(), HuID: p.ToHU, QtyOnHand: qty.ToNumeric(p.Qty), QtyAllocated: mustNumeric("0")}); err != nil {
+			return sqlcgen.StockLedger{}, err
+		}
+	}
+	if serial != nil {
+		if err := q.SetSerialLocation(ctx, sqlcgen.SetSerialLocationParams{ID: serial.ID, LocationID: p.To}); err != nil {
+			return sqlcgen.StockLedger{}, err
+		}
+	}
+	return move, nil
+}
+
+// ensureAvailable locks the source balance row of the given handling unit and
+// status for the rest of the transaction, so concurrent moves out of the same
+// bin serialize, and checks that want can leave it. Warehouses or location
+// types flagged with allow_negative_stock skip the check.
+func ensureAvailable(ctx context.Context, q *sqlcgen.Queries, key stockKey, locationID, huID pgtype.UUID, status string, want *big.Rat) error {
+	free := qty.Zero()
+	bal, err := q.LockStockBalance(ctx, sqlcgen.LockStockBalanceParams{ItemID: key.ItemID, LocationID: locationID, LotID: key.LotID, SerialID: key.SerialID, Status: status, HuID: huID})
+	switch {
+	case err == nil:
+		free = qty.Sub(qty.FromNumeric(bal.QtyOnHand), qty.FromNumeric(bal.QtyAllocated))
+	case !errors.Is(err, pgx.ErrNoRows):
+		return err
+	}
+	if free.Cmp(want) >= 0 {
+		return nil
+	}
+	allowed, err := q.AllowsNegativeStock(ctx, locationID)
+	if err != nil {
+		return err
+	}
+	if allowed {
+		return nil
+	}
+	return &InsufficientStockError{ItemID: key.ItemID.String(), LocationID: locationID.String(), Available: qty.String(free), Requested: qty.String(want)}
+}
+
+// checkReason enforces the reason_codes catalog: the code must be active and
+// allowed for the move type, carry a comment or reference when the code
+// demands one, and the actor must hold the code's permission if it has one.
+func checkReason(ctx context.Context, q *sqlcgen.Queries, p posting) error {
+	rc, err := q.GetReasonCode(ctx, p.ReasonCode)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return fmt.Errorf("%w: unknown reason_code %q", ErrInvalidMove, p.ReasonCode)
+	}
+	if err != nil {
+		return err
+	}
+	if !rc.Active {
+		return fmt.Errorf("%w: reason_code %s is inactive", ErrInvalidMove, rc.Code)
+	}
+	if !slices.Contains(rc.MoveTypes, p.MoveType) {
+		return fmt.Errorf("%w: reason_code %s is not allowed for %s", ErrInvalidMove, rc.Code, p.MoveType)
+	}
+	if rc.RequiresComment && strings.TrimSpace(p.Comment) == "" {
+		return fmt.Errorf("%w: reason_code %s requires a comment", ErrInvalidMove, rc.Code)
+	}
+	if rc.RequiresReference && (p.RefType == "" || p.RefID == "") {
+		return fmt.Errorf("%w: reason_code %s requires ref_type and ref_id", ErrInvalidMove, rc.Code)
+	}
+	if rc.Permission.Valid {
+		perms, err := q.ListPermissionsByUserID(ctx, p.ActorID)
+		if err != nil {
+			return err
+		}
+		if !slices.Contains(perms, rc.Permission.String) {
+			return fmt.Errorf("%w: reason_code %s requires %s", ErrForbidden, rc.Code, rc.Permission.String)
+		}
+	}
+	return nil
+}
+
+// checkFrozen rejects moves touching a location frozen by a count session;
+// only that session's own adjustments (ref_type count, ref_id the session)
+// pass.
+func checkFrozen(ctx context.Context, q *sqlcgen.Queries, p posting) error {
+	var ids []pgtype.UUID
+	for _, id := range []pgtype.UUID{p.From, p.To} {
+		if id.Valid {
+			ids = append(ids, id)
+		}
+	}
+	rows, err := q.ListFrozenLocations(ctx, ids)
+	if err != nil {
+		return err
+	}
+	for _, r := range rows {
+		if p.RefType == RefCount && p.RefID == r.FrozenByCountID.String() {
+			continue
+		}
+		return fmt.Errorf("%w: location %s is frozen by count %s", ErrInvalidMove, r.Code, r.FrozenByCountID.String())
+	}
+	return nil
 }
 
+// checkTransit keeps in-transit locations for transfer order legs: any other
+// move into or out of one is rejected.
+func checkTransit(ctx context.Context, q *sqlcgen.Queries, p posting) error {
+	if p.RefType == RefTransfer {
+		return nil
+	}
+	var ids []pgtype.UUID
+	for _, id := range []pgtype.UUID{p.From, p.To} {
+		if id.Valid {
+			ids = append(ids, id)
+		}
+	}
+	codes, err := q.ListTransitLocations(ctx, ids)
+	if err != nil {
+		return err
+	}
+	if len(codes) > 0 {
+		return fmt.Errorf("%w: location %s is in transit; use a transfer order", ErrInvalidMove, codes[0])
+	}
+	return nil
+}
+
+// checkHandlingUnits requires the handling units a posting takes from or puts
+// into to sit at that side's location. A unit that is both source and
+// destination of a move between two locations is being carried along; only
+// handling unit moves (ref_type handling_unit) may do that, since they move
+// the unit itself and all of its content.
+func checkHandlingUnits(ctx context.Context, q *sqlcgen.Queries, p posting) error {
+	var ids []pgtype.UUID
+	for _, id := range []pgtype.UUID{p.FromHU, p.ToHU} {
+		if id.Valid {
+			ids = append(ids, id)
+		}
+	}
+	if len(ids) == 0 {
+		return nil
+	}
+	rows, err := q.ListHandlingUnitLocations(ctx, ids)
+	if err != nil {
+		return err
+	}
+	hus := make(map[pgtype.UUID]sqlcgen.ListHandlingUnitLocationsRow, len(rows))
+	for _, r := range rows {
+		hus[r.ID] = r
+	}
+	for _, id := range ids {
+		if _, ok := hus[id]; !ok {
+			return fmt.Errorf("%w: unknown handling unit %s", ErrInvalidMove, id.String())
+		}
+	}
+	if p.FromHU.Valid && hus[p.FromHU].LocationID != p.From {
+		return fmt.Errorf("%w: handling unit %s is not at the source location", ErrInvalidMove, hus[p.FromHU].Code)
+	}
+	if !p.ToHU.Valid {
+		return nil
+	}
+	if p.ToHU == p.FromHU && p.From != p.To {
+		if p.RefType != RefHandlingUnit {
+			return fmt.Errorf("%w: handling unit %s moves as a whole; use a handling unit move", ErrInvalidMove, hus[p.ToHU].Code)
+		}
+		return nil
+	}
+	if hus[p.ToHU].LocationID != p.To {
+		return fmt.Errorf("%w: handling unit %s is not at the destination location", ErrInvalidMove, hus[p.ToHU].Code)
+	}
+	return nil
+}
+
+// toBaseQty converts amount from uom (the base unit when empty) into the
+// item's base unit. Both the entered and the converted quantity must fit the
+// decimal places of their unit, so 0.333 EA fails while 0.333 KG passes.
+func toBaseQty(ctx context.Context, q *sqlcgen.Queries, itemID pgtype.UUID, amount *big.Rat, uom string) (*big.Rat, string, error) {
+	conv, err := q.GetUomConversion(ctx, sqlcgen.GetUomConversionParams{Uom: uom, ItemID: itemID})
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, "", fmt.Errorf("%w: unknown item or uom %q", ErrInvalidMove, uom)
+	}
+	if err != nil {
+		return nil, "", err
+	}
+	if !conv.Factor.Valid {
+		return nil, "", fmt.Errorf("%w: no conversion from %s to %s for this item", ErrInvalidMove, uom, conv.BaseUom)
+	}
+	if d, ok := qty.Decimals(amount); !ok || d > int(conv.UomDecimals) {
+		return nil, "", fmt.Errorf("%w: %s allows %d decimal places", ErrInvalidMove, uomOr(uom, conv.BaseUom), conv.UomDecimals)
+	}
+	base := qty.Mul(amount, qty.FromNumeric(conv.Factor))
+	if d, ok := qty.Decimals(base); !ok || d > int(conv.BaseDecimals) {
+		return nil, "", fmt.Errorf("%w: %s %s is %s %s, which allows %d decimal places", ErrInvalidMove, qty.String(amount), uomOr(uom, conv.BaseUom), qty.String(base), conv.BaseUom, conv.BaseDecimals)
+	}
+	return base, conv.BaseUom, nil
+}
+
+func uomOr(uom, base string) string {
+	if uom == "" {
+		return base
+	}
+	return uom
+}
+
+// resolveTracking turns the lot code or serial number of a posting into a
+// balance key according to the item's tracking mode. Serials move one unit at
+// a time and must currently sit at p.From (nowhere, for stock coming in); the
+// locked serial row is returned so the caller can record its new location.
+// Postings without a source may introduce lots and serials not seen before.
+func resolveTracking(ctx context.Context, q *sqlcgen.Queries, p posting) (stockKey, *sqlcgen.Serial, error) {
+	key := stockKey{ItemID: p.ItemID}
+	inbound := !p.From.Valid
+	item, err := q.GetItem(ctx, p.ItemID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return key, nil, fmt.Errorf("%w: unknown item", ErrInvalidMove)
+	}
+	if err != nil {
+		return key, nil, err
+	}
+	switch item.TrackingMode {
+	case "lot":
+		if p.LotCode == "" || p.SerialNo != "" {
+			return key, nil, fmt.Errorf("%w: item %s is lot tracked, lot_code required", ErrInvalidMove, item.Sku)
+		}
+		lot, err := q.GetLotByCode(ctx, sqlcgen.GetLotByCodeParams{ItemID: p.ItemID, LotCode: p.LotCode})
+		if errors.Is(err, pgx.ErrNoRows) && inbound {
+			lot, err = q.CreateLot(ctx, sqlcgen.CreateLotParams{ItemID: p.ItemID, LotCode: p.LotCode, ExpiresOn: p.ExpiresOn})
+		}
+		if errors.Is(err, pgx.ErrNoRows) {
+			return key, nil, fmt.Errorf("%w: unknown lot %q", ErrInvalidMove, p.LotCode)
+		}
+		if err != nil {
+			return key, nil, err
+		}
+		key.LotID = lot.ID
+		return key, nil, nil
+	case "serial":
+		if p.SerialNo == "" {
+			return key, nil, fmt.Errorf("%w: item %s is serial tracked, serial_no required", ErrInvalidMove, item.Sku)
+		}
+		if p.Qty.Cmp(big.NewRat(1, 1)) != 0 {
+			return key, nil, fmt.Errorf("%w: serial tracked moves carry qty 1", ErrInvalidMove)
+		}
+		serial, err := q.LockSerialByNo(ctx, sqlcgen.LockSerialByNoParams{ItemID: p.ItemID, SerialNo: p.SerialNo})
+		if errors.Is(err, pgx.ErrNoRows) && inbound {
+			serial, err = q.CreateSerial(ctx, sqlcgen.CreateSerialParams{ItemID: p.ItemID, SerialNo: p.SerialNo})
+		}
+		if errors.Is(err, pgx.ErrNoRows) {
+			return key, nil, fmt.Errorf("%w: unknown serial %q", ErrInvalidMove, p.SerialNo)
+		}
+		if err != nil {
+			return key, nil, err
+		}
+		if serial.LocationID != p.From {
+			if inbound {
+				return key, nil, fmt.Errorf("%w: serial %q is already on hand", ErrInvalidMove, p.SerialNo)
+			}
+			return key, nil, fmt.Errorf("%w: serial %q is not at the source location", ErrInvalidMove, p.SerialNo)
+		}
+		key.LotID = serial.LotID
+		key.SerialID = serial.ID
+		return key, &serial, nil
+	default:
+		if p.LotCode != "" || p.SerialNo != "" {
+			return key, nil, fmt.Errorf("%w: item %s is not lot or serial tracked", ErrInvalidMove, item.Sku)
+		}
+		return key, nil, nil
+	}
+}
+
+func (s StockService) replay(ctx context.Context, endpoint, key, reqHash string, out any) (bool, error) {
+	return idempotency.Replay(ctx, s.Queries, endpoint, key, reqHash, out)
+}
+
+func remember(ctx context.Context, q *sqlcgen.Queries, endpoint, key string, actorID pgtype.UUID, reqHash string, resp any) error {
+	return idempotency.Remember(ctx, q, endpoint, key, actorID, reqHash, resp)
+}
+
+func hashReq(v any) string { return idempotency.Hash(v) }
+
 func scanUUID(v string) (pgtype.UUID, error) {
 	var u pgtype.UUID
 	if err := u.Scan(v); err != nil {
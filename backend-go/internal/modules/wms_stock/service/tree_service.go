@@ -0,0 +1,114 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"erpwms/backend-go/internal/common/locpath"
+	"erpwms/backend-go/internal/common/qty"
+	"erpwms/backend-go/internal/db/sqlcgen"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// TreeFilter selects the children of Path (the top level when empty) in a
+// warehouse's location tree. ItemID or Sku narrows the stock totals to one
+// item.
+type TreeFilter struct {
+	WarehouseID string
+	Path        string
+	ItemID      string
+	Sku         string
+}
+
+// TreeNode is one node of the location tree with the stock of every location
+// at or below it. LocationCodes lists the locations whose path ends at the
+// node, typically the bin itself.
+type TreeNode struct {
+	Path          string   `json:"path"`
+	Label         string   `json:"label"`
+	Depth         int      `json:"depth"`
+	Level         string   `json:"level,omitempty"`
+	HasChildren   bool     `json:"has_children"`
+	Locations     int32    `json:"locations"`
+	LocationCodes []string `json:"location_codes"`
+	Items         int32    `json:"items"`
+	QtyOnHand     string   `json:"qty_on_hand"`
+	QtyAllocated  string   `json:"qty_allocated"`
+}
+
+// LocationTree lists the direct children of f.Path. Locations without a
+// path are not part of the tree.
+func (s StockService) LocationTree(ctx context.Context, f TreeFilter) ([]TreeNode, error) {
+	wh, path, itemID, err := treeScope(f)
+	if err != nil {
+		return nil, err
+	}
+	depth := locpath.Depth(path)
+	rows, err := s.Queries.ListLocationTreeNodes(ctx, sqlcgen.ListLocationTreeNodesParams{Depth: int32(depth), WarehouseID: wh, Parent: path, ItemID: itemID, Sku: f.Sku})
+	if err != nil {
+		return nil, err
+	}
+	out := make([]TreeNode, 0, len(rows))
+	for _, r := range rows {
+		n := TreeNode{
+			Path: r.Path, Label: r.Path[strings.LastIndexByte(r.Path, '.')+1:], Depth: depth + 1, Level: locpath.Level(depth + 1), HasChildren: r.HasChildren,
+			Locations: r.Locations, LocationCodes: r.LocationCodes, Items: r.Items,
+			QtyOnHand: qty.String(qty.FromNumeric(r.QtyOnHand)), QtyAllocated: qty.String(qty.FromNumeric(r.QtyAllocated)),
+		}
+		out = append(out, n)
+	}
+	return out, nil
+}
+
+// RollupLine is the stock of one item and status at or below a tree node.
+type RollupLine struct {
+	ItemID       string `json:"item_id"`
+	Sku          string `json:"sku"`
+	ItemName     string `json:"item_name"`
+	Uom          string `json:"uom"`
+	Status       string `json:"status"`
+	QtyOnHand    string `json:"qty_on_hand"`
+	QtyAllocated string `json:"qty_allocated"`
+	Locations    int32  `json:"locations"`
+}
+
+// Rollup totals the stock per item and status in every location at or below
+// f.Path, or in the whole warehouse when it is empty.
+func (s StockService) Rollup(ctx context.Context, f TreeFilter) ([]RollupLine, error) {
+	wh, path, itemID, err := treeScope(f)
+	if err != nil {
+		return nil, err
+	}
+	rows, err := s.Queries.ListStockRollup(ctx, sqlcgen.ListStockRollupParams{WarehouseID: wh, Path: path, ItemID: itemID, Sku: f.Sku})
+	if err != nil {
+		return nil, err
+	}
+	out := make([]RollupLine, 0, len(rows))
+	for _, r := range rows {
+		out = append(out, RollupLine{
+			ItemID: r.ItemID.String(), Sku: r.Sku, ItemName: r.ItemName, Uom: r.Uom, Status: r.Status,
+			QtyOnHand: qty.String(qty.FromNumeric(r.QtyOnHand)), QtyAllocated: qty.String(qty.FromNumeric(r.QtyAllocated)), Locations: r.Locations,
+		})
+	}
+	return out, nil
+}
+
+// treeScope validates the filter into the warehouse, normalized path and
+// item it selects.
+func treeScope(f TreeFilter) (wh pgtype.UUID, path string, itemID pgtype.UUID, err error) {
+	wid, err := uuid.Parse(f.WarehouseID)
+	if err != nil {
+		return wh, "", itemID, fmt.Errorf("%w: warehouse_id is required", ErrInvalidMove)
+	}
+	if path, err = locpath.Normalize(f.Path); err != nil {
+		return wh, "", itemID, fmt.Errorf("%w: %v", ErrInvalidMove, err)
+	}
+	if f.ItemID != "" {
+		if itemID, err = scanUUID(f.ItemID); err != nil {
+			return wh, "", itemID, fmt.Errorf("%w: item_id: %v", ErrInvalidMove, err)
+		}
+	}
+	return pgUUID(wid), path, itemID, nil
+}
@@ -0,0 +1,643 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"time"
+
+	"erpwms/backend-go/internal/common/idempotency"
+	"erpwms/backend-go/internal/common/qty"
+	"erpwms/backend-go/internal/common/store"
+	"erpwms/backend-go/internal/db/sqlcgen"
+	stocksvc "erpwms/backend-go/internal/modules/wms_stock/service"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+var (
+	ErrNotFound = store.ErrNotFound
+	ErrConflict = store.ErrConflict
+	ErrInvalid  = store.ErrInvalid
+)
+
+// conflictFields names the field behind each unique constraint.
+var conflictFields = map[string]string{
+	"transfer_order_lines_order_id_line_no_key": "line number",
+}
+
+// Transfer order states. An order is in transit from its first shipment
+// until every line is received or it is closed; closing writes off what is
+// still in transit.
+const (
+	StatusOpen      = "open"
+	StatusInTransit = "in_transit"
+	StatusReceived  = "received"
+	StatusClosed    = "closed"
+	StatusCancelled = "cancelled"
+)
+
+// TransitLocationType is the type of the virtual location holding stock
+// between the two legs. Each destination warehouse has one, created on
+// first use with code TransitLocationCode.
+const (
+	TransitLocationType = "in_transit"
+	TransitLocationCode = "IN-TRANSIT"
+)
+
+// Ledger legs of a transfer order.
+const (
+	LegShip    = "ship"
+	LegReceive = "receive"
+	LegLoss    = "loss"
+)
+
+// TransferService moves stock between warehouses. Both legs and the write-off
+// are posted through Stock with ref_type transfer_order, the only moves
+// allowed in and out of an in-transit location.
+type TransferService struct {
+	DB      *pgxpool.Pool
+	Queries *sqlcgen.Queries
+	Stock   stocksvc.StockService
+}
+
+type TransferInput struct {
+	FromWarehouseID string              `json:"from_warehouse_id"`
+	ToWarehouseID   string              `json:"to_warehouse_id"`
+	Comment         string              `json:"comment,omitempty"`
+	Lines           []TransferLineInput `json:"lines"`
+}
+
+// TransferLineInput is a quantity in Uom or the item's base unit.
+type TransferLineInput struct {
+	ItemID string `json:"item_id"`
+	Qty    string `json:"qty"`
+	Uom    string `json:"uom,omitempty"`
+}
+
+type TransferOrder struct {
+	ID                string         `json:"id"`
+	TransferNo        int64          `json:"transfer_no"`
+	FromWarehouseID   string         `json:"from_warehouse_id"`
+	ToWarehouseID     string         `json:"to_warehouse_id"`
+	TransitLocationID string         `json:"transit_location_id"`
+	Status            string         `json:"status"`
+	Comment           string         `json:"comment,omitempty"`
+	HasDiscrepancy    bool           `json:"has_discrepancy"`
+	CreatedAt         time.Time      `json:"created_at"`
+	UpdatedAt         time.Time      `json:"updated_at"`
+	Lines             []TransferLine `json:"lines,omitempty"`
+	Moves             []TransferMove `json:"moves,omitempty"`
+}
+
+// TransferLine quantities are in the item's base unit. QtyLost is what was
+// still in transit when the order was closed.
+type TransferLine struct {
+	LineNo       int32  `json:"line_no"`
+	ItemID       string `json:"item_id"`
+	Qty          string `json:"qty"`
+	QtyShipped   string `json:"qty_shipped"`
+	QtyReceived  string `json:"qty_received"`
+	QtyLost      string `json:"qty_lost"`
+	QtyInTransit string `json:"qty_in_transit"`
+}
+
+// TransferMove is one ledger line booked for the order.
+type TransferMove struct {
+	MoveID         string    `json:"move_id"`
+	Leg            string    `json:"leg"`
+	LineNo         int32     `json:"line_no"`
+	Qty            string    `json:"qty"`
+	FromLocationID string    `json:"from_location_id,omitempty"`
+	ToLocationID   string    `json:"to_location_id,omitempty"`
+	LotCode        string    `json:"lot_code,omitempty"`
+	SerialNo       string    `json:"serial_no,omitempty"`
+	ActorUserID    string    `json:"actor_user_id,omitempty"`
+	Ts             time.Time `json:"ts"`
+}
+
+type TransferFilter struct {
+	FromWarehouseID string
+	ToWarehouseID   string
+	Status          string
+	Discrepancy     bool
+	Limit           int32
+	Offset          int32
+}
+
+// ShipRequest books stock out of source bins into transit. LocationID is a
+// bin of the source warehouse.
+type ShipRequest struct {
+	Lines []LegLine `json:"lines"`
+}
+
+// ReceiveRequest books stock out of transit into destination bins.
+// LocationID is a bin of the destination warehouse; LotCode and SerialNo
+// must match what was shipped.
+type ReceiveRequest struct {
+	Lines []LegLine `json:"lines"`
+}
+
+type LegLine struct {
+	LineNo     int32  `json:"line_no"`
+	Qty        string `json:"qty"`
+	Uom        string `json:"uom,omitempty"`
+	LocationID string `json:"location_id"`
+	LotCode    string `json:"lot_code,omitempty"`
+	SerialNo   string `json:"serial_no,omitempty"`
+}
+
+// CloseRequest needs a comment, which goes on the write-off moves.
+type CloseRequest struct {
+	Comment string `json:"comment"`
+}
+
+// LegResponse is the order after a leg plus the moves it booked.
+type LegResponse struct {
+	Order TransferOrder  `json:"order"`
+	Moves []TransferMove `json:"moves"`
+}
+
+// CreateTransferOrder creates the order and, on first use, the destination
+// warehouse's in-transit location.
+func (s TransferService) CreateTransferOrder(ctx context.Context, in TransferInput, actor uuid.UUID) (TransferOrder, error) {
+	if len(in.Lines) == 0 {
+		return TransferOrder{}, fmt.Errorf("%w: lines are required", ErrInvalid)
+	}
+	fromID, err := store.ParseUUID("from_warehouse_id", in.FromWarehouseID)
+	if err != nil {
+		return TransferOrder{}, err
+	}
+	toID, err := store.ParseUUID("to_warehouse_id", in.ToWarehouseID)
+	if err != nil {
+		return TransferOrder{}, err
+	}
+	if fromID == toID {
+		return TransferOrder{}, fmt.Errorf("%w: from and to warehouse must differ", ErrInvalid)
+	}
+	var out TransferOrder
+	err = s.mutate(ctx, actor, "transfer.created", func(q *sqlcgen.Queries) (string, any, error) {
+		transit, err := q.EnsureTransitLocation(ctx, store.UUID(toID))
+		if err != nil {
+			return "", nil, err
+		}
+		if transit.Type != TransitLocationType {
+			return "", nil, fmt.Errorf("%w: location %s of the destination warehouse is a %s location", ErrConflict, TransitLocationCode, transit.Type)
+		}
+		to, err := q.CreateTransferOrder(ctx, sqlcgen.CreateTransferOrderParams{
+			FromWarehouseID: store.UUID(fromID), ToWarehouseID: store.UUID(toID), TransitLocationID: transit.ID, Comment: store.Text(in.Comment), CreatedBy: store.UUID(actor),
+		})
+		if err != nil {
+			return "", nil, err
+		}
+		lines := make([]sqlcgen.TransferOrderLine, 0, len(in.Lines))
+		for i, l := range in.Lines {
+			itemID, err := store.ParseUUID("item_id", l.ItemID)
+			if err != nil {
+				return "", nil, err
+			}
+			base, err := s.Stock.BaseQty(ctx, q, itemID, l.Qty, l.Uom)
+			if err != nil {
+				return "", nil, fmt.Errorf("line %d: %w", i+1, err)
+			}
+			if base.Sign() <= 0 {
+				return "", nil, fmt.Errorf("%w: line %d: qty must be positive", ErrInvalid, i+1)
+			}
+			line, err := q.InsertTransferOrderLine(ctx, sqlcgen.InsertTransferOrderLineParams{OrderID: to.ID, LineNo: int32(i + 1), ItemID: store.UUID(itemID), Qty: qty.ToNumeric(base)})
+			if err != nil {
+				return "", nil, err
+			}
+			lines = append(lines, line)
+		}
+		out = toOrder(to, lines)
+		return out.ID, out, nil
+	})
+	return out, err
+}
+
+// GetTransferOrder returns the order with its lines and every move booked
+// for it.
+func (s TransferService) GetTransferOrder(ctx context.Context, id uuid.UUID) (TransferOrder, error) {
+	to, err := s.Queries.GetTransferOrder(ctx, store.UUID(id))
+	if err != nil {
+		return TransferOrder{}, store.MapErr(err, conflictFields)
+	}
+	lines, err := s.Queries.ListTransferOrderLines(ctx, to.ID)
+	if err != nil {
+		return TransferOrder{}, err
+	}
+	moves, err := s.Queries.ListTransferOrderMoves(ctx, to.ID)
+	if err != nil {
+		return TransferOrder{}, err
+	}
+	out := toOrder(to, lines)
+	out.Moves = make([]TransferMove, 0, len(moves))
+	for _, m := range moves {
+		out.Moves = append(out.Moves, TransferMove{
+			MoveID: m.MoveID.String(), Leg: m.Leg, LineNo: m.LineNo, Qty: qty.String(qty.FromNumeric(m.Qty)),
+			FromLocationID: store.OptUUID(m.FromLocationID), ToLocationID: store.OptUUID(m.ToLocationID), LotCode: m.LotCode.String,
+			SerialNo: m.SerialNo.String, ActorUserID: store.OptUUID(m.ActorUserID), Ts: m.Ts.Time,
+		})
+	}
+	return out, nil
+}
+
+// ListTransferOrders lists order headers. Discrepancy keeps orders closed
+// with stock written off in transit.
+func (s TransferService) ListTransferOrders(ctx context.Context, f TransferFilter) ([]TransferOrder, error) {
+	p := sqlcgen.ListTransferOrdersParams{Status: f.Status, Discrepancy: f.Discrepancy, PageLimit: f.Limit, PageOffset: f.Offset}
+	if f.FromWarehouseID != "" {
+		id, err := store.ParseUUID("from_warehouse_id", f.FromWarehouseID)
+		if err != nil {
+			return nil, err
+		}
+		p.FromWarehouseID = store.UUID(id)
+	}
+	if f.ToWarehouseID != "" {
+		id, err := store.ParseUUID("to_warehouse_id", f.ToWarehouseID)
+		if err != nil {
+			return nil, err
+		}
+		p.ToWarehouseID = store.UUID(id)
+	}
+	rows, err := s.Queries.ListTransferOrders(ctx, p)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]TransferOrder, 0, len(rows))
+	for _, r := range rows {
+		out = append(out, toOrder(r, nil))
+	}
+	return out, nil
+}
+
+// Ship posts the outbound leg: a transfer from each source bin into the
+// destination's in-transit location. Lines may ship in several goes up to
+// their ordered quantity.
+func (s TransferService) Ship(ctx context.Context, id uuid.UUID, req ShipRequest, actor uuid.UUID, endpoint, idemKey string) (LegResponse, error) {
+	reqHash := idempotency.Hash([]any{id.String(), req})
+	var prev LegResponse
+	if found, err := idempotency.Replay(ctx, s.Queries, endpoint, idemKey, reqHash, &prev); err != nil || found {
+		return prev, err
+	}
+	if len(req.Lines) == 0 {
+		return LegResponse{}, fmt.Errorf("%w: lines are required", ErrInvalid)
+	}
+
+	tx, err := s.DB.Begin(ctx)
+	if err != nil {
+		return LegResponse{}, err
+	}
+	defer tx.Rollback(ctx)
+	q := s.Queries.WithTx(tx)
+
+	to, err := q.LockTransferOrder(ctx, store.UUID(id))
+	if err != nil {
+		return LegResponse{}, store.MapErr(err, conflictFields)
+	}
+	if to.Status != StatusOpen && to.Status != StatusInTransit {
+		return LegResponse{}, fmt.Errorf("%w: transfer order is %s", ErrInvalid, to.Status)
+	}
+	resp := LegResponse{Moves: []TransferMove{}}
+	for i, l := range req.Lines {
+		line, err := q.GetTransferOrderLineByNo(ctx, sqlcgen.GetTransferOrderLineByNoParams{OrderID: to.ID, LineNo: l.LineNo})
+		if err != nil {
+			return LegResponse{}, fmt.Errorf("line %d: %w", i+1, store.MapErr(err, conflictFields))
+		}
+		if err := binOf(ctx, q, "location_id", l.LocationID, to.FromWarehouseID); err != nil {
+			return LegResponse{}, fmt.Errorf("line %d: %w", i+1, err)
+		}
+		amount, err := s.legQty(ctx, q, line, l)
+		if err != nil {
+			return LegResponse{}, fmt.Errorf("line %d: %w", i+1, err)
+		}
+		open := qty.Sub(qty.FromNumeric(line.Qty), qty.FromNumeric(line.QtyShipped))
+		if amount.Cmp(open) > 0 {
+			return LegResponse{}, fmt.Errorf("%w: line %d: only %s left to ship", ErrInvalid, l.LineNo, qty.String(open))
+		}
+		move, _, err := s.Stock.PostMove(ctx, q, stocksvc.MoveTransfer, stocksvc.MoveRequest{
+			ItemID: line.ItemID.String(), Qty: qty.String(amount), FromLocationID: l.LocationID, ToLocationID: to.TransitLocationID.String(),
+			ReasonCode: "TRANSIT", LotCode: l.LotCode, SerialNo: l.SerialNo, RefType: stocksvc.RefTransfer, RefID: to.ID.String(),
+		}, actor)
+		if err != nil {
+			return LegResponse{}, fmt.Errorf("line %d: %w", i+1, err)
+		}
+		if err := book(ctx, q, to, line, move, LegShip, sqlcgen.AddTransferLineQtyParams{Shipped: qty.ToNumeric(amount)}); err != nil {
+			return LegResponse{}, err
+		}
+		resp.Moves = append(resp.Moves, legMove(move, LegShip, line.LineNo, l.LotCode, l.SerialNo))
+	}
+	if to.Status == StatusOpen {
+		if to, err = q.SetTransferOrderStatus(ctx, sqlcgen.SetTransferOrderStatusParams{ID: to.ID, Status: StatusInTransit}); err != nil {
+			return LegResponse{}, err
+		}
+	}
+	return s.finish(ctx, tx, q, to, resp, actor, "transfer.shipped", endpoint, idemKey, reqHash)
+}
+
+// Receive posts the inbound leg: a transfer out of transit into destination
+// bins. Each lot and serial can only be received up to what this order
+// shipped of it; the order is received once every line is complete.
+func (s TransferService) Receive(ctx context.Context, id uuid.UUID, req ReceiveRequest, actor uuid.UUID, endpoint, idemKey string) (LegResponse, error) {
+	reqHash := idempotency.Hash([]any{id.String(), req})
+	var prev LegResponse
+	if found, err := idempotency.Replay(ctx, s.Queries, endpoint, idemKey, reqHash, &prev); err != nil || found {
+		return prev, err
+	}
+	if len(req.Lines) == 0 {
+		return LegResponse{}, fmt.Errorf("%w: lines are required", ErrInvalid)
+	}
+
+	tx, err := s.DB.Begin(ctx)
+	if err != nil {
+		return LegResponse{}, err
+	}
+	defer tx.Rollback(ctx)
+	q := s.Queries.WithTx(tx)
+
+	to, err := q.LockTransferOrder(ctx, store.UUID(id))
+	if err != nil {
+		return LegResponse{}, store.MapErr(err, conflictFields)
+	}
+	if to.Status != StatusInTransit {
+		return LegResponse{}, fmt.Errorf("%w: transfer order is %s", ErrInvalid, to.Status)
+	}
+	rows, err := q.ListTransferInTransit(ctx, to.ID)
+	if err != nil {
+		return LegResponse{}, err
+	}
+	pending := inTransit(rows)
+	resp := LegResponse{Moves: []TransferMove{}}
+	for i, l := range req.Lines {
+		line, err := q.GetTransferOrderLineByNo(ctx, sqlcgen.GetTransferOrderLineByNoParams{OrderID: to.ID, LineNo: l.LineNo})
+		if err != nil {
+			return LegResponse{}, fmt.Errorf("line %d: %w", i+1, store.MapErr(err, conflictFields))
+		}
+		if err := binOf(ctx, q, "location_id", l.LocationID, to.ToWarehouseID); err != nil {
+			return LegResponse{}, fmt.Errorf("line %d: %w", i+1, err)
+		}
+		amount, err := s.legQty(ctx, q, line, l)
+		if err != nil {
+			return LegResponse{}, fmt.Errorf("line %d: %w", i+1, err)
+		}
+		key := transitKey{LineID: line.ID, LotCode: l.LotCode, SerialNo: l.SerialNo}
+		if err := take(pending, key, amount); err != nil {
+			return LegResponse{}, fmt.Errorf("line %d: %w", i+1, err)
+		}
+		move, _, err := s.Stock.PostMove(ctx, q, stocksvc.MoveTransfer, stocksvc.MoveRequest{
+			ItemID: line.ItemID.String(), Qty: qty.String(amount), FromLocationID: to.TransitLocationID.String(), ToLocationID: l.LocationID,
+			ReasonCode: "TRANSIT", LotCode: l.LotCode, SerialNo: l.SerialNo, RefType: stocksvc.RefTransfer, RefID: to.ID.String(),
+		}, actor)
+		if err != nil {
+			return LegResponse{}, fmt.Errorf("line %d: %w", i+1, err)
+		}
+		if err := book(ctx, q, to, line, move, LegReceive, sqlcgen.AddTransferLineQtyParams{Received: qty.ToNumeric(amount)}); err != nil {
+			return LegResponse{}, err
+		}
+		resp.Moves = append(resp.Moves, legMove(move, LegReceive, line.LineNo, l.LotCode, l.SerialNo))
+	}
+	lines, err := q.ListTransferOrderLines(ctx, to.ID)
+	if err != nil {
+		return LegResponse{}, err
+	}
+	if fullyReceived(lines) {
+		if to, err = q.SetTransferOrderStatus(ctx, sqlcgen.SetTransferOrderStatusParams{ID: to.ID, Status: StatusReceived}); err != nil {
+			return LegResponse{}, err
+		}
+	}
+	return s.finish(ctx, tx, q, to, resp, actor, "transfer.received", endpoint, idemKey, reqHash)
+}
+
+// Close ends an order that will receive nothing more. Stock still in transit
+// is issued out of the in-transit location as TRANSIT_LOSS and reported as
+// the line's qty_lost; quantities never shipped are simply not moved.
+func (s TransferService) Close(ctx context.Context, id uuid.UUID, req CloseRequest, actor uuid.UUID) (LegResponse, error) {
+	if req.Comment == "" {
+		return LegResponse{}, fmt.Errorf("%w: comment is required", ErrInvalid)
+	}
+	var out LegResponse
+	err := s.mutate(ctx, actor, "transfer.closed", func(q *sqlcgen.Queries) (string, any, error) {
+		to, err := q.LockTransferOrder(ctx, store.UUID(id))
+		if err != nil {
+			return "", nil, err
+		}
+		if to.Status != StatusInTransit && to.Status != StatusReceived {
+			return "", nil, fmt.Errorf("%w: transfer order is %s", ErrInvalid, to.Status)
+		}
+		rows, err := q.ListTransferInTransit(ctx, to.ID)
+		if err != nil {
+			return "", nil, err
+		}
+		lines, err := q.ListTransferOrderLines(ctx, to.ID)
+		if err != nil {
+			return "", nil, err
+		}
+		byID := make(map[pgtype.UUID]sqlcgen.TransferOrderLine, len(lines))
+		for _, l := range lines {
+			byID[l.ID] = l
+		}
+		out.Moves = []TransferMove{}
+		for _, r := range rows {
+			line := byID[r.LineID]
+			move, _, err := s.Stock.PostMove(ctx, q, stocksvc.MoveIssue, stocksvc.MoveRequest{
+				ItemID: line.ItemID.String(), Qty: qty.String(qty.FromNumeric(r.Qty)), FromLocationID: to.TransitLocationID.String(),
+				ReasonCode: "TRANSIT_LOSS", LotCode: r.LotCode.String, SerialNo: r.SerialNo.String, Comment: req.Comment,
+				RefType: stocksvc.RefTransfer, RefID: to.ID.String(),
+			}, actor)
+			if err != nil {
+				return "", nil, fmt.Errorf("line %d: %w", line.LineNo, err)
+			}
+			if err := book(ctx, q, to, line, move, LegLoss, sqlcgen.AddTransferLineQtyParams{Lost: r.Qty}); err != nil {
+				return "", nil, err
+			}
+			out.Moves = append(out.Moves, legMove(move, LegLoss, line.LineNo, r.LotCode.String, r.SerialNo.String))
+		}
+		if to, err = q.SetTransferOrderStatus(ctx, sqlcgen.SetTransferOrderStatusParams{ID: to.ID, Status: StatusClosed}); err != nil {
+			return "", nil, err
+		}
+		if lines, err = q.ListTransferOrderLines(ctx, to.ID); err != nil {
+			return "", nil, err
+		}
+		out.Order = toOrder(to, lines)
+		return out.Order.ID, out, nil
+	})
+	return out, err
+}
+
+// CancelTransferOrder drops an order nothing has shipped on yet.
+func (s TransferService) CancelTransferOrder(ctx context.Context, id uuid.UUID, actor uuid.UUID) (TransferOrder, error) {
+	var out TransferOrder
+	err := s.mutate(ctx, actor, "transfer.cancelled", func(q *sqlcgen.Queries) (string, any, error) {
+		to, err := q.LockTransferOrder(ctx, store.UUID(id))
+		if err != nil {
+			return "", nil, err
+		}
+		if to.Status != StatusOpen {
+			return "", nil, fmt.Errorf("%w: transfer order is %s", ErrInvalid, to.Status)
+		}
+		if to, err = q.SetTransferOrderStatus(ctx, sqlcgen.SetTransferOrderStatusParams{ID: to.ID, Status: StatusCancelled}); err != nil {
+			return "", nil, err
+		}
+		out = toOrder(to, nil)
+		return out.ID, out, nil
+	})
+	return out, err
+}
+
+// legQty converts a leg line into the item's base unit.
+func (s TransferService) legQty(ctx context.Context, q *sqlcgen.Queries, line sqlcgen.TransferOrderLine, l LegLine) (*big.Rat, error) {
+	amount, err := s.Stock.BaseQty(ctx, q, uuid.UUID(line.ItemID.Bytes), l.Qty, l.Uom)
+	if err != nil {
+		return nil, err
+	}
+	if amount.Sign() <= 0 {
+		return nil, fmt.Errorf("%w: qty must be positive", ErrInvalid)
+	}
+	return amount, nil
+}
+
+// finish records the leg's event and audit entry, remembers the response
+// for the idempotency key and commits.
+func (s TransferService) finish(ctx context.Context, tx pgx.Tx, q *sqlcgen.Queries, to sqlcgen.TransferOrder, resp LegResponse, actor uuid.UUID, topic, endpoint, idemKey, reqHash string) (LegResponse, error) {
+	lines, err := q.ListTransferOrderLines(ctx, to.ID)
+	if err != nil {
+		return LegResponse{}, err
+	}
+	resp.Order = toOrder(to, lines)
+	payload, _ := json.Marshal(resp)
+	if err := store.Record(ctx, q, actor, topic, "transfer_orders", to.ID.String(), payload); err != nil {
+		return LegResponse{}, err
+	}
+	if err := idempotency.Remember(ctx, q, endpoint, idemKey, store.UUID(actor), reqHash, resp); err != nil {
+		return LegResponse{}, err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return LegResponse{}, err
+	}
+	return resp, nil
+}
+
+// mutate runs fn in a transaction with its outbox event and audit entry
+// on transfer_orders; see store.Mutate.
+func (s TransferService) mutate(ctx context.Context, actor uuid.UUID, topic string, fn func(q *sqlcgen.Queries) (string, any, error)) error {
+	return store.Mutate(ctx, s.DB, s.Queries, actor, topic, "transfer_orders", conflictFields, fn)
+}
+
+// book links a posted move to the order and adds its quantity to the line.
+func book(ctx context.Context, q *sqlcgen.Queries, to sqlcgen.TransferOrder, line sqlcgen.TransferOrderLine, move sqlcgen.StockLedger, leg string, add sqlcgen.AddTransferLineQtyParams) error {
+	if err := q.InsertTransferOrderMove(ctx, sqlcgen.InsertTransferOrderMoveParams{MoveID: move.MoveID, OrderID: to.ID, LineID: line.ID, Leg: leg}); err != nil {
+		return err
+	}
+	zero := qty.ToNumeric(qty.Zero())
+	for _, n := range []*pgtype.Numeric{&add.Shipped, &add.Received, &add.Lost} {
+		if !n.Valid {
+			*n = zero
+		}
+	}
+	add.ID = line.ID
+	_, err := q.AddTransferLineQty(ctx, add)
+	return err
+}
+
+// binOf checks that a leg's bin is an active location of the warehouse and
+// not an in-transit one.
+func binOf(ctx context.Context, q *sqlcgen.Queries, field, v string, warehouseID pgtype.UUID) error {
+	id, err := store.ParseUUID(field, v)
+	if err != nil {
+		return err
+	}
+	loc, err := q.GetLocation(ctx, store.UUID(id))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return fmt.Errorf("%w: unknown %s", ErrInvalid, field)
+	}
+	if err != nil {
+		return err
+	}
+	if !loc.Active || loc.Type == TransitLocationType || loc.WarehouseID != warehouseID {
+		return fmt.Errorf("%w: %s must be an active bin of warehouse %s", ErrInvalid, field, warehouseID.String())
+	}
+	return nil
+}
+
+// transitKey is what receipts are matched on: the order line and the exact
+// lot and serial that were shipped.
+type transitKey struct {
+	LineID   pgtype.UUID
+	LotCode  string
+	SerialNo string
+}
+
+func inTransit(rows []sqlcgen.ListTransferInTransitRow) map[transitKey]*big.Rat {
+	out := make(map[transitKey]*big.Rat, len(rows))
+	for _, r := range rows {
+		k := transitKey{LineID: r.LineID, LotCode: r.LotCode.String, SerialNo: r.SerialNo.String}
+		out[k] = qty.FromNumeric(r.Qty)
+	}
+	return out
+}
+
+// take consumes amount from what is in transit under key, so several
+// receipt lines in one request cannot receive the same stock twice.
+func take(pending map[transitKey]*big.Rat, key transitKey, amount *big.Rat) error {
+	have, ok := pending[key]
+	if !ok {
+		have = qty.Zero()
+	}
+	if amount.Cmp(have) > 0 {
+		what := "stock"
+		switch {
+		case key.SerialNo != "":
+			what = "serial " + key.SerialNo
+		case key.LotCode != "":
+			what = "lot " + key.LotCode
+		}
+		return fmt.Errorf("%w: only %s of %s in transit on this line", ErrInvalid, qty.String(have), what)
+	}
+	pending[key] = qty.Sub(have, amount)
+	return nil
+}
+
+// lineInTransit is shipped minus received minus written off.
+func lineInTransit(l sqlcgen.TransferOrderLine) *big.Rat {
+	return qty.Sub(qty.FromNumeric(l.QtyShipped), qty.Add(qty.FromNumeric(l.QtyReceived), qty.FromNumeric(l.QtyLost)))
+}
+
+func fullyReceived(lines []sqlcgen.TransferOrderLine) bool {
+	for _, l := range lines {
+		if qty.FromNumeric(l.QtyReceived).Cmp(qty.FromNumeric(l.Qty)) < 0 {
+			return false
+		}
+	}
+	return true
+}
+
+func legMove(m sqlcgen.StockLedger, leg string, lineNo int32, lotCode, serialNo string) TransferMove {
+	return TransferMove{
+		MoveID: m.MoveID.String(), Leg: leg, LineNo: lineNo, Qty: qty.String(qty.FromNumeric(m.Qty)),
+		FromLocationID: store.OptUUID(m.FromLocationID), ToLocationID: store.OptUUID(m.ToLocationID), LotCode: lotCode, SerialNo: serialNo,
+		ActorUserID: store.OptUUID(m.ActorUserID), Ts: m.Ts.Time,
+	}
+}
+
+// toOrder maps an order; lines is nil for list views.
+func toOrder(to sqlcgen.TransferOrder, lines []sqlcgen.TransferOrderLine) TransferOrder {
+	out := TransferOrder{
+		ID: to.ID.String(), TransferNo: to.TransferNo, FromWarehouseID: to.FromWarehouseID.String(), ToWarehouseID: to.ToWarehouseID.String(),
+		TransitLocationID: to.TransitLocationID.String(), Status: to.Status, Comment: to.Comment.String, CreatedAt: to.CreatedAt.Time, UpdatedAt: to.UpdatedAt.Time,
+	}
+	for _, l := range lines {
+		lost := qty.FromNumeric(l.QtyLost)
+		if lost.Sign() > 0 {
+			out.HasDiscrepancy = true
+		}
+		out.Lines = append(out.Lines, TransferLine{
+			LineNo: l.LineNo, ItemID: l.ItemID.String(), Qty: qty.String(qty.FromNumeric(l.Qty)), QtyShipped: qty.String(qty.FromNumeric(l.QtyShipped)),
+			QtyReceived: qty.String(qty.FromNumeric(l.QtyReceived)), QtyLost: qty.String(lost), QtyInTransit: qty.String(lineInTransit(l)),
+		})
+	}
+	return out
+}
@@ -0,0 +1,71 @@
+package service
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+
+	"erpwms/backend-go/internal/common/qty"
+	"erpwms/backend-go/internal/common/store"
+	"erpwms/backend-go/internal/db/sqlcgen"
+	"github.com/google/uuid"
+)
+
+func line(ordered, shipped, received, lost int64) sqlcgen.TransferOrderLine {
+	return sqlcgen.TransferOrderLine{
+		Qty: qty.ToNumeric(big.NewRat(ordered, 1)), QtyShipped: qty.ToNumeric(big.NewRat(shipped, 1)),
+		QtyReceived: qty.ToNumeric(big.NewRat(received, 1)), QtyLost: qty.ToNumeric(big.NewRat(lost, 1)),
+	}
+}
+
+func TestLineInTransit(t *testing.T) {
+	if got := lineInTransit(line(10, 8, 5, 1)); got.Cmp(big.NewRat(2, 1)) != 0 {
+		t.Fatalf("got %s", got.RatString())
+	}
+}
+
+func TestFullyReceived(t *testing.T) {
+	if !fullyReceived([]sqlcgen.TransferOrderLine{line(5, 5, 5, 0), line(2, 2, 2, 0)}) {
+		t.Fatal("every line received in full")
+	}
+	// Shipping short does not complete the order; only closing does.
+	if fullyReceived([]sqlcgen.TransferOrderLine{line(5, 5, 5, 0), line(2, 1, 1, 0)}) {
+		t.Fatal("line 2 still has 1 to receive")
+	}
+}
+
+func TestTakeMatchesLotAndLine(t *testing.T) {
+	l1, l2 := store.UUID(uuid.New()), store.UUID(uuid.New())
+	pending := inTransit([]sqlcgen.ListTransferInTransitRow{
+		{LineID: l1, LotCode: store.Text("A"), Qty: qty.ToNumeric(big.NewRat(5, 1))},
+		{LineID: l1, LotCode: store.Text("B"), Qty: qty.ToNumeric(big.NewRat(2, 1))},
+		{LineID: l2, Qty: qty.ToNumeric(big.NewRat(3, 1))},
+	})
+	if err := take(pending, transitKey{LineID: l1, LotCode: "A"}, big.NewRat(4, 1)); err != nil {
+		t.Fatal(err)
+	}
+	// Only 1 of lot A is left after the first receipt in the same request.
+	if err := take(pending, transitKey{LineID: l1, LotCode: "A"}, big.NewRat(2, 1)); !errors.Is(err, ErrInvalid) {
+		t.Fatalf("got %v", err)
+	}
+	if err := take(pending, transitKey{LineID: l1, LotCode: "C"}, big.NewRat(1, 1)); !errors.Is(err, ErrInvalid) {
+		t.Fatalf("lot never shipped: got %v", err)
+	}
+	if err := take(pending, transitKey{LineID: l2, LotCode: "A"}, big.NewRat(1, 1)); !errors.Is(err, ErrInvalid) {
+		t.Fatalf("lot of another line: got %v", err)
+	}
+	if err := take(pending, transitKey{LineID: l2}, big.NewRat(3, 1)); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestToOrderFlagsDiscrepancy(t *testing.T) {
+	to := sqlcgen.TransferOrder{Status: StatusClosed}
+	if got := toOrder(to, []sqlcgen.TransferOrderLine{line(5, 5, 5, 0)}); got.HasDiscrepancy {
+		t.Fatal("nothing lost")
+	}
+	got := toOrder(to, []sqlcgen.TransferOrderLine{line(5, 5, 5, 0), line(4, 4, 3, 1)})
+	if !got.HasDiscrepancy || got.Lines[1].QtyLost != "1" || got.Lines[1].QtyInTransit != "0" {
+		t.Fatalf("got %+v", got)
+	}
+}